@@ -9,6 +9,7 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/moorara/cherry/internal/action"
 	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
 	"github.com/moorara/cherry/pkg/cui"
 )
 
@@ -82,6 +83,7 @@ func (c *build) Run(args []string) int {
 	}
 
 	ctx := context.Background()
+	ctx = step.ContextWithUI(ctx, c.ui)
 	ctx = action.ContextWithSpec(ctx, c.Spec)
 	ctx, cancel := context.WithTimeout(ctx, buildTimeout)
 	defer cancel()
@@ -89,12 +91,14 @@ func (c *build) Run(args []string) int {
 	// Try finding any possible failure before running the command
 	if err := c.action.Dry(ctx); err != nil {
 		c.ui.Errorf("%s", err)
+		summarize(c.ui, "build", err)
 		return buildDryErr
 	}
 
 	// Running the command
 	if err := c.action.Run(ctx); err != nil {
 		c.ui.Errorf("%s", err)
+		defer summarize(c.ui, "build", err)
 
 		// Try reverting back any side effect in case of failure
 		if err := c.action.Revert(ctx); err != nil {
@@ -105,5 +109,7 @@ func (c *build) Run(args []string) int {
 		return buildRunErr
 	}
 
+	summarize(c.ui, "build", nil)
+
 	return 0
 }
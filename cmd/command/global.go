@@ -0,0 +1,94 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/moorara/cherry/pkg/cui"
+)
+
+// GlobalFlags are the flags that can be used with any command.
+type GlobalFlags struct {
+	Output string
+	// Verbosity is 1 for -v and 2 for -vv.
+	Verbosity int
+	Quiet     bool
+}
+
+// Level returns the verbosity level of user interface for the flags.
+func (f GlobalFlags) Level() cui.Level {
+	switch {
+	case f.Quiet:
+		return cui.Quiet
+	case f.Verbosity >= 2:
+		return cui.Trace
+	case f.Verbosity == 1:
+		return cui.Verbose
+	default:
+		return cui.Normal
+	}
+}
+
+// ParseGlobalFlags extracts the global flags from command-line arguments and returns the rest of arguments.
+// Global flags can be used either before or after the command name (i.e. cherry -output json release).
+// A single -v flag without any command is kept for printing the version.
+func ParseGlobalFlags(args []string) (GlobalFlags, []string, error) {
+	var flags GlobalFlags
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Arguments after -- are not flags
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+
+		switch name {
+		case "output":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return GlobalFlags{}, nil, fmt.Errorf("flag needs an argument: -%s", name)
+				}
+				i++
+				value = args[i]
+			}
+			flags.Output = value
+
+		case "v":
+			flags.Verbosity++
+
+		case "vv":
+			flags.Verbosity += 2
+
+		case "quiet", "q":
+			flags.Quiet = true
+
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if len(rest) == 0 && len(args) == 1 && args[0] == "-v" {
+		return GlobalFlags{}, args, nil
+	}
+
+	if flags.Quiet && flags.Verbosity > 0 {
+		return GlobalFlags{}, nil, errors.New("-quiet cannot be used with -v or -vv")
+	}
+
+	return flags, rest, nil
+}
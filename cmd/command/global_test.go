@@ -0,0 +1,110 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+		expectedFlags GlobalFlags
+		expectedArgs  []string
+	}{
+		{
+			name:          "NoFlag",
+			args:          []string{"release", "-minor"},
+			expectedFlags: GlobalFlags{},
+			expectedArgs:  []string{"release", "-minor"},
+		},
+		{
+			name:          "MissingValue",
+			args:          []string{"release", "-output"},
+			expectedError: "flag needs an argument: -output",
+		},
+		{
+			name:          "BeforeCommand",
+			args:          []string{"-output", "json", "release", "-minor"},
+			expectedFlags: GlobalFlags{Output: "json"},
+			expectedArgs:  []string{"release", "-minor"},
+		},
+		{
+			name:          "AfterCommand",
+			args:          []string{"build", "--output=json", "-cross-compile"},
+			expectedFlags: GlobalFlags{Output: "json"},
+			expectedArgs:  []string{"build", "-cross-compile"},
+		},
+		{
+			name:          "Verbose",
+			args:          []string{"-v", "release"},
+			expectedFlags: GlobalFlags{Verbosity: 1},
+			expectedArgs:  []string{"release"},
+		},
+		{
+			name:          "VeryVerbose",
+			args:          []string{"release", "-vv", "-minor"},
+			expectedFlags: GlobalFlags{Verbosity: 2},
+			expectedArgs:  []string{"release", "-minor"},
+		},
+		{
+			name:          "Quiet",
+			args:          []string{"update", "-quiet"},
+			expectedFlags: GlobalFlags{Quiet: true},
+			expectedArgs:  []string{"update"},
+		},
+		{
+			name:          "QuietAndVerbose",
+			args:          []string{"update", "-quiet", "-v"},
+			expectedError: "-quiet cannot be used with -v or -vv",
+		},
+		{
+			name:          "Version",
+			args:          []string{"-v"},
+			expectedFlags: GlobalFlags{},
+			expectedArgs:  []string{"-v"},
+		},
+		{
+			name:          "AfterDoubleDash",
+			args:          []string{"update", "--", "-output", "json"},
+			expectedFlags: GlobalFlags{},
+			expectedArgs:  []string{"update", "--", "-output", "json"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			flags, args, err := ParseGlobalFlags(tc.args)
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedFlags, flags)
+				assert.Equal(t, tc.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestGlobalFlagsLevel(t *testing.T) {
+	tests := []struct {
+		name          string
+		flags         GlobalFlags
+		expectedLevel cui.Level
+	}{
+		{"Default", GlobalFlags{}, cui.Normal},
+		{"Quiet", GlobalFlags{Quiet: true}, cui.Quiet},
+		{"Verbose", GlobalFlags{Verbosity: 1}, cui.Verbose},
+		{"Trace", GlobalFlags{Verbosity: 2}, cui.Trace},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedLevel, tc.flags.Level())
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package command
 
-import "context"
+import (
+	"context"
+
+	"github.com/moorara/cherry/pkg/cui"
+)
 
 type mockCUI struct {
 	OutputfInFormat string
@@ -11,6 +15,18 @@ type mockCUI struct {
 	WarnfOutVals    []interface{}
 	ErrorfInFormat  string
 	ErrorfOutVals   []interface{}
+	DebugfInFormat  string
+	DebugfOutVals   []interface{}
+	TracefInFormat  string
+	TracefOutVals   []interface{}
+	EventInEvents   []cui.Event
+
+	AskInQuery       string
+	AskOutAnswer     string
+	AskOutError      error
+	ConfirmInQuery   string
+	ConfirmOutResult bool
+	ConfirmOutError  error
 }
 
 func (m *mockCUI) Outputf(format string, vals ...interface{}) {
@@ -33,6 +49,30 @@ func (m *mockCUI) Errorf(format string, vals ...interface{}) {
 	m.ErrorfOutVals = vals
 }
 
+func (m *mockCUI) Debugf(format string, vals ...interface{}) {
+	m.DebugfInFormat = format
+	m.DebugfOutVals = vals
+}
+
+func (m *mockCUI) Tracef(format string, vals ...interface{}) {
+	m.TracefInFormat = format
+	m.TracefOutVals = vals
+}
+
+func (m *mockCUI) Event(e cui.Event) {
+	m.EventInEvents = append(m.EventInEvents, e)
+}
+
+func (m *mockCUI) Ask(query string) (string, error) {
+	m.AskInQuery = query
+	return m.AskOutAnswer, m.AskOutError
+}
+
+func (m *mockCUI) Confirm(query string) (bool, error) {
+	m.ConfirmInQuery = query
+	return m.ConfirmOutResult, m.ConfirmOutError
+}
+
 type mockAction struct {
 	DryInCtx       context.Context
 	DryOutError    error
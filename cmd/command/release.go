@@ -2,11 +2,13 @@ package command
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/moorara/cherry/internal/action"
 	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
 	"github.com/moorara/cherry/pkg/cui"
 	"github.com/moorara/cherry/pkg/semver"
 )
@@ -16,6 +18,7 @@ const (
 	releaseDryErr    = 32
 	releaseRunErr    = 33
 	releaseRevertErr = 34
+	releaseAbortErr  = 35
 
 	releaseTimeout = 10 * time.Minute
 
@@ -31,6 +34,12 @@ const (
 		-comment:  add a comment for the release
 		-model     release model: master, branch                        (default: master)
 		-build:    build the artifacts and include them in the release  (default: false)
+		-provider: release provider: github, gitlab, gitea              (default: from git remote url)
+		-sign:     sign the release commits and tag                     (default: false)
+		-yes:      do not ask for confirmation before releasing         (default: false)
+		-plan:     only print the release plan without releasing        (default: false)
+		-module:   release a module from the modules in spec
+		-changed:  release all modules changed since their latest tags  (default: false)
 	
 	Examples:
 
@@ -41,22 +50,37 @@ const (
 		cherry release -major
 		cherry release -major -build
 		cherry release -comment "release comment"
+		cherry release -provider gitlab
+		cherry release -provider gitea
+		cherry release -sign
+		cherry release -yes
+		cherry release -plan
+		cherry release -module sdk
+		cherry release -changed
 	`
 )
 
 // release is the release command.
 type release struct {
-	ui     cui.CUI
-	Spec   spec.Spec
-	action action.Action
+	ui             cui.CUI
+	Spec           spec.Spec
+	action         action.Action
+	moduleAction   func(spec.Module) action.Action
+	changedModules func(context.Context, spec.Spec) ([]spec.Module, error)
 }
 
 // NewRelease creates a new release command.
-func NewRelease(ui cui.CUI, workDir, githubToken string, s spec.Spec) (cli.Command, error) {
+func NewRelease(ui cui.CUI, workDir string, config step.ProviderConfig, s spec.Spec) (cli.Command, error) {
 	return &release{
 		ui:     ui,
 		Spec:   s,
-		action: action.NewRelease(ui, workDir, githubToken, s),
+		action: action.NewRelease(ui, workDir, config, s),
+		moduleAction: func(m spec.Module) action.Action {
+			return action.NewModuleRelease(ui, workDir, config, s, m)
+		},
+		changedModules: func(ctx context.Context, s spec.Spec) ([]spec.Module, error) {
+			return action.ChangedModules(ctx, workDir, s)
+		},
 	}, nil
 }
 
@@ -74,13 +98,18 @@ func (c *release) Help() string {
 func (c *release) Run(args []string) int {
 	var segment semver.Segment
 	var patch, minor, major bool
-	var comment string
+	var comment, module string
+	var yes, plan, changed bool
 
 	fs := c.Spec.Release.FlagSet()
 	fs.BoolVar(&patch, "patch", true, "")
 	fs.BoolVar(&minor, "minor", false, "")
 	fs.BoolVar(&major, "major", false, "")
 	fs.StringVar(&comment, "comment", "", "")
+	fs.BoolVar(&yes, "yes", false, "")
+	fs.BoolVar(&plan, "plan", false, "")
+	fs.StringVar(&module, "module", "", "")
+	fs.BoolVar(&changed, "changed", false, "")
 	fs.Usage = func() {
 		c.ui.Outputf(c.Help())
 	}
@@ -105,29 +134,102 @@ func (c *release) Run(args []string) int {
 	}
 
 	ctx := context.Background()
+	ctx = step.ContextWithUI(ctx, c.ui)
 	ctx = action.ContextWithSpec(ctx, c.Spec)
 	ctx = action.ContextWithReleaseParams(ctx, segment, comment)
+	if plan {
+		ctx = action.ContextWithReleasePlan(ctx)
+	}
+
+	var modules []spec.Module
+
+	if changed {
+		var err error
+		if modules, err = c.changedModules(ctx, c.Spec); err != nil {
+			c.ui.Errorf("%s", err)
+			summarize(c.ui, "release", err)
+			return releaseDryErr
+		}
+
+		if len(modules) == 0 {
+			c.ui.Infof("No module has changed since its latest release")
+			summarize(c.ui, "release", nil)
+			return 0
+		}
+	} else if module != "" {
+		m, ok := c.Spec.Module(module)
+		if !ok {
+			c.ui.Errorf("unknown module: %s", module)
+			return releaseFlagErr
+		}
+
+		modules = []spec.Module{m}
+	}
+
+	// Release the repository if no module is specified
+	if len(modules) == 0 {
+		code, err := c.release(ctx, c.action, plan, yes)
+		summarize(c.ui, "release", err)
+		return code
+	}
+
+	for _, m := range modules {
+		c.ui.Infof("📦 Releasing module %s ...", m.Name)
+
+		if code, err := c.release(ctx, c.moduleAction(m), plan, yes); err != nil {
+			summarize(c.ui, "release", err)
+			return code
+		}
+	}
+
+	summarize(c.ui, "release", nil)
+
+	return 0
+}
+
+// release runs a release action and returns the exit code along with the error if any.
+func (c *release) release(ctx context.Context, a action.Action, plan, yes bool) (int, error) {
 	ctx, cancel := context.WithTimeout(ctx, releaseTimeout)
 	defer cancel()
 
 	// Try finding any possible failure before running the command
-	if err := c.action.Dry(ctx); err != nil {
+	if err := a.Dry(ctx); err != nil {
 		c.ui.Errorf("%s", err)
-		return releaseDryErr
+		return releaseDryErr, err
+	}
+
+	// The plan is printed by the dry run
+	if plan {
+		return 0, nil
+	}
+
+	// Pushing to master, changing the branch protection, and publishing the release cannot be undone
+	if !yes {
+		ok, err := c.ui.Confirm("Do you want to proceed with the release?")
+		if err != nil {
+			c.ui.Errorf("%s", err)
+			return releaseAbortErr, err
+		}
+
+		if !ok {
+			err = errors.New("release aborted")
+			c.ui.Warnf("%s", err)
+			return releaseAbortErr, err
+		}
 	}
 
 	// Running the command
-	if err := c.action.Run(ctx); err != nil {
+	if err := a.Run(ctx); err != nil {
 		c.ui.Errorf("%s", err)
 
 		// Try reverting back any side effect in case of failure
-		if err := c.action.Revert(ctx); err != nil {
-			c.ui.Errorf("%s", err)
-			return releaseRevertErr
+		if rerr := a.Revert(ctx); rerr != nil {
+			c.ui.Errorf("%s", rerr)
+			return releaseRevertErr, err
 		}
 
-		return releaseRunErr
+		return releaseRunErr, err
 	}
 
-	return 0
+	return 0, nil
 }
@@ -1,11 +1,14 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/moorara/cherry/internal/action"
 	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
 	"github.com/moorara/cherry/pkg/cui"
 	"github.com/stretchr/testify/assert"
 )
@@ -15,29 +18,34 @@ func TestNewRelease(t *testing.T) {
 		name          string
 		ui            cui.CUI
 		workDir       string
-		githubToken   string
+		config        step.ProviderConfig
 		spec          spec.Spec
 		expectedError error
 	}{
 		{
-			name:        "NoGitHubToken",
-			ui:          &mockCUI{},
-			workDir:     ".",
-			githubToken: "",
-			spec:        spec.Spec{},
+			name:    "NoToken",
+			ui:      &mockCUI{},
+			workDir: ".",
+			config:  step.ProviderConfig{},
+			spec:    spec.Spec{},
 		},
 		{
-			name:        "OK",
-			ui:          &mockCUI{},
-			workDir:     ".",
-			githubToken: "github-token",
-			spec:        spec.Spec{},
+			name:    "OK",
+			ui:      &mockCUI{},
+			workDir: ".",
+			config: step.ProviderConfig{
+				GitHubToken: "github-token",
+				GitLabToken: "gitlab-token",
+				GiteaURL:    "https://gitea.example.com",
+				GiteaToken:  "gitea-token",
+			},
+			spec: spec.Spec{},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			cmd, err := NewRelease(tc.ui, tc.workDir, tc.githubToken, tc.spec)
+			cmd, err := NewRelease(tc.ui, tc.workDir, tc.config, tc.spec)
 
 			if tc.expectedError == nil {
 				assert.NotNil(t, cmd)
@@ -93,10 +101,34 @@ func TestReleaseRun(t *testing.T) {
 			args:         []string{},
 			expectedExit: releaseDryErr,
 		},
+		{
+			name: "ConfirmFails",
+			cmd: &release{
+				ui: &mockCUI{
+					ConfirmOutError: errors.New("EOF"),
+				},
+				Spec:   spec.Spec{},
+				action: &mockAction{},
+			},
+			args:         []string{},
+			expectedExit: releaseAbortErr,
+		},
+		{
+			name: "Aborted",
+			cmd: &release{
+				ui: &mockCUI{
+					ConfirmOutResult: false,
+				},
+				Spec:   spec.Spec{},
+				action: &mockAction{},
+			},
+			args:         []string{},
+			expectedExit: releaseAbortErr,
+		},
 		{
 			name: "RunFails",
 			cmd: &release{
-				ui:   &mockCUI{},
+				ui:   &mockCUI{ConfirmOutResult: true},
 				Spec: spec.Spec{},
 				action: &mockAction{
 					RunOutError: errors.New("error on run: action"),
@@ -108,7 +140,7 @@ func TestReleaseRun(t *testing.T) {
 		{
 			name: "RevertFails",
 			cmd: &release{
-				ui:   &mockCUI{},
+				ui:   &mockCUI{ConfirmOutResult: true},
 				Spec: spec.Spec{},
 				action: &mockAction{
 					RunOutError:    errors.New("error on run: action"),
@@ -121,7 +153,7 @@ func TestReleaseRun(t *testing.T) {
 		{
 			name: "PatchSuccess",
 			cmd: &release{
-				ui:     &mockCUI{},
+				ui:     &mockCUI{ConfirmOutResult: true},
 				Spec:   spec.Spec{},
 				action: &mockAction{},
 			},
@@ -135,7 +167,7 @@ func TestReleaseRun(t *testing.T) {
 				Spec:   spec.Spec{},
 				action: &mockAction{},
 			},
-			args:         []string{"-minor"},
+			args:         []string{"-minor", "-yes"},
 			expectedExit: 0,
 		},
 		{
@@ -145,7 +177,117 @@ func TestReleaseRun(t *testing.T) {
 				Spec:   spec.Spec{},
 				action: &mockAction{},
 			},
-			args:         []string{"-major"},
+			args:         []string{"-major", "-yes"},
+			expectedExit: 0,
+		},
+		{
+			name: "PlanSuccess",
+			cmd: &release{
+				ui:   &mockCUI{},
+				Spec: spec.Spec{},
+				action: &mockAction{
+					RunOutError: errors.New("error on run: action"),
+				},
+			},
+			args:         []string{"-plan"},
+			expectedExit: 0,
+		},
+		{
+			name: "UnknownModule",
+			cmd: &release{
+				ui:   &mockCUI{},
+				Spec: spec.Spec{},
+			},
+			args:         []string{"-module", "sdk"},
+			expectedExit: releaseFlagErr,
+		},
+		{
+			name: "ModuleDryFails",
+			cmd: &release{
+				ui: &mockCUI{},
+				Spec: spec.Spec{
+					Modules: []spec.Module{{Name: "sdk", Dir: "sdk"}},
+				},
+				moduleAction: func(spec.Module) action.Action {
+					return &mockAction{
+						DryOutError: errors.New("error on dry: action"),
+					}
+				},
+			},
+			args:         []string{"-module", "sdk"},
+			expectedExit: releaseDryErr,
+		},
+		{
+			name: "ModuleSuccess",
+			cmd: &release{
+				ui: &mockCUI{},
+				Spec: spec.Spec{
+					Modules: []spec.Module{{Name: "sdk", Dir: "sdk"}},
+				},
+				moduleAction: func(spec.Module) action.Action {
+					return &mockAction{}
+				},
+			},
+			args:         []string{"-module", "sdk", "-yes"},
+			expectedExit: 0,
+		},
+		{
+			name: "ChangedModulesFails",
+			cmd: &release{
+				ui:   &mockCUI{},
+				Spec: spec.Spec{},
+				changedModules: func(context.Context, spec.Spec) ([]spec.Module, error) {
+					return nil, errors.New("git error")
+				},
+			},
+			args:         []string{"-changed"},
+			expectedExit: releaseDryErr,
+		},
+		{
+			name: "NoChangedModule",
+			cmd: &release{
+				ui:   &mockCUI{},
+				Spec: spec.Spec{},
+				changedModules: func(context.Context, spec.Spec) ([]spec.Module, error) {
+					return []spec.Module{}, nil
+				},
+			},
+			args:         []string{"-changed"},
+			expectedExit: 0,
+		},
+		{
+			name: "ChangedModuleRunFails",
+			cmd: &release{
+				ui:   &mockCUI{},
+				Spec: spec.Spec{},
+				moduleAction: func(m spec.Module) action.Action {
+					if m.Name == "tools" {
+						return &mockAction{
+							RunOutError: errors.New("error on run: action"),
+						}
+					}
+					return &mockAction{}
+				},
+				changedModules: func(context.Context, spec.Spec) ([]spec.Module, error) {
+					return []spec.Module{{Name: "sdk"}, {Name: "tools"}}, nil
+				},
+			},
+			args:         []string{"-changed", "-yes"},
+			expectedExit: releaseRunErr,
+		},
+		{
+			name: "ChangedModulesSuccess",
+			cmd: &release{
+				ui:   &mockCUI{},
+				Spec: spec.Spec{},
+				moduleAction: func(spec.Module) action.Action {
+					return &mockAction{}
+				},
+				changedModules: func(context.Context, spec.Spec) ([]spec.Module, error) {
+					return []spec.Module{{Name: "sdk"}, {Name: "tools"}}, nil
+				},
+			},
+			args:         []string{"-changed", "-yes"},
 			expectedExit: 0,
 		},
 	}
@@ -0,0 +1,19 @@
+package command
+
+import "github.com/moorara/cherry/pkg/cui"
+
+// summarize reports the final result of a command.
+func summarize(ui cui.CUI, command string, err error) {
+	e := cui.Event{
+		Type:    cui.EventSummary,
+		Command: command,
+		Status:  cui.StatusSucceeded,
+	}
+
+	if err != nil {
+		e.Status = cui.StatusFailed
+		e.Error = err.Error()
+	}
+
+	ui.Event(e)
+}
@@ -0,0 +1,49 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       string
+		err           error
+		expectedEvent cui.Event
+	}{
+		{
+			name:    "Succeeded",
+			command: "build",
+			err:     nil,
+			expectedEvent: cui.Event{
+				Type:    cui.EventSummary,
+				Command: "build",
+				Status:  cui.StatusSucceeded,
+			},
+		},
+		{
+			name:    "Failed",
+			command: "release",
+			err:     errors.New("error on run: action"),
+			expectedEvent: cui.Event{
+				Type:    cui.EventSummary,
+				Command: "release",
+				Status:  cui.StatusFailed,
+				Error:   "error on run: action",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := &mockCUI{}
+			summarize(ui, tc.command, tc.err)
+
+			assert.Equal(t, []cui.Event{tc.expectedEvent}, ui.EventInEvents)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/mitchellh/cli"
 	"github.com/moorara/cherry/internal/action"
+	"github.com/moorara/cherry/internal/step"
 	"github.com/moorara/cherry/pkg/cui"
 )
 
@@ -35,10 +36,10 @@ type update struct {
 }
 
 // NewUpdate creates a new update command.
-func NewUpdate(ui cui.CUI, githubToken string) (cli.Command, error) {
+func NewUpdate(ui cui.CUI, config step.ProviderConfig) (cli.Command, error) {
 	return &update{
 		ui:     ui,
-		action: action.NewUpdate(ui, githubToken),
+		action: action.NewUpdate(ui, config),
 	}, nil
 }
 
@@ -63,18 +64,21 @@ func (c *update) Run(args []string) int {
 		return updateFlagErr
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	ctx := step.ContextWithUI(context.Background(), c.ui)
+	ctx, cancel := context.WithTimeout(ctx, updateTimeout)
 	defer cancel()
 
 	// Try finding any possible failure before running the command
 	if err := c.action.Dry(ctx); err != nil {
 		c.ui.Errorf("%s", err)
+		summarize(c.ui, "update", err)
 		return updateDryErr
 	}
 
 	// Running the command
 	if err := c.action.Run(ctx); err != nil {
 		c.ui.Errorf("%s", err)
+		defer summarize(c.ui, "update", err)
 
 		// Try reverting back any side effect in case of failure
 		if err := c.action.Revert(ctx); err != nil {
@@ -85,5 +89,7 @@ func (c *update) Run(args []string) int {
 		return updateRunErr
 	}
 
+	summarize(c.ui, "update", nil)
+
 	return 0
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/moorara/cherry/internal/step"
 	"github.com/moorara/cherry/pkg/cui"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,19 +14,29 @@ func TestNewUpdate(t *testing.T) {
 	tests := []struct {
 		name          string
 		ui            cui.CUI
-		githubToken   string
+		config        step.ProviderConfig
 		expectedError error
 	}{
 		{
-			name:        "OK",
-			ui:          &mockCUI{},
-			githubToken: "github-token",
+			name: "OK",
+			ui:   &mockCUI{},
+			config: step.ProviderConfig{
+				GitHubToken: "github-token",
+			},
+		},
+		{
+			name: "Gitea",
+			ui:   &mockCUI{},
+			config: step.ProviderConfig{
+				GiteaURL:   "https://gitea.example.com",
+				GiteaToken: "gitea-token",
+			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			cmd, err := NewUpdate(tc.ui, tc.githubToken)
+			cmd, err := NewUpdate(tc.ui, tc.config)
 
 			if tc.expectedError == nil {
 				assert.NotNil(t, cmd)
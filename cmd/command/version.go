@@ -0,0 +1,176 @@
+package command
+
+import (
+	"context"
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/moorara/cherry/internal/action"
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/moorara/cherry/pkg/semver"
+)
+
+const (
+	versionFlagErr   = 51
+	versionDryErr    = 52
+	versionRunErr    = 53
+	versionRevertErr = 54
+
+	versionTimeout = time.Minute
+
+	versionSynopsis = `inspect and change the version`
+	versionHelp     = `
+	Use this command for inspecting and changing the version without releasing.
+	The version is printed in text or JSON format (see -output).
+
+	Subcommands:
+
+		show:      print the current version
+		next:      print the version the next release will have
+		bump:      update the version files with the version the next release will have
+		set:       update the version files with a given version
+		validate:  make sure the version files have a valid and the same version
+
+	Flags:
+
+		-patch:    bump the patch version                         (default: true)
+		-minor:    bump the minor version                         (default: false)
+		-major:    bump the major version                         (default: false)
+		-commit:   create a commit for the updated version files  (default: false)
+
+	Examples:
+
+		cherry version show
+		cherry version next -minor
+		cherry version bump -minor
+		cherry version bump -major -commit
+		cherry version set 1.2.3
+		cherry version set 1.2.3 -commit
+		cherry version validate
+		cherry -output json version show
+	`
+)
+
+// version is the version command.
+type version struct {
+	ui     cui.CUI
+	Spec   spec.Spec
+	action action.Action
+}
+
+// NewVersion creates a new version command.
+func NewVersion(ui cui.CUI, workDir string, s spec.Spec) (cli.Command, error) {
+	return &version{
+		ui:     ui,
+		Spec:   s,
+		action: action.NewVersion(ui, workDir, s),
+	}, nil
+}
+
+// Synopsis returns a short one-line synopsis of the command.
+func (c *version) Synopsis() string {
+	return versionSynopsis
+}
+
+// Help returns a long help text including usage, description, and list of flags for the command.
+func (c *version) Help() string {
+	return versionHelp
+}
+
+// Run runs the actual command with the given command-line arguments.
+func (c *version) Run(args []string) int {
+	p := action.VersionParams{
+		Operation: action.VersionShow,
+	}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		p.Operation, args = args[0], args[1:]
+	}
+
+	switch p.Operation {
+	case action.VersionShow, action.VersionNext, action.VersionBump, action.VersionSet, action.VersionValidate:
+	default:
+		c.ui.Errorf("unknown subcommand: %s", p.Operation)
+		return versionFlagErr
+	}
+
+	// The new version can be given either before or after the flags
+	if p.Operation == action.VersionSet && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		p.Version, args = args[0], args[1:]
+	}
+
+	var patch, minor, major bool
+
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	fs.BoolVar(&patch, "patch", true, "")
+	fs.BoolVar(&minor, "minor", false, "")
+	fs.BoolVar(&major, "major", false, "")
+	fs.BoolVar(&p.Commit, "commit", false, "")
+	fs.Usage = func() {
+		c.ui.Outputf(c.Help())
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return versionFlagErr
+	}
+
+	if p.Operation == action.VersionSet && p.Version == "" {
+		p.Version = fs.Arg(0)
+	}
+
+	if p.Operation == action.VersionSet && p.Version == "" {
+		c.ui.Errorf("no version to set")
+		return versionFlagErr
+	}
+
+	// Patch default is true
+	if patch {
+		p.Segment = semver.Patch
+	}
+
+	// Minor is preferred over patch
+	if minor {
+		p.Segment = semver.Minor
+	}
+
+	// Major is preferred over minor and patch
+	if major {
+		p.Segment = semver.Major
+	}
+
+	ctx := context.Background()
+	ctx = step.ContextWithUI(ctx, c.ui)
+	ctx = action.ContextWithSpec(ctx, c.Spec)
+	ctx = action.ContextWithVersionParams(ctx, p)
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+
+	// Try finding any possible failure before running the command
+	if err := c.action.Dry(ctx); err != nil {
+		c.ui.Errorf("%s", err)
+		summarize(c.ui, "version", err)
+		return versionDryErr
+	}
+
+	// Running the command
+	if err := c.action.Run(ctx); err != nil {
+		c.ui.Errorf("%s", err)
+		defer summarize(c.ui, "version", err)
+
+		// Try reverting back any side effect in case of failure
+		if err := c.action.Revert(ctx); err != nil {
+			c.ui.Errorf("%s", err)
+			return versionRevertErr
+		}
+
+		return versionRunErr
+	}
+
+	summarize(c.ui, "version", nil)
+
+	return 0
+}
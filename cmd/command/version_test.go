@@ -0,0 +1,190 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moorara/cherry/internal/action"
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/moorara/cherry/pkg/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		ui            cui.CUI
+		workDir       string
+		spec          spec.Spec
+		expectedError error
+	}{
+		{
+			name:    "OK",
+			ui:      &mockCUI{},
+			workDir: ".",
+			spec:    spec.Spec{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewVersion(tc.ui, tc.workDir, tc.spec)
+
+			if tc.expectedError == nil {
+				assert.NotNil(t, cmd)
+				assert.NoError(t, err)
+			} else {
+				assert.Nil(t, cmd)
+				assert.Equal(t, tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestVersionSynopsis(t *testing.T) {
+	cmd := &version{}
+	synopsis := cmd.Synopsis()
+
+	assert.Equal(t, versionSynopsis, synopsis)
+}
+
+func TestVersionHelp(t *testing.T) {
+	cmd := &version{}
+	help := cmd.Help()
+
+	assert.Equal(t, versionHelp, help)
+}
+
+func TestVersionRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		action         *mockAction
+		args           []string
+		expectedExit   int
+		expectedParams action.VersionParams
+	}{
+		{
+			name:         "UnknownSubcommand",
+			action:       &mockAction{},
+			args:         []string{"reset"},
+			expectedExit: versionFlagErr,
+		},
+		{
+			name:         "InvalidFlags",
+			action:       &mockAction{},
+			args:         []string{"bump", "-unknown"},
+			expectedExit: versionFlagErr,
+		},
+		{
+			name:         "NoVersionToSet",
+			action:       &mockAction{},
+			args:         []string{"set", "-commit"},
+			expectedExit: versionFlagErr,
+		},
+		{
+			name: "DryFails",
+			action: &mockAction{
+				DryOutError: errors.New("error on dry: action"),
+			},
+			args:         []string{"show"},
+			expectedExit: versionDryErr,
+		},
+		{
+			name: "RunFails",
+			action: &mockAction{
+				RunOutError: errors.New("error on run: action"),
+			},
+			args:         []string{"bump"},
+			expectedExit: versionRunErr,
+		},
+		{
+			name: "RevertFails",
+			action: &mockAction{
+				RunOutError:    errors.New("error on run: action"),
+				RevertOutError: errors.New("error on revert: action"),
+			},
+			args:         []string{"bump"},
+			expectedExit: versionRevertErr,
+		},
+		{
+			name:         "DefaultSuccess",
+			action:       &mockAction{},
+			args:         []string{},
+			expectedExit: 0,
+			expectedParams: action.VersionParams{
+				Operation: action.VersionShow,
+				Segment:   semver.Patch,
+			},
+		},
+		{
+			name:         "NextSuccess",
+			action:       &mockAction{},
+			args:         []string{"next", "-minor"},
+			expectedExit: 0,
+			expectedParams: action.VersionParams{
+				Operation: action.VersionNext,
+				Segment:   semver.Minor,
+			},
+		},
+		{
+			name:         "BumpSuccess",
+			action:       &mockAction{},
+			args:         []string{"bump", "-major", "-commit"},
+			expectedExit: 0,
+			expectedParams: action.VersionParams{
+				Operation: action.VersionBump,
+				Segment:   semver.Major,
+				Commit:    true,
+			},
+		},
+		{
+			name:         "SetSuccess",
+			action:       &mockAction{},
+			args:         []string{"set", "1.2.3", "-commit"},
+			expectedExit: 0,
+			expectedParams: action.VersionParams{
+				Operation: action.VersionSet,
+				Version:   "1.2.3",
+				Commit:    true,
+			},
+		},
+		{
+			name:         "SetAfterFlagsSuccess",
+			action:       &mockAction{},
+			args:         []string{"set", "-commit", "1.2.3"},
+			expectedExit: 0,
+			expectedParams: action.VersionParams{
+				Operation: action.VersionSet,
+				Version:   "1.2.3",
+				Commit:    true,
+			},
+		},
+		{
+			name:         "ValidateSuccess",
+			action:       &mockAction{},
+			args:         []string{"validate"},
+			expectedExit: 0,
+			expectedParams: action.VersionParams{
+				Operation: action.VersionValidate,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := &version{
+				ui:     &mockCUI{},
+				Spec:   spec.Spec{},
+				action: tc.action,
+			}
+
+			exit := cmd.Run(tc.args)
+			assert.Equal(t, tc.expectedExit, exit)
+
+			if tc.expectedExit == 0 {
+				assert.Equal(t, tc.expectedParams, action.VersionParamsFromContext(tc.action.RunInCtx))
+			}
+		})
+	}
+}
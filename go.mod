@@ -3,6 +3,8 @@ module github.com/moorara/cherry
 go 1.13
 
 require (
+	github.com/go-git/go-billy/v5 v5.0.0
+	github.com/go-git/go-git/v5 v5.2.0
 	github.com/gorilla/mux v1.7.3
 	github.com/mitchellh/cli v1.0.0
 	github.com/moorara/konfig v0.3.2
@@ -2,8 +2,13 @@ package action
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/cui"
 )
 
 // contextKey is the type for the keys added to context.
@@ -33,3 +38,26 @@ type Action interface {
 	Run(context.Context) error
 	Revert(context.Context) error
 }
+
+// stepName returns the type name of a step.
+func stepName(s step.Step) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", s), "*step.")
+}
+
+// runStep runs a step and reports when the step starts, finishes, or fails.
+func runStep(ctx context.Context, ui cui.CUI, s step.Step) error {
+	name := stepName(s)
+	ui.Event(cui.Event{Type: cui.EventStepStarted, Step: name})
+
+	start := time.Now()
+	if err := s.Run(ctx); err != nil {
+		ui.Event(cui.Event{Type: cui.EventStepFailed, Step: name, Error: err.Error()})
+		return err
+	}
+
+	duration := time.Since(start)
+	ui.Debugf("%s finished in %s", name, duration.Round(time.Millisecond))
+	ui.Event(cui.Event{Type: cui.EventStepFinished, Step: name, Duration: duration.Seconds()})
+
+	return nil
+}
@@ -2,9 +2,12 @@ package action
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/cui"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,3 +64,49 @@ func TestSpecFromContext(t *testing.T) {
 		})
 	}
 }
+
+func TestRunStep(t *testing.T) {
+	tests := []struct {
+		name           string
+		step           step.Step
+		expectedError  string
+		expectedEvents []string
+	}{
+		{
+			name: "Fails",
+			step: &step.GitGetRepo{
+				Mock: &mockStep{RunOutError: errors.New("error on run: step")},
+			},
+			expectedError:  "error on run: step",
+			expectedEvents: []string{cui.EventStepStarted, cui.EventStepFailed},
+		},
+		{
+			name: "Succeeds",
+			step: &step.GitGetRepo{
+				Mock: &mockStep{},
+			},
+			expectedEvents: []string{cui.EventStepStarted, cui.EventStepFinished},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := &mockCUI{}
+			err := runStep(context.Background(), ui, tc.step)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Equal(t, tc.expectedError, ui.EventInEvents[1].Error)
+			}
+
+			types := []string{}
+			for _, e := range ui.EventInEvents {
+				assert.Equal(t, "GitGetRepo", e.Step)
+				types = append(types, e.Type)
+			}
+			assert.Equal(t, tc.expectedEvents, types)
+		})
+	}
+}
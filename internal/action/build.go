@@ -23,6 +23,11 @@ type build struct {
 
 // NewBuild creates an instance of Build action.
 func NewBuild(ui cui.CUI, workDir string, s spec.Spec) Action {
+	var gogit *step.GoGit
+	if step.UseGoGit(s.Git) {
+		gogit = step.NewGoGit(workDir)
+	}
+
 	return &build{
 		ui: ui,
 		step1: &step.GoList{
@@ -31,13 +36,22 @@ func NewBuild(ui cui.CUI, workDir string, s spec.Spec) Action {
 		},
 		step2: &step.SemVerRead{
 			WorkDir:  workDir,
+			GoGit:    gogit,
+			Source:   s.VersionSource,
 			Filename: s.VersionFile,
+			Pattern:  s.VersionPattern,
+			Match:    tagPattern(s.Release.Templates, ""),
+
+			Versioning:   s.Versioning,
+			CalVerFormat: s.CalVerFormat,
 		},
 		step3: &step.GitGetHEAD{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step4: &step.GitGetBranch{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step5: &step.GoVersion{
 			WorkDir: workDir,
@@ -52,6 +66,15 @@ func NewBuild(ui cui.CUI, workDir string, s spec.Spec) Action {
 	}
 }
 
+// readVersion returns the version read by a step in the versioning scheme of spec.
+func readVersion(s spec.Spec, st *step.SemVerRead) string {
+	if s.Versioning == step.VersioningCalVer {
+		return st.Result.CalVer.Version()
+	}
+
+	return st.Result.Version.Version()
+}
+
 func (b *build) getLDFlags(s spec.Spec) string {
 	buildTool := s.ToolName
 	if s.ToolVersion != "" {
@@ -59,7 +82,7 @@ func (b *build) getLDFlags(s spec.Spec) string {
 	}
 
 	vPkg := b.step1.Result.PackagePath
-	versionFlag := fmt.Sprintf("-X %s.Version=%s", vPkg, b.step2.Result.Version.Version())
+	versionFlag := fmt.Sprintf("-X %s.Version=%s", vPkg, readVersion(s, b.step2))
 	revisionFlag := fmt.Sprintf("-X %s.Revision=%s", vPkg, b.step3.Result.ShortSHA)
 	branchFlag := fmt.Sprintf("-X %s.Branch=%s", vPkg, b.step4.Result.Name)
 	goVersionFlag := fmt.Sprintf("-X %s.GoVersion=%s", vPkg, b.step5.Result.Version)
@@ -115,23 +138,25 @@ func (b *build) Dry(ctx context.Context) error {
 func (b *build) Run(ctx context.Context) error {
 	s := SpecFromContext(ctx)
 
-	if err := b.step1.Run(ctx); err != nil {
+	if err := runStep(ctx, b.ui, b.step1); err != nil {
 		return err
 	}
 
-	if err := b.step2.Run(ctx); err != nil {
+	if err := runStep(ctx, b.ui, b.step2); err != nil {
 		return err
 	}
 
-	if err := b.step3.Run(ctx); err != nil {
+	b.ui.Event(cui.Event{Type: cui.EventVersion, Version: readVersion(s, b.step2)})
+
+	if err := runStep(ctx, b.ui, b.step3); err != nil {
 		return err
 	}
 
-	if err := b.step4.Run(ctx); err != nil {
+	if err := runStep(ctx, b.ui, b.step4); err != nil {
 		return err
 	}
 
-	if err := b.step5.Run(ctx); err != nil {
+	if err := runStep(ctx, b.ui, b.step5); err != nil {
 		return err
 	}
 
@@ -140,12 +165,13 @@ func (b *build) Run(ctx context.Context) error {
 		b.step6.Platforms = s.Build.Platforms
 	}
 
-	if err := b.step6.Run(ctx); err != nil {
+	if err := runStep(ctx, b.ui, b.step6); err != nil {
 		return err
 	}
 
 	for _, bin := range b.step6.Result.Binaries {
 		b.ui.Infof("🍒 %s", bin)
+		b.ui.Event(cui.Event{Type: cui.EventArtifact, Artifact: bin})
 	}
 
 	return nil
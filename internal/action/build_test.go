@@ -34,12 +34,47 @@ func TestNewBuild(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:    "GitVersion",
+			ui:      &mockCUI{},
+			workDir: ".",
+			s: spec.Spec{
+				ToolName:      "cherry",
+				ToolVersion:   "test",
+				VersionSource: "git",
+				Build: spec.Build{
+					MainFile:   "main.go",
+					BinaryFile: "bin/app",
+				},
+			},
+		},
+		{
+			name:    "CalVer",
+			ui:      &mockCUI{},
+			workDir: ".",
+			s: spec.Spec{
+				ToolName:     "cherry",
+				ToolVersion:  "test",
+				Versioning:   "calver",
+				CalVerFormat: "YY.0W.MICRO",
+				Build: spec.Build{
+					MainFile:   "main.go",
+					BinaryFile: "bin/app",
+				},
+			},
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			action := NewBuild(tc.ui, tc.workDir, tc.s)
 			assert.NotNil(t, action)
+
+			b := action.(*build)
+			assert.Equal(t, tc.s.VersionSource, b.step2.Source)
+			assert.Equal(t, "v*", b.step2.Match)
+			assert.Equal(t, tc.s.Versioning, b.step2.Versioning)
+			assert.Equal(t, tc.s.CalVerFormat, b.step2.CalVerFormat)
 		})
 	}
 }
@@ -0,0 +1,197 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/ociimage"
+)
+
+const (
+	dockerHubRegistry = "registry-1.docker.io"
+	defaultImageUser  = "65534:65534"
+)
+
+var (
+	defaultImageTags = []string{"{{.Version}}", "latest"}
+	imageRepoRegex   = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	imageTagRegex    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+)
+
+// imageReference splits an image reference (i.e. ghcr.io/username/app) into the registry host and the repository.
+// The first component is the registry if it has a dot or a port or it is localhost, otherwise the image is on Docker Hub.
+func imageReference(image string) (string, string, error) {
+	registry, repo := "docker.io", image
+	if i := strings.Index(image, "/"); i > 0 {
+		if first := image[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			registry, repo = first, image[i+1:]
+		}
+	}
+
+	if !imageRepoRegex.MatchString(repo) {
+		return "", "", fmt.Errorf("invalid image: %s", image)
+	}
+
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = dockerHubRegistry
+		if !strings.Contains(repo, "/") {
+			repo = "library/" + repo
+		}
+	}
+
+	return registry, repo, nil
+}
+
+// imageTags renders the tags of image for a release.
+func imageTags(d spec.Docker, vars releaseVars) ([]string, error) {
+	templates := d.Tags
+	if len(templates) == 0 {
+		templates = defaultImageTags
+	}
+
+	tags := []string{}
+	for _, t := range templates {
+		tag, err := renderText("image tag", t, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		if !imageTagRegex.MatchString(tag) {
+			return nil, fmt.Errorf("invalid image tag: %q", tag)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// imageConfig returns the configuration of image for running containers.
+// A port without a protocol is a tcp port.
+func imageConfig(d spec.Docker) ociimage.Config {
+	config := ociimage.Config{
+		User:       d.User,
+		Env:        d.Env,
+		Entrypoint: d.Entrypoint,
+		Cmd:        d.Cmd,
+		WorkingDir: d.WorkingDir,
+		Labels:     d.Labels,
+	}
+
+	if config.User == "" {
+		config.User = defaultImageUser
+	}
+
+	if len(d.Expose) > 0 {
+		config.ExposedPorts = map[string]struct{}{}
+		for _, port := range d.Expose {
+			if !strings.Contains(port, "/") {
+				port += "/tcp"
+			}
+			config.ExposedPorts[port] = struct{}{}
+		}
+	}
+
+	return config
+}
+
+// imageFiles converts the files in spec to the files copied into images.
+func imageFiles(d spec.Docker) ([]step.ImageFile, error) {
+	files := []step.ImageFile{}
+	for _, f := range d.Files {
+		pf, err := packageFile(f, false)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, step.ImageFile{
+			Src:  pf.Src,
+			Dst:  pf.Dst,
+			Mode: pf.Mode,
+		})
+	}
+
+	return files, nil
+}
+
+// imageFile returns the path of OCI image layout tarball.
+func imageFile(s spec.Spec) string {
+	if f := s.Release.Docker.File; f != "" {
+		return f
+	}
+
+	return filepath.Join(filepath.Dir(s.Build.BinaryFile), filepath.Base(s.Build.BinaryFile)+"-image.tar")
+}
+
+// imageName returns the image reference with its tags for printing.
+func imageName(d spec.Docker, tags []string) string {
+	return fmt.Sprintf("%s (%s)", d.Image, strings.Join(tags, ", "))
+}
+
+// setImage sets the parameters of steps for building and pushing the image.
+// In dry mode, the binaries are determined from the platforms since they are not built yet.
+// step1 and step13 should be run first.
+func (r *release) setImage(s spec.Spec, vars releaseVars, dry bool) error {
+	d := s.Release.Docker
+
+	hasLinux := false
+	for _, platform := range s.Build.Platforms {
+		if strings.HasPrefix(platform, "linux-") {
+			hasLinux = true
+		}
+	}
+
+	if !hasLinux {
+		return errors.New("docker image requires at least one linux platform")
+	}
+
+	registry, repo, err := imageReference(d.Image)
+	if err != nil {
+		return err
+	}
+
+	tags, err := imageTags(d, vars)
+	if err != nil {
+		return err
+	}
+
+	files, err := imageFiles(d)
+	if err != nil {
+		return err
+	}
+
+	r.step34.BinDir = d.BinDir
+	r.step34.Files = files
+	r.step34.Config = imageConfig(d)
+	r.step34.Tags = tags
+	r.step34.File = imageFile(s)
+	r.step34.Annotations = map[string]string{
+		ociimage.AnnotationVersion:  vars.Version,
+		ociimage.AnnotationRevision: r.step13.Result.SHA,
+		ociimage.AnnotationSource:   fmt.Sprintf("https://%s/%s", r.step1.Result.Host, r.step1.Result.Repo),
+	}
+
+	if dry {
+		r.step34.Binaries = []string{}
+		for _, platform := range s.Build.Platforms {
+			r.step34.Binaries = append(r.step34.Binaries, fmt.Sprintf("%s-%s", s.Build.BinaryFile, platform))
+		}
+	} else {
+		r.step34.Binaries = r.step15.Result.Binaries
+	}
+
+	r.step35.Registry = registry
+	r.step35.Repository = repo
+	r.step35.Insecure = d.Insecure
+	r.step35.Username = r.config.DockerUsername
+	r.step35.Password = r.config.DockerPassword
+	r.step35.Image = r.step34.Result.Image
+	r.step35.Tags = tags
+
+	return nil
+}
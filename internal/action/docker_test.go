@@ -0,0 +1,191 @@
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/ociimage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageReference(t *testing.T) {
+	tests := []struct {
+		image            string
+		expectedRegistry string
+		expectedRepo     string
+		expectedError    error
+	}{
+		{"ghcr.io/username/app", "ghcr.io", "username/app", nil},
+		{"localhost:5000/app", "localhost:5000", "app", nil},
+		{"localhost/team/app", "localhost", "team/app", nil},
+		{"username/app", "registry-1.docker.io", "username/app", nil},
+		{"app", "registry-1.docker.io", "library/app", nil},
+		{"docker.io/username/app", "registry-1.docker.io", "username/app", nil},
+		{"ghcr.io/username/app:latest", "", "", errors.New("invalid image: ghcr.io/username/app:latest")},
+		{"ghcr.io/Username/App", "", "", errors.New("invalid image: ghcr.io/Username/App")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.image, func(t *testing.T) {
+			registry, repo, err := imageReference(tc.image)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedRegistry, registry)
+			assert.Equal(t, tc.expectedRepo, repo)
+		})
+	}
+}
+
+func TestImageTags(t *testing.T) {
+	tests := []struct {
+		name          string
+		docker        spec.Docker
+		vars          releaseVars
+		expectedTags  []string
+		expectedError error
+	}{
+		{
+			name:         "Default",
+			docker:       spec.Docker{},
+			vars:         releaseVars{Version: "0.2.0"},
+			expectedTags: []string{"0.2.0", "latest"},
+		},
+		{
+			name:         "Custom",
+			docker:       spec.Docker{Tags: []string{"v{{.Version}}", "stable"}},
+			vars:         releaseVars{Version: "0.2.0"},
+			expectedTags: []string{"v0.2.0", "stable"},
+		},
+		{
+			name:          "InvalidTemplate",
+			docker:        spec.Docker{Tags: []string{"{{.Version"}},
+			vars:          releaseVars{Version: "0.2.0"},
+			expectedError: errors.New("invalid image tag template: template: image tag:1: unclosed action"),
+		},
+		{
+			name:          "InvalidTag",
+			docker:        spec.Docker{},
+			vars:          releaseVars{Version: "0.2.0+build.1"},
+			expectedError: errors.New(`invalid image tag: "0.2.0+build.1"`),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tags, err := imageTags(tc.docker, tc.vars)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedTags, tags)
+		})
+	}
+}
+
+func TestImageConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		docker         spec.Docker
+		expectedConfig ociimage.Config
+	}{
+		{
+			name:   "Default",
+			docker: spec.Docker{},
+			expectedConfig: ociimage.Config{
+				User: "65534:65534",
+			},
+		},
+		{
+			name: "Custom",
+			docker: spec.Docker{
+				User:       "1000:1000",
+				Env:        []string{"LOG_LEVEL=info"},
+				Cmd:        []string{"serve"},
+				WorkingDir: "/data",
+				Labels:     map[string]string{"team": "platform"},
+				Expose:     []string{"8080", "53/udp"},
+			},
+			expectedConfig: ociimage.Config{
+				User:         "1000:1000",
+				ExposedPorts: map[string]struct{}{"8080/tcp": {}, "53/udp": {}},
+				Env:          []string{"LOG_LEVEL=info"},
+				Cmd:          []string{"serve"},
+				WorkingDir:   "/data",
+				Labels:       map[string]string{"team": "platform"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := imageConfig(tc.docker)
+
+			assert.Equal(t, tc.expectedConfig, config)
+		})
+	}
+}
+
+func TestImageFiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		docker        spec.Docker
+		expectedFiles []step.ImageFile
+		expectedError error
+	}{
+		{
+			name: "NoDst",
+			docker: spec.Docker{
+				Files: []spec.PackageFile{{Src: "app.yaml"}},
+			},
+			expectedError: errors.New("package file requires src and dst: {Src:app.yaml Dst: Mode:}"),
+		},
+		{
+			name: "Success",
+			docker: spec.Docker{
+				Files: []spec.PackageFile{{Src: "app.yaml", Dst: "/etc/app/app.yaml", Mode: "0600"}},
+			},
+			expectedFiles: []step.ImageFile{
+				{Src: "app.yaml", Dst: "/etc/app/app.yaml", Mode: 0600},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := imageFiles(tc.docker)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
+
+func TestImageFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		spec         spec.Spec
+		expectedFile string
+	}{
+		{
+			name: "Default",
+			spec: spec.Spec{
+				Build: spec.Build{BinaryFile: "bin/app"},
+			},
+			expectedFile: "bin/app-image.tar",
+		},
+		{
+			name: "Custom",
+			spec: spec.Spec{
+				Build:   spec.Build{BinaryFile: "bin/app"},
+				Release: spec.Release{Docker: spec.Docker{File: "dist/image.tar"}},
+			},
+			expectedFile: "dist/image.tar",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedFile, imageFile(tc.spec))
+		})
+	}
+}
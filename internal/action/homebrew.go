@@ -0,0 +1,404 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+)
+
+const (
+	homebrewMethodAPI = "api"
+	homebrewMethodGit = "git"
+
+	defaultFormulaFolder = "Formula"
+	defaultFormulaCommit = "{{.Name}} {{.Version}}"
+
+	defaultFormulaTemplate = `class {{.Class}} < Formula
+{{- if .Description}}
+  desc {{printf "%q" .Description}}
+{{- end}}
+  homepage {{printf "%q" .Homepage}}
+  version {{printf "%q" .Version}}
+{{- if .License}}
+  license {{printf "%q" .License}}
+{{- end}}
+{{- if .MacOS}}
+
+  on_macos do
+{{- range .MacOS}}
+    if {{.CPU}}
+      url {{printf "%q" .URL}}
+      sha256 {{printf "%q" .SHA256}}
+    end
+{{- end}}
+  end
+{{- end}}
+{{- if .Linux}}
+
+  on_linux do
+{{- range .Linux}}
+    if {{.CPU}}
+      url {{printf "%q" .URL}}
+      sha256 {{printf "%q" .SHA256}}
+    end
+{{- end}}
+  end
+{{- end}}
+
+  def install
+    {{.Install}}
+  end
+
+  test do
+    {{.Test}}
+  end
+end
+`
+)
+
+// homebrewCPUs are the Ruby conditions for the architectures supported by Homebrew.
+var homebrewCPUs = map[string]string{
+	"amd64": "Hardware::CPU.intel? && Hardware::CPU.is_64_bit?",
+	"386":   "Hardware::CPU.intel? && !Hardware::CPU.is_64_bit?",
+	"arm64": "Hardware::CPU.arm? && Hardware::CPU.is_64_bit?",
+	"arm":   "Hardware::CPU.arm? && !Hardware::CPU.is_64_bit?",
+}
+
+// homebrewAsset is a release asset (binary) for a platform supported by Homebrew.
+type homebrewAsset struct {
+	OS     string
+	Arch   string
+	Name   string
+	URL    string
+	SHA256 string
+}
+
+// CPU returns the Ruby condition for the architecture of asset.
+func (a homebrewAsset) CPU() string {
+	return homebrewCPUs[a.Arch]
+}
+
+// newHomebrewAsset creates an asset from the name of a binary built for a platform (i.e. app-darwin-amd64).
+// If the platform is not supported by Homebrew, false is returned.
+func newHomebrewAsset(name string) (homebrewAsset, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) < 3 {
+		return homebrewAsset{}, false
+	}
+
+	goos, arch := parts[len(parts)-2], parts[len(parts)-1]
+	if goos != "darwin" && goos != "linux" {
+		return homebrewAsset{}, false
+	}
+
+	if _, ok := homebrewCPUs[arch]; !ok {
+		return homebrewAsset{}, false
+	}
+
+	return homebrewAsset{
+		OS:   goos,
+		Arch: arch,
+		Name: name,
+	}, true
+}
+
+// homebrewVars are the variables available to the formula template.
+type homebrewVars struct {
+	Name        string
+	Class       string
+	Binary      string
+	Description string
+	Homepage    string
+	License     string
+	Version     string
+	Tag         string
+	Install     string
+	Test        string
+	MacOS       []homebrewAsset
+	Linux       []homebrewAsset
+}
+
+// addAsset adds an asset to the assets of its operating system.
+func (v *homebrewVars) addAsset(a homebrewAsset) {
+	if a.OS == "darwin" {
+		v.MacOS = append(v.MacOS, a)
+	} else {
+		v.Linux = append(v.Linux, a)
+	}
+}
+
+// homebrewFormula is a rendered formula and the commit message for publishing it to the tap repository.
+type homebrewFormula struct {
+	Path    string
+	Content []byte
+	Message string
+}
+
+// formulaClass returns the Ruby class name of a formula (i.e. cherry-cli --> CherryCli and app@2 --> AppAT2).
+func formulaClass(name string) string {
+	name = strings.Replace(name, "@", "AT", -1)
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || unicode.IsSpace(r)
+	})
+
+	var class string
+	for _, part := range parts {
+		class += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return class
+}
+
+// indent indents all lines of a Ruby block except the first one.
+func indent(block string) string {
+	return strings.Replace(block, "\n", "\n    ", -1)
+}
+
+// renderFormula renders a Homebrew formula and its commit message.
+// Default values are used for the formula template, install and test blocks, folder, and commit message if not set.
+// The formula template file is relative to workDir.
+func renderFormula(workDir string, h spec.Homebrew, vars homebrewVars) (homebrewFormula, error) {
+	text := defaultFormulaTemplate
+	if h.Template != "" {
+		data, err := ioutil.ReadFile(filepath.Join(workDir, h.Template))
+		if err != nil {
+			return homebrewFormula{}, err
+		}
+		text = string(data)
+	}
+
+	message := h.CommitMessage
+	if message == "" {
+		message = defaultFormulaCommit
+	}
+
+	folder := h.Folder
+	if folder == "" {
+		folder = defaultFormulaFolder
+	}
+
+	vars.Install = indent(vars.Install)
+	vars.Test = indent(vars.Test)
+
+	content, err := renderText("formula", text, vars)
+	if err != nil {
+		return homebrewFormula{}, err
+	}
+
+	if message, err = renderText("commit_message", message, vars); err != nil {
+		return homebrewFormula{}, err
+	}
+
+	return homebrewFormula{
+		Path:    path.Join(folder, vars.Name+".rb"),
+		Content: []byte(content),
+		Message: message,
+	}, nil
+}
+
+// renderText parses and executes a text template with the given data.
+func renderText(name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %s", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid %s template: %s", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// sha256File returns the hex-encoded SHA-256 checksum of a file.
+func sha256File(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// assetURLs returns the download urls of the uploaded assets by their names.
+// The download urls of assets may change after the draft release is published, so the published ones are preferred.
+func (r *release) assetURLs() map[string]string {
+	urls := map[string]string{}
+	for _, a := range r.step16.Result.Assets {
+		urls[a.Name] = a.DownloadURL
+	}
+	for _, a := range r.step25.Result.Release.Assets {
+		urls[a.Name] = a.DownloadURL
+	}
+
+	return urls
+}
+
+// binaryChecksum returns the SHA-256 checksum of a binary built by step15.
+func (r *release) binaryChecksum(bin string) (string, error) {
+	if !filepath.IsAbs(bin) {
+		bin = filepath.Join(r.step15.WorkDir, bin)
+	}
+
+	return sha256File(bin)
+}
+
+// homebrewStep returns the step for publishing the Homebrew formula to the tap repository.
+func (r *release) homebrewStep(s spec.Spec) step.Step {
+	if s.Release.Homebrew.Method == homebrewMethodGit {
+		return r.step30
+	}
+
+	return r.step29
+}
+
+// formulaVars returns the variables for rendering the Homebrew formula of release.
+// If dry is true, placeholders are used for the urls and checksums of assets since they are not built and uploaded yet.
+// Otherwise, the binaries built by step15 and the assets of release published by step25 are used.
+func (r *release) formulaVars(s spec.Spec, vars releaseVars, dry bool) (homebrewVars, error) {
+	h := s.Release.Homebrew
+	binary := filepath.Base(s.Build.BinaryFile)
+
+	hv := homebrewVars{
+		Name:        h.Formula,
+		Binary:      binary,
+		Description: h.Description,
+		Homepage:    h.Homepage,
+		License:     h.License,
+		Version:     vars.Version,
+		Tag:         vars.Tag,
+		Install:     h.Install,
+		Test:        h.Test,
+	}
+
+	if hv.Name == "" {
+		hv.Name = binary
+	}
+
+	if hv.Homepage == "" {
+		hv.Homepage = fmt.Sprintf("https://%s/%s", r.step1.Result.Host, r.step1.Result.Repo)
+	}
+
+	if hv.Install == "" {
+		hv.Install = fmt.Sprintf("bin.install Dir[%q].first => %q", binary+"-*", binary)
+	}
+
+	if hv.Test == "" {
+		hv.Test = fmt.Sprintf(`system "#{bin}/%s", "--version"`, binary)
+	}
+
+	hv.Class = formulaClass(hv.Name)
+
+	if dry {
+		for _, platform := range s.Build.Platforms {
+			if a, ok := newHomebrewAsset(binary + "-" + platform); ok {
+				a.URL = fmt.Sprintf("<download url of %s>", a.Name)
+				a.SHA256 = fmt.Sprintf("<sha256 of %s>", a.Name)
+				hv.addAsset(a)
+			}
+		}
+	} else {
+		urls := r.assetURLs()
+		for _, bin := range r.step15.Result.Binaries {
+			a, ok := newHomebrewAsset(filepath.Base(bin))
+			if !ok {
+				continue
+			}
+
+			if a.URL = urls[a.Name]; a.URL == "" {
+				return homebrewVars{}, fmt.Errorf("no download url for asset %s", a.Name)
+			}
+
+			var err error
+			if a.SHA256, err = r.binaryChecksum(bin); err != nil {
+				return homebrewVars{}, err
+			}
+
+			hv.addAsset(a)
+		}
+	}
+
+	if len(hv.MacOS) == 0 && len(hv.Linux) == 0 {
+		return homebrewVars{}, errors.New("no darwin or linux binary for the Homebrew formula")
+	}
+
+	return hv, nil
+}
+
+// setHomebrew renders the Homebrew formula and sets the parameters of step for publishing it to the tap repository.
+// step1 should be run first and the release provider should be created.
+func (r *release) setHomebrew(ctx context.Context, s spec.Spec, vars releaseVars, dry bool) error {
+	h := s.Release.Homebrew
+
+	if h.Method != "" && h.Method != homebrewMethodAPI && h.Method != homebrewMethodGit {
+		return fmt.Errorf("unknown homebrew method: %s", h.Method)
+	}
+
+	hv, err := r.formulaVars(s, vars, dry)
+	if err != nil {
+		return err
+	}
+
+	formula, err := renderFormula(r.workDir, h, hv)
+	if err != nil {
+		return err
+	}
+
+	if h.Method == homebrewMethodGit {
+		r.step30.URL = r.gitRepoURL(h.Tap, h.TapURL)
+		r.step30.Branch = h.Branch
+		r.step30.Files = []step.GitFile{
+			{Path: formula.Path, Content: formula.Content},
+		}
+		r.step30.Message = formula.Message
+
+		return nil
+	}
+
+	if h.Tap == "" {
+		return errors.New("homebrew tap is required for publishing the formula using the contents API")
+	}
+
+	// The contents API is only supported for GitHub
+	p, ok := r.provider.(*step.GitHubProvider)
+	if !ok {
+		return fmt.Errorf("homebrew formula cannot be published using the contents API of %s, use git method instead", r.provider.Name())
+	}
+
+	token, err := p.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.step29.Client = r.client
+	r.step29.Token = token
+	r.step29.BaseURL = p.APIURL
+	r.step29.Repo = h.Tap
+	r.step29.Branch = h.Branch
+	r.step29.Path = formula.Path
+	r.step29.Content = formula.Content
+	r.step29.Message = formula.Message
+
+	return nil
+}
+
+// homebrewTap returns the tap repository for the plan.
+func homebrewTap(h spec.Homebrew) string {
+	if h.Method == homebrewMethodGit && h.TapURL != "" {
+		return h.TapURL
+	}
+
+	return h.Tap
+}
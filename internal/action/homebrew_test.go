@@ -0,0 +1,267 @@
+package action
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHomebrewAsset(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedAsset homebrewAsset
+		expectedOK    bool
+	}{
+		{"app", homebrewAsset{}, false},
+		{"app-windows-amd64", homebrewAsset{}, false},
+		{"app-linux-ppc64le", homebrewAsset{}, false},
+		{"app-darwin-amd64", homebrewAsset{OS: "darwin", Arch: "amd64", Name: "app-darwin-amd64"}, true},
+		{"my-app-linux-arm64", homebrewAsset{OS: "linux", Arch: "arm64", Name: "my-app-linux-arm64"}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			asset, ok := newHomebrewAsset(tc.name)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedAsset, asset)
+		})
+	}
+}
+
+func TestFormulaClass(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedClass string
+	}{
+		{"cherry", "Cherry"},
+		{"cherry-cli", "CherryCli"},
+		{"go_tool.v2", "GoToolV2"},
+		{"app@2", "AppAT2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedClass, formulaClass(tc.name))
+		})
+	}
+}
+
+func TestRenderFormula(t *testing.T) {
+	td, err := ioutil.TempDir("", "cherry-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(td)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(td, "formula.rb.tmpl"), []byte("class {{.Class}} < Formula\n  version \"{{.Version}}\"\nend\n"), 0644))
+
+	vars := homebrewVars{
+		Name:        "app",
+		Class:       "App",
+		Binary:      "app",
+		Description: `The "app" tool`,
+		Homepage:    "https://github.com/username/app",
+		License:     "MIT",
+		Version:     "0.2.0",
+		Tag:         "v0.2.0",
+		Install:     "bin.install Dir[\"app-*\"].first => \"app\"\nman1.install \"app.1\"",
+		Test:        `system "#{bin}/app", "--version"`,
+		MacOS: []homebrewAsset{
+			{OS: "darwin", Arch: "amd64", Name: "app-darwin-amd64", URL: "https://example.com/app-darwin-amd64", SHA256: "aaaa"},
+			{OS: "darwin", Arch: "arm64", Name: "app-darwin-arm64", URL: "https://example.com/app-darwin-arm64", SHA256: "bbbb"},
+		},
+		Linux: []homebrewAsset{
+			{OS: "linux", Arch: "amd64", Name: "app-linux-amd64", URL: "https://example.com/app-linux-amd64", SHA256: "cccc"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		homebrew        spec.Homebrew
+		vars            homebrewVars
+		expectedError   string
+		expectedFormula homebrewFormula
+	}{
+		{
+			name:          "NoTemplateFile",
+			homebrew:      spec.Homebrew{Template: "unknown.rb.tmpl"},
+			vars:          vars,
+			expectedError: "open " + filepath.Join(td, "unknown.rb.tmpl") + ": no such file or directory",
+		},
+		{
+			name:          "InvalidCommitMessage",
+			homebrew:      spec.Homebrew{CommitMessage: "{{.Formula}} {{.Version}}"},
+			vars:          vars,
+			expectedError: `invalid commit_message template: template: commit_message:1:2: executing "commit_message" at <.Formula>: can't evaluate field Formula in type action.homebrewVars`,
+		},
+		{
+			name:     "Default",
+			homebrew: spec.Homebrew{},
+			vars:     vars,
+			expectedFormula: homebrewFormula{
+				Path:    "Formula/app.rb",
+				Message: "app 0.2.0",
+				Content: []byte(`class App < Formula
+  desc "The \"app\" tool"
+  homepage "https://github.com/username/app"
+  version "0.2.0"
+  license "MIT"
+
+  on_macos do
+    if Hardware::CPU.intel? && Hardware::CPU.is_64_bit?
+      url "https://example.com/app-darwin-amd64"
+      sha256 "aaaa"
+    end
+    if Hardware::CPU.arm? && Hardware::CPU.is_64_bit?
+      url "https://example.com/app-darwin-arm64"
+      sha256 "bbbb"
+    end
+  end
+
+  on_linux do
+    if Hardware::CPU.intel? && Hardware::CPU.is_64_bit?
+      url "https://example.com/app-linux-amd64"
+      sha256 "cccc"
+    end
+  end
+
+  def install
+    bin.install Dir["app-*"].first => "app"
+    man1.install "app.1"
+  end
+
+  test do
+    system "#{bin}/app", "--version"
+  end
+end
+`),
+			},
+		},
+		{
+			name: "Custom",
+			homebrew: spec.Homebrew{
+				Folder:        "Casks",
+				Template:      "formula.rb.tmpl",
+				CommitMessage: "Update {{.Name}} to {{.Tag}}",
+			},
+			vars: vars,
+			expectedFormula: homebrewFormula{
+				Path:    "Casks/app.rb",
+				Message: "Update app to v0.2.0",
+				Content: []byte("class App < Formula\n  version \"0.2.0\"\nend\n"),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			formula, err := renderFormula(td, tc.homebrew, tc.vars)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedFormula.Path, formula.Path)
+				assert.Equal(t, tc.expectedFormula.Message, formula.Message)
+				assert.Equal(t, string(tc.expectedFormula.Content), string(formula.Content))
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestReleaseFormulaVars(t *testing.T) {
+	td, err := ioutil.TempDir("", "cherry-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(td)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(td, "app-linux-amd64"), []byte("linux"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(td, "app-windows-amd64"), []byte("windows"), 0755))
+
+	s := spec.Spec{
+		Build: spec.Build{
+			BinaryFile: "bin/app",
+			Platforms:  []string{"linux-amd64", "darwin-arm64", "windows-amd64"},
+		},
+	}
+
+	r := &release{
+		step1:  &step.GitGetRepo{},
+		step15: &step.GoBuild{WorkDir: td},
+		step16: &step.ReleaseUploadAssets{},
+		step25: &step.ReleaseEdit{},
+	}
+
+	r.step1.Result.Host = "github.com"
+	r.step1.Result.Repo = "username/app"
+	r.step15.Result.Binaries = []string{"app-linux-amd64", "app-windows-amd64"}
+	r.step16.Result.Assets = []step.ReleaseAsset{
+		{Name: "app-linux-amd64", DownloadURL: "https://github.com/username/app/releases/download/untagged-1234/app-linux-amd64"},
+	}
+
+	vars := releaseVars{Version: "0.2.0", Tag: "v0.2.0"}
+
+	t.Run("Dry", func(t *testing.T) {
+		hv, err := r.formulaVars(s, vars, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, homebrewVars{
+			Name:     "app",
+			Class:    "App",
+			Binary:   "app",
+			Homepage: "https://github.com/username/app",
+			Version:  "0.2.0",
+			Tag:      "v0.2.0",
+			Install:  `bin.install Dir["app-*"].first => "app"`,
+			Test:     `system "#{bin}/app", "--version"`,
+			MacOS: []homebrewAsset{
+				{OS: "darwin", Arch: "arm64", Name: "app-darwin-arm64", URL: "<download url of app-darwin-arm64>", SHA256: "<sha256 of app-darwin-arm64>"},
+			},
+			Linux: []homebrewAsset{
+				{OS: "linux", Arch: "amd64", Name: "app-linux-amd64", URL: "<download url of app-linux-amd64>", SHA256: "<sha256 of app-linux-amd64>"},
+			},
+		}, hv)
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		s := s
+		s.Release.Homebrew = spec.Homebrew{
+			Formula:  "my-app",
+			Homepage: "https://app.example.com",
+		}
+
+		// The download url of published release is preferred over the one of draft release
+		r.step25.Result.Release.Assets = []step.ReleaseAsset{
+			{Name: "app-linux-amd64", DownloadURL: "https://github.com/username/app/releases/download/v0.2.0/app-linux-amd64"},
+		}
+
+		hv, err := r.formulaVars(s, vars, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "my-app", hv.Name)
+		assert.Equal(t, "MyApp", hv.Class)
+		assert.Equal(t, "https://app.example.com", hv.Homepage)
+		assert.Empty(t, hv.MacOS)
+		assert.Equal(t, []homebrewAsset{
+			{
+				OS:     "linux",
+				Arch:   "amd64",
+				Name:   "app-linux-amd64",
+				URL:    "https://github.com/username/app/releases/download/v0.2.0/app-linux-amd64",
+				SHA256: "caf90169eefa5f807d577486b9f795ab86ae2983c5c20806cff959117e90af18",
+			},
+		}, hv.Linux)
+	})
+
+	t.Run("NoBinary", func(t *testing.T) {
+		s := s
+		s.Build.Platforms = []string{"windows-amd64"}
+
+		_, err := r.formulaVars(s, vars, true)
+		assert.Equal(t, errors.New("no darwin or linux binary for the Homebrew formula"), err)
+	})
+}
@@ -2,6 +2,9 @@ package action
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/moorara/cherry/pkg/cui"
 )
 
 type mockCUI struct {
@@ -11,8 +14,21 @@ type mockCUI struct {
 	InfofOutVals    []interface{}
 	WarnfInFormat   string
 	WarnfOutVals    []interface{}
+	WarnfMessages   []string
 	ErrorfInFormat  string
 	ErrorfOutVals   []interface{}
+	DebugfInFormat  string
+	DebugfOutVals   []interface{}
+	TracefInFormat  string
+	TracefOutVals   []interface{}
+	EventInEvents   []cui.Event
+
+	AskInQuery       string
+	AskOutAnswer     string
+	AskOutError      error
+	ConfirmInQuery   string
+	ConfirmOutResult bool
+	ConfirmOutError  error
 }
 
 func (m *mockCUI) Outputf(format string, vals ...interface{}) {
@@ -28,6 +44,7 @@ func (m *mockCUI) Infof(format string, vals ...interface{}) {
 func (m *mockCUI) Warnf(format string, vals ...interface{}) {
 	m.WarnfInFormat = format
 	m.WarnfOutVals = vals
+	m.WarnfMessages = append(m.WarnfMessages, fmt.Sprintf(format, vals...))
 }
 
 func (m *mockCUI) Errorf(format string, vals ...interface{}) {
@@ -35,6 +52,30 @@ func (m *mockCUI) Errorf(format string, vals ...interface{}) {
 	m.ErrorfOutVals = vals
 }
 
+func (m *mockCUI) Debugf(format string, vals ...interface{}) {
+	m.DebugfInFormat = format
+	m.DebugfOutVals = vals
+}
+
+func (m *mockCUI) Tracef(format string, vals ...interface{}) {
+	m.TracefInFormat = format
+	m.TracefOutVals = vals
+}
+
+func (m *mockCUI) Event(e cui.Event) {
+	m.EventInEvents = append(m.EventInEvents, e)
+}
+
+func (m *mockCUI) Ask(query string) (string, error) {
+	m.AskInQuery = query
+	return m.AskOutAnswer, m.AskOutError
+}
+
+func (m *mockCUI) Confirm(query string) (bool, error) {
+	m.ConfirmInQuery = query
+	return m.ConfirmOutResult, m.ConfirmOutError
+}
+
 type mockStep struct {
 	DryInCtx       context.Context
 	DryOutError    error
@@ -0,0 +1,116 @@
+package action
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+)
+
+// moduleDir returns the directory of a module in slash-separated form.
+func moduleDir(m spec.Module) string {
+	return path.Clean(filepath.ToSlash(m.Dir))
+}
+
+// moduleOf returns the name of the innermost module that a file belongs to.
+// If the file does not belong to any module, an empty string is returned.
+func moduleOf(modules []spec.Module, file string) string {
+	var name string
+	depth := -1
+
+	for _, m := range modules {
+		dir := moduleDir(m)
+
+		d := 0
+		if dir != "." {
+			if !strings.HasPrefix(file, dir+"/") {
+				continue
+			}
+			d = strings.Count(dir, "/") + 1
+		}
+
+		if d > depth {
+			name, depth = m.Name, d
+		}
+	}
+
+	return name
+}
+
+// ChangedModules returns the modules in spec that have changes since their latest tags.
+// A module without any tag is considered changed.
+// Changes to the version files of a module are ignored, since they are updated after every release.
+// A file in a nested module only belongs to the innermost module.
+func ChangedModules(ctx context.Context, workDir string, s spec.Spec) ([]spec.Module, error) {
+	var gogit *step.GoGit
+	if step.UseGoGit(s.Git) {
+		gogit = step.NewGoGit(workDir)
+	}
+
+	changed := []spec.Module{}
+
+	for _, m := range s.Modules {
+		latest := &step.GitLatestTag{
+			WorkDir: workDir,
+			GoGit:   gogit,
+		}
+
+		if m.TagPrefix != "" {
+			latest.Match = m.TagPrefix + "*"
+		}
+
+		if err := latest.Run(ctx); err != nil {
+			return nil, err
+		}
+
+		if latest.Result.Tag == "" {
+			changed = append(changed, m)
+			continue
+		}
+
+		versionFiles := map[string]bool{}
+		for _, f := range m.VersionFiles {
+			versionFiles[path.Join(moduleDir(m), filepath.ToSlash(f.Path))] = true
+		}
+
+		// There is no version file when the version is derived from git tags
+		if s.VersionSource != step.VersionSourceGit {
+			version := &step.SemVerRead{
+				WorkDir:  filepath.Join(workDir, m.Dir),
+				Filename: m.VersionFile,
+				Pattern:  m.VersionPattern,
+
+				Versioning:   s.Versioning,
+				CalVerFormat: s.CalVerFormat,
+			}
+
+			if err := version.Run(ctx); err != nil {
+				return nil, err
+			}
+
+			versionFiles[path.Join(moduleDir(m), filepath.ToSlash(version.Result.Filename))] = true
+		}
+
+		diff := &step.GitChangedFiles{
+			WorkDir: workDir,
+			GoGit:   gogit,
+			Since:   latest.Result.Tag,
+		}
+
+		if err := diff.Run(ctx); err != nil {
+			return nil, err
+		}
+
+		for _, file := range diff.Result.Files {
+			if !versionFiles[file] && moduleOf(s.Modules, file) == m.Name {
+				changed = append(changed, m)
+				break
+			}
+		}
+	}
+
+	return changed, nil
+}
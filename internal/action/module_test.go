@@ -0,0 +1,126 @@
+package action
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModuleOf(t *testing.T) {
+	modules := []spec.Module{
+		{Name: "root", Dir: "."},
+		{Name: "sdk", Dir: "sdk"},
+		{Name: "lint", Dir: "./tools/lint/"},
+	}
+
+	tests := []struct {
+		file           string
+		expectedModule string
+	}{
+		{"README.md", "root"},
+		{"sdk/VERSION", "sdk"},
+		{"sdkv2/VERSION", "root"},
+		{"tools/README.md", "root"},
+		{"tools/lint/main.go", "lint"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expectedModule, moduleOf(modules, tc.file))
+	}
+
+	assert.Equal(t, "", moduleOf(modules[1:], "README.md"))
+}
+
+func TestChangedModules(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir, err := ioutil.TempDir("", "cherry-modules-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=octocat", "-c", "user.email=octocat@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	write := func(file, content string) {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	write("sdk/VERSION", "1.3.0\n")
+	write("tools/VERSION", "0.2.1\n")
+	write("tools/version.go", "package main\n\nconst Version = \"0.2.1\"\n")
+	write("api/VERSION", "0.1.0-0\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "Releasing")
+	git("tag", "sdk/v1.3.0")
+	git("tag", "tools/v0.2.1")
+
+	// Only the version files are updated after the releases
+	write("sdk/VERSION", "1.3.1-0\n")
+	write("tools/VERSION", "0.2.2-0\n")
+	write("tools/version.go", "package main\n\nconst Version = \"0.2.2-0\"\n")
+	git("commit", "-q", "-am", "Beginning")
+
+	s := spec.Spec{
+		Git: "cli",
+		Modules: []spec.Module{
+			{Name: "sdk", Dir: "sdk", TagPrefix: "sdk/"},
+			{Name: "tools", Dir: "tools", TagPrefix: "tools/", VersionFiles: []spec.VersionFile{{Path: "version.go"}}},
+			{Name: "api", Dir: "api", TagPrefix: "api/"},
+		},
+	}
+
+	ctx := context.Background()
+
+	t.Run("NoChange", func(t *testing.T) {
+		modules, err := ChangedModules(ctx, dir, s)
+		assert.NoError(t, err)
+		assert.Equal(t, []spec.Module{s.Modules[2]}, modules)
+	})
+
+	write("tools/main.go", "package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "Add main")
+
+	t.Run("Changed", func(t *testing.T) {
+		modules, err := ChangedModules(ctx, dir, s)
+		assert.NoError(t, err)
+		assert.Equal(t, []spec.Module{s.Modules[1], s.Modules[2]}, modules)
+	})
+
+	t.Run("GitVersion", func(t *testing.T) {
+		s := s
+		s.VersionSource = "git"
+
+		modules, err := ChangedModules(ctx, dir, s)
+		assert.NoError(t, err)
+		assert.Equal(t, []spec.Module{s.Modules[0], s.Modules[1], s.Modules[2]}, modules)
+	})
+
+	t.Run("NoVersionFile", func(t *testing.T) {
+		s := spec.Spec{
+			Git: "cli",
+			Modules: []spec.Module{
+				{Name: "sdk", Dir: "sdk", TagPrefix: "sdk/", VersionFile: "version.json"},
+			},
+		}
+
+		modules, err := ChangedModules(ctx, dir, s)
+		assert.Error(t, err)
+		assert.Nil(t, modules)
+	})
+}
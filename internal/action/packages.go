@@ -0,0 +1,149 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/linuxpkg"
+)
+
+const (
+	systemdUnitDir            = "/lib/systemd/system"
+	systemdUnitMode           = 0644
+	defaultSystemdPostInstall = "systemctl daemon-reload >/dev/null 2>&1 || true"
+)
+
+// packageFile converts a file in spec to a file installed by Linux packages.
+func packageFile(f spec.PackageFile, config bool) (step.PackageFile, error) {
+	if f.Src == "" || f.Dst == "" {
+		return step.PackageFile{}, fmt.Errorf("package file requires src and dst: %+v", f)
+	}
+
+	var mode os.FileMode
+	if f.Mode != "" {
+		m, err := strconv.ParseUint(f.Mode, 8, 32)
+		if err != nil {
+			return step.PackageFile{}, fmt.Errorf("invalid mode for package file %s: %s", f.Src, f.Mode)
+		}
+		mode = os.FileMode(m)
+	}
+
+	return step.PackageFile{
+		Src:    f.Src,
+		Dst:    f.Dst,
+		Mode:   mode,
+		Config: config,
+	}, nil
+}
+
+// packageFiles returns the files, configuration files, and systemd units installed by Linux packages.
+func packageFiles(p spec.Packages) ([]step.PackageFile, error) {
+	files := []step.PackageFile{}
+
+	for _, f := range p.Files {
+		pf, err := packageFile(f, false)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, pf)
+	}
+
+	for _, f := range p.ConfigFiles {
+		pf, err := packageFile(f, true)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, pf)
+	}
+
+	for _, unit := range p.SystemdUnits {
+		files = append(files, step.PackageFile{
+			Src:  unit,
+			Dst:  path.Join(systemdUnitDir, filepath.Base(unit)),
+			Mode: systemdUnitMode,
+		})
+	}
+
+	return files, nil
+}
+
+// linuxPackage returns the metadata of Linux packages for a release.
+// The systemd manager configuration is reloaded after installation if the packages have systemd units.
+func linuxPackage(s spec.Spec, version string) linuxpkg.Package {
+	p := s.Release.Packages
+
+	name := p.Name
+	if name == "" {
+		name = filepath.Base(s.Build.BinaryFile)
+	}
+
+	postInstall := p.PostInstall
+	if postInstall == "" && len(p.SystemdUnits) > 0 {
+		postInstall = defaultSystemdPostInstall
+	}
+
+	return linuxpkg.Package{
+		Name:        name,
+		Version:     version,
+		Maintainer:  p.Maintainer,
+		Description: p.Description,
+		Homepage:    p.Homepage,
+		License:     p.License,
+		Depends:     p.Depends,
+		PostInstall: postInstall,
+	}
+}
+
+// packageAssets returns the names of Linux packages built for the platforms of a release.
+func packageAssets(s spec.Spec, pkg linuxpkg.Package) []string {
+	assets := []string{}
+	for _, platform := range s.Build.Platforms {
+		if !strings.HasPrefix(platform, "linux-") {
+			continue
+		}
+
+		pkg.Arch = strings.TrimPrefix(platform, "linux-")
+		for _, format := range s.Release.Packages.Formats {
+			assets = append(assets, pkg.Filename(format))
+		}
+	}
+
+	return assets
+}
+
+// setPackages sets the parameters of step for building Linux packages.
+// In dry mode, the binaries are determined from the platforms since they are not built yet.
+func (r *release) setPackages(s spec.Spec, vars releaseVars, dry bool) error {
+	pkg := linuxPackage(s, vars.Version)
+	if len(packageAssets(s, pkg)) == 0 {
+		return errors.New("linux packages require at least one linux platform")
+	}
+
+	files, err := packageFiles(s.Release.Packages)
+	if err != nil {
+		return err
+	}
+
+	r.step33.Formats = s.Release.Packages.Formats
+	r.step33.BinDir = s.Release.Packages.BinDir
+	r.step33.Package = pkg
+	r.step33.Files = files
+
+	if dry {
+		r.step33.Binaries = []string{}
+		for _, platform := range s.Build.Platforms {
+			r.step33.Binaries = append(r.step33.Binaries, fmt.Sprintf("%s-%s", s.Build.BinaryFile, platform))
+		}
+	} else {
+		r.step33.Binaries = r.step15.Result.Binaries
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/linuxpkg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageFiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		packages      spec.Packages
+		expectedFiles []step.PackageFile
+		expectedError error
+	}{
+		{
+			name: "NoDst",
+			packages: spec.Packages{
+				Files: []spec.PackageFile{{Src: "README.md"}},
+			},
+			expectedError: errors.New("package file requires src and dst: {Src:README.md Dst: Mode:}"),
+		},
+		{
+			name: "InvalidMode",
+			packages: spec.Packages{
+				ConfigFiles: []spec.PackageFile{{Src: "app.yaml", Dst: "/etc/app/app.yaml", Mode: "rw"}},
+			},
+			expectedError: errors.New("invalid mode for package file app.yaml: rw"),
+		},
+		{
+			name: "Success",
+			packages: spec.Packages{
+				Files:        []spec.PackageFile{{Src: "README.md", Dst: "/usr/share/doc/app/README.md"}},
+				ConfigFiles:  []spec.PackageFile{{Src: "app.yaml", Dst: "/etc/app/app.yaml", Mode: "0600"}},
+				SystemdUnits: []string{"packaging/app.service"},
+			},
+			expectedFiles: []step.PackageFile{
+				{Src: "README.md", Dst: "/usr/share/doc/app/README.md"},
+				{Src: "app.yaml", Dst: "/etc/app/app.yaml", Mode: 0600, Config: true},
+				{Src: "packaging/app.service", Dst: "/lib/systemd/system/app.service", Mode: 0644},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files, err := packageFiles(tc.packages)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
+
+func TestLinuxPackage(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            spec.Spec
+		version         string
+		expectedPackage linuxpkg.Package
+	}{
+		{
+			name: "Defaults",
+			spec: spec.Spec{
+				Build: spec.Build{BinaryFile: "bin/app"},
+				Release: spec.Release{
+					Packages: spec.Packages{
+						Maintainer:   "Jane Doe <jane@example.com>",
+						Description:  "A test application",
+						SystemdUnits: []string{"app.service"},
+					},
+				},
+			},
+			version: "0.2.0",
+			expectedPackage: linuxpkg.Package{
+				Name:        "app",
+				Version:     "0.2.0",
+				Maintainer:  "Jane Doe <jane@example.com>",
+				Description: "A test application",
+				PostInstall: "systemctl daemon-reload >/dev/null 2>&1 || true",
+			},
+		},
+		{
+			name: "Custom",
+			spec: spec.Spec{
+				Build: spec.Build{BinaryFile: "bin/app"},
+				Release: spec.Release{
+					Packages: spec.Packages{
+						Name:         "app-cli",
+						Maintainer:   "Jane Doe <jane@example.com>",
+						Description:  "A test application",
+						Homepage:     "https://github.com/username/app",
+						License:      "MIT",
+						Depends:      []string{"git"},
+						SystemdUnits: []string{"app.service"},
+						PostInstall:  "systemctl enable app",
+					},
+				},
+			},
+			version: "0.2.0",
+			expectedPackage: linuxpkg.Package{
+				Name:        "app-cli",
+				Version:     "0.2.0",
+				Maintainer:  "Jane Doe <jane@example.com>",
+				Description: "A test application",
+				Homepage:    "https://github.com/username/app",
+				License:     "MIT",
+				Depends:     []string{"git"},
+				PostInstall: "systemctl enable app",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedPackage, linuxPackage(tc.spec, tc.version))
+		})
+	}
+}
+
+func TestPackageAssets(t *testing.T) {
+	s := spec.Spec{
+		Build: spec.Build{
+			Platforms: []string{"linux-amd64", "linux-arm64", "darwin-amd64", "windows-amd64"},
+		},
+		Release: spec.Release{
+			Packages: spec.Packages{Formats: []string{"deb", "rpm", "apk"}},
+		},
+	}
+
+	assets := packageAssets(s, linuxpkg.Package{Name: "app", Version: "0.2.0"})
+	assert.Equal(t, []string{
+		"app_0.2.0_amd64.deb", "app-0.2.0-1.x86_64.rpm", "app_0.2.0_x86_64.apk",
+		"app_0.2.0_arm64.deb", "app-0.2.0-1.aarch64.rpm", "app_0.2.0_aarch64.apk",
+	}, assets)
+}
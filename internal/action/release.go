@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/moorara/cherry/internal/spec"
@@ -16,6 +18,7 @@ import (
 const (
 	segmentKey = contextKey("ReleaseSegment")
 	commentKey = contextKey("ReleaseComment")
+	planKey    = contextKey("ReleasePlan")
 )
 
 // ContextWithReleaseParams returns a new context that has input parameters for Release action.
@@ -44,72 +47,151 @@ func ReleaseParamsFromContext(ctx context.Context) (segment semver.Segment, comm
 	return segment, comment
 }
 
+// ContextWithReleasePlan returns a new context that asks Release action to print the full plan of steps.
+func ContextWithReleasePlan(ctx context.Context) context.Context {
+	return context.WithValue(ctx, planKey, true)
+}
+
+// ReleasePlanFromContext returns true if Release action should print the full plan of steps.
+func ReleasePlanFromContext(ctx context.Context) bool {
+	plan, _ := ctx.Value(planKey).(bool)
+	return plan
+}
+
+// releasePlan is a summary of the changes a release will make.
+type releasePlan struct {
+	Repo           string
+	Module         string
+	Provider       string
+	Branch         string
+	CurrentVersion string
+	ReleaseVersion string
+	NextVersion    string
+	Tag            string
+	Changelog      bool
+	TagOnly        bool
+	Assets         []string
+	Homebrew       string
+	Scoop          string
+	Winget         string
+	Image          string
+	Steps          []cui.PlanStep
+}
+
 // release is the action for release command.
 type release struct {
-	ui     cui.CUI
-	step1  *step.GitGetRepo
-	step2  *step.GitGetBranch
-	step3  *step.GitStatus
-	step4  *step.GitPull
-	step5  *step.SemVerRead
-	step6  *step.SemVerUpdate
-	step7  *step.GitHubCreateRelease
-	step8  *step.ChangelogGenerate
-	step9  *step.GitAdd
-	step10 *step.GitCommit
-	step11 *step.GitTag
-	step12 *step.GoList
-	step13 *step.GitGetHEAD
-	step14 *step.GoVersion
-	step15 *step.GoBuild
-	step16 *step.GitHubUploadAssets
-	step17 *step.GitHubBranchProtection
-	step18 *step.GitHubBranchProtection
-	step19 *step.GitPush
-	step20 *step.GitPushTag
-	step21 *step.SemVerUpdate
-	step22 *step.GitAdd
-	step23 *step.GitCommit
-	step24 *step.GitPush
-	step25 *step.GitHubEditRelease
+	ui       cui.CUI
+	workDir  string
+	client   *http.Client
+	config   step.ProviderConfig
+	provider step.ReleaseProvider
+	gogit    *step.GoGit
+	module   *spec.Module
+	step1    *step.GitGetRepo
+	step2    *step.GitGetBranch
+	step3    *step.GitStatus
+	step4    *step.GitPull
+	step5    *step.SemVerRead
+	step6    *step.SemVerUpdate
+	step7    *step.ReleaseCreate
+	step8    *step.ChangelogGenerate
+	step9    *step.GitAdd
+	step10   *step.GitCommit
+	step11   *step.GitTag
+	step12   *step.GoList
+	step13   *step.GitGetHEAD
+	step14   *step.GoVersion
+	step15   *step.GoBuild
+	step16   *step.ReleaseUploadAssets
+	step17   *step.BranchProtection
+	step18   *step.BranchProtection
+	step19   *step.GitPush
+	step20   *step.GitPushTag
+	step21   *step.SemVerUpdate
+	step22   *step.GitAdd
+	step23   *step.GitCommit
+	step24   *step.GitPush
+	step25   *step.ReleaseEdit
+	step26   *step.GitVerifyTag
+	step27   *step.GitLatestTag
+	step28   *step.GoModMajor
+	step29   *step.GitHubUpdateFile
+	step30   *step.GitPublishFiles
+	step31   *step.GitPublishFiles
+	step32   *step.GitPublishFiles
+	step33   *step.LinuxPackages
+	step34   *step.ImageBuild
+	step35   *step.ImagePush
+	plan     releasePlan
 }
 
 // NewRelease creates an instance of Release action.
-func NewRelease(ui cui.CUI, workDir, githubToken string, s spec.Spec) Action {
-	transport := &http.Transport{}
-	client := &http.Client{
-		Transport: transport,
+// The release provider is created after the remote repository is known.
+// GitHub settings in spec are used if they are not set in config.
+func NewRelease(ui cui.CUI, workDir string, config step.ProviderConfig, s spec.Spec) Action {
+	client := step.NewHTTPClient()
+	config.UI = ui
+
+	if config.GitHubURL == "" {
+		config.GitHubURL = s.GitHub.BaseURL
+	}
+
+	if config.GitHubUploadURL == "" {
+		config.GitHubUploadURL = s.GitHub.UploadURL
+	}
+
+	if config.CABundle == "" {
+		config.CABundle = s.GitHub.CABundle
+	}
+
+	var gogit *step.GoGit
+	if step.UseGoGit(s.Git) {
+		gogit = step.NewGoGit(workDir)
 	}
 
 	return &release{
-		ui: ui,
+		ui:      ui,
+		workDir: workDir,
+		client:  client,
+		config:  config,
+		gogit:   gogit,
 		step1: &step.GitGetRepo{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step2: &step.GitGetBranch{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step3: &step.GitStatus{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step4: &step.GitPull{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step5: &step.SemVerRead{
 			WorkDir:  workDir,
+			GoGit:    gogit,
+			Source:   s.VersionSource,
 			Filename: s.VersionFile,
+			Pattern:  s.VersionPattern,
+			Match:    tagPattern(s.Release.Templates, ""),
+
+			Versioning:   s.Versioning,
+			CalVerFormat: s.CalVerFormat,
 		},
 		step6: &step.SemVerUpdate{
 			WorkDir:  workDir,
 			Filename: s.VersionFile,
+			Pattern:  s.VersionPattern,
+			Files:    versionFiles(s.VersionFiles),
 			Version:  "TBD",
 		},
-		step7: &step.GitHubCreateRelease{
-			Client:  client,
-			Token:   githubToken,
-			BaseURL: step.GitHubAPIURL,
-			Repo:    "TBD",
-			ReleaseData: step.GitHubReleaseData{
+		step7: &step.ReleaseCreate{
+			Provider: nil, // TBD
+			ReleaseData: step.ReleaseData{
 				Name:       "TBD",
 				TagName:    "TBD",
 				Target:     "TBD",
@@ -119,20 +201,23 @@ func NewRelease(ui cui.CUI, workDir, githubToken string, s spec.Spec) Action {
 		},
 		step8: &step.ChangelogGenerate{
 			WorkDir:     workDir,
-			GitHubToken: githubToken,
+			GitHubToken: config.GitHubToken,
 			Repo:        "TBD",
 			Tag:         "TBD",
 		},
 		step9: &step.GitAdd{
 			WorkDir: workDir,
+			GoGit:   gogit,
 			Files:   nil, // TBD
 		},
 		step10: &step.GitCommit{
 			WorkDir: workDir,
+			GoGit:   gogit,
 			Message: "TBD",
 		},
 		step11: &step.GitTag{
 			WorkDir:    workDir,
+			GoGit:      gogit,
 			Tag:        "TBD",
 			Annotation: "TBD",
 		},
@@ -142,6 +227,7 @@ func NewRelease(ui cui.CUI, workDir, githubToken string, s spec.Spec) Action {
 		},
 		step13: &step.GitGetHEAD{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step14: &step.GoVersion{
 			WorkDir: workDir,
@@ -153,61 +239,53 @@ func NewRelease(ui cui.CUI, workDir, githubToken string, s spec.Spec) Action {
 			BinaryFile: s.Build.BinaryFile,
 			Platforms:  nil, // TBD
 		},
-		step16: &step.GitHubUploadAssets{
-			Client:           client,
-			Token:            githubToken,
-			BaseURL:          step.GitHubAPIURL,
-			Repo:             "TBD",
-			ReleaseID:        0, // TBD
-			ReleaseUploadURL: "TBD",
-			AssetFiles:       nil, // TBD
+		step16: &step.ReleaseUploadAssets{
+			Provider:   nil, // TBD
+			AssetFiles: nil, // TBD
 		},
-		step17: &step.GitHubBranchProtection{
-			Client:  client,
-			Token:   githubToken,
-			BaseURL: step.GitHubAPIURL,
-			Repo:    "TBD",
-			Branch:  "TBD",
-			Enabled: false,
+		step17: &step.BranchProtection{
+			Provider: nil, // TBD
+			Branch:   "TBD",
+			Enabled:  false,
 		},
-		step18: &step.GitHubBranchProtection{
-			Client:  client,
-			Token:   githubToken,
-			BaseURL: step.GitHubAPIURL,
-			Repo:    "TBD",
-			Branch:  "TBD",
-			Enabled: true,
+		step18: &step.BranchProtection{
+			Provider: nil, // TBD
+			Branch:   "TBD",
+			Enabled:  true,
 		},
 		step19: &step.GitPush{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
 		step20: &step.GitPushTag{
 			WorkDir: workDir,
+			GoGit:   gogit,
 			Tag:     "TBD",
 		},
 		step21: &step.SemVerUpdate{
 			WorkDir:  workDir,
 			Filename: s.VersionFile,
+			Pattern:  s.VersionPattern,
+			Files:    versionFiles(s.VersionFiles),
 			Version:  "TBD",
 		},
 		step22: &step.GitAdd{
 			WorkDir: workDir,
+			GoGit:   gogit,
 			Files:   nil, // TBD
 		},
 		step23: &step.GitCommit{
 			WorkDir: workDir,
+			GoGit:   gogit,
 			Message: "TBD",
 		},
 		step24: &step.GitPush{
 			WorkDir: workDir,
+			GoGit:   gogit,
 		},
-		step25: &step.GitHubEditRelease{
-			Client:    client,
-			Token:     githubToken,
-			BaseURL:   step.GitHubAPIURL,
-			Repo:      "TBD",
-			ReleaseID: 0, // TBD
-			ReleaseData: step.GitHubReleaseData{
+		step25: &step.ReleaseEdit{
+			Provider: nil, // TBD
+			ReleaseData: step.ReleaseData{
 				Name:       "TBD",
 				TagName:    "TBD",
 				Target:     "TBD",
@@ -216,17 +294,328 @@ func NewRelease(ui cui.CUI, workDir, githubToken string, s spec.Spec) Action {
 				Body:       "TBD",
 			},
 		},
+		step26: &step.GitVerifyTag{
+			WorkDir: workDir,
+			Tag:     "TBD",
+			Sign:    nil, // TBD
+		},
+		step27: &step.GitLatestTag{
+			WorkDir: workDir,
+			GoGit:   gogit,
+			// Only the release tags are considered for the previous release
+			Match: tagPattern(s.Release.Templates, ""),
+		},
+		step28: &step.GoModMajor{
+			WorkDir: workDir,
+			Major:   0, // TBD
+		},
+		step29: &step.GitHubUpdateFile{
+			Client:  client,
+			Token:   "TBD",
+			BaseURL: "TBD",
+			Repo:    "TBD",
+			Path:    "TBD",
+			Content: nil, // TBD
+			Message: "TBD",
+		},
+		step30: &step.GitPublishFiles{
+			URL:     "TBD",
+			Files:   nil, // TBD
+			Message: "TBD",
+		},
+		step31: &step.GitPublishFiles{
+			URL:     "TBD",
+			Files:   nil, // TBD
+			Message: "TBD",
+		},
+		step32: &step.GitPublishFiles{
+			URL:     "TBD",
+			Files:   nil, // TBD
+			Message: "TBD",
+		},
+		step33: &step.LinuxPackages{
+			WorkDir:  workDir,
+			Formats:  nil, // TBD
+			Binaries: nil, // TBD
+		},
+		step34: &step.ImageBuild{
+			WorkDir:  workDir,
+			Binaries: nil, // TBD
+			Tags:     nil, // TBD
+			File:     "TBD",
+		},
+		step35: &step.ImagePush{
+			Client:     client,
+			Registry:   "TBD",
+			Repository: "TBD",
+			Image:      nil, // TBD
+			Tags:       nil, // TBD
+		},
+	}
+}
+
+// NewModuleRelease creates an instance of Release action for a module in a monorepo.
+// The version files, build, and tag prefix of module are used instead of the ones for repository.
+func NewModuleRelease(ui cui.CUI, workDir string, config step.ProviderConfig, s spec.Spec, m spec.Module) Action {
+	s.VersionFile = m.VersionFile
+	s.VersionPattern = m.VersionPattern
+	s.VersionFiles = m.VersionFiles
+	s.Build = m.Build
+
+	r := NewRelease(ui, workDir, config, s).(*release)
+	r.module = &m
+
+	moduleDir := filepath.Join(workDir, m.Dir)
+	r.step5.WorkDir = moduleDir
+	r.step6.WorkDir = moduleDir
+	r.step12.WorkDir = moduleDir
+	r.step14.WorkDir = moduleDir
+	r.step15.WorkDir = moduleDir
+	r.step21.WorkDir = moduleDir
+	r.step28.WorkDir = moduleDir
+	r.step33.WorkDir = moduleDir
+	r.step34.WorkDir = moduleDir
+
+	// Only the release tags of module are considered for the previous release
+	r.step27.Match = tagPattern(s.Release.Templates, m.TagPrefix)
+	r.step5.Match = tagPattern(s.Release.Templates, m.TagPrefix)
+
+	return r
+}
+
+// spec returns the spec from context with the build specifications of module if releasing a module.
+func (r *release) spec(ctx context.Context) spec.Spec {
+	s := SpecFromContext(ctx)
+	if r.module != nil {
+		s.Build = r.module.Build
+	}
+
+	// Publishing requires the release artifacts, so it is skipped if they are not built
+	if !s.Release.Build {
+		s.Release.Homebrew = spec.Homebrew{}
+		s.Release.Scoop = spec.Scoop{}
+		s.Release.Winget = spec.Winget{}
+		s.Release.Packages = spec.Packages{}
+		s.Release.Docker = spec.Docker{}
+	}
+
+	return s
+}
+
+// skippedPublish returns the configured publishing that is skipped since the release artifacts are not built.
+func skippedPublish(s spec.Spec) []string {
+	skipped := []string{}
+	if s.Release.Build {
+		return skipped
+	}
+
+	if s.Release.Homebrew.Enabled() {
+		skipped = append(skipped, "Homebrew formula")
+	}
+
+	if s.Release.Scoop.Enabled() {
+		skipped = append(skipped, "Scoop manifest")
+	}
+
+	if s.Release.Winget.Enabled() {
+		skipped = append(skipped, "winget manifests")
+	}
+
+	if s.Release.Packages.Enabled() {
+		skipped = append(skipped, "Linux packages")
+	}
+
+	if s.Release.Docker.Enabled() {
+		skipped = append(skipped, "Docker image")
+	}
+
+	return skipped
+}
+
+// moduleName returns the name of module being released if any.
+func (r *release) moduleName() string {
+	if r.module == nil {
+		return ""
+	}
+
+	return r.module.Name
+}
+
+// tagPrefix returns the prefix for the release tag.
+func (r *release) tagPrefix() string {
+	if r.module == nil {
+		return ""
+	}
+
+	return r.module.TagPrefix
+}
+
+// checkUnreleased makes sure there are commits to release when the version is derived from git tags.
+// step5 should be run first.
+func (r *release) checkUnreleased(fromGit bool) error {
+	if fromGit && r.step5.Result.Tag != "" && r.step5.Result.Commits == 0 {
+		return fmt.Errorf("no commit since the latest release %s", r.step5.Result.Tag)
+	}
+
+	return nil
+}
+
+// versions are the versions involved in a release.
+type versions struct {
+	Current string
+	Release string
+	Next    string
+	// Major is the major version of release if it is different from the current major version.
+	Major uint
+}
+
+// releaseVersions returns the current version read by a step, the version to release, and the next version for development.
+// For calendar versioning, the release version is determined by the current date and there is no next version.
+// The step should be run first.
+func releaseVersions(s spec.Spec, st *step.SemVerRead, segment semver.Segment) (versions, error) {
+	if s.Versioning == step.VersioningCalVer {
+		curr := st.Result.CalVer
+		release, err := curr.Next(time.Now())
+		if err != nil {
+			return versions{}, err
+		}
+
+		return versions{
+			Current: curr.Version(),
+			Release: release.Version(),
+		}, nil
+	}
+
+	curr := st.Result.Version
+	release, next := curr.Release(segment)
+	next.Prerelease = []string{"0"}
+
+	v := versions{
+		Current: curr.Version(),
+		Release: release.Version(),
+		Next:    next.Version(),
+	}
+
+	if release.Major != curr.Major {
+		v.Major = release.Major
+	}
+
+	return v, nil
+}
+
+// updatedFiles returns the paths of all version files updated by a step relative to the root of repository.
+func (r *release) updatedFiles(st *step.SemVerUpdate) []string {
+	files := []string{r.repoFile(st.Result.Filename)}
+	for _, file := range st.Result.Files {
+		files = append(files, r.repoFile(file))
+	}
+
+	return files
+}
+
+// repoFile returns the path of a file in module directory relative to the root of repository.
+func (r *release) repoFile(file string) string {
+	if r.module == nil {
+		return file
+	}
+
+	return filepath.Join(r.module.Dir, file)
+}
+
+// createProvider creates the release provider for the remote repository.
+// If no provider is specified in spec, it will be determined from the remote repository host.
+// step1 should be run first.
+func (r *release) createProvider(s spec.Spec) error {
+	provider, err := step.NewReleaseProvider(r.client, r.config, s.Release.Provider, r.step1.Result.Host, r.step1.Result.Repo)
+	if err != nil {
+		return err
+	}
+
+	r.provider = provider
+
+	return nil
+}
+
+// setChangelogGitHub sets the GitHub urls and access token for generating the change log.
+// The access token may be an installation token if authenticating as a GitHub App.
+func (r *release) setChangelogGitHub(ctx context.Context) error {
+	p, ok := r.provider.(*step.GitHubProvider)
+	if !ok {
+		return nil
+	}
+
+	token, err := p.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.step8.GitHubToken = token
+	r.step8.BaseURL = p.URL
+	r.step8.APIURL = p.APIURL
+
+	return nil
+}
+
+// signing returns the configurations for signing the release commits and tag.
+// If signing is not enabled in spec, nil is returned.
+func signing(s spec.Spec) *step.GitSigning {
+	if !s.Release.Sign {
+		return nil
+	}
+
+	return &step.GitSigning{
+		Format: s.Release.SigningFormat,
+		Key:    s.Release.SigningKey,
+	}
+}
+
+// versionFiles returns the additional version files for updating along with the version file.
+func versionFiles(files []spec.VersionFile) []step.VersionFile {
+	if len(files) == 0 {
+		return nil
+	}
+
+	vfs := make([]step.VersionFile, len(files))
+	for i, f := range files {
+		vfs[i] = step.VersionFile{
+			Filename: f.Path,
+			Pattern:  f.Pattern,
+		}
 	}
+
+	return vfs
 }
 
-func (r *release) getLDFlags(s spec.Spec) string {
+// setGitToken sets the access token of release provider for pushing and pulling using the pure-Go git.
+func (r *release) setGitToken(ctx context.Context) error {
+	if r.gogit == nil {
+		return nil
+	}
+
+	switch p := r.provider.(type) {
+	case *step.GitHubProvider:
+		token, err := p.AccessToken(ctx)
+		if err != nil {
+			return err
+		}
+		r.gogit.Token = token
+	case *step.GitLabProvider:
+		r.gogit.Token = p.Token
+	case *step.GiteaProvider:
+		r.gogit.Token = p.Token
+	}
+
+	return nil
+}
+
+func (r *release) getLDFlags(s spec.Spec, version string) string {
 	buildTool := s.ToolName
 	if s.ToolVersion != "" {
 		buildTool += "@" + s.ToolVersion
 	}
 
 	vPkg := r.step12.Result.PackagePath
-	versionFlag := fmt.Sprintf("-X %s.Version=%s", vPkg, r.step6.Version)
+	versionFlag := fmt.Sprintf("-X %s.Version=%s", vPkg, version)
 	revisionFlag := fmt.Sprintf("-X %s.Revision=%s", vPkg, r.step13.Result.ShortSHA)
 	branchFlag := fmt.Sprintf("-X %s.Branch=%s", vPkg, r.step2.Result.Name)
 	goVersionFlag := fmt.Sprintf("-X %s.GoVersion=%s", vPkg, r.step14.Result.Version)
@@ -237,18 +626,313 @@ func (r *release) getLDFlags(s spec.Spec) string {
 	return ldflags
 }
 
+// printPlan prints a summary of the changes the release will make.
+func (r *release) printPlan() {
+	p := r.plan
+
+	r.ui.Outputf("📋 Release plan for %s (%s):", p.Repo, p.Provider)
+	if p.Module != "" {
+		r.ui.Outputf("     Module:      %s", p.Module)
+	}
+	if p.TagOnly {
+		r.ui.Outputf("     Version:     %s ➡️  %s (from git tags)", p.CurrentVersion, p.ReleaseVersion)
+	} else if p.NextVersion == "" {
+		r.ui.Outputf("     Version:     %s ➡️  %s", p.CurrentVersion, p.ReleaseVersion)
+	} else {
+		r.ui.Outputf("     Version:     %s ➡️  %s (next: %s)", p.CurrentVersion, p.ReleaseVersion, p.NextVersion)
+	}
+	r.ui.Outputf("     Tag:         %s", p.Tag)
+	r.ui.Outputf("     Branch:      %s", p.Branch)
+
+	if p.Changelog {
+		r.ui.Outputf("     Changelog:   %s", "CHANGELOG.md will be created/updated")
+	}
+
+	if len(p.Assets) > 0 {
+		r.ui.Outputf("     Assets:      %s", strings.Join(p.Assets, ", "))
+	} else {
+		r.ui.Outputf("     Assets:      %s", "none")
+	}
+
+	if p.Homebrew != "" {
+		r.ui.Outputf("     Homebrew:    %s", p.Homebrew)
+	}
+
+	if p.Scoop != "" {
+		r.ui.Outputf("     Scoop:       %s", p.Scoop)
+	}
+
+	if p.Winget != "" {
+		r.ui.Outputf("     Winget:      %s", p.Winget)
+	}
+
+	if p.Image != "" {
+		r.ui.Outputf("     Image:       %s", p.Image)
+	}
+
+	if !p.TagOnly {
+		r.ui.Warnf("     Protection:  push to %s branch will be temporarily enabled and disabled again", p.Branch)
+	}
+}
+
+// planSteps returns the steps the release will execute with their resolved parameters.
+// Dry should be run first, so the parameters of steps are resolved.
+func (r *release) planSteps(s spec.Spec) []cui.PlanStep {
+	param := func(name, value string) cui.PlanParam {
+		return cui.PlanParam{Name: name, Value: value}
+	}
+
+	plan := func(st step.Step, description string, params ...cui.PlanParam) cui.PlanStep {
+		return cui.PlanStep{Step: stepName(st), Description: description, Params: params}
+	}
+
+	curr := r.plan.ReleaseVersion
+	var releaseFiles []string
+
+	steps := []cui.PlanStep{
+		plan(r.step4, fmt.Sprintf("Pull %s branch", r.plan.Branch), param("branch", r.plan.Branch)),
+	}
+
+	if !r.plan.TagOnly {
+		releaseFiles = r.step9.Files
+		steps = append(steps,
+			plan(r.step6, "Update the version files with the release version", param("files", strings.Join(r.updatedFiles(r.step6), ", ")), param("version", r.step6.Version)),
+		)
+
+		if len(r.step28.Result.Files) > 0 {
+			steps = append(steps,
+				plan(r.step28, fmt.Sprintf("Rewrite the Go module path for v%d", r.step28.Major), param("module", r.step28.Result.NewPath), param("files", strings.Join(r.step28.Result.Files, ", "))),
+			)
+		}
+	}
+
+	steps = append(steps,
+		plan(r.step7, "Create a draft release", param("provider", r.plan.Provider), param("name", r.step7.ReleaseData.Name), param("tag", r.step7.ReleaseData.TagName), param("target", r.step7.ReleaseData.Target)),
+	)
+
+	if r.plan.Changelog {
+		releaseFiles = append(releaseFiles, r.step8.Result.Filename)
+		steps = append(steps,
+			plan(r.step8, "Create/Update the change log", param("file", r.step8.Result.Filename), param("repo", r.step8.Repo), param("tag", r.step8.Tag)),
+		)
+	}
+
+	if !r.plan.TagOnly {
+		steps = append(steps,
+			plan(r.step9, "Add files to staging", param("files", strings.Join(releaseFiles, ", "))),
+			plan(r.step10, fmt.Sprintf("Create a commit for %s", curr), param("message", r.step10.Message), param("signed", signedParam(r.step10.Sign))),
+		)
+	}
+
+	steps = append(steps,
+		plan(r.step11, fmt.Sprintf("Create tag %s", r.step11.Tag), param("tag", r.step11.Tag), param("annotation", r.step11.Annotation), param("signed", signedParam(r.step11.Sign))),
+	)
+
+	if s.Release.Sign {
+		steps = append(steps,
+			plan(r.step26, fmt.Sprintf("Verify the signature of tag %s", r.step26.Tag), param("tag", r.step26.Tag)),
+		)
+	}
+
+	if s.Release.Build {
+		steps = append(steps,
+			plan(r.step15, "Cross-compile and build artifacts", param("main", r.step15.MainFile), param("binary", r.step15.BinaryFile), param("platforms", strings.Join(r.step15.Platforms, ", ")), param("ldflags", r.step15.LDFlags)),
+		)
+
+		if s.Release.Packages.Enabled() {
+			steps = append(steps,
+				plan(r.step33, "Build Linux packages", param("name", r.step33.Package.Name), param("version", r.step33.Package.Version), param("formats", strings.Join(r.step33.Formats, ", "))),
+			)
+		}
+
+		if s.Release.Docker.Enabled() {
+			steps = append(steps,
+				plan(r.step34, "Build OCI image", param("image", s.Release.Docker.Image), param("platforms", strings.Join(r.step34.Result.Platforms, ", ")), param("tags", strings.Join(r.step34.Tags, ", ")), param("file", r.step34.File)),
+			)
+		}
+
+		steps = append(steps,
+			plan(r.step16, fmt.Sprintf("Upload artifacts to release %s", curr), param("assets", strings.Join(r.plan.Assets, ", "))),
+		)
+	}
+
+	// Only the release tag is pushed when the version is derived from git tags
+	if r.plan.TagOnly {
+		steps = append(steps,
+			plan(r.step20, fmt.Sprintf("Push release tag %s", r.step20.Tag), param("tag", r.step20.Tag)),
+			plan(r.step25, fmt.Sprintf("Publish release %s", curr), param("name", r.step25.ReleaseData.Name), param("tag", r.step25.ReleaseData.TagName), param("target", r.step25.ReleaseData.Target), param("body", r.step25.ReleaseData.Body)),
+		)
+
+		return append(steps, r.planPublish(s)...)
+	}
+
+	steps = append(steps,
+		plan(r.step17, fmt.Sprintf("Temporarily enable push to %s branch", r.step17.Branch), param("branch", r.step17.Branch), param("protection", "disabled")),
+		plan(r.step19, fmt.Sprintf("Push release commit %s", curr), param("branch", r.plan.Branch)),
+		plan(r.step20, fmt.Sprintf("Push release tag %s", r.step20.Tag), param("tag", r.step20.Tag)),
+	)
+
+	if r.plan.NextVersion != "" {
+		steps = append(steps,
+			plan(r.step21, "Update the version files with the next version", param("files", strings.Join(r.updatedFiles(r.step21), ", ")), param("version", r.step21.Version)),
+			plan(r.step22, "Add files to staging", param("files", strings.Join(r.step22.Files, ", "))),
+			plan(r.step23, fmt.Sprintf("Create a commit for %s", r.plan.NextVersion), param("message", r.step23.Message), param("signed", signedParam(r.step23.Sign))),
+			plan(r.step24, fmt.Sprintf("Push commit for next version %s", r.plan.NextVersion), param("branch", r.plan.Branch)),
+		)
+	}
+
+	steps = append(steps,
+		plan(r.step25, fmt.Sprintf("Publish release %s", curr), param("name", r.step25.ReleaseData.Name), param("tag", r.step25.ReleaseData.TagName), param("target", r.step25.ReleaseData.Target), param("body", r.step25.ReleaseData.Body)),
+	)
+
+	steps = append(steps, r.planPublish(s)...)
+	steps = append(steps,
+		plan(r.step18, fmt.Sprintf("Re-disable push to %s branch", r.plan.Branch), param("branch", r.plan.Branch), param("protection", "enabled")),
+	)
+
+	return steps
+}
+
+// planPublish returns the planned steps for publishing the package manifests and the image after the release.
+func (r *release) planPublish(s spec.Spec) []cui.PlanStep {
+	param := func(name, value string) cui.PlanParam {
+		return cui.PlanParam{Name: name, Value: value}
+	}
+
+	plan := func(st step.Step, desc string, params ...cui.PlanParam) cui.PlanStep {
+		return cui.PlanStep{Step: stepName(st), Description: desc, Params: params}
+	}
+
+	steps := []cui.PlanStep{}
+
+	if h := s.Release.Homebrew; h.Enabled() {
+		var file, message string
+		if h.Method == homebrewMethodGit {
+			file, message = gitFilePaths(r.step30.Files), r.step30.Message
+		} else {
+			file, message = r.step29.Path, r.step29.Message
+		}
+
+		steps = append(steps,
+			plan(r.homebrewStep(s), "Publish the Homebrew formula to tap", param("tap", homebrewTap(h)), param("file", file), param("message", message)),
+		)
+	}
+
+	if s.Release.Scoop.Enabled() {
+		steps = append(steps,
+			plan(r.step31, "Publish the Scoop manifest to bucket", param("bucket", r.step31.URL), param("file", gitFilePaths(r.step31.Files)), param("message", r.step31.Message)),
+		)
+	}
+
+	if s.Release.Winget.Enabled() {
+		steps = append(steps,
+			plan(r.step32, "Publish the winget manifests to repository", param("repo", r.step32.URL), param("files", gitFilePaths(r.step32.Files)), param("message", r.step32.Message)),
+		)
+	}
+
+	if d := s.Release.Docker; d.Enabled() && d.Push {
+		steps = append(steps,
+			plan(r.step35, "Push image to registry", param("registry", r.step35.Registry), param("repository", r.step35.Repository), param("tags", strings.Join(r.step35.Tags, ", "))),
+		)
+	}
+
+	return steps
+}
+
+// publishSteps returns the enabled steps for publishing the package manifests and the image in the order they are run.
+func (r *release) publishSteps(s spec.Spec) []step.Step {
+	steps := []step.Step{}
+
+	if s.Release.Homebrew.Enabled() {
+		steps = append(steps, r.homebrewStep(s))
+	}
+
+	if s.Release.Scoop.Enabled() {
+		steps = append(steps, r.step31)
+	}
+
+	if s.Release.Winget.Enabled() {
+		steps = append(steps, r.step32)
+	}
+
+	if d := s.Release.Docker; d.Enabled() && d.Push {
+		steps = append(steps, r.step35)
+	}
+
+	return steps
+}
+
+// gitFilePaths returns the comma-separated paths of files to be committed.
+func gitFilePaths(files []step.GitFile) string {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+
+	return strings.Join(paths, ", ")
+}
+
+// signedParam returns the value of signed parameter for a planned commit or tag.
+func signedParam(sign *step.GitSigning) string {
+	if sign == nil {
+		return "no"
+	}
+
+	format := sign.Format
+	if format == "" {
+		format = step.SigningFormatGPG
+	}
+
+	if sign.Key == "" {
+		return format
+	}
+
+	return fmt.Sprintf("%s (%s)", format, sign.Key)
+}
+
+// printSteps prints the steps the release will execute with their resolved parameters.
+func (r *release) printSteps() {
+	r.ui.Outputf("📋 Release steps:")
+
+	for i, st := range r.plan.Steps {
+		r.ui.Outputf("  %2d. %s (%s)", i+1, st.Description, st.Step)
+		for _, p := range st.Params {
+			lines := strings.Split(p.Value, "\n")
+			r.ui.Outputf("        %-11s %s", p.Name+":", lines[0])
+			for _, line := range lines[1:] {
+				r.ui.Outputf("        %-11s %s", "", line)
+			}
+		}
+	}
+}
+
 // Dry is a dry run of the action.
 func (r *release) Dry(ctx context.Context) error {
 	r.ui.Outputf("⏺️  Running preflight checks ...")
 
-	s := SpecFromContext(ctx)
-	segment, _ := ReleaseParamsFromContext(ctx)
+	s := r.spec(ctx)
+	segment, comment := ReleaseParamsFromContext(ctx)
+
+	for _, name := range skippedPublish(SpecFromContext(ctx)) {
+		r.ui.Warnf("⚠️  Skipping %s since release artifacts are not built (-build)", name)
+	}
+
+	templates, err := parseTemplates(s.Release.Templates)
+	if err != nil {
+		return err
+	}
+	templates.tagPrefix = r.tagPrefix()
 
 	// Get repo name
 	if err := r.step1.Run(ctx); err != nil {
 		return err
 	}
 
+	// Create the release provider for repo
+	if err := r.createProvider(s); err != nil {
+		return err
+	}
+
 	// Get branch name
 	if err := r.step2.Run(ctx); err != nil {
 		return err
@@ -277,47 +961,117 @@ func (r *release) Dry(ctx context.Context) error {
 		return err
 	}
 
-	// Release the version
-	curr, next := r.step5.Result.Version.Release(segment)
-	next.Prerelease = []string{"0"}
-
-	// Dry -- Update the version file with the current version
-	r.step6.Version = curr.Version()
-	if err := r.step6.Dry(ctx); err != nil {
+	// When the version is derived from git tags, only a tag is created and pushed for the release
+	fromGit := s.VersionSource == step.VersionSourceGit
+	if err := r.checkUnreleased(fromGit); err != nil {
 		return err
 	}
 
-	// Dry -- Create a draft release
-	r.step7.Repo = r.step1.Result.Repo
-	if err := r.step7.Dry(ctx); err != nil {
+	// Release the version
+	v, err := releaseVersions(s, r.step5, segment)
+	if err != nil {
 		return err
 	}
 
-	// Dry -- Create/Update change log
-	r.step8.Repo = r.step1.Result.Repo
-	r.step8.Tag = curr.GitTag()
-	if err := r.step8.Dry(ctx); err != nil {
+	// No commit is created for the next version when there is no next version (calendar versioning)
+	nextCommit := !fromGit && v.Next != ""
+
+	// Get the previous release tag
+	if err := r.step27.Run(ctx); err != nil {
 		return err
 	}
 
-	// Dry -- Add unstaged to files to staging
-	// The CHANGELOG.md file may not exist if this is the first release
-	r.step9.Files = []string{r.step6.Result.Filename}
-	if err := r.step9.Dry(ctx); err != nil {
+	vars := releaseVars{
+		Version:         v.Release,
+		PreviousVersion: versionFromTag(s, r.step27.Result.Tag),
+		NextVersion:     v.Next,
+		PreviousTag:     r.step27.Result.Tag,
+		Module:          r.moduleName(),
+		Comment:         comment,
+	}
+
+	// Render the commit messages, tag, and release name
+	text, err := templates.render(&vars)
+	if err != nil {
 		return err
 	}
 
-	// Dry -- Create a commit for current version
-	r.step10.Message = fmt.Sprintf("Releasing %s", curr.Version())
-	if err := r.step10.Dry(ctx); err != nil {
+	if !fromGit {
+		// Dry -- Update the version file with the current version
+		r.step6.Version = v.Release
+		if err := r.step6.Dry(ctx); err != nil {
+			return err
+		}
+
+		// Dry -- Rewrite the Go module path for the major version
+		if v.Major != 0 {
+			r.step28.Major = v.Major
+			if err := r.step28.Dry(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Dry -- Create a draft release
+	r.step7.Provider = r.provider
+	r.step7.ReleaseData.Name = text.ReleaseName
+	r.step7.ReleaseData.TagName = text.Tag
+	r.step7.ReleaseData.Target = r.step2.Result.Name
+	if err := r.step7.Dry(ctx); err != nil {
 		return err
 	}
 
+	// The change log is generated from GitHub issues and pull requests.
+	// The change log file cannot be committed when the version is derived from git tags.
+	changelog := r.provider.Name() == step.ProviderGitHub && !fromGit
+	if changelog {
+		// Dry -- Create/Update change log
+		if err := r.setChangelogGitHub(ctx); err != nil {
+			return err
+		}
+		r.step8.Repo = r.step1.Result.Repo
+		r.step8.Tag = text.Tag
+		if err := r.step8.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
+	if !fromGit {
+		// Dry -- Add unstaged to files to staging
+		// The CHANGELOG.md file may not exist if this is the first release
+		r.step9.Files = r.updatedFiles(r.step6)
+		for _, file := range r.step28.Result.Files {
+			r.step9.Files = append(r.step9.Files, r.repoFile(file))
+		}
+		if err := r.step9.Dry(ctx); err != nil {
+			return err
+		}
+
+		// Dry -- Create a commit for current version
+		r.step10.Message = text.ReleaseCommit
+		r.step10.Sign = signing(s)
+		if err := r.step10.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
 	// Dry -- Create a tag for current version
+	r.step11.Tag = text.Tag
+	r.step11.Annotation = text.TagAnnotation
+	r.step11.Sign = signing(s)
 	if err := r.step11.Dry(ctx); err != nil {
 		return err
 	}
 
+	if s.Release.Sign {
+		// Dry -- Verify the signature of tag for current version
+		r.step26.Tag = text.Tag
+		r.step26.Sign = signing(s)
+		if err := r.step26.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
 	if s.Release.Build {
 		// Find package version path
 		if err := r.step12.Run(ctx); err != nil {
@@ -335,88 +1089,230 @@ func (r *release) Dry(ctx context.Context) error {
 		}
 
 		// Dry -- Cross-compile and build artifacts
-		r.step15.LDFlags = r.getLDFlags(s)
+		r.step15.LDFlags = r.getLDFlags(s, v.Release)
+		r.step15.Platforms = s.Build.Platforms
 		if err := r.step15.Dry(ctx); err != nil {
 			return err
 		}
 
 		// Dry -- Upload build artifacts to release
-		r.step16.Repo = r.step1.Result.Repo
-		r.step16.ReleaseID = r.step7.Result.Release.ID
+		r.step16.Provider = r.provider
+		r.step16.Release = r.step7.Result.Release
 		if err := r.step16.Dry(ctx); err != nil {
 			return err
 		}
 	}
 
-	// Dry -- Temporarily disable the master branch protection
-	r.step17.Repo = r.step1.Result.Repo
-	r.step17.Branch = r.step2.Result.Name
-	if err := r.step17.Dry(ctx); err != nil {
-		return err
+	if s.Release.Packages.Enabled() {
+		// Dry -- Build Linux packages
+		if err := r.setPackages(s, vars, true); err != nil {
+			return err
+		}
+		if err := r.step33.Dry(ctx); err != nil {
+			return err
+		}
 	}
 
-	// Dry -- Make sure we re-enable the master branch protection
-	defer func() {
-		r.step18.Repo = r.step1.Result.Repo
-		r.step18.Branch = r.step2.Result.Name
-		_ = r.step18.Dry(ctx)
-	}()
+	if s.Release.Docker.Enabled() {
+		// Dry -- Build OCI image
+		if err := r.setImage(s, vars, true); err != nil {
+			return err
+		}
+		if err := r.step34.Dry(ctx); err != nil {
+			return err
+		}
+	}
 
-	// Dry -- Push the commit for current release
-	if err := r.step19.Dry(ctx); err != nil {
-		return err
+	if !fromGit {
+		// Dry -- Temporarily disable the master branch protection
+		r.step17.Provider = r.provider
+		r.step17.Branch = r.step2.Result.Name
+		if err := r.step17.Dry(ctx); err != nil {
+			return err
+		}
+
+		// Dry -- Make sure we re-enable the master branch protection
+		defer func() {
+			r.step18.Provider = r.provider
+			r.step18.Branch = r.step2.Result.Name
+			_ = r.step18.Dry(ctx)
+		}()
+
+		// Dry -- Push the commit for current release
+		if err := r.step19.Dry(ctx); err != nil {
+			return err
+		}
 	}
 
 	// Dry -- Push the tag for current release
+	r.step20.Tag = text.Tag
 	if err := r.step20.Dry(ctx); err != nil {
 		return err
 	}
 
-	// Dry -- Update the version file with the next version
-	r.step21.Version = next.Version()
-	if err := r.step21.Dry(ctx); err != nil {
-		return err
-	}
+	if nextCommit {
+		// Dry -- Update the version file with the next version
+		r.step21.Version = v.Next
+		if err := r.step21.Dry(ctx); err != nil {
+			return err
+		}
 
-	// Dry -- Add unstaged to files to staging
-	r.step22.Files = []string{r.step21.Result.Filename}
-	if err := r.step22.Dry(ctx); err != nil {
-		return err
+		// Dry -- Add unstaged to files to staging
+		r.step22.Files = r.updatedFiles(r.step21)
+		if err := r.step22.Dry(ctx); err != nil {
+			return err
+		}
+
+		//  Dry -- Create a commit for next version
+		r.step23.Message = text.NextCommit
+		r.step23.Sign = signing(s)
+		if err := r.step23.Dry(ctx); err != nil {
+			return err
+		}
+
+		// Dry -- Push the commit for next release
+		if err := r.step24.Dry(ctx); err != nil {
+			return err
+		}
 	}
 
-	//  Dry -- Create a commit for next version
-	r.step23.Message = fmt.Sprintf("Beginning %s [skip ci]", next.Version())
-	if err := r.step23.Dry(ctx); err != nil {
-		return err
+	// The change log is generated during the release, so a placeholder is used for previewing the release body
+	if changelog {
+		vars.Changelog = fmt.Sprintf("<change log for %s>", text.Tag)
 	}
 
-	// Dry -- Push the commit for next release
-	if err := r.step24.Dry(ctx); err != nil {
+	// Validate the release body template
+	body, err := render(templates.releaseBody, vars)
+	if err != nil {
 		return err
 	}
 
 	// Dry -- Edit the draft release and make it ready
-	r.step25.Repo = r.step1.Result.Repo
-	r.step25.ReleaseID = r.step7.Result.Release.ID
+	r.step25.Provider = r.provider
+	r.step25.Release = r.step7.Result.Release
+	r.step25.ReleaseData.Name = text.ReleaseName
+	r.step25.ReleaseData.TagName = text.Tag
+	r.step25.ReleaseData.Target = r.step2.Result.Name
+	r.step25.ReleaseData.Body = body
 	if err := r.step25.Dry(ctx); err != nil {
 		return err
 	}
 
+	if s.Release.Homebrew.Enabled() {
+		// Dry -- Publish the Homebrew formula to tap
+		if err := r.setHomebrew(ctx, s, vars, true); err != nil {
+			return err
+		}
+		if err := r.homebrewStep(s).Dry(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.Release.Scoop.Enabled() {
+		// Dry -- Publish the Scoop manifest to bucket
+		if err := r.setScoop(s, vars, true); err != nil {
+			return err
+		}
+		if err := r.step31.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.Release.Winget.Enabled() {
+		// Dry -- Publish the winget manifests to repository
+		if err := r.setWinget(s, vars, true); err != nil {
+			return err
+		}
+		if err := r.step32.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
+	if d := s.Release.Docker; d.Enabled() && d.Push {
+		// Dry -- Push image to registry
+		if err := r.step35.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
+	r.plan = releasePlan{
+		Repo:           r.step1.Result.Repo,
+		Module:         r.moduleName(),
+		Provider:       r.provider.Name(),
+		Branch:         r.step2.Result.Name,
+		CurrentVersion: v.Current,
+		ReleaseVersion: v.Release,
+		NextVersion:    v.Next,
+		Tag:            text.Tag,
+		Changelog:      changelog,
+		TagOnly:        fromGit,
+	}
+
+	if s.Release.Build {
+		for _, platform := range s.Build.Platforms {
+			r.plan.Assets = append(r.plan.Assets, fmt.Sprintf("%s-%s", filepath.Base(s.Build.BinaryFile), platform))
+		}
+
+		if s.Release.Packages.Enabled() {
+			r.plan.Assets = append(r.plan.Assets, packageAssets(s, r.step33.Package)...)
+		}
+	}
+
+	if s.Release.Homebrew.Enabled() {
+		r.plan.Homebrew = homebrewTap(s.Release.Homebrew)
+	}
+
+	if s.Release.Scoop.Enabled() {
+		r.plan.Scoop = r.step31.URL
+	}
+
+	if s.Release.Winget.Enabled() {
+		r.plan.Winget = r.step32.URL
+	}
+
+	if s.Release.Docker.Enabled() {
+		r.plan.Image = imageName(s.Release.Docker, r.step34.Tags)
+	}
+
+	r.plan.Steps = r.planSteps(s)
+	r.ui.Event(cui.Event{Type: cui.EventPlan, Version: v.Release, Plan: r.plan.Steps})
+
+	r.printPlan()
+	if ReleasePlanFromContext(ctx) {
+		r.printSteps()
+	}
+
 	return nil
 }
 
 // Run executes the action.
 func (r *release) Run(ctx context.Context) error {
-	s := SpecFromContext(ctx)
+	s := r.spec(ctx)
 	segment, comment := ReleaseParamsFromContext(ctx)
 
+	templates, err := parseTemplates(s.Release.Templates)
+	if err != nil {
+		return err
+	}
+	templates.tagPrefix = r.tagPrefix()
+
 	// Get repo name
-	if err := r.step1.Run(ctx); err != nil {
+	if err := runStep(ctx, r.ui, r.step1); err != nil {
+		return err
+	}
+
+	// Create the release provider for repo
+	if err := r.createProvider(s); err != nil {
+		return err
+	}
+
+	// The pure-Go git authenticates with the token of release provider
+	if err := r.setGitToken(ctx); err != nil {
 		return err
 	}
 
 	// Get branch name
-	if err := r.step2.Run(ctx); err != nil {
+	if err := runStep(ctx, r.ui, r.step2); err != nil {
 		return err
 	}
 
@@ -425,7 +1321,7 @@ func (r *release) Run(ctx context.Context) error {
 	}
 
 	// Get git status
-	if err := r.step3.Run(ctx); err != nil {
+	if err := runStep(ctx, r.ui, r.step3); err != nil {
 		return err
 	}
 
@@ -437,174 +1333,343 @@ func (r *release) Run(ctx context.Context) error {
 	r.ui.Outputf("⬇️  Pulling master branch ...")
 
 	// Pulling master branch
-	if err := r.step4.Run(ctx); err != nil {
+	if err := runStep(ctx, r.ui, r.step4); err != nil {
 		return err
 	}
 
 	// Read the version
-	if err := r.step5.Run(ctx); err != nil {
+	if err := runStep(ctx, r.ui, r.step5); err != nil {
 		return err
 	}
 
-	// Release the version
-	curr, next := r.step5.Result.Version.Release(segment)
-	next.Prerelease = []string{"0"}
+	// When the version is derived from git tags, only a tag is created and pushed for the release
+	fromGit := s.VersionSource == step.VersionSourceGit
+	if err := r.checkUnreleased(fromGit); err != nil {
+		return err
+	}
 
-	// Update the version file with the current version
-	r.step6.Version = curr.Version()
-	if err := r.step6.Run(ctx); err != nil {
+	// Release the version
+	v, err := releaseVersions(s, r.step5, segment)
+	if err != nil {
 		return err
 	}
 
-	r.ui.Outputf("⬆️  Creating draft release %s ...", curr.Version())
+	// No commit is created for the next version when there is no next version (calendar versioning)
+	nextCommit := !fromGit && v.Next != ""
 
-	// Create a draft release
-	r.step7.Repo = r.step1.Result.Repo
-	r.step7.ReleaseData.Name = curr.Version()
-	r.step7.ReleaseData.TagName = curr.GitTag()
-	r.step7.ReleaseData.Target = r.step2.Result.Name
-	if err := r.step7.Run(ctx); err != nil {
+	// Get the previous release tag
+	if err := runStep(ctx, r.ui, r.step27); err != nil {
 		return err
 	}
 
-	r.ui.Outputf("➡️  Creating/Updating change log ...")
+	vars := releaseVars{
+		Version:         v.Release,
+		PreviousVersion: versionFromTag(s, r.step27.Result.Tag),
+		NextVersion:     v.Next,
+		PreviousTag:     r.step27.Result.Tag,
+		Module:          r.moduleName(),
+		Comment:         comment,
+	}
 
-	// Create/Update change log
-	r.step8.Repo = r.step1.Result.Repo
-	r.step8.Tag = curr.GitTag()
-	if err := r.step8.Run(ctx); err != nil {
+	// Render the commit messages, tag, and release name
+	text, err := templates.render(&vars)
+	if err != nil {
 		return err
 	}
 
-	// Add unstaged to files to staging
-	r.step9.Files = []string{r.step6.Result.Filename, r.step8.Result.Filename}
-	if err := r.step9.Run(ctx); err != nil {
-		return err
+	r.ui.Event(cui.Event{Type: cui.EventVersion, Version: v.Release})
+
+	// The files to commit for the release
+	var files []string
+
+	if !fromGit {
+		// Update the version file with the current version
+		r.step6.Version = v.Release
+		if err := runStep(ctx, r.ui, r.step6); err != nil {
+			return err
+		}
+
+		// Rewrite the Go module path and import paths for the major version
+		if v.Major != 0 {
+			r.step28.Major = v.Major
+			if err := runStep(ctx, r.ui, r.step28); err != nil {
+				return err
+			}
+		}
+
+		files = r.updatedFiles(r.step6)
+		for _, file := range r.step28.Result.Files {
+			files = append(files, r.repoFile(file))
+		}
 	}
 
-	// Create a commit for current version
-	r.step10.Message = fmt.Sprintf("Releasing %s", curr.Version())
-	if err := r.step10.Run(ctx); err != nil {
+	r.ui.Outputf("⬆️  Creating draft release %s ...", v.Release)
+
+	// Create a draft release
+	r.step7.Provider = r.provider
+	r.step7.ReleaseData.Name = text.ReleaseName
+	r.step7.ReleaseData.TagName = text.Tag
+	r.step7.ReleaseData.Target = r.step2.Result.Name
+	if err := runStep(ctx, r.ui, r.step7); err != nil {
 		return err
 	}
 
+	// The change log is generated from GitHub issues and pull requests.
+	// The change log file cannot be committed when the version is derived from git tags.
+	if r.provider.Name() == step.ProviderGitHub && !fromGit {
+		r.ui.Outputf("➡️  Creating/Updating change log ...")
+
+		// Create/Update change log
+		if err := r.setChangelogGitHub(ctx); err != nil {
+			return err
+		}
+		r.step8.Repo = r.step1.Result.Repo
+		r.step8.Tag = text.Tag
+		if err := runStep(ctx, r.ui, r.step8); err != nil {
+			return err
+		}
+
+		files = append(files, r.step8.Result.Filename)
+	}
+
+	if !fromGit {
+		// Add unstaged to files to staging
+		r.step9.Files = files
+		if err := runStep(ctx, r.ui, r.step9); err != nil {
+			return err
+		}
+
+		// Create a commit for current version
+		r.step10.Message = text.ReleaseCommit
+		r.step10.Sign = signing(s)
+		if err := runStep(ctx, r.ui, r.step10); err != nil {
+			return err
+		}
+	}
+
 	// Create a tag for current version
-	r.step11.Tag = curr.GitTag()
-	r.step11.Annotation = fmt.Sprintf("Version %s", curr.Version())
-	if err := r.step11.Run(ctx); err != nil {
+	r.step11.Tag = text.Tag
+	r.step11.Annotation = text.TagAnnotation
+	r.step11.Sign = signing(s)
+	if err := runStep(ctx, r.ui, r.step11); err != nil {
 		return err
 	}
 
+	if s.Release.Sign {
+		r.ui.Outputf("🔏 Verifying signed tag %s ...", text.Tag)
+
+		// Verify the signature of tag for current version
+		r.step26.Tag = text.Tag
+		r.step26.Sign = signing(s)
+		if err := runStep(ctx, r.ui, r.step26); err != nil {
+			return err
+		}
+	}
+
 	if s.Release.Build {
 		r.ui.Outputf("➡️  Building artifacts ...")
 
 		// Find package version path
-		if err := r.step12.Run(ctx); err != nil {
+		if err := runStep(ctx, r.ui, r.step12); err != nil {
 			return err
 		}
 
 		// Get commit SHA hashes
-		if err := r.step13.Run(ctx); err != nil {
+		if err := runStep(ctx, r.ui, r.step13); err != nil {
 			return err
 		}
 
 		// Get Go version
-		if err := r.step14.Run(ctx); err != nil {
+		if err := runStep(ctx, r.ui, r.step14); err != nil {
 			return err
 		}
 
 		// Cross-compile and build artifacts
-		r.step15.LDFlags = r.getLDFlags(s)
+		r.step15.LDFlags = r.getLDFlags(s, v.Release)
 		r.step15.Platforms = s.Build.Platforms
-		if err := r.step15.Run(ctx); err != nil {
+		if err := runStep(ctx, r.ui, r.step15); err != nil {
 			return err
 		}
 
-		r.ui.Outputf("➡️️  Uploading artifacts to release %s ...", r.step7.Result.Release.Name)
+		assets := append([]string{}, r.step15.Result.Binaries...)
+
+		if s.Release.Packages.Enabled() {
+			r.ui.Outputf("📦 Building Linux packages ...")
+
+			// Build Linux packages for linux binaries
+			if err := r.setPackages(s, vars, false); err != nil {
+				return err
+			}
+			if err := runStep(ctx, r.ui, r.step33); err != nil {
+				return err
+			}
+
+			assets = append(assets, r.step33.Result.Files...)
+		}
+
+		if s.Release.Docker.Enabled() {
+			r.ui.Outputf("🐳 Building OCI image ...")
+
+			// Build OCI image for linux binaries
+			if err := r.setImage(s, vars, false); err != nil {
+				return err
+			}
+			if err := runStep(ctx, r.ui, r.step34); err != nil {
+				return err
+			}
+
+			r.ui.Event(cui.Event{Type: cui.EventArtifact, Artifact: r.step34.Result.File})
+		}
+
+		for _, asset := range assets {
+			r.ui.Event(cui.Event{Type: cui.EventArtifact, Artifact: asset})
+		}
+
+		r.ui.Outputf("➡️️  Uploading artifacts to release %s ...", v.Release)
 
 		// Upload build artifacts to release
-		r.step16.Repo = r.step1.Result.Repo
-		r.step16.ReleaseID = r.step7.Result.Release.ID
-		r.step16.ReleaseUploadURL = r.step7.Result.Release.UploadURL
-		r.step16.AssetFiles = r.step15.Result.Binaries
-		if err := r.step16.Run(ctx); err != nil {
+		r.step16.Provider = r.provider
+		r.step16.Release = r.step7.Result.Release
+		r.step16.AssetFiles = assets
+		if err := runStep(ctx, r.ui, r.step16); err != nil {
 			return err
 		}
 	}
 
-	r.ui.Warnf("🔓 Temporarily enabling push to master branch ...")
+	if !fromGit {
+		r.ui.Warnf("🔓 Temporarily enabling push to master branch ...")
 
-	// Temporarily disable the master branch protection
-	r.step17.Repo = r.step1.Result.Repo
-	r.step17.Branch = r.step2.Result.Name
-	if err := r.step17.Run(ctx); err != nil {
+		// Temporarily disable the master branch protection
+		r.step17.Provider = r.provider
+		r.step17.Branch = r.step2.Result.Name
+		if err := runStep(ctx, r.ui, r.step17); err != nil {
+			return err
+		}
+
+		// Make sure we re-enable the master branch protection
+		defer func() {
+			r.ui.Warnf("🔒 Re-disabling push to master branch ...")
+
+			r.step18.Provider = r.provider
+			r.step18.Branch = r.step2.Result.Name
+			if err := runStep(ctx, r.ui, r.step18); err != nil {
+				r.ui.Errorf("Error: %s", err)
+			}
+		}()
+
+		r.ui.Infof("⬆️  Pushing release commit %s ...", v.Release)
+
+		// Push the commit for current release
+		if err := runStep(ctx, r.ui, r.step19); err != nil {
+			return err
+		}
+	}
+
+	r.ui.Infof("⬆️  Pushing release tag %s ...", v.Release)
+
+	// Push the tag for current release
+	r.step20.Tag = text.Tag
+	if err := runStep(ctx, r.ui, r.step20); err != nil {
 		return err
 	}
 
-	// Make sure we re-enable the master branch protection
-	defer func() {
-		r.ui.Warnf("🔒 Re-disabling push to master branch ...")
+	if nextCommit {
+		// Update the version file with the next version
+		r.step21.Version = v.Next
+		if err := runStep(ctx, r.ui, r.step21); err != nil {
+			return err
+		}
 
-		r.step18.Repo = r.step1.Result.Repo
-		r.step18.Branch = r.step2.Result.Name
-		if err := r.step18.Run(ctx); err != nil {
-			r.ui.Errorf("Error: %s", err)
+		// Add unstaged to files to staging
+		r.step22.Files = r.updatedFiles(r.step21)
+		if err := runStep(ctx, r.ui, r.step22); err != nil {
+			return err
 		}
-	}()
 
-	r.ui.Infof("⬆️  Pushing release commit %s ...", r.step7.Result.Release.Name)
+		//  Create a commit for next version
+		r.step23.Message = text.NextCommit
+		r.step23.Sign = signing(s)
+		if err := runStep(ctx, r.ui, r.step23); err != nil {
+			return err
+		}
 
-	// Push the commit for current release
-	if err := r.step19.Run(ctx); err != nil {
-		return err
+		r.ui.Infof("⬆️  Pushing commit for next version %s ...", v.Next)
+
+		// Push the commit for next release
+		if err := runStep(ctx, r.ui, r.step24); err != nil {
+			return err
+		}
 	}
 
-	r.ui.Infof("⬆️  Pushing release tag %s ...", r.step7.Result.Release.Name)
+	r.ui.Infof("⬆️  Publishing release %s ...", v.Release)
 
-	// Push the tag for current release
-	r.step20.Tag = curr.GitTag()
-	if err := r.step20.Run(ctx); err != nil {
+	vars.Changelog = r.step8.Result.Changelog
+	body, err := render(templates.releaseBody, vars)
+	if err != nil {
 		return err
 	}
 
-	// Update the version file with the next version
-	r.step21.Version = next.Version()
-	if err := r.step21.Run(ctx); err != nil {
+	// Edit the draft release and make it ready
+	r.step25.Provider = r.provider
+	r.step25.Release = r.step7.Result.Release
+	r.step25.ReleaseData.Name = text.ReleaseName
+	r.step25.ReleaseData.TagName = text.Tag
+	r.step25.ReleaseData.Target = r.step2.Result.Name
+	r.step25.ReleaseData.Body = body
+	if err := runStep(ctx, r.ui, r.step25); err != nil {
 		return err
 	}
 
-	// Add unstaged to files to staging
-	r.step22.Files = []string{r.step21.Result.Filename}
-	if err := r.step22.Run(ctx); err != nil {
-		return err
+	if s.Release.Homebrew.Enabled() {
+		r.ui.Infof("🍺 Publishing Homebrew formula to %s ...", homebrewTap(s.Release.Homebrew))
+
+		// Publish the Homebrew formula to tap
+		if err := r.setHomebrew(ctx, s, vars, false); err != nil {
+			return err
+		}
+		if err := runStep(ctx, r.ui, r.homebrewStep(s)); err != nil {
+			return err
+		}
 	}
 
-	//  Create a commit for next version
-	r.step23.Message = fmt.Sprintf("Beginning %s [skip ci]", next.Version())
-	if err := r.step23.Run(ctx); err != nil {
-		return err
+	if s.Release.Scoop.Enabled() {
+		// Publish the Scoop manifest to bucket
+		if err := r.setScoop(s, vars, false); err != nil {
+			return err
+		}
+
+		r.ui.Infof("🪣 Publishing Scoop manifest to %s ...", r.step31.URL)
+
+		if err := runStep(ctx, r.ui, r.step31); err != nil {
+			return err
+		}
 	}
 
-	r.ui.Infof("⬆️  Pushing commit for next version %s ...", next.Version())
+	if s.Release.Winget.Enabled() {
+		// Publish the winget manifests to repository
+		if err := r.setWinget(s, vars, false); err != nil {
+			return err
+		}
 
-	// Push the commit for next release
-	if err := r.step24.Run(ctx); err != nil {
-		return err
+		r.ui.Infof("📦 Publishing winget manifests to %s ...", r.step32.URL)
+
+		if err := runStep(ctx, r.ui, r.step32); err != nil {
+			return err
+		}
 	}
 
-	r.ui.Infof("⬆️  Publishing release %s ...", r.step7.Result.Release.Name)
+	if d := s.Release.Docker; d.Enabled() && d.Push {
+		r.ui.Infof("🐳 Pushing image %s ...", imageName(d, r.step34.Tags))
 
-	// Edit the draft release and make it ready
-	r.step25.Repo = r.step1.Result.Repo
-	r.step25.ReleaseID = r.step7.Result.Release.ID
-	r.step25.ReleaseData.Name = curr.Version()
-	r.step25.ReleaseData.TagName = curr.GitTag()
-	r.step25.ReleaseData.Target = r.step2.Result.Name
-	r.step25.ReleaseData.Body = fmt.Sprintf("%s\n\n%s", comment, r.step8.Result.Changelog)
-	if err := r.step25.Run(ctx); err != nil {
-		return err
+		// Push image to registry
+		r.step35.Image = r.step34.Result.Image
+		if err := runStep(ctx, r.ui, r.step35); err != nil {
+			return err
+		}
 	}
 
+	r.ui.Event(cui.Event{Type: cui.EventRelease, Version: v.Release, URL: r.step25.Result.Release.URL})
+
 	return nil
 }
 
@@ -613,13 +1678,57 @@ func (r *release) Revert(ctx context.Context) error {
 	r.ui.Outputf("🛑 Reverting back ...")
 
 	steps := []step.Step{
-		r.step25, r.step24, r.step23, r.step22, r.step21,
+		r.step27, r.step26, r.step25, r.step24, r.step23, r.step22, r.step21,
 		r.step20, r.step19, r.step18, r.step17, r.step16,
 		r.step15, r.step14, r.step13, r.step12, r.step11,
-		r.step10, r.step9, r.step8, r.step7, r.step6,
+		r.step10, r.step9, r.step8, r.step7, r.step28, r.step6,
 		r.step5, r.step4, r.step3, r.step2, r.step1,
 	}
 
+	switch s := r.spec(ctx); {
+	// No commit is created when the version is derived from git tags
+	case s.VersionSource == step.VersionSourceGit:
+		steps = []step.Step{
+			r.step27, r.step26, r.step25, r.step20, r.step16,
+			r.step15, r.step14, r.step13, r.step12, r.step11, r.step7,
+			r.step5, r.step4, r.step3, r.step2, r.step1,
+		}
+	// No commit is created for the next version with calendar versioning
+	case s.Versioning == step.VersioningCalVer:
+		steps = []step.Step{
+			r.step27, r.step26, r.step25,
+			r.step20, r.step19, r.step18, r.step17, r.step16,
+			r.step15, r.step14, r.step13, r.step12, r.step11,
+			r.step10, r.step9, r.step8, r.step7, r.step28, r.step6,
+			r.step5, r.step4, r.step3, r.step2, r.step1,
+		}
+	}
+
+	// The Linux packages are built from the binaries, so they are removed before the binaries
+	if r.spec(ctx).Release.Packages.Enabled() {
+		for i, st := range steps {
+			if st == r.step15 {
+				steps = append(steps[:i], append([]step.Step{r.step33}, steps[i:]...)...)
+				break
+			}
+		}
+	}
+
+	// The image is built from the binaries, so it is removed before the binaries
+	if r.spec(ctx).Release.Docker.Enabled() {
+		for i, st := range steps {
+			if st == r.step15 {
+				steps = append(steps[:i], append([]step.Step{r.step34}, steps[i:]...)...)
+				break
+			}
+		}
+	}
+
+	// The package manifests are published after the release, so they are reverted first in reverse order
+	for _, st := range r.publishSteps(r.spec(ctx)) {
+		steps = append([]step.Step{st}, steps...)
+	}
+
 	for _, s := range steps {
 		if err := s.Revert(ctx); err != nil {
 			return err
@@ -3,10 +3,16 @@ package action
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/moorara/cherry/internal/spec"
 	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/calver"
 	"github.com/moorara/cherry/pkg/cui"
 	"github.com/moorara/cherry/pkg/semver"
 	"github.com/stretchr/testify/assert"
@@ -98,17 +104,22 @@ func TestContext(t *testing.T) {
 
 func TestNewRelease(t *testing.T) {
 	tests := []struct {
-		name        string
-		ui          cui.CUI
-		workDir     string
-		githubToken string
-		s           spec.Spec
+		name    string
+		ui      cui.CUI
+		workDir string
+		config  step.ProviderConfig
+		s       spec.Spec
 	}{
 		{
-			name:        "OK",
-			ui:          &mockCUI{},
-			workDir:     ".",
-			githubToken: "github-token",
+			name:    "OK",
+			ui:      &mockCUI{},
+			workDir: ".",
+			config: step.ProviderConfig{
+				GitHubToken: "github-token",
+				GitLabToken: "gitlab-token",
+				GiteaURL:    "https://gitea.example.com",
+				GiteaToken:  "gitea-token",
+			},
 			s: spec.Spec{
 				ToolName:    "cherry",
 				ToolVersion: "test",
@@ -129,12 +140,71 @@ func TestNewRelease(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			action := NewRelease(tc.ui, tc.workDir, tc.githubToken, tc.s)
+			action := NewRelease(tc.ui, tc.workDir, tc.config, tc.s)
 			assert.NotNil(t, action)
+			assert.Equal(t, "v*", action.(*release).step27.Match)
 		})
 	}
 }
 
+func TestNewModuleRelease(t *testing.T) {
+	s := spec.Spec{
+		ToolName:    "cherry",
+		ToolVersion: "test",
+		VersionFile: "VERSION",
+		VersionFiles: []spec.VersionFile{
+			{Path: "Chart.yaml"},
+		},
+	}
+
+	m := spec.Module{
+		Name:           "sdk",
+		Dir:            "sdk",
+		VersionFile:    "version.txt",
+		VersionPattern: `sdk-(\S+)`,
+		VersionFiles: []spec.VersionFile{
+			{Path: "version.go"},
+		},
+		TagPrefix: "sdk/",
+		Build: spec.Build{
+			MainFile:       "main.go",
+			BinaryFile:     "bin/sdk",
+			VersionPackage: "./version",
+		},
+	}
+
+	action := NewModuleRelease(&mockCUI{}, "/repo", step.ProviderConfig{}, s, m)
+	assert.NotNil(t, action)
+
+	r := action.(*release)
+	assert.Equal(t, &m, r.module)
+	assert.Equal(t, "/repo", r.step1.WorkDir)
+	assert.Equal(t, "/repo/sdk", r.step5.WorkDir)
+	assert.Equal(t, "version.txt", r.step5.Filename)
+	assert.Equal(t, `sdk-(\S+)`, r.step5.Pattern)
+	assert.Equal(t, []step.VersionFile{{Filename: "version.go"}}, r.step6.Files)
+	assert.Equal(t, []step.VersionFile{{Filename: "version.go"}}, r.step21.Files)
+	assert.Equal(t, "/repo/sdk", r.step15.WorkDir)
+	assert.Equal(t, "bin/sdk", r.step15.BinaryFile)
+	assert.Equal(t, "./version", r.step12.Package)
+	assert.Equal(t, "sdk/v*", r.step27.Match)
+	assert.Equal(t, "sdk/v*", r.step5.Match)
+	assert.Equal(t, "sdk/VERSION", r.repoFile("VERSION"))
+
+	r.step6.Result.Filename = "version.txt"
+	r.step6.Result.Files = []string{"version.go"}
+	assert.Equal(t, []string{"sdk/version.txt", "sdk/version.go"}, r.updatedFiles(r.step6))
+	assert.Equal(t, "sdk/", r.tagPrefix())
+
+	ctx := ContextWithSpec(context.Background(), s)
+	assert.Equal(t, m.Build, r.spec(ctx).Build)
+}
+
+func TestReleasePlanFromContext(t *testing.T) {
+	assert.False(t, ReleasePlanFromContext(context.Background()))
+	assert.True(t, ReleasePlanFromContext(ContextWithReleasePlan(context.Background())))
+}
+
 func TestReleaseDry(t *testing.T) {
 	ctx := ContextWithSpec(
 		ContextWithReleaseParams(
@@ -146,7 +216,8 @@ func TestReleaseDry(t *testing.T) {
 			ToolName:    "cherry",
 			ToolVersion: "test",
 			Build: spec.Build{
-				Platforms: []string{"linux-amd64", "darwin-amd64"},
+				BinaryFile: "bin/app",
+				Platforms:  []string{"linux-amd64", "darwin-amd64"},
 			},
 			Release: spec.Release{
 				Build: true,
@@ -154,6 +225,92 @@ func TestReleaseDry(t *testing.T) {
 		},
 	)
 
+	signed := SpecFromContext(ctx)
+	signed.Release.Sign = true
+	signedCtx := ContextWithSpec(ctx, signed)
+
+	templated := SpecFromContext(ctx)
+	templated.Release.Templates.Tag = "api/v{{.Version}}"
+	templated.Release.Templates.ReleaseName = "API {{.Version}} (previous: {{.PreviousVersion}})"
+	templatedCtx := ContextWithSpec(ctx, templated)
+
+	majorCtx := ContextWithReleaseParams(ctx, semver.Major, "comment")
+
+	fromGit := SpecFromContext(ctx)
+	fromGit.VersionSource = "git"
+	gitCtx := ContextWithSpec(ctx, fromGit)
+
+	calendar := SpecFromContext(ctx)
+	calendar.Versioning = "calver"
+	calverCtx := ContextWithSpec(ctx, calendar)
+
+	calverFormat, _ := calver.ParseFormat(calver.DefaultFormat)
+	calverRelease := calverFormat.Version(time.Now()).Version()
+
+	homebrew := SpecFromContext(ctx)
+	homebrew.Release.Homebrew.Tap = "username/homebrew-tap"
+	homebrewCtx := ContextWithSpec(ctx, homebrew)
+
+	homebrewGit := SpecFromContext(ctx)
+	homebrewGit.Release.Homebrew.Method = "git"
+	homebrewGit.Release.Homebrew.TapURL = "git@github.com:username/homebrew-tap.git"
+	homebrewGitCtx := ContextWithSpec(ctx, homebrewGit)
+
+	homebrewNoBuild := SpecFromContext(homebrewCtx)
+	homebrewNoBuild.Release.Build = false
+	homebrewNoBuildCtx := ContextWithSpec(ctx, homebrewNoBuild)
+
+	windows := SpecFromContext(ctx)
+	windows.Build.Platforms = append(windows.Build.Platforms, "windows-amd64")
+	windows.Release.Scoop.BucketURL = "git@github.com:username/scoop-bucket.git"
+	windows.Release.Winget = spec.Winget{
+		RepoURL:     "git@github.com:username/winget-pkgs.git",
+		Publisher:   "username",
+		Description: "A test application",
+		License:     "MIT",
+	}
+	windowsCtx := ContextWithSpec(ctx, windows)
+
+	packages := SpecFromContext(ctx)
+	packages.Release.Packages = spec.Packages{
+		Formats:     []string{"deb", "rpm"},
+		Maintainer:  "Jane Doe <jane@example.com>",
+		Description: "A test application",
+	}
+	packagesCtx := ContextWithSpec(ctx, packages)
+
+	packagesNoBuild := SpecFromContext(packagesCtx)
+	packagesNoBuild.Release.Build = false
+	packagesNoBuildCtx := ContextWithSpec(ctx, packagesNoBuild)
+
+	docker := SpecFromContext(ctx)
+	docker.Release.Docker = spec.Docker{
+		Image: "ghcr.io/username/app",
+		Push:  true,
+	}
+	dockerCtx := ContextWithSpec(ctx, docker)
+
+	dockerNoBuild := SpecFromContext(dockerCtx)
+	dockerNoBuild.Release.Build = false
+	dockerNoBuildCtx := ContextWithSpec(ctx, dockerNoBuild)
+
+	scoopNoBuild := SpecFromContext(windowsCtx)
+	scoopNoBuild.Release.Build = false
+	scoopNoBuildCtx := ContextWithSpec(ctx, scoopNoBuild)
+
+	wingetNoPublisher := SpecFromContext(windowsCtx)
+	wingetNoPublisher.Release.Winget.Publisher = ""
+	wingetNoPublisher.Release.Winget.PackageIdentifier = "username.app"
+	wingetNoPublisherCtx := ContextWithSpec(ctx, wingetNoPublisher)
+
+	invalidTemplate := SpecFromContext(ctx)
+	invalidTemplate.Release.Templates.ReleaseCommit = "Releasing {{.Version"
+	invalidTemplateCtx := ContextWithSpec(ctx, invalidTemplate)
+
+	invalidTag := SpecFromContext(ctx)
+	invalidTag.Release.Templates.Tag = "v{{.Version}} {{.Comment}}"
+	invalidTagCtx := ContextWithSpec(ctx, invalidTag)
+
 	step1OK := &step.GitGetRepo{Mock: &mockStep{}}
 
 	step2OK := &step.GitGetBranch{Mock: &mockStep{}}
@@ -168,10 +325,37 @@ func TestReleaseDry(t *testing.T) {
 	step5OK.Result.Filename = "VERSION"
 	step5OK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
 
+	step5Git := &step.SemVerRead{Mock: &mockStep{}}
+	step5Git.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 1, Prerelease: []string{"3"}, Metadata: []string{"gabcdef1"}}
+	step5Git.Result.Tag = "v0.2.0"
+	step5Git.Result.Commits = 3
+
+	step5Tagged := &step.SemVerRead{Mock: &mockStep{}}
+	step5Tagged.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+	step5Tagged.Result.Tag = "v0.2.0"
+
+	step5CalVer := &step.SemVerRead{Mock: &mockStep{}}
+	step5CalVer.Result.Filename = "VERSION"
+	step5CalVer.Result.CalVer, _ = calverFormat.Parse("2020.01.3")
+
+	step5CalVerAhead := &step.SemVerRead{Mock: &mockStep{}}
+	step5CalVerAhead.Result.Filename = "VERSION"
+	step5CalVerAhead.Result.CalVer, _ = calverFormat.Parse("9999.01.0")
+
+	step27OK := &step.GitLatestTag{Mock: &mockStep{}}
+	step27OK.Result.Tag = "v0.1.0"
+
+	step28OK := &step.GoModMajor{Mock: &mockStep{}}
+
+	step28Major := &step.GoModMajor{Mock: &mockStep{}}
+	step28Major.Result.Path = "github.com/username/repo"
+	step28Major.Result.NewPath = "github.com/username/repo/v1"
+	step28Major.Result.Files = []string{"go.mod", "main.go"}
+
 	step6OK := &step.SemVerUpdate{Mock: &mockStep{}}
 	step6OK.Result.Filename = "VERSION"
 
-	step7OK := &step.GitHubCreateRelease{Mock: &mockStep{}}
+	step7OK := &step.ReleaseCreate{Mock: &mockStep{}}
 	step8OK := &step.ChangelogGenerate{Mock: &mockStep{}}
 
 	step9OK := &step.GitAdd{Mock: &mockStep{}}
@@ -191,10 +375,10 @@ func TestReleaseDry(t *testing.T) {
 	step15OK := &step.GoBuild{Mock: &mockStep{}}
 	step15OK.Result.Binaries = []string{"/tmp/cherry-1234/bin/app"}
 
-	step16OK := &step.GitHubUploadAssets{Mock: &mockStep{}}
+	step16OK := &step.ReleaseUploadAssets{Mock: &mockStep{}}
 
-	step17OK := &step.GitHubBranchProtection{Mock: &mockStep{}}
-	step18OK := &step.GitHubBranchProtection{Mock: &mockStep{}}
+	step17OK := &step.BranchProtection{Mock: &mockStep{}}
+	step18OK := &step.BranchProtection{Mock: &mockStep{}}
 	step19OK := &step.GitPush{Mock: &mockStep{}}
 	step20OK := &step.GitPushTag{Mock: &mockStep{}}
 
@@ -204,14 +388,32 @@ func TestReleaseDry(t *testing.T) {
 	step22OK := &step.GitAdd{Mock: &mockStep{}}
 	step23OK := &step.GitCommit{Mock: &mockStep{}}
 	step24OK := &step.GitPush{Mock: &mockStep{}}
-	step25OK := &step.GitHubEditRelease{Mock: &mockStep{}}
+	step25OK := &step.ReleaseEdit{Mock: &mockStep{}}
+	step29OK := &step.GitHubUpdateFile{Mock: &mockStep{}}
+	step30OK := &step.GitPublishFiles{Mock: &mockStep{}}
+	step31OK := &step.GitPublishFiles{Mock: &mockStep{}}
+	step32OK := &step.GitPublishFiles{Mock: &mockStep{}}
+	step33OK := &step.LinuxPackages{Mock: &mockStep{}}
+	step34OK := &step.ImageBuild{Mock: &mockStep{}}
+	step35OK := &step.ImagePush{Mock: &mockStep{}}
 
 	tests := []struct {
-		name          string
-		action        Action
-		ctx           context.Context
-		expectedError error
+		name            string
+		action          Action
+		ctx             context.Context
+		expectedError   error
+		expectedPlan    releasePlan
+		expectedSteps   []string
+		expectedWarning string
 	}{
+		{
+			name: "InvalidTemplate",
+			action: &release{
+				ui: &mockCUI{},
+			},
+			ctx:           invalidTemplateCtx,
+			expectedError: errors.New("invalid release_commit template: template: release_commit:1: unclosed action"),
+		},
 		{
 			name: "Step1Fails",
 			action: &release{
@@ -313,7 +515,7 @@ func TestReleaseDry(t *testing.T) {
 			expectedError: errors.New("error on run: step5"),
 		},
 		{
-			name: "Step6Fails",
+			name: "Step27Fails",
 			action: &release{
 				ui:    &mockCUI{},
 				step1: step1OK,
@@ -321,6 +523,41 @@ func TestReleaseDry(t *testing.T) {
 				step3: step3OK,
 				step4: step4OK,
 				step5: step5OK,
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step27"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step27"),
+		},
+		{
+			name: "InvalidTag",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+			},
+			ctx:           invalidTagCtx,
+			expectedError: errors.New(`invalid tag template: "v0.2.0 comment" is not a valid tag name`),
+		},
+		{
+			name: "Step6Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6: &step.SemVerUpdate{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step6"),
@@ -330,17 +567,39 @@ func TestReleaseDry(t *testing.T) {
 			ctx:           ctx,
 			expectedError: errors.New("error on dry: step6"),
 		},
+		{
+			name: "Step28Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step6:  step6OK,
+				step28: &step.GoModMajor{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step28"),
+					},
+				},
+			},
+			ctx:           majorCtx,
+			expectedError: errors.New("error on dry: step28"),
+		},
 		{
 			name: "Step7Fails",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: &step.GitHubCreateRelease{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step7"),
 					},
@@ -352,14 +611,16 @@ func TestReleaseDry(t *testing.T) {
 		{
 			name: "Step8Fails",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: step7OK,
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step8"),
@@ -372,15 +633,17 @@ func TestReleaseDry(t *testing.T) {
 		{
 			name: "Step9Fails",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: step7OK,
-				step8: step8OK,
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
 				step9: &step.GitAdd{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step9"),
@@ -393,16 +656,18 @@ func TestReleaseDry(t *testing.T) {
 		{
 			name: "Step10Fails",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: step7OK,
-				step8: step8OK,
-				step9: step9OK,
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
 				step10: &step.GitCommit{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step10"),
@@ -421,6 +686,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -435,6 +702,32 @@ func TestReleaseDry(t *testing.T) {
 			ctx:           ctx,
 			expectedError: errors.New("error on dry: step11"),
 		},
+		{
+			name: "Step26Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step26"),
+					},
+				},
+			},
+			ctx:           signedCtx,
+			expectedError: errors.New("error on dry: step26"),
+		},
 		{
 			name: "Step12Fails",
 			action: &release{
@@ -444,6 +737,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -468,6 +763,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -493,6 +790,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -519,6 +818,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -546,6 +847,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -556,7 +859,7 @@ func TestReleaseDry(t *testing.T) {
 				step13: step13OK,
 				step14: step14OK,
 				step15: step15OK,
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step16"),
 					},
@@ -574,6 +877,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -585,7 +890,7 @@ func TestReleaseDry(t *testing.T) {
 				step14: step14OK,
 				step15: step15OK,
 				step16: step16OK,
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step17"),
 					},
@@ -603,6 +908,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -634,6 +941,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -666,6 +975,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -699,6 +1010,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -733,6 +1046,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -768,6 +1083,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -804,6 +1121,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -823,7 +1142,7 @@ func TestReleaseDry(t *testing.T) {
 				step22: step22OK,
 				step23: step23OK,
 				step24: step24OK,
-				step25: &step.GitHubEditRelease{
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step25"),
 					},
@@ -841,6 +1160,8 @@ func TestReleaseDry(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -863,321 +1184,2298 @@ func TestReleaseDry(t *testing.T) {
 				step25: step25OK,
 			},
 			ctx: ctx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
 		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			err := tc.action.Dry(tc.ctx)
-			assert.Equal(t, tc.expectedError, err)
-		})
-	}
-}
-
-func TestReleaseRun(t *testing.T) {
-	ctx := ContextWithSpec(
-		ContextWithReleaseParams(
-			context.Background(),
-			semver.Patch,
-			"comment",
-		),
-		spec.Spec{
-			ToolName:    "cherry",
-			ToolVersion: "test",
-			Build: spec.Build{
-				Platforms: []string{"linux-amd64", "darwin-amd64"},
+		{
+			name: "SuccessPlan",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
 			},
-			Release: spec.Release{
-				Build: true,
+			ctx: ContextWithReleasePlan(ctx),
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
 			},
 		},
-	)
-
-	step1OK := &step.GitGetRepo{Mock: &mockStep{}}
-
-	step2OK := &step.GitGetBranch{Mock: &mockStep{}}
-	step2OK.Result.Name = "master"
-
-	step3OK := &step.GitStatus{Mock: &mockStep{}}
-	step3OK.Result.IsClean = true
-
-	step4OK := &step.GitPull{Mock: &mockStep{}}
-
-	step5OK := &step.SemVerRead{Mock: &mockStep{}}
-	step5OK.Result.Filename = "VERSION"
-	step5OK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
-
-	step6OK := &step.SemVerUpdate{Mock: &mockStep{}}
-	step6OK.Result.Filename = "VERSION"
-
-	step7OK := &step.GitHubCreateRelease{Mock: &mockStep{}}
-	step7OK.Result.Release = step.GitHubRelease{
-		ID:         2,
-		Name:       "0.2.0",
-		TagName:    "v0.2.0",
-		Target:     "master",
-		Draft:      true,
-		Prerelease: false,
-	}
-
-	step8OK := &step.ChangelogGenerate{Mock: &mockStep{}}
-	step8OK.Result.Filename = "CHANGELOG.md"
-	step8OK.Result.Changelog = "change log ..."
-
-	step9OK := &step.GitAdd{Mock: &mockStep{}}
-	step10OK := &step.GitCommit{Mock: &mockStep{}}
-	step11OK := &step.GitTag{Mock: &mockStep{}}
-
-	step12OK := &step.GoList{Mock: &mockStep{}}
-	step12OK.Result.PackagePath = "github.com/username/repo/cmd/version"
-
-	step13OK := &step.GitGetHEAD{Mock: &mockStep{}}
-	step13OK.Result.SHA = "aaaaaaa"
-	step13OK.Result.ShortSHA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
-
-	step14OK := &step.GoVersion{Mock: &mockStep{}}
-	step14OK.Result.Version = "go1.13"
-
-	step15OK := &step.GoBuild{Mock: &mockStep{}}
-	step15OK.Result.Binaries = []string{"bin/app-linux-amd64", "bin/app-darwin-amd64"}
-
-	step16OK := &step.GitHubUploadAssets{Mock: &mockStep{}}
-	step16OK.Result.Assets = []step.GitHubAsset{
-		{ID: 1, Name: "bin/app-linux-amd64"},
-		{ID: 2, Name: "bin/app-darwin-amd64"},
-	}
-
-	step17OK := &step.GitHubBranchProtection{Mock: &mockStep{}}
-	step18OK := &step.GitHubBranchProtection{Mock: &mockStep{}}
-	step19OK := &step.GitPush{Mock: &mockStep{}}
-	step20OK := &step.GitPushTag{Mock: &mockStep{}}
-
-	step21OK := &step.SemVerUpdate{Mock: &mockStep{}}
-	step21OK.Result.Filename = "VERSION"
-
-	step22OK := &step.GitAdd{Mock: &mockStep{}}
-	step23OK := &step.GitCommit{Mock: &mockStep{}}
-	step24OK := &step.GitPush{Mock: &mockStep{}}
-
-	step25OK := &step.GitHubEditRelease{Mock: &mockStep{}}
-	step25OK.Result.Release = step.GitHubRelease{
-		ID:         2,
-		Name:       "0.2.0",
-		TagName:    "v0.2.0",
-		Target:     "master",
-		Draft:      false,
-		Prerelease: false,
-		Body:       "text",
-	}
-
-	tests := []struct {
-		name          string
-		action        Action
-		ctx           context.Context
-		expectedError error
-	}{
 		{
-			name: "Step1Fails",
+			name: "SuccessMajor",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28Major,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+			},
+			ctx: majorCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "1.0.0",
+				NextVersion:    "1.0.1-0",
+				Tag:            "v1.0.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "GoModMajor", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+		},
+		{
+			name: "SuccessTemplates",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+			},
+			ctx: templatedCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "api/v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+		},
+		{
+			name: "SuccessModule",
 			action: &release{
 				ui: &mockCUI{},
-				step1: &step.GitGetRepo{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step1"),
+				module: &spec.Module{
+					Name:      "sdk",
+					Dir:       "sdk",
+					TagPrefix: "sdk/",
+					Build: spec.Build{
+						BinaryFile: "bin/sdk",
+						Platforms:  []string{"linux-amd64"},
 					},
 				},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+			},
+			ctx: ctx,
+			expectedPlan: releasePlan{
+				Module:         "sdk",
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "sdk/v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"sdk-linux-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step1"),
 		},
 		{
-			name: "Step2Fails",
+			name: "NoCommitSinceTag",
 			action: &release{
 				ui:    &mockCUI{},
 				step1: step1OK,
-				step2: &step.GitGetBranch{
+				step2: step2OK,
+				step3: step3OK,
+				step4: step4OK,
+				step5: step5Tagged,
+			},
+			ctx:           gitCtx,
+			expectedError: errors.New("no commit since the latest release v0.2.0"),
+		},
+		{
+			name: "SuccessGit",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5Git,
+				step27: step27OK,
+				step7:  step7OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step20: step20OK,
+				step25: step25OK,
+			},
+			ctx: gitCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.1-3+gabcdef1",
+				ReleaseVersion: "0.2.1",
+				NextVersion:    "0.2.2-0",
+				Tag:            "v0.2.1",
+				TagOnly:        true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "ReleaseCreate", "GitTag", "GoBuild", "ReleaseUploadAssets", "GitPushTag", "ReleaseEdit",
+			},
+		},
+		{
+			name: "CalVerAhead",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: step4OK,
+				step5: step5CalVerAhead,
+			},
+			ctx:           calverCtx,
+			expectedError: fmt.Errorf("calendar version 9999.01.0 is ahead of the release date %s", time.Now().Format("2006-01-02")),
+		},
+		{
+			name: "SuccessCalVer",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5CalVer,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step25: step25OK,
+			},
+			ctx: calverCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "2020.01.3",
+				ReleaseVersion: calverRelease,
+				Tag:            "v" + calverRelease,
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag", "ReleaseEdit", "BranchProtection",
+			},
+		},
+		{
+			name: "HomebrewNoBuild",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step29: step29OK,
+			},
+			ctx: homebrewNoBuildCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+			expectedWarning: "⚠️  Skipping Homebrew formula since release artifacts are not built (-build)",
+		},
+		{
+			name: "Step29Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step29: &step.GitHubUpdateFile{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step29"),
+					},
+				},
+			},
+			ctx:           homebrewCtx,
+			expectedError: errors.New("error on dry: step29"),
+		},
+		{
+			name: "Step30Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step30: &step.GitPublishFiles{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step30"),
+					},
+				},
+			},
+			ctx:           homebrewGitCtx,
+			expectedError: errors.New("error on dry: step30"),
+		},
+		{
+			name: "SuccessHomebrew",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step29: step29OK,
+			},
+			ctx: homebrewCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+				Homebrew:       "username/homebrew-tap",
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "GitHubUpdateFile", "BranchProtection",
+			},
+		},
+		{
+			name: "SuccessHomebrewGit",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step30: step30OK,
+			},
+			ctx: homebrewGitCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+				Homebrew:       "git@github.com:username/homebrew-tap.git",
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "GitPublishFiles", "BranchProtection",
+			},
+		},
+		{
+			name: "ScoopNoBuild",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step31: step31OK,
+				step32: step32OK,
+			},
+			ctx: scoopNoBuildCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+			expectedWarning: "⚠️  Skipping Scoop manifest since release artifacts are not built (-build)",
+		},
+		{
+			name: "Step31Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step31: &step.GitPublishFiles{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step31"),
+					},
+				},
+				step32: step32OK,
+			},
+			ctx:           windowsCtx,
+			expectedError: errors.New("error on dry: step31"),
+		},
+		{
+			name: "WingetNoPublisher",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step31: step31OK,
+				step32: step32OK,
+			},
+			ctx:           wingetNoPublisherCtx,
+			expectedError: errors.New("winget publisher is required"),
+		},
+		{
+			name: "Step32Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step31: step31OK,
+				step32: &step.GitPublishFiles{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step32"),
+					},
+				},
+			},
+			ctx:           windowsCtx,
+			expectedError: errors.New("error on dry: step32"),
+		},
+		{
+			name: "SuccessScoopWinget",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step31: step31OK,
+				step32: step32OK,
+			},
+			ctx: windowsCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64", "app-windows-amd64"},
+				Scoop:          "git@github.com:username/scoop-bucket.git",
+				Winget:         "git@github.com:username/winget-pkgs.git",
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "GitPublishFiles", "GitPublishFiles", "BranchProtection",
+			},
+		},
+		{
+			name: "PackagesNoBuild",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step33: step33OK,
+			},
+			ctx: packagesNoBuildCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+			expectedWarning: "⚠️  Skipping Linux packages since release artifacts are not built (-build)",
+		},
+		{
+			name: "Step33Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step33: &step.LinuxPackages{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step33"),
+					},
+				},
+			},
+			ctx:           packagesCtx,
+			expectedError: errors.New("error on dry: step33"),
+		},
+		{
+			name: "SuccessPackages",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step33: step33OK,
+			},
+			ctx: packagesCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64", "app_0.2.0_amd64.deb", "app-0.2.0-1.x86_64.rpm"},
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "LinuxPackages", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+		},
+		{
+			name: "DockerNoBuild",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step34: step34OK,
+				step35: step35OK,
+			},
+			ctx: dockerNoBuildCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "BranchProtection",
+			},
+			expectedWarning: "⚠️  Skipping Docker image since release artifacts are not built (-build)",
+		},
+		{
+			name: "Step34Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step34: &step.ImageBuild{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step34"),
+					},
+				},
+				step35: step35OK,
+			},
+			ctx:           dockerCtx,
+			expectedError: errors.New("error on dry: step34"),
+		},
+		{
+			name: "Step35Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step34: step34OK,
+				step35: &step.ImagePush{
+					Mock: &mockStep{
+						DryOutError: errors.New("error on dry: step35"),
+					},
+				},
+			},
+			ctx:           dockerCtx,
+			expectedError: errors.New("error on dry: step35"),
+		},
+		{
+			name: "SuccessDocker",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step34: step34OK,
+				step35: step35OK,
+			},
+			ctx: dockerCtx,
+			expectedPlan: releasePlan{
+				Provider:       "github",
+				Branch:         "master",
+				CurrentVersion: "0.2.0",
+				ReleaseVersion: "0.2.0",
+				NextVersion:    "0.2.1-0",
+				Tag:            "v0.2.0",
+				Changelog:      true,
+				Assets:         []string{"app-linux-amd64", "app-darwin-amd64"},
+				Image:          "ghcr.io/username/app (0.2.0, latest)",
+			},
+			expectedSteps: []string{
+				"GitPull", "SemVerUpdate", "ReleaseCreate", "ChangelogGenerate", "GitAdd", "GitCommit", "GitTag",
+				"GoBuild", "ImageBuild", "ReleaseUploadAssets", "BranchProtection", "GitPush", "GitPushTag",
+				"SemVerUpdate", "GitAdd", "GitCommit", "GitPush", "ReleaseEdit", "ImagePush", "BranchProtection",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.action.Dry(tc.ctx)
+			assert.Equal(t, tc.expectedError, err)
+
+			if tc.expectedError == nil {
+				plan := tc.action.(*release).plan
+
+				steps := []string{}
+				for _, st := range plan.Steps {
+					steps = append(steps, st.Step)
+				}
+				assert.Equal(t, tc.expectedSteps, steps)
+
+				plan.Steps = nil
+				assert.Equal(t, tc.expectedPlan, plan)
+			}
+
+			if tc.expectedWarning != "" {
+				assert.Contains(t, tc.action.(*release).ui.(*mockCUI).WarnfMessages, tc.expectedWarning)
+			}
+		})
+	}
+}
+
+func TestReleaseSetGitToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		release       *release
+		expectedToken string
+	}{
+		{
+			name: "CLI",
+			release: &release{
+				provider: &step.GitLabProvider{Token: "gitlab-token"},
+			},
+			expectedToken: "",
+		},
+		{
+			name: "GitHub",
+			release: &release{
+				gogit:    &step.GoGit{},
+				provider: &step.GitHubProvider{Token: "github-token"},
+			},
+			expectedToken: "github-token",
+		},
+		{
+			name: "GitLab",
+			release: &release{
+				gogit:    &step.GoGit{},
+				provider: &step.GitLabProvider{Token: "gitlab-token"},
+			},
+			expectedToken: "gitlab-token",
+		},
+		{
+			name: "Gitea",
+			release: &release{
+				gogit:    &step.GoGit{},
+				provider: &step.GiteaProvider{Token: "gitea-token"},
+			},
+			expectedToken: "gitea-token",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.release.setGitToken(context.Background())
+			assert.NoError(t, err)
+
+			if tc.release.gogit != nil {
+				assert.Equal(t, tc.expectedToken, tc.release.gogit.Token)
+			}
+		})
+	}
+}
+
+func TestReleaseRun(t *testing.T) {
+	ctx := ContextWithSpec(
+		ContextWithReleaseParams(
+			context.Background(),
+			semver.Patch,
+			"comment",
+		),
+		spec.Spec{
+			ToolName:    "cherry",
+			ToolVersion: "test",
+			Build: spec.Build{
+				Platforms: []string{"linux-amd64", "darwin-amd64"},
+			},
+			Release: spec.Release{
+				Build: true,
+			},
+		},
+	)
+
+	signed := SpecFromContext(ctx)
+	signed.Release.Sign = true
+	signedCtx := ContextWithSpec(ctx, signed)
+
+	fromGit := SpecFromContext(ctx)
+	fromGit.VersionSource = "git"
+	gitCtx := ContextWithSpec(ctx, fromGit)
+
+	calendar := SpecFromContext(ctx)
+	calendar.Versioning = "calver"
+	calverCtx := ContextWithSpec(ctx, calendar)
+
+	calverFormat, _ := calver.ParseFormat(calver.DefaultFormat)
+
+	homebrew := SpecFromContext(ctx)
+	homebrew.Release.Homebrew.Tap = "username/homebrew-tap"
+	homebrewCtx := ContextWithSpec(ctx, homebrew)
+
+	homebrewGit := SpecFromContext(ctx)
+	homebrewGit.Release.Homebrew.Method = "git"
+	homebrewGit.Release.Homebrew.TapURL = "git@github.com:username/homebrew-tap.git"
+	homebrewGitCtx := ContextWithSpec(ctx, homebrewGit)
+
+	windows := SpecFromContext(ctx)
+	windows.Build.Platforms = append(windows.Build.Platforms, "windows-amd64")
+	windows.Release.Scoop.Bucket = "username/scoop-bucket"
+	windows.Release.Winget = spec.Winget{
+		Repo:        "username/winget-pkgs",
+		Publisher:   "username",
+		Description: "A test application",
+		License:     "MIT",
+	}
+	windowsCtx := ContextWithSpec(ctx, windows)
+
+	packages := SpecFromContext(ctx)
+	packages.Release.Packages = spec.Packages{
+		Formats:     []string{"deb", "rpm"},
+		Maintainer:  "Jane Doe <jane@example.com>",
+		Description: "A test application",
+	}
+	packagesCtx := ContextWithSpec(ctx, packages)
+
+	docker := SpecFromContext(ctx)
+	docker.Release.Docker = spec.Docker{
+		Image: "ghcr.io/username/app",
+		Push:  true,
+	}
+	dockerCtx := ContextWithSpec(ctx, docker)
+
+	binDir, err := ioutil.TempDir("", "cherry-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(binDir)
+	assert.NoError(t, os.Mkdir(filepath.Join(binDir, "bin"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "bin/app-linux-amd64"), []byte("linux"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "bin/app-darwin-amd64"), []byte("darwin"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(binDir, "bin/app-windows-amd64"), []byte("windows"), 0755))
+
+	step1OK := &step.GitGetRepo{Mock: &mockStep{}}
+
+	step1GitLab := &step.GitGetRepo{Mock: &mockStep{}}
+	step1GitLab.Result.Host = "gitlab.com"
+	step1GitLab.Result.Repo = "octocat/Hello-World"
+
+	step2OK := &step.GitGetBranch{Mock: &mockStep{}}
+	step2OK.Result.Name = "master"
+
+	step3OK := &step.GitStatus{Mock: &mockStep{}}
+	step3OK.Result.IsClean = true
+
+	step4OK := &step.GitPull{Mock: &mockStep{}}
+
+	step5OK := &step.SemVerRead{Mock: &mockStep{}}
+	step5OK.Result.Filename = "VERSION"
+	step5OK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+
+	step5Git := &step.SemVerRead{Mock: &mockStep{}}
+	step5Git.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 1, Prerelease: []string{"3"}, Metadata: []string{"gabcdef1"}}
+	step5Git.Result.Tag = "v0.2.0"
+	step5Git.Result.Commits = 3
+
+	step5Tagged := &step.SemVerRead{Mock: &mockStep{}}
+	step5Tagged.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+	step5Tagged.Result.Tag = "v0.2.0"
+
+	step5CalVer := &step.SemVerRead{Mock: &mockStep{}}
+	step5CalVer.Result.Filename = "VERSION"
+	step5CalVer.Result.CalVer, _ = calverFormat.Parse("2020.01.3")
+
+	step5CalVerAhead := &step.SemVerRead{Mock: &mockStep{}}
+	step5CalVerAhead.Result.Filename = "VERSION"
+	step5CalVerAhead.Result.CalVer, _ = calverFormat.Parse("9999.01.0")
+
+	step27OK := &step.GitLatestTag{Mock: &mockStep{}}
+	step27OK.Result.Tag = "v0.1.0"
+
+	step28OK := &step.GoModMajor{Mock: &mockStep{}}
+
+	step6OK := &step.SemVerUpdate{Mock: &mockStep{}}
+	step6OK.Result.Filename = "VERSION"
+
+	step7OK := &step.ReleaseCreate{Mock: &mockStep{}}
+	step7OK.Result.Release = step.Release{
+		ID:         2,
+		Name:       "0.2.0",
+		TagName:    "v0.2.0",
+		Target:     "master",
+		Draft:      true,
+		Prerelease: false,
+	}
+
+	step8OK := &step.ChangelogGenerate{Mock: &mockStep{}}
+	step8OK.Result.Filename = "CHANGELOG.md"
+	step8OK.Result.Changelog = "change log ..."
+
+	// Change log is only generated for GitHub repositories
+	step8Fails := &step.ChangelogGenerate{
+		Mock: &mockStep{
+			RunOutError: errors.New("error on run: step8"),
+		},
+	}
+
+	step9OK := &step.GitAdd{Mock: &mockStep{}}
+	step10OK := &step.GitCommit{Mock: &mockStep{}}
+	step11OK := &step.GitTag{Mock: &mockStep{}}
+
+	step12OK := &step.GoList{Mock: &mockStep{}}
+	step12OK.Result.PackagePath = "github.com/username/repo/cmd/version"
+
+	step13OK := &step.GitGetHEAD{Mock: &mockStep{}}
+	step13OK.Result.SHA = "aaaaaaa"
+	step13OK.Result.ShortSHA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+
+	step14OK := &step.GoVersion{Mock: &mockStep{}}
+	step14OK.Result.Version = "go1.13"
+
+	step15OK := &step.GoBuild{Mock: &mockStep{}}
+	step15OK.Result.Binaries = []string{"bin/app-linux-amd64", "bin/app-darwin-amd64"}
+
+	step16OK := &step.ReleaseUploadAssets{Mock: &mockStep{}}
+	step16OK.Result.Assets = []step.ReleaseAsset{
+		{ID: 1, Name: "bin/app-linux-amd64"},
+		{ID: 2, Name: "bin/app-darwin-amd64"},
+	}
+
+	step17OK := &step.BranchProtection{Mock: &mockStep{}}
+	step18OK := &step.BranchProtection{Mock: &mockStep{}}
+	step19OK := &step.GitPush{Mock: &mockStep{}}
+	step20OK := &step.GitPushTag{Mock: &mockStep{}}
+
+	step21OK := &step.SemVerUpdate{Mock: &mockStep{}}
+	step21OK.Result.Filename = "VERSION"
+
+	step22OK := &step.GitAdd{Mock: &mockStep{}}
+	step23OK := &step.GitCommit{Mock: &mockStep{}}
+	step24OK := &step.GitPush{Mock: &mockStep{}}
+
+	step25OK := &step.ReleaseEdit{Mock: &mockStep{}}
+
+	step15Homebrew := &step.GoBuild{Mock: &mockStep{}, WorkDir: binDir}
+	step15Homebrew.Result.Binaries = []string{"bin/app-linux-amd64", "bin/app-darwin-amd64"}
+
+	step25Homebrew := &step.ReleaseEdit{Mock: &mockStep{}}
+	step25Homebrew.Result.Release.Assets = []step.ReleaseAsset{
+		{Name: "app-linux-amd64", DownloadURL: "https://github.com/username/repo/releases/download/v0.2.0/app-linux-amd64"},
+		{Name: "app-darwin-amd64", DownloadURL: "https://github.com/username/repo/releases/download/v0.2.0/app-darwin-amd64"},
+	}
+
+	step15Windows := &step.GoBuild{Mock: &mockStep{}, WorkDir: binDir}
+	step15Windows.Result.Binaries = []string{"bin/app-linux-amd64", "bin/app-darwin-amd64", "bin/app-windows-amd64"}
+
+	step25Windows := &step.ReleaseEdit{Mock: &mockStep{}}
+	step25Windows.Result.Release.Assets = []step.ReleaseAsset{
+		{Name: "app-linux-amd64", DownloadURL: "https://github.com/username/repo/releases/download/v0.2.0/app-linux-amd64"},
+		{Name: "app-darwin-amd64", DownloadURL: "https://github.com/username/repo/releases/download/v0.2.0/app-darwin-amd64"},
+		{Name: "app-windows-amd64", DownloadURL: "https://github.com/username/repo/releases/download/v0.2.0/app-windows-amd64"},
+	}
+
+	step29OK := &step.GitHubUpdateFile{Mock: &mockStep{}}
+	step30OK := &step.GitPublishFiles{Mock: &mockStep{}}
+	step31OK := &step.GitPublishFiles{Mock: &mockStep{}}
+	step32OK := &step.GitPublishFiles{Mock: &mockStep{}}
+
+	step33OK := &step.LinuxPackages{Mock: &mockStep{}}
+	step33OK.Result.Files = []string{"bin/app_0.2.0_amd64.deb", "bin/app-0.2.0-1.x86_64.rpm"}
+
+	step34OK := &step.ImageBuild{Mock: &mockStep{}}
+	step34OK.Result.File = "bin/app-image.tar"
+
+	step35OK := &step.ImagePush{Mock: &mockStep{}}
+	step25OK.Result.Release = step.Release{
+		ID:         2,
+		Name:       "0.2.0",
+		TagName:    "v0.2.0",
+		Target:     "master",
+		Draft:      false,
+		Prerelease: false,
+		Body:       "text",
+	}
+
+	tests := []struct {
+		name          string
+		action        Action
+		ctx           context.Context
+		expectedError error
+	}{
+		{
+			name: "Step1Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step1: &step.GitGetRepo{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step1"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step1"),
+		},
+		{
+			name: "Step2Fails",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: &step.GitGetBranch{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step2"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step2"),
+		},
+		{
+			name: "BranchNotMaster",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: &step.GitGetBranch{
+					Mock: &mockStep{},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("release has to be done from master branch"),
+		},
+		{
+			name: "Step3Fails",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: &step.GitStatus{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step3"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step3"),
+		},
+		{
+			name: "BranchNotClean",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: &step.GitStatus{
+					Mock: &mockStep{},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("working directory is not clean and has uncommitted changes"),
+		},
+		{
+			name: "Step4Fails",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: &step.GitPull{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step4"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step4"),
+		},
+		{
+			name: "Step5Fails",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: step4OK,
+				step5: &step.SemVerRead{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step5"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step5"),
+		},
+		{
+			name: "Step27Fails",
+			action: &release{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: step4OK,
+				step5: step5OK,
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step27"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step27"),
+		},
+		{
+			name: "Step6Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6: &step.SemVerUpdate{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step6"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step6"),
+		},
+		{
+			name: "Step28Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step6:  step6OK,
+				step28: &step.GoModMajor{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step28"),
+					},
+				},
+			},
+			ctx:           ContextWithReleaseParams(ctx, semver.Major, "comment"),
+			expectedError: errors.New("error on run: step28"),
+		},
+		{
+			name: "Step7Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7: &step.ReleaseCreate{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step7"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step7"),
+		},
+		{
+			name: "Step8Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8: &step.ChangelogGenerate{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step8"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step8"),
+		},
+		{
+			name: "Step9Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9: &step.GitAdd{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step9"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step9"),
+		},
+		{
+			name: "Step10Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: &step.GitCommit{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step10"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step10"),
+		},
+		{
+			name: "Step11Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: &step.GitTag{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step11"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step11"),
+		},
+		{
+			name: "Step26Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step26"),
+					},
+				},
+			},
+			ctx:           signedCtx,
+			expectedError: errors.New("error on run: step26"),
+		},
+		{
+			name: "Step12Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: &step.GoList{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step12"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step12"),
+		},
+		{
+			name: "Step13Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: &step.GitGetHEAD{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step13"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step13"),
+		},
+		{
+			name: "Step14Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: &step.GoVersion{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step14"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step14"),
+		},
+		{
+			name: "Step15Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: &step.GoBuild{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step15"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step15"),
+		},
+		{
+			name: "Step16Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: &step.ReleaseUploadAssets{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step16"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step16"),
+		},
+		{
+			name: "Step17Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: &step.BranchProtection{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step17"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step17"),
+		},
+		{
+			name: "Step19Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: &step.GitPush{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step19"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step19"),
+		},
+		{
+			name: "Step20Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: &step.GitPushTag{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step20"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step20"),
+		},
+		{
+			name: "Step21Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: &step.SemVerUpdate{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step21"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step21"),
+		},
+		{
+			name: "Step22Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: &step.GitAdd{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step22"),
+					},
+				},
+			},
+			ctx:           ctx,
+			expectedError: errors.New("error on run: step22"),
+		},
+		{
+			name: "Step23Fails",
+			action: &release{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: &step.GitCommit{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step2"),
+						RunOutError: errors.New("error on run: step23"),
 					},
 				},
 			},
 			ctx:           ctx,
-			expectedError: errors.New("error on run: step2"),
+			expectedError: errors.New("error on run: step23"),
 		},
 		{
-			name: "BranchNotMaster",
+			name: "Step24Fails",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: &step.GitGetBranch{
-					Mock: &mockStep{},
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: &step.GitPush{
+					Mock: &mockStep{
+						RunOutError: errors.New("error on run: step24"),
+					},
 				},
 			},
 			ctx:           ctx,
-			expectedError: errors.New("release has to be done from master branch"),
+			expectedError: errors.New("error on run: step24"),
 		},
 		{
-			name: "Step3Fails",
+			name: "Step25Fails",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: &step.GitStatus{
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step3"),
+						RunOutError: errors.New("error on run: step25"),
 					},
 				},
 			},
 			ctx:           ctx,
-			expectedError: errors.New("error on run: step3"),
+			expectedError: errors.New("error on run: step25"),
 		},
 		{
-			name: "BranchNotClean",
+			name: "Success",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: &step.GitStatus{
-					Mock: &mockStep{},
-				},
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("working directory is not clean and has uncommitted changes"),
+			ctx: ctx,
 		},
 		{
-			name: "Step4Fails",
+			name: "UnknownProvider",
 			action: &release{
 				ui:    &mockCUI{},
 				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: &step.GitPull{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step4"),
-					},
-				},
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step4"),
-		},
-		{
-			name: "Step5Fails",
-			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: &step.SemVerRead{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step5"),
-					},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Provider: "bitbucket",
 				},
-			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step5"),
+			}),
+			expectedError: errors.New("unknown release provider: bitbucket"),
 		},
 		{
-			name: "Step6Fails",
+			name: "GitLabSuccess",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: &step.SemVerUpdate{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step6"),
-					},
-				},
+				ui:     &mockCUI{},
+				step1:  step1GitLab,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8Fails,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step6"),
+			ctx: ctx,
 		},
 		{
-			name: "Step7Fails",
+			name: "NoCommitSinceTag",
 			action: &release{
 				ui:    &mockCUI{},
 				step1: step1OK,
 				step2: step2OK,
 				step3: step3OK,
 				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: &step.GitHubCreateRelease{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step7"),
-					},
-				},
+				step5: step5Tagged,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step7"),
+			ctx:           gitCtx,
+			expectedError: errors.New("no commit since the latest release v0.2.0"),
 		},
 		{
-			name: "Step8Fails",
+			name: "SuccessGit",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: step7OK,
-				step8: &step.ChangelogGenerate{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step8"),
-					},
-				},
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5Git,
+				step27: step27OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step20: step20OK,
+				step25: step25OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step8"),
+			ctx: gitCtx,
 		},
 		{
-			name: "Step9Fails",
+			name: "CalVerAhead",
 			action: &release{
 				ui:    &mockCUI{},
 				step1: step1OK,
 				step2: step2OK,
 				step3: step3OK,
 				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: step7OK,
-				step8: step8OK,
-				step9: &step.GitAdd{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step9"),
-					},
-				},
+				step5: step5CalVerAhead,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step9"),
+			ctx:           calverCtx,
+			expectedError: fmt.Errorf("calendar version 9999.01.0 is ahead of the release date %s", time.Now().Format("2006-01-02")),
 		},
 		{
-			name: "Step10Fails",
+			name: "SuccessCalVer",
 			action: &release{
-				ui:    &mockCUI{},
-				step1: step1OK,
-				step2: step2OK,
-				step3: step3OK,
-				step4: step4OK,
-				step5: step5OK,
-				step6: step6OK,
-				step7: step7OK,
-				step8: step8OK,
-				step9: step9OK,
-				step10: &step.GitCommit{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step10"),
-					},
-				},
+				ui:     &mockCUI{},
+				step1:  step1OK,
+				step2:  step2OK,
+				step3:  step3OK,
+				step4:  step4OK,
+				step5:  step5CalVer,
+				step27: step27OK,
+				step28: step28OK,
+				step6:  step6OK,
+				step7:  step7OK,
+				step8:  step8OK,
+				step9:  step9OK,
+				step10: step10OK,
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15OK,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step25: step25OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step10"),
+			ctx: calverCtx,
 		},
 		{
-			name: "Step11Fails",
+			name: "HomebrewNoDownloadURL",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1185,46 +3483,71 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
 				step9:  step9OK,
 				step10: step10OK,
-				step11: &step.GitTag{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step11"),
-					},
-				},
+				step11: step11OK,
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15Homebrew,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step29: step29OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step11"),
+			ctx:           homebrewCtx,
+			expectedError: errors.New("no download url for asset app-linux-amd64"),
 		},
 		{
-			name: "Step12Fails",
+			name: "HomebrewNotGitHub",
 			action: &release{
 				ui:     &mockCUI{},
-				step1:  step1OK,
+				step1:  step1GitLab,
 				step2:  step2OK,
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
 				step9:  step9OK,
 				step10: step10OK,
 				step11: step11OK,
-				step12: &step.GoList{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step12"),
-					},
-				},
+				step12: step12OK,
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15Homebrew,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Homebrew,
+				step29: step29OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step12"),
+			ctx:           homebrewCtx,
+			expectedError: errors.New("homebrew formula cannot be published using the contents API of gitlab, use git method instead"),
 		},
 		{
-			name: "Step13Fails",
+			name: "Step29Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1232,6 +3555,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1239,17 +3564,30 @@ func TestReleaseRun(t *testing.T) {
 				step10: step10OK,
 				step11: step11OK,
 				step12: step12OK,
-				step13: &step.GitGetHEAD{
+				step13: step13OK,
+				step14: step14OK,
+				step15: step15Homebrew,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Homebrew,
+				step29: &step.GitHubUpdateFile{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step13"),
+						RunOutError: errors.New("error on run: step29"),
 					},
 				},
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step13"),
+			ctx:           homebrewCtx,
+			expectedError: errors.New("error on run: step29"),
 		},
 		{
-			name: "Step14Fails",
+			name: "Step30Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1257,6 +3595,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1265,17 +3605,29 @@ func TestReleaseRun(t *testing.T) {
 				step11: step11OK,
 				step12: step12OK,
 				step13: step13OK,
-				step14: &step.GoVersion{
+				step14: step14OK,
+				step15: step15Homebrew,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Homebrew,
+				step30: &step.GitPublishFiles{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step14"),
+						RunOutError: errors.New("error on run: step30"),
 					},
 				},
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step14"),
+			ctx:           homebrewGitCtx,
+			expectedError: errors.New("error on run: step30"),
 		},
 		{
-			name: "Step15Fails",
+			name: "SuccessHomebrew",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1283,6 +3635,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1292,17 +3646,23 @@ func TestReleaseRun(t *testing.T) {
 				step12: step12OK,
 				step13: step13OK,
 				step14: step14OK,
-				step15: &step.GoBuild{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step15"),
-					},
-				},
+				step15: step15Homebrew,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Homebrew,
+				step29: step29OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step15"),
+			ctx: homebrewCtx,
 		},
 		{
-			name: "Step16Fails",
+			name: "SuccessHomebrewGit",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1310,6 +3670,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1319,18 +3681,23 @@ func TestReleaseRun(t *testing.T) {
 				step12: step12OK,
 				step13: step13OK,
 				step14: step14OK,
-				step15: step15OK,
-				step16: &step.GitHubUploadAssets{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step16"),
-					},
-				},
+				step15: step15Homebrew,
+				step16: step16OK,
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Homebrew,
+				step30: step30OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step16"),
+			ctx: homebrewGitCtx,
 		},
 		{
-			name: "Step17Fails",
+			name: "WindowsNoDownloadURL",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1338,6 +3705,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1347,19 +3716,25 @@ func TestReleaseRun(t *testing.T) {
 				step12: step12OK,
 				step13: step13OK,
 				step14: step14OK,
-				step15: step15OK,
+				step15: step15Windows,
 				step16: step16OK,
-				step17: &step.GitHubBranchProtection{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step17"),
-					},
-				},
+				step17: step17OK,
+				step18: step18OK,
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step31: step31OK,
+				step32: step32OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step17"),
+			ctx:           windowsCtx,
+			expectedError: errors.New("no download url for asset app-windows-amd64"),
 		},
 		{
-			name: "Step19Fails",
+			name: "Step31Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1367,6 +3742,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1376,21 +3753,29 @@ func TestReleaseRun(t *testing.T) {
 				step12: step12OK,
 				step13: step13OK,
 				step14: step14OK,
-				step15: step15OK,
+				step15: step15Windows,
 				step16: step16OK,
 				step17: step17OK,
 				step18: step18OK,
-				step19: &step.GitPush{
+				step19: step19OK,
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Windows,
+				step31: &step.GitPublishFiles{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step19"),
+						RunOutError: errors.New("error on run: step31"),
 					},
 				},
+				step32: step32OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step19"),
+			ctx:           windowsCtx,
+			expectedError: errors.New("error on run: step31"),
 		},
 		{
-			name: "Step20Fails",
+			name: "Step32Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1398,6 +3783,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1407,22 +3794,29 @@ func TestReleaseRun(t *testing.T) {
 				step12: step12OK,
 				step13: step13OK,
 				step14: step14OK,
-				step15: step15OK,
+				step15: step15Windows,
 				step16: step16OK,
 				step17: step17OK,
 				step18: step18OK,
 				step19: step19OK,
-				step20: &step.GitPushTag{
+				step20: step20OK,
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Windows,
+				step31: step31OK,
+				step32: &step.GitPublishFiles{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step20"),
+						RunOutError: errors.New("error on run: step32"),
 					},
 				},
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step20"),
+			ctx:           windowsCtx,
+			expectedError: errors.New("error on run: step32"),
 		},
 		{
-			name: "Step21Fails",
+			name: "SuccessScoopWinget",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1430,6 +3824,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1439,23 +3835,24 @@ func TestReleaseRun(t *testing.T) {
 				step12: step12OK,
 				step13: step13OK,
 				step14: step14OK,
-				step15: step15OK,
+				step15: step15Windows,
 				step16: step16OK,
 				step17: step17OK,
 				step18: step18OK,
 				step19: step19OK,
 				step20: step20OK,
-				step21: &step.SemVerUpdate{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step21"),
-					},
-				},
+				step21: step21OK,
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25Windows,
+				step31: step31OK,
+				step32: step32OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step21"),
+			ctx: windowsCtx,
 		},
 		{
-			name: "Step22Fails",
+			name: "Step33Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1463,6 +3860,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1479,17 +3878,21 @@ func TestReleaseRun(t *testing.T) {
 				step19: step19OK,
 				step20: step20OK,
 				step21: step21OK,
-				step22: &step.GitAdd{
+				step22: step22OK,
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step33: &step.LinuxPackages{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step22"),
+						RunOutError: errors.New("error on run: step33"),
 					},
 				},
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step22"),
+			ctx:           packagesCtx,
+			expectedError: errors.New("error on run: step33"),
 		},
 		{
-			name: "Step23Fails",
+			name: "SuccessPackages",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1497,6 +3900,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1514,17 +3919,15 @@ func TestReleaseRun(t *testing.T) {
 				step20: step20OK,
 				step21: step21OK,
 				step22: step22OK,
-				step23: &step.GitCommit{
-					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step23"),
-					},
-				},
+				step23: step23OK,
+				step24: step24OK,
+				step25: step25OK,
+				step33: step33OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step23"),
+			ctx: packagesCtx,
 		},
 		{
-			name: "Step24Fails",
+			name: "Step34Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1532,6 +3935,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1550,17 +3955,20 @@ func TestReleaseRun(t *testing.T) {
 				step21: step21OK,
 				step22: step22OK,
 				step23: step23OK,
-				step24: &step.GitPush{
+				step24: step24OK,
+				step25: step25OK,
+				step34: &step.ImageBuild{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step24"),
+						RunOutError: errors.New("error on run: step34"),
 					},
 				},
+				step35: step35OK,
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step24"),
+			ctx:           dockerCtx,
+			expectedError: errors.New("error on run: step34"),
 		},
 		{
-			name: "Step25Fails",
+			name: "Step35Fails",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1568,6 +3976,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1587,17 +3997,19 @@ func TestReleaseRun(t *testing.T) {
 				step22: step22OK,
 				step23: step23OK,
 				step24: step24OK,
-				step25: &step.GitHubEditRelease{
+				step25: step25OK,
+				step34: step34OK,
+				step35: &step.ImagePush{
 					Mock: &mockStep{
-						RunOutError: errors.New("error on run: step25"),
+						RunOutError: errors.New("error on run: step35"),
 					},
 				},
 			},
-			ctx:           ctx,
-			expectedError: errors.New("error on run: step25"),
+			ctx:           dockerCtx,
+			expectedError: errors.New("error on run: step35"),
 		},
 		{
-			name: "Success",
+			name: "SuccessDocker",
 			action: &release{
 				ui:     &mockCUI{},
 				step1:  step1OK,
@@ -1605,6 +4017,8 @@ func TestReleaseRun(t *testing.T) {
 				step3:  step3OK,
 				step4:  step4OK,
 				step5:  step5OK,
+				step27: step27OK,
+				step28: step28OK,
 				step6:  step6OK,
 				step7:  step7OK,
 				step8:  step8OK,
@@ -1625,8 +4039,10 @@ func TestReleaseRun(t *testing.T) {
 				step23: step23OK,
 				step24: step24OK,
 				step25: step25OK,
+				step34: step34OK,
+				step35: step35OK,
 			},
-			ctx: ctx,
+			ctx: dockerCtx,
 		},
 	}
 
@@ -1645,11 +4061,92 @@ func TestReleaseRevert(t *testing.T) {
 		ctx           context.Context
 		expectedError error
 	}{
+		{
+			name: "Step29Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step29: &step.GitHubUpdateFile{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step29"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build:    true,
+					Homebrew: spec.Homebrew{Tap: "username/homebrew-tap"},
+				},
+			}),
+			expectedError: errors.New("error on revert: step29"),
+		},
+		{
+			name: "Step30Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step30: &step.GitPublishFiles{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step30"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build:    true,
+					Homebrew: spec.Homebrew{Method: "git", TapURL: "git@github.com:username/homebrew-tap.git"},
+				},
+			}),
+			expectedError: errors.New("error on revert: step30"),
+		},
+		{
+			name: "Step32Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step32: &step.GitPublishFiles{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step32"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build:  true,
+					Winget: spec.Winget{Repo: "username/winget-pkgs"},
+				},
+			}),
+			expectedError: errors.New("error on revert: step32"),
+		},
+		{
+			name: "Step31Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step31: &step.GitPublishFiles{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step31"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build: true,
+					Scoop: spec.Scoop{Bucket: "username/scoop-bucket"},
+				},
+			}),
+			expectedError: errors.New("error on revert: step31"),
+		},
 		{
 			name: "Step25Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step25"),
 					},
@@ -1662,7 +4159,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step24Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1678,7 +4184,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step23Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1697,7 +4212,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step22Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1719,7 +4243,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step21Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1744,7 +4277,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step20Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1772,7 +4314,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step19Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1803,7 +4354,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step18Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1824,7 +4384,7 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step18"),
 					},
@@ -1837,7 +4397,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step17Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1858,10 +4427,10 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step17"),
 					},
@@ -1874,7 +4443,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step16Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1895,13 +4473,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step16"),
 					},
@@ -1910,11 +4488,158 @@ func TestReleaseRevert(t *testing.T) {
 			ctx:           context.Background(),
 			expectedError: errors.New("error on revert: step16"),
 		},
+		{
+			name: "Step33Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
+					Mock: &mockStep{},
+				},
+				step24: &step.GitPush{
+					Mock: &mockStep{},
+				},
+				step23: &step.GitCommit{
+					Mock: &mockStep{},
+				},
+				step22: &step.GitAdd{
+					Mock: &mockStep{},
+				},
+				step21: &step.SemVerUpdate{
+					Mock: &mockStep{},
+				},
+				step20: &step.GitPushTag{
+					Mock: &mockStep{},
+				},
+				step19: &step.GitPush{
+					Mock: &mockStep{},
+				},
+				step18: &step.BranchProtection{
+					Mock: &mockStep{},
+				},
+				step17: &step.BranchProtection{
+					Mock: &mockStep{},
+				},
+				step16: &step.ReleaseUploadAssets{
+					Mock: &mockStep{},
+				},
+				step15: &step.GoBuild{
+					Mock: &mockStep{},
+				},
+				step33: &step.LinuxPackages{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step33"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build:    true,
+					Packages: spec.Packages{Formats: []string{"deb"}},
+				},
+			}),
+			expectedError: errors.New("error on revert: step33"),
+		},
+		{
+			name: "Step34Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
+					Mock: &mockStep{},
+				},
+				step24: &step.GitPush{
+					Mock: &mockStep{},
+				},
+				step23: &step.GitCommit{
+					Mock: &mockStep{},
+				},
+				step22: &step.GitAdd{
+					Mock: &mockStep{},
+				},
+				step21: &step.SemVerUpdate{
+					Mock: &mockStep{},
+				},
+				step20: &step.GitPushTag{
+					Mock: &mockStep{},
+				},
+				step19: &step.GitPush{
+					Mock: &mockStep{},
+				},
+				step18: &step.BranchProtection{
+					Mock: &mockStep{},
+				},
+				step17: &step.BranchProtection{
+					Mock: &mockStep{},
+				},
+				step16: &step.ReleaseUploadAssets{
+					Mock: &mockStep{},
+				},
+				step15: &step.GoBuild{
+					Mock: &mockStep{},
+				},
+				step34: &step.ImageBuild{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step34"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build:  true,
+					Docker: spec.Docker{Image: "ghcr.io/username/app"},
+				},
+			}),
+			expectedError: errors.New("error on revert: step34"),
+		},
+		{
+			name: "Step35Fails",
+			action: &release{
+				ui: &mockCUI{},
+				step35: &step.ImagePush{
+					Mock: &mockStep{
+						RevertOutError: errors.New("error on revert: step35"),
+					},
+				},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{
+				Release: spec.Release{
+					Build:  true,
+					Docker: spec.Docker{Image: "ghcr.io/username/app", Push: true},
+				},
+			}),
+			expectedError: errors.New("error on revert: step35"),
+		},
 		{
 			name: "Step15Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1935,13 +4660,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -1957,7 +4682,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step14Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -1978,13 +4712,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2003,7 +4737,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step13Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2024,13 +4767,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2052,7 +4795,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step12Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2073,13 +4825,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2104,7 +4856,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step11Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2125,13 +4886,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2159,7 +4920,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step10Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2180,13 +4950,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2217,7 +4987,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step9Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2238,13 +5017,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2278,7 +5057,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step8Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2299,13 +5087,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2342,7 +5130,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step7Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2363,13 +5160,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2396,7 +5193,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step7"),
 					},
@@ -2409,7 +5206,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step6Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2430,13 +5236,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2463,7 +5269,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2479,7 +5285,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step5Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2500,13 +5315,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2533,7 +5348,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2552,7 +5367,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step4Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2573,13 +5397,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2606,7 +5430,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2628,7 +5452,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step3Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2649,13 +5482,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2682,7 +5515,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2707,7 +5540,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step2Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2728,13 +5570,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2761,7 +5603,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2789,7 +5631,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Step1Fails",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2810,13 +5661,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2843,7 +5694,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2874,7 +5725,16 @@ func TestReleaseRevert(t *testing.T) {
 			name: "Success",
 			action: &release{
 				ui: &mockCUI{},
-				step25: &step.GitHubEditRelease{
+				step27: &step.GitLatestTag{
+					Mock: &mockStep{},
+				},
+				step28: &step.GoModMajor{
+					Mock: &mockStep{},
+				},
+				step26: &step.GitVerifyTag{
+					Mock: &mockStep{},
+				},
+				step25: &step.ReleaseEdit{
 					Mock: &mockStep{},
 				},
 				step24: &step.GitPush{
@@ -2895,13 +5755,13 @@ func TestReleaseRevert(t *testing.T) {
 				step19: &step.GitPush{
 					Mock: &mockStep{},
 				},
-				step18: &step.GitHubBranchProtection{
+				step18: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step17: &step.GitHubBranchProtection{
+				step17: &step.BranchProtection{
 					Mock: &mockStep{},
 				},
-				step16: &step.GitHubUploadAssets{
+				step16: &step.ReleaseUploadAssets{
 					Mock: &mockStep{},
 				},
 				step15: &step.GoBuild{
@@ -2928,7 +5788,7 @@ func TestReleaseRevert(t *testing.T) {
 				step8: &step.ChangelogGenerate{
 					Mock: &mockStep{},
 				},
-				step7: &step.GitHubCreateRelease{
+				step7: &step.ReleaseCreate{
 					Mock: &mockStep{},
 				},
 				step6: &step.SemVerUpdate{
@@ -2952,6 +5812,60 @@ func TestReleaseRevert(t *testing.T) {
 			},
 			ctx: context.Background(),
 		},
+		{
+			name: "SuccessGit",
+			action: &release{
+				ui:     &mockCUI{},
+				step27: &step.GitLatestTag{Mock: &mockStep{}},
+				step26: &step.GitVerifyTag{Mock: &mockStep{}},
+				step25: &step.ReleaseEdit{Mock: &mockStep{}},
+				step20: &step.GitPushTag{Mock: &mockStep{}},
+				step16: &step.ReleaseUploadAssets{Mock: &mockStep{}},
+				step15: &step.GoBuild{Mock: &mockStep{}},
+				step14: &step.GoVersion{Mock: &mockStep{}},
+				step13: &step.GitGetHEAD{Mock: &mockStep{}},
+				step12: &step.GoList{Mock: &mockStep{}},
+				step11: &step.GitTag{Mock: &mockStep{}},
+				step7:  &step.ReleaseCreate{Mock: &mockStep{}},
+				step5:  &step.SemVerRead{Mock: &mockStep{}},
+				step4:  &step.GitPull{Mock: &mockStep{}},
+				step3:  &step.GitStatus{Mock: &mockStep{}},
+				step2:  &step.GitGetBranch{Mock: &mockStep{}},
+				step1:  &step.GitGetRepo{Mock: &mockStep{}},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{VersionSource: "git"}),
+		},
+		{
+			name: "SuccessCalVer",
+			action: &release{
+				ui:     &mockCUI{},
+				step27: &step.GitLatestTag{Mock: &mockStep{}},
+				step26: &step.GitVerifyTag{Mock: &mockStep{}},
+				step25: &step.ReleaseEdit{Mock: &mockStep{}},
+				step20: &step.GitPushTag{Mock: &mockStep{}},
+				step19: &step.GitPush{Mock: &mockStep{}},
+				step18: &step.BranchProtection{Mock: &mockStep{}},
+				step17: &step.BranchProtection{Mock: &mockStep{}},
+				step16: &step.ReleaseUploadAssets{Mock: &mockStep{}},
+				step15: &step.GoBuild{Mock: &mockStep{}},
+				step14: &step.GoVersion{Mock: &mockStep{}},
+				step13: &step.GitGetHEAD{Mock: &mockStep{}},
+				step12: &step.GoList{Mock: &mockStep{}},
+				step11: &step.GitTag{Mock: &mockStep{}},
+				step10: &step.GitCommit{Mock: &mockStep{}},
+				step9:  &step.GitAdd{Mock: &mockStep{}},
+				step8:  &step.ChangelogGenerate{Mock: &mockStep{}},
+				step7:  &step.ReleaseCreate{Mock: &mockStep{}},
+				step28: &step.GoModMajor{Mock: &mockStep{}},
+				step6:  &step.SemVerUpdate{Mock: &mockStep{}},
+				step5:  &step.SemVerRead{Mock: &mockStep{}},
+				step4:  &step.GitPull{Mock: &mockStep{}},
+				step3:  &step.GitStatus{Mock: &mockStep{}},
+				step2:  &step.GitGetBranch{Mock: &mockStep{}},
+				step1:  &step.GitGetRepo{Mock: &mockStep{}},
+			},
+			ctx: ContextWithSpec(context.Background(), spec.Spec{Versioning: "calver"}),
+		},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,176 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/moorara/cherry/internal/spec"
+)
+
+// releaseVars are the variables available to release templates.
+type releaseVars struct {
+	Version         string
+	PreviousVersion string
+	NextVersion     string
+	Tag             string
+	PreviousTag     string
+	Module          string
+	Changelog       string
+	Comment         string
+}
+
+// releaseTemplates are the parsed templates for the commit messages, tag, and release.
+// tagPrefix is prepended to the rendered tag (i.e. sdk/ for modules in a monorepo).
+type releaseTemplates struct {
+	tagPrefix     string
+	releaseCommit *template.Template
+	nextCommit    *template.Template
+	tag           *template.Template
+	tagAnnotation *template.Template
+	releaseName   *template.Template
+	releaseBody   *template.Template
+}
+
+// parseTemplates parses the release templates in spec.
+// Default templates are used for empty ones.
+func parseTemplates(t spec.Templates) (*releaseTemplates, error) {
+	t.SetDefaults()
+
+	parse := func(name, text string) (*template.Template, error) {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s template: %s", name, err)
+		}
+		return tmpl, nil
+	}
+
+	var err error
+	rt := new(releaseTemplates)
+
+	if rt.releaseCommit, err = parse("release_commit", t.ReleaseCommit); err != nil {
+		return nil, err
+	}
+
+	if rt.nextCommit, err = parse("next_commit", t.NextCommit); err != nil {
+		return nil, err
+	}
+
+	if rt.tag, err = parse("tag", t.Tag); err != nil {
+		return nil, err
+	}
+
+	if rt.tagAnnotation, err = parse("tag_annotation", t.TagAnnotation); err != nil {
+		return nil, err
+	}
+
+	if rt.releaseName, err = parse("release_name", t.ReleaseName); err != nil {
+		return nil, err
+	}
+
+	if rt.releaseBody, err = parse("release_body", t.ReleaseBody); err != nil {
+		return nil, err
+	}
+
+	return rt, nil
+}
+
+// render executes a template with the release variables.
+func render(tmpl *template.Template, vars releaseVars) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("invalid %s template: %s", tmpl.Name(), err)
+	}
+
+	return buf.String(), nil
+}
+
+// renderTag renders the tag template with a prefix and makes sure the result is a valid tag name.
+func renderTag(tmpl *template.Template, prefix string, vars releaseVars) (string, error) {
+	tag, err := render(tmpl, vars)
+	if err != nil {
+		return "", err
+	}
+
+	if tag != "" {
+		tag = prefix + tag
+	}
+
+	if tag == "" || strings.ContainsAny(tag, " ~^:?*[\\") || strings.Contains(tag, "..") || strings.HasSuffix(tag, "/") {
+		return "", fmt.Errorf("invalid tag template: %q is not a valid tag name", tag)
+	}
+
+	return tag, nil
+}
+
+// releaseText is the rendered commit messages, tag, and release name.
+type releaseText struct {
+	ReleaseCommit string
+	NextCommit    string
+	Tag           string
+	TagAnnotation string
+	ReleaseName   string
+}
+
+// render renders all templates except the release body.
+// The tag is rendered first, so it is available to other templates as .Tag.
+func (t *releaseTemplates) render(vars *releaseVars) (releaseText, error) {
+	var err error
+	var text releaseText
+
+	if text.Tag, err = renderTag(t.tag, t.tagPrefix, *vars); err != nil {
+		return releaseText{}, err
+	}
+
+	vars.Tag = text.Tag
+
+	if text.ReleaseCommit, err = render(t.releaseCommit, *vars); err != nil {
+		return releaseText{}, err
+	}
+
+	if text.NextCommit, err = render(t.nextCommit, *vars); err != nil {
+		return releaseText{}, err
+	}
+
+	if text.TagAnnotation, err = render(t.tagAnnotation, *vars); err != nil {
+		return releaseText{}, err
+	}
+
+	if text.ReleaseName, err = render(t.releaseName, *vars); err != nil {
+		return releaseText{}, err
+	}
+
+	return text, nil
+}
+
+// tagPattern returns a glob pattern that matches the release tags created by the tag template (i.e. v* or sdk/v*).
+// If the tag template is invalid, the pattern matches any tag with the prefix.
+func tagPattern(t spec.Templates, prefix string) string {
+	templates, err := parseTemplates(t)
+	if err != nil {
+		return prefix + "*"
+	}
+
+	pattern, err := render(templates.tag, releaseVars{Version: "*"})
+	if err != nil || !strings.Contains(pattern, "*") {
+		return prefix + "*"
+	}
+
+	return prefix + pattern
+}
+
+// versionFromTag returns the version in a git tag (e.g. v1.2.3, release-1.2.3, or api/v1.2.3).
+// The version is parsed using the versioning scheme in spec.
+// If the tag does not have a version, an empty string is returned.
+func versionFromTag(s spec.Spec, tag string) string {
+	name := path.Base(tag)
+	if i := strings.IndexAny(name, "0123456789"); i >= 0 {
+		if v, err := parseVersion(s, name[i:]); err == nil {
+			return v
+		}
+	}
+
+	return ""
+}
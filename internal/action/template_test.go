@@ -0,0 +1,196 @@
+package action
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTemplates(t *testing.T) {
+	tests := []struct {
+		name          string
+		templates     spec.Templates
+		expectedError string
+	}{
+		{
+			name:      "Defaults",
+			templates: spec.Templates{},
+		},
+		{
+			name: "Custom",
+			templates: spec.Templates{
+				ReleaseCommit: "chore(release): {{.Version}}",
+				Tag:           "release-{{.Version}}",
+			},
+		},
+		{
+			name: "InvalidNextCommit",
+			templates: spec.Templates{
+				NextCommit: "Beginning {{.NextVersion}",
+			},
+			expectedError: "invalid next_commit template: ",
+		},
+		{
+			name: "InvalidReleaseBody",
+			templates: spec.Templates{
+				ReleaseBody: "{{if .Changelog}}",
+			},
+			expectedError: "invalid release_body template: ",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			templates, err := parseTemplates(tc.templates)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, templates)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				assert.Nil(t, templates)
+			}
+		})
+	}
+}
+
+func TestReleaseTemplatesRender(t *testing.T) {
+	tests := []struct {
+		name          string
+		templates     spec.Templates
+		vars          releaseVars
+		expectedText  releaseText
+		expectedBody  string
+		expectedError error
+	}{
+		{
+			name:      "Defaults",
+			templates: spec.Templates{},
+			vars: releaseVars{
+				Version:     "0.2.0",
+				NextVersion: "0.2.1-0",
+				Changelog:   "## 0.2.0",
+				Comment:     "comment",
+			},
+			expectedText: releaseText{
+				ReleaseCommit: "Releasing 0.2.0",
+				NextCommit:    "Beginning 0.2.1-0 [skip ci]",
+				Tag:           "v0.2.0",
+				TagAnnotation: "Version 0.2.0",
+				ReleaseName:   "0.2.0",
+			},
+			expectedBody: "comment\n\n## 0.2.0",
+		},
+		{
+			name: "Custom",
+			templates: spec.Templates{
+				ReleaseCommit: "chore(release): {{.Tag}}",
+				Tag:           "api/v{{.Version}}",
+				TagAnnotation: "API {{.Version}}",
+				ReleaseName:   "API {{.Version}}",
+				ReleaseBody:   "Changes since {{.PreviousTag}} ({{.PreviousVersion}})\n\n{{.Changelog}}",
+			},
+			vars: releaseVars{
+				Version:         "0.2.0",
+				PreviousVersion: "0.1.0",
+				NextVersion:     "0.2.1-0",
+				PreviousTag:     "api/v0.1.0",
+				Changelog:       "## 0.2.0",
+			},
+			expectedText: releaseText{
+				ReleaseCommit: "chore(release): api/v0.2.0",
+				NextCommit:    "Beginning 0.2.1-0 [skip ci]",
+				Tag:           "api/v0.2.0",
+				TagAnnotation: "API 0.2.0",
+				ReleaseName:   "API 0.2.0",
+			},
+			expectedBody: "Changes since api/v0.1.0 (0.1.0)\n\n## 0.2.0",
+		},
+		{
+			name: "EmptyTag",
+			templates: spec.Templates{
+				Tag: "{{.Comment}}",
+			},
+			vars: releaseVars{
+				Version: "0.2.0",
+			},
+			expectedError: errors.New(`invalid tag template: "" is not a valid tag name`),
+		},
+		{
+			name: "UnknownVariable",
+			templates: spec.Templates{
+				ReleaseName: "{{.Title}}",
+			},
+			vars: releaseVars{
+				Version: "0.2.0",
+			},
+			expectedError: errors.New("invalid release_name template: template: release_name:1:2: executing \"release_name\" at <.Title>: can't evaluate field Title in type action.releaseVars"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			templates, err := parseTemplates(tc.templates)
+			assert.NoError(t, err)
+
+			vars := tc.vars
+			text, err := templates.render(&vars)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedText, text)
+				assert.Equal(t, tc.expectedText.Tag, vars.Tag)
+
+				body, err := render(templates.releaseBody, vars)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedBody, body)
+			} else {
+				assert.Equal(t, tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestTagPattern(t *testing.T) {
+	tests := []struct {
+		templates       spec.Templates
+		prefix          string
+		expectedPattern string
+	}{
+		{spec.Templates{}, "", "v*"},
+		{spec.Templates{}, "sdk/", "sdk/v*"},
+		{spec.Templates{Tag: "release-{{.Version}}"}, "", "release-*"},
+		{spec.Templates{Tag: "{{.Module}}"}, "api/", "api/*"},
+		{spec.Templates{Tag: "v{{.Version}"}, "", "*"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expectedPattern, tagPattern(tc.templates, tc.prefix))
+	}
+}
+
+func TestVersionFromTag(t *testing.T) {
+	tests := []struct {
+		spec            spec.Spec
+		tag             string
+		expectedVersion string
+	}{
+		{spec.Spec{}, "", ""},
+		{spec.Spec{}, "latest", ""},
+		{spec.Spec{}, "v0.1.0", "0.1.0"},
+		{spec.Spec{}, "release-1.2.3", "1.2.3"},
+		{spec.Spec{}, "api/v1.2.3", "1.2.3"},
+		{spec.Spec{}, "v2.0.0-rc.1", "2.0.0-rc.1"},
+		{spec.Spec{Versioning: "calver"}, "v2020.01.0", "2020.01.0"},
+		{spec.Spec{Versioning: "calver"}, "api/v2020.01.2-beta", "2020.01.2-beta"},
+		{spec.Spec{Versioning: "calver"}, "v0.1.0", ""},
+		{spec.Spec{Versioning: "calver", CalVerFormat: "YY.0W.MICRO"}, "v20.05.1", "20.05.1"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expectedVersion, versionFromTag(tc.spec, tc.tag))
+	}
+}
@@ -16,31 +16,28 @@ const repo = "moorara/cherry"
 
 // update is the action for update command.
 type update struct {
-	ui    cui.CUI
-	step1 *step.GitHubGetLatestRelease
-	step2 *step.GitHubDownloadAsset
+	ui       cui.CUI
+	client   *http.Client
+	config   step.ProviderConfig
+	provider step.ReleaseProvider
+	step1    *step.ReleaseGetLatest
+	step2    *step.ReleaseDownloadAsset
 }
 
 // NewUpdate creates an instance of Update action.
-func NewUpdate(ui cui.CUI, githubToken string) Action {
-	transport := &http.Transport{}
-	client := &http.Client{
-		Transport: transport,
-	}
+func NewUpdate(ui cui.CUI, config step.ProviderConfig) Action {
+	client := step.NewHTTPClient()
+	config.UI = ui
 
 	return &update{
-		ui: ui,
-		step1: &step.GitHubGetLatestRelease{
-			Client:  client,
-			Token:   githubToken,
-			BaseURL: step.GitHubAPIURL,
-			Repo:    repo,
+		ui:     ui,
+		client: client,
+		config: config,
+		step1: &step.ReleaseGetLatest{
+			Provider: nil, // TBD
 		},
-		step2: &step.GitHubDownloadAsset{
-			Client:    client,
-			Token:     githubToken,
-			BaseURL:   step.GitHubURL,
-			Repo:      repo,
+		step2: &step.ReleaseDownloadAsset{
+			Provider:  nil, // TBD
 			Tag:       "TBD",
 			AssetName: "TBD",
 			Filepath:  "TBD",
@@ -48,6 +45,29 @@ func NewUpdate(ui cui.CUI, githubToken string) Action {
 	}
 }
 
+// createProvider creates the release provider for Cherry repository.
+// Cherry is always released on GitHub, so the provider settings for other repositories are not used.
+// If a GitHub Enterprise Server is configured, its token is not sent to github.com either.
+func (u *update) createProvider() error {
+	config := step.ProviderConfig{
+		CABundle: u.config.CABundle,
+		UI:       u.config.UI,
+	}
+
+	if u.config.GitHubURL == "" && u.config.GitHubUploadURL == "" {
+		config.GitHubToken = u.config.GitHubToken
+	}
+
+	provider, err := step.NewReleaseProvider(u.client, config, step.ProviderGitHub, "github.com", repo)
+	if err != nil {
+		return err
+	}
+
+	u.provider = provider
+
+	return nil
+}
+
 // Dry is a dry run of the action.
 func (u *update) Dry(ctx context.Context) error {
 	u.ui.Outputf("◉ Running preflight checks ...")
@@ -57,11 +77,17 @@ func (u *update) Dry(ctx context.Context) error {
 		return err
 	}
 
+	if err = u.createProvider(); err != nil {
+		return err
+	}
+
 	// Running Dry does not set .Result.LatestRelease.TagName
+	u.step1.Provider = u.provider
 	if err = u.step1.Run(ctx); err != nil {
 		return err
 	}
 
+	u.step2.Provider = u.provider
 	u.step2.Tag = u.step1.Result.LatestRelease.TagName
 	u.step2.AssetName = fmt.Sprintf("cherry-%s-%s", runtime.GOOS, runtime.GOARCH)
 	u.step2.Filepath = binPath
@@ -80,23 +106,31 @@ func (u *update) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err = u.createProvider(); err != nil {
+		return err
+	}
+
 	u.ui.Outputf("⬇ Getting the latest release of Cherry ...")
 
-	if err = u.step1.Run(ctx); err != nil {
+	u.step1.Provider = u.provider
+	if err = runStep(ctx, u.ui, u.step1); err != nil {
 		return err
 	}
 
 	u.ui.Outputf("⬇ Downloading the latest release of Cherry ...")
 
+	u.step2.Provider = u.provider
 	u.step2.Tag = u.step1.Result.LatestRelease.TagName
 	u.step2.AssetName = fmt.Sprintf("cherry-%s-%s", runtime.GOOS, runtime.GOARCH)
 	u.step2.Filepath = binPath
 
-	if err = u.step2.Run(ctx); err != nil {
+	if err = runStep(ctx, u.ui, u.step2); err != nil {
 		return err
 	}
 
 	u.ui.Infof("🍒 Cherry %s installed successfully.", u.step1.Result.LatestRelease.Name)
+	u.ui.Event(cui.Event{Type: cui.EventVersion, Version: u.step1.Result.LatestRelease.Name})
+	u.ui.Event(cui.Event{Type: cui.EventArtifact, Artifact: binPath})
 
 	return nil
 }
@@ -12,25 +12,87 @@ import (
 
 func TestNewUpdate(t *testing.T) {
 	tests := []struct {
-		name        string
-		ui          cui.CUI
-		githubToken string
+		name   string
+		ui     cui.CUI
+		config step.ProviderConfig
 	}{
 		{
-			name:        "OK",
-			ui:          &mockCUI{},
-			githubToken: "github-token",
+			name: "OK",
+			ui:   &mockCUI{},
+			config: step.ProviderConfig{
+				GitHubToken: "github-token",
+			},
+		},
+		{
+			name: "Gitea",
+			ui:   &mockCUI{},
+			config: step.ProviderConfig{
+				GiteaURL:   "https://gitea.example.com/",
+				GiteaToken: "gitea-token",
+			},
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			action := NewUpdate(tc.ui, tc.githubToken)
+			action := NewUpdate(tc.ui, tc.config)
 			assert.NotNil(t, action)
 		})
 	}
 }
 
+func TestUpdateCreateProvider(t *testing.T) {
+	tests := []struct {
+		name           string
+		config         step.ProviderConfig
+		expectedAPIURL string
+		expectedToken  string
+	}{
+		{
+			name: "GitHub",
+			config: step.ProviderConfig{
+				GitHubToken: "github-token",
+			},
+			expectedAPIURL: "https://api.github.com",
+			expectedToken:  "github-token",
+		},
+		{
+			name: "Gitea",
+			config: step.ProviderConfig{
+				GitHubToken: "github-token",
+				GiteaURL:    "https://gitea.example.com/",
+				GiteaToken:  "gitea-token",
+			},
+			expectedAPIURL: "https://api.github.com",
+			expectedToken:  "github-token",
+		},
+		{
+			name: "GitHubEnterprise",
+			config: step.ProviderConfig{
+				GitHubToken:     "ghe-token",
+				GitHubURL:       "https://github.example.com",
+				GitHubUploadURL: "https://uploads.github.example.com",
+			},
+			expectedAPIURL: "https://api.github.com",
+			expectedToken:  "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u := NewUpdate(&mockCUI{}, tc.config).(*update)
+			assert.NoError(t, u.createProvider())
+
+			provider, ok := u.provider.(*step.GitHubProvider)
+			assert.True(t, ok)
+			assert.Equal(t, repo, provider.Repo)
+			assert.Equal(t, tc.expectedAPIURL, provider.APIURL)
+			assert.Equal(t, tc.expectedToken, provider.Token)
+			assert.Empty(t, provider.UploadURL)
+		})
+	}
+}
+
 func TestUpdateDry(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -42,7 +104,7 @@ func TestUpdateDry(t *testing.T) {
 			name: "Step1Fails",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{
 						RunOutError: errors.New("error on run: step1"),
 					},
@@ -55,10 +117,10 @@ func TestUpdateDry(t *testing.T) {
 			name: "Step2Fails",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{},
 				},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{
 						DryOutError: errors.New("error on dry: step2"),
 					},
@@ -71,10 +133,10 @@ func TestUpdateDry(t *testing.T) {
 			name: "Success",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{},
 				},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{},
 				},
 			},
@@ -101,7 +163,7 @@ func TestUpdateRun(t *testing.T) {
 			name: "Step1Fails",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{
 						RunOutError: errors.New("error on run: step1"),
 					},
@@ -114,10 +176,10 @@ func TestUpdateRun(t *testing.T) {
 			name: "Step2Fails",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{},
 				},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{
 						RunOutError: errors.New("error on run: step2"),
 					},
@@ -130,10 +192,10 @@ func TestUpdateRun(t *testing.T) {
 			name: "Success",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{},
 				},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{},
 				},
 			},
@@ -160,7 +222,7 @@ func TestUpdateRevert(t *testing.T) {
 			name: "Step2Fails",
 			action: &update{
 				ui: &mockCUI{},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step2"),
 					},
@@ -173,10 +235,10 @@ func TestUpdateRevert(t *testing.T) {
 			name: "Step1Fails",
 			action: &update{
 				ui: &mockCUI{},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{},
 				},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{
 						RevertOutError: errors.New("error on revert: step1"),
 					},
@@ -189,10 +251,10 @@ func TestUpdateRevert(t *testing.T) {
 			name: "Success",
 			action: &update{
 				ui: &mockCUI{},
-				step1: &step.GitHubGetLatestRelease{
+				step1: &step.ReleaseGetLatest{
 					Mock: &mockStep{},
 				},
-				step2: &step.GitHubDownloadAsset{
+				step2: &step.ReleaseDownloadAsset{
 					Mock: &mockStep{},
 				},
 			},
@@ -0,0 +1,327 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/calver"
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/moorara/cherry/pkg/semver"
+)
+
+// Operations of Version action.
+const (
+	VersionShow     = "show"
+	VersionNext     = "next"
+	VersionBump     = "bump"
+	VersionSet      = "set"
+	VersionValidate = "validate"
+)
+
+const versionKey = contextKey("VersionParams")
+
+// VersionParams are the input parameters for Version action.
+type VersionParams struct {
+	Operation string
+	Segment   semver.Segment
+	// Version is the new version for the set operation.
+	Version string
+	// Commit creates a commit for the changed version files.
+	Commit bool
+}
+
+// ContextWithVersionParams returns a new context that has input parameters for Version action.
+func ContextWithVersionParams(ctx context.Context, p VersionParams) context.Context {
+	return context.WithValue(ctx, versionKey, p)
+}
+
+// VersionParamsFromContext retrieves input parameters for Version action from a context.
+// If no operation is found, the show operation will be returned.
+func VersionParamsFromContext(ctx context.Context) VersionParams {
+	p, _ := ctx.Value(versionKey).(VersionParams)
+	if p.Operation == "" {
+		p.Operation = VersionShow
+	}
+
+	return p
+}
+
+// parseVersion parses a version string using the versioning scheme in spec and returns the normalized version.
+func parseVersion(s spec.Spec, version string) (string, error) {
+	if s.Versioning == step.VersioningCalVer {
+		format := s.CalVerFormat
+		if format == "" {
+			format = calver.DefaultFormat
+		}
+
+		f, err := calver.ParseFormat(format)
+		if err != nil {
+			return "", err
+		}
+
+		v, err := f.Parse(version)
+		if err != nil {
+			return "", err
+		}
+
+		return v.Version(), nil
+	}
+
+	v, err := semver.Parse(version)
+	if err != nil {
+		return "", err
+	}
+
+	return v.Version(), nil
+}
+
+// version is the action for version command.
+type version struct {
+	ui    cui.CUI
+	step1 *step.SemVerRead
+	step2 *step.GitStatus
+	step3 *step.SemVerUpdate
+	step4 *step.GitAdd
+	step5 *step.GitCommit
+	// files read the additional version files for validating.
+	files []*step.SemVerRead
+}
+
+// NewVersion creates an instance of Version action.
+func NewVersion(ui cui.CUI, workDir string, s spec.Spec) Action {
+	var gogit *step.GoGit
+	if step.UseGoGit(s.Git) {
+		gogit = step.NewGoGit(workDir)
+	}
+
+	v := &version{
+		ui: ui,
+		step1: &step.SemVerRead{
+			WorkDir:  workDir,
+			GoGit:    gogit,
+			Source:   s.VersionSource,
+			Filename: s.VersionFile,
+			Pattern:  s.VersionPattern,
+			Match:    tagPattern(s.Release.Templates, ""),
+
+			Versioning:   s.Versioning,
+			CalVerFormat: s.CalVerFormat,
+		},
+		step2: &step.GitStatus{
+			WorkDir: workDir,
+			GoGit:   gogit,
+		},
+		step3: &step.SemVerUpdate{
+			WorkDir:  workDir,
+			Filename: s.VersionFile,
+			Pattern:  s.VersionPattern,
+			Files:    versionFiles(s.VersionFiles),
+			Version:  "TBD",
+		},
+		step4: &step.GitAdd{
+			WorkDir: workDir,
+			GoGit:   gogit,
+			Files:   nil, // TBD
+		},
+		step5: &step.GitCommit{
+			WorkDir: workDir,
+			GoGit:   gogit,
+			Message: "TBD",
+			Sign:    signing(s),
+		},
+	}
+
+	for _, f := range s.VersionFiles {
+		v.files = append(v.files, &step.SemVerRead{
+			WorkDir:      workDir,
+			Filename:     f.Path,
+			Pattern:      f.Pattern,
+			Versioning:   s.Versioning,
+			CalVerFormat: s.CalVerFormat,
+		})
+	}
+
+	return v
+}
+
+// targetVersion returns the version an operation reports or sets.
+// step1 should be run first.
+func (v *version) targetVersion(s spec.Spec, p VersionParams) (string, error) {
+	switch p.Operation {
+	case VersionShow, VersionValidate:
+		return readVersion(s, v.step1), nil
+
+	case VersionNext, VersionBump:
+		vers, err := releaseVersions(s, v.step1, p.Segment)
+		if err != nil {
+			return "", err
+		}
+		return vers.Release, nil
+
+	case VersionSet:
+		if p.Version == "" {
+			return "", errors.New("no version to set")
+		}
+		// The version may be given as a git tag (i.e. v1.2.3)
+		return parseVersion(s, strings.TrimPrefix(p.Version, "v"))
+
+	default:
+		return "", fmt.Errorf("unknown version operation: %s", p.Operation)
+	}
+}
+
+// changes determines whether or not an operation changes the version files.
+func changes(p VersionParams) bool {
+	return p.Operation == VersionBump || p.Operation == VersionSet
+}
+
+// validate makes sure all additional version files have the same version as the version file.
+func (v *version) validate(ctx context.Context, s spec.Spec, target string) error {
+	for _, f := range v.files {
+		if err := f.Run(ctx); err != nil {
+			return err
+		}
+
+		if got := readVersion(s, f); got != target {
+			return fmt.Errorf("version file %s has version %s instead of %s", f.Filename, got, target)
+		}
+	}
+
+	return nil
+}
+
+// Dry is a dry run of the action.
+func (v *version) Dry(ctx context.Context) error {
+	s := SpecFromContext(ctx)
+	p := VersionParamsFromContext(ctx)
+
+	// Read the version
+	if err := v.step1.Run(ctx); err != nil {
+		return err
+	}
+
+	target, err := v.targetVersion(s, p)
+	if err != nil {
+		return err
+	}
+
+	if !changes(p) {
+		return nil
+	}
+
+	if s.VersionSource == step.VersionSourceGit {
+		return errors.New("cannot change the version when it is derived from git tags")
+	}
+
+	if p.Commit {
+		// Get git status
+		if err := v.step2.Run(ctx); err != nil {
+			return err
+		}
+
+		if !v.step2.Result.IsClean {
+			return errors.New("working directory is not clean and has uncommitted changes")
+		}
+	}
+
+	// Dry -- Update the version files
+	v.step3.Version = target
+	if err := v.step3.Dry(ctx); err != nil {
+		return err
+	}
+
+	if p.Commit {
+		// Dry -- Add the version files to staging
+		v.step4.Files = append([]string{v.step3.Result.Filename}, v.step3.Result.Files...)
+		if err := v.step4.Dry(ctx); err != nil {
+			return err
+		}
+
+		// Dry -- Create a commit for the version
+		v.step5.Message = fmt.Sprintf("Update version to %s", target)
+		if err := v.step5.Dry(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run executes the action.
+func (v *version) Run(ctx context.Context) error {
+	s := SpecFromContext(ctx)
+	p := VersionParamsFromContext(ctx)
+
+	// Read the version
+	if err := runStep(ctx, v.ui, v.step1); err != nil {
+		return err
+	}
+
+	target, err := v.targetVersion(s, p)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case p.Operation == VersionValidate:
+		// Version files are not used when the version is derived from git tags
+		if s.VersionSource != step.VersionSourceGit {
+			if err := v.validate(ctx, s, target); err != nil {
+				return err
+			}
+		}
+
+	case changes(p):
+		// Update the version files
+		v.step3.Version = target
+		if err := runStep(ctx, v.ui, v.step3); err != nil {
+			return err
+		}
+
+		if p.Commit {
+			// Add the version files to staging
+			v.step4.Files = append([]string{v.step3.Result.Filename}, v.step3.Result.Files...)
+			if err := runStep(ctx, v.ui, v.step4); err != nil {
+				return err
+			}
+
+			// Create a commit for the version
+			v.step5.Message = fmt.Sprintf("Update version to %s", target)
+			if err := runStep(ctx, v.ui, v.step5); err != nil {
+				return err
+			}
+		}
+	}
+
+	v.ui.Outputf("%s", target)
+	v.ui.Event(cui.Event{Type: cui.EventVersion, Version: target})
+
+	return nil
+}
+
+// Revert reverts back an executed action.
+func (v *version) Revert(ctx context.Context) error {
+	p := VersionParamsFromContext(ctx)
+
+	// Only the steps changing the version files have side effects to revert.
+	// The commit is the last step, so it has not been created if the action failed.
+	steps := []step.Step{}
+	if changes(p) {
+		if p.Commit {
+			steps = append(steps, v.step4)
+		}
+		steps = append(steps, v.step3)
+	}
+
+	for _, s := range steps {
+		if err := s.Revert(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,576 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/moorara/cherry/pkg/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionParamsFromContext(t *testing.T) {
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		expectedParams VersionParams
+	}{
+		{
+			name: "Default",
+			ctx:  context.Background(),
+			expectedParams: VersionParams{
+				Operation: VersionShow,
+				Segment:   semver.Patch,
+			},
+		},
+		{
+			name: "Bump",
+			ctx: ContextWithVersionParams(context.Background(), VersionParams{
+				Operation: VersionBump,
+				Segment:   semver.Minor,
+				Commit:    true,
+			}),
+			expectedParams: VersionParams{
+				Operation: VersionBump,
+				Segment:   semver.Minor,
+				Commit:    true,
+			},
+		},
+		{
+			name: "Set",
+			ctx: ContextWithVersionParams(context.Background(), VersionParams{
+				Operation: VersionSet,
+				Version:   "1.2.3",
+			}),
+			expectedParams: VersionParams{
+				Operation: VersionSet,
+				Version:   "1.2.3",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedParams, VersionParamsFromContext(tc.ctx))
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		spec            spec.Spec
+		version         string
+		expectedVersion string
+		expectedError   string
+	}{
+		{
+			name:          "InvalidSemVer",
+			spec:          spec.Spec{},
+			version:       "x.y.z",
+			expectedError: "invalid semantic version",
+		},
+		{
+			name:            "SemVer",
+			spec:            spec.Spec{},
+			version:         "1.2.3-rc.1",
+			expectedVersion: "1.2.3-rc.1",
+		},
+		{
+			name:          "InvalidFormat",
+			spec:          spec.Spec{Versioning: "calver", CalVerFormat: "YYYY.PATCH"},
+			version:       "2020.1",
+			expectedError: `invalid calendar versioning format "YYYY.PATCH": unknown token "PATCH"`,
+		},
+		{
+			name:          "InvalidCalVer",
+			spec:          spec.Spec{Versioning: "calver"},
+			version:       "1.2.3",
+			expectedError: `invalid calendar version "1.2.3" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:            "CalVer",
+			spec:            spec.Spec{Versioning: "calver"},
+			version:         "2020.01.2",
+			expectedVersion: "2020.01.2",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := parseVersion(tc.spec, tc.version)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVersion, version)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestNewVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		ui            cui.CUI
+		workDir       string
+		s             spec.Spec
+		expectedFiles []string
+	}{
+		{
+			name:    "OK",
+			ui:      &mockCUI{},
+			workDir: ".",
+			s:       spec.Spec{},
+		},
+		{
+			name:    "VersionFiles",
+			ui:      &mockCUI{},
+			workDir: ".",
+			s: spec.Spec{
+				Git:        "go",
+				Versioning: "calver",
+				VersionFiles: []spec.VersionFile{
+					{Path: "package.json"},
+					{Path: "README.md", Pattern: `app-(\S+)\.tar\.gz`},
+				},
+			},
+			expectedFiles: []string{"package.json", "README.md"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			action := NewVersion(tc.ui, tc.workDir, tc.s)
+			assert.NotNil(t, action)
+
+			v := action.(*version)
+			assert.Equal(t, tc.s.Versioning, v.step1.Versioning)
+			assert.Len(t, v.step3.Files, len(tc.expectedFiles))
+
+			files := []string{}
+			for _, f := range v.files {
+				assert.Equal(t, tc.s.Versioning, f.Versioning)
+				files = append(files, f.Filename)
+			}
+			assert.Equal(t, len(tc.expectedFiles), len(files))
+			for i, file := range tc.expectedFiles {
+				assert.Equal(t, file, files[i])
+			}
+		})
+	}
+}
+
+func TestVersionDry(t *testing.T) {
+	bumpCtx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionBump, Segment: semver.Minor})
+	commitCtx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionBump, Commit: true})
+	gitCtx := ContextWithSpec(bumpCtx, spec.Spec{VersionSource: "git"})
+
+	step1OK := &step.SemVerRead{Mock: &mockStep{}}
+	step1OK.Result.Filename = "VERSION"
+	step1OK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+
+	step2OK := &step.GitStatus{Mock: &mockStep{}}
+	step2OK.Result.IsClean = true
+
+	step2Dirty := &step.GitStatus{Mock: &mockStep{}}
+
+	step3OK := &step.SemVerUpdate{Mock: &mockStep{}}
+	step3OK.Result.Filename = "VERSION"
+
+	tests := []struct {
+		name          string
+		action        Action
+		ctx           context.Context
+		expectedError error
+	}{
+		{
+			name: "Step1Fails",
+			action: &version{
+				ui: &mockCUI{},
+				step1: &step.SemVerRead{
+					Mock: &mockStep{RunOutError: errors.New("error on run: step1")},
+				},
+			},
+			ctx:           context.Background(),
+			expectedError: errors.New("error on run: step1"),
+		},
+		{
+			name: "UnknownOperation",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:           ContextWithVersionParams(context.Background(), VersionParams{Operation: "reset"}),
+			expectedError: errors.New("unknown version operation: reset"),
+		},
+		{
+			name: "NoVersionToSet",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:           ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionSet}),
+			expectedError: errors.New("no version to set"),
+		},
+		{
+			name: "InvalidVersionToSet",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:           ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionSet, Version: "x.y.z"}),
+			expectedError: errors.New("invalid semantic version"),
+		},
+		{
+			name: "VersionFromGit",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:           gitCtx,
+			expectedError: errors.New("cannot change the version when it is derived from git tags"),
+		},
+		{
+			name: "Step2Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: &step.GitStatus{
+					Mock: &mockStep{RunOutError: errors.New("error on run: step2")},
+				},
+			},
+			ctx:           commitCtx,
+			expectedError: errors.New("error on run: step2"),
+		},
+		{
+			name: "NotClean",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2Dirty,
+			},
+			ctx:           commitCtx,
+			expectedError: errors.New("working directory is not clean and has uncommitted changes"),
+		},
+		{
+			name: "Step3Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step3: &step.SemVerUpdate{
+					Mock: &mockStep{DryOutError: errors.New("error on dry: step3")},
+				},
+			},
+			ctx:           bumpCtx,
+			expectedError: errors.New("error on dry: step3"),
+		},
+		{
+			name: "Step4Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: &step.GitAdd{
+					Mock: &mockStep{DryOutError: errors.New("error on dry: step4")},
+				},
+			},
+			ctx:           commitCtx,
+			expectedError: errors.New("error on dry: step4"),
+		},
+		{
+			name: "Step5Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: &step.GitAdd{Mock: &mockStep{}},
+				step5: &step.GitCommit{
+					Mock: &mockStep{DryOutError: errors.New("error on dry: step5")},
+				},
+			},
+			ctx:           commitCtx,
+			expectedError: errors.New("error on dry: step5"),
+		},
+		{
+			name: "ShowSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:           context.Background(),
+			expectedError: nil,
+		},
+		{
+			name: "BumpSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step3: step3OK,
+			},
+			ctx:           bumpCtx,
+			expectedError: nil,
+		},
+		{
+			name: "CommitSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step2: step2OK,
+				step3: step3OK,
+				step4: &step.GitAdd{Mock: &mockStep{}},
+				step5: &step.GitCommit{Mock: &mockStep{}},
+			},
+			ctx:           commitCtx,
+			expectedError: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.action.Dry(tc.ctx)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestVersionRun(t *testing.T) {
+	showCtx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionShow})
+	nextCtx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionNext, Segment: semver.Major})
+	setCtx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionSet, Version: "v1.2.3", Commit: true})
+	validateCtx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionValidate})
+
+	step1OK := &step.SemVerRead{Mock: &mockStep{}}
+	step1OK.Result.Filename = "VERSION"
+	step1OK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+
+	step3OK := &step.SemVerUpdate{Mock: &mockStep{}}
+	step3OK.Result.Filename = "VERSION"
+	step3OK.Result.Files = []string{"package.json"}
+
+	fileOK := &step.SemVerRead{Mock: &mockStep{}, Filename: "package.json"}
+	fileOK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+
+	fileMismatch := &step.SemVerRead{Mock: &mockStep{}, Filename: "package.json"}
+	fileMismatch.Result.Version = semver.SemVer{Major: 0, Minor: 1, Patch: 0}
+
+	tests := []struct {
+		name            string
+		action          Action
+		ctx             context.Context
+		expectedError   error
+		expectedVersion string
+	}{
+		{
+			name: "Step1Fails",
+			action: &version{
+				ui: &mockCUI{},
+				step1: &step.SemVerRead{
+					Mock: &mockStep{RunOutError: errors.New("error on run: step1")},
+				},
+			},
+			ctx:           showCtx,
+			expectedError: errors.New("error on run: step1"),
+		},
+		{
+			name: "ShowSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:             showCtx,
+			expectedVersion: "0.2.0",
+		},
+		{
+			name: "NextSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+			},
+			ctx:             nextCtx,
+			expectedVersion: "1.0.0",
+		},
+		{
+			name: "ValidateFileFails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				files: []*step.SemVerRead{
+					{Mock: &mockStep{RunOutError: errors.New("error on run: file")}},
+				},
+			},
+			ctx:           validateCtx,
+			expectedError: errors.New("error on run: file"),
+		},
+		{
+			name: "ValidateMismatch",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				files: []*step.SemVerRead{fileOK, fileMismatch},
+			},
+			ctx:           validateCtx,
+			expectedError: errors.New("version file package.json has version 0.1.0 instead of 0.2.0"),
+		},
+		{
+			name: "ValidateSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				files: []*step.SemVerRead{fileOK},
+			},
+			ctx:             validateCtx,
+			expectedVersion: "0.2.0",
+		},
+		{
+			name: "Step3Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step3: &step.SemVerUpdate{
+					Mock: &mockStep{RunOutError: errors.New("error on run: step3")},
+				},
+			},
+			ctx:           setCtx,
+			expectedError: errors.New("error on run: step3"),
+		},
+		{
+			name: "Step4Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step3: step3OK,
+				step4: &step.GitAdd{
+					Mock: &mockStep{RunOutError: errors.New("error on run: step4")},
+				},
+			},
+			ctx:           setCtx,
+			expectedError: errors.New("error on run: step4"),
+		},
+		{
+			name: "Step5Fails",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step3: step3OK,
+				step4: &step.GitAdd{Mock: &mockStep{}},
+				step5: &step.GitCommit{
+					Mock: &mockStep{RunOutError: errors.New("error on run: step5")},
+				},
+			},
+			ctx:           setCtx,
+			expectedError: errors.New("error on run: step5"),
+		},
+		{
+			name: "SetSuccess",
+			action: &version{
+				ui:    &mockCUI{},
+				step1: step1OK,
+				step3: step3OK,
+				step4: &step.GitAdd{Mock: &mockStep{}},
+				step5: &step.GitCommit{Mock: &mockStep{}},
+			},
+			ctx:             setCtx,
+			expectedVersion: "1.2.3",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.action.Run(tc.ctx)
+			assert.Equal(t, tc.expectedError, err)
+
+			if tc.expectedError == nil {
+				v := tc.action.(*version)
+				events := v.ui.(*mockCUI).EventInEvents
+				assert.Equal(t, cui.Event{Type: cui.EventVersion, Version: tc.expectedVersion}, events[len(events)-1])
+			}
+		})
+	}
+}
+
+func TestVersionSetCommit(t *testing.T) {
+	step1OK := &step.SemVerRead{Mock: &mockStep{}}
+	step1OK.Result.Version = semver.SemVer{Major: 0, Minor: 2, Patch: 0}
+
+	step3OK := &step.SemVerUpdate{Mock: &mockStep{}}
+	step3OK.Result.Filename = "VERSION"
+	step3OK.Result.Files = []string{"package.json"}
+
+	v := &version{
+		ui:    &mockCUI{},
+		step1: step1OK,
+		step3: step3OK,
+		step4: &step.GitAdd{Mock: &mockStep{}},
+		step5: &step.GitCommit{Mock: &mockStep{}},
+	}
+
+	ctx := ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionSet, Version: "1.2.3", Commit: true})
+	assert.NoError(t, v.Run(ctx))
+	assert.Equal(t, "1.2.3", v.step3.Version)
+	assert.Equal(t, []string{"VERSION", "package.json"}, v.step4.Files)
+	assert.Equal(t, "Update version to 1.2.3", v.step5.Message)
+}
+
+func TestVersionRevert(t *testing.T) {
+	tests := []struct {
+		name          string
+		action        Action
+		ctx           context.Context
+		expectedError error
+	}{
+		{
+			name: "Show",
+			action: &version{
+				ui: &mockCUI{},
+			},
+			ctx:           context.Background(),
+			expectedError: nil,
+		},
+		{
+			name: "Step4Fails",
+			action: &version{
+				ui: &mockCUI{},
+				step4: &step.GitAdd{
+					Mock: &mockStep{RevertOutError: errors.New("error on revert: step4")},
+				},
+			},
+			ctx:           ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionBump, Commit: true}),
+			expectedError: errors.New("error on revert: step4"),
+		},
+		{
+			name: "Step3Fails",
+			action: &version{
+				ui: &mockCUI{},
+				step3: &step.SemVerUpdate{
+					Mock: &mockStep{RevertOutError: errors.New("error on revert: step3")},
+				},
+			},
+			ctx:           ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionSet, Version: "1.2.3"}),
+			expectedError: errors.New("error on revert: step3"),
+		},
+		{
+			name: "Success",
+			action: &version{
+				ui:    &mockCUI{},
+				step3: &step.SemVerUpdate{Mock: &mockStep{}},
+				step4: &step.GitAdd{Mock: &mockStep{}},
+			},
+			ctx:           ContextWithVersionParams(context.Background(), VersionParams{Operation: VersionBump, Commit: true}),
+			expectedError: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.action.Revert(tc.ctx)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,466 @@
+package action
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	defaultScoopFolder = "bucket"
+	defaultScoopCommit = "{{.Name}}: Update to version {{.Version}}"
+
+	defaultWingetFolder   = "manifests"
+	defaultWingetCommit   = "New version: {{.Name}} version {{.Version}}"
+	wingetLocale          = "en-US"
+	wingetManifestVersion = "1.6.0"
+)
+
+// scoopArchs are the Scoop architectures for the architectures supported by Scoop.
+var scoopArchs = map[string]string{
+	"amd64": "64bit",
+	"386":   "32bit",
+	"arm64": "arm64",
+}
+
+// wingetArchs are the winget architectures for the architectures supported by winget.
+var wingetArchs = map[string]string{
+	"amd64": "x64",
+	"386":   "x86",
+	"arm64": "arm64",
+	"arm":   "arm",
+}
+
+// windowsAsset is a release asset (binary) for a Windows platform.
+type windowsAsset struct {
+	Arch   string
+	Name   string
+	URL    string
+	SHA256 string
+}
+
+// newWindowsAsset creates an asset from the name of a binary built for a platform (i.e. app-windows-amd64).
+// If the platform is not windows, false is returned.
+func newWindowsAsset(name string) (windowsAsset, bool) {
+	parts := strings.Split(name, "-")
+	if len(parts) < 3 || parts[len(parts)-2] != "windows" {
+		return windowsAsset{}, false
+	}
+
+	return windowsAsset{
+		Arch: parts[len(parts)-1],
+		Name: name,
+	}, true
+}
+
+// windowsVars are the variables for rendering the Scoop and winget manifests.
+// Name, Version, and Tag are also available to the commit message templates.
+type windowsVars struct {
+	Name        string
+	Binary      string
+	Description string
+	Homepage    string
+	License     string
+	Version     string
+	Tag         string
+	Assets      []windowsAsset
+}
+
+// windowsAssets returns the Windows assets of release for the given architectures.
+// If dry is true, placeholders are used for the urls and checksums of assets since they are not built and uploaded yet.
+// Otherwise, the binaries built by step15 and the assets uploaded by step16 (or published by step25) are used.
+func (r *release) windowsAssets(s spec.Spec, archs map[string]string, dry bool) ([]windowsAsset, error) {
+	binary := filepath.Base(s.Build.BinaryFile)
+	assets := []windowsAsset{}
+
+	if dry {
+		for _, platform := range s.Build.Platforms {
+			if a, ok := newWindowsAsset(binary + "-" + platform); ok && archs[a.Arch] != "" {
+				a.URL = fmt.Sprintf("<download url of %s>", a.Name)
+				a.SHA256 = fmt.Sprintf("<sha256 of %s>", a.Name)
+				assets = append(assets, a)
+			}
+		}
+
+		return assets, nil
+	}
+
+	urls := r.assetURLs()
+	for _, bin := range r.step15.Result.Binaries {
+		a, ok := newWindowsAsset(filepath.Base(bin))
+		if !ok || archs[a.Arch] == "" {
+			continue
+		}
+
+		if a.URL = urls[a.Name]; a.URL == "" {
+			return nil, fmt.Errorf("no download url for asset %s", a.Name)
+		}
+
+		var err error
+		if a.SHA256, err = r.binaryChecksum(bin); err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, a)
+	}
+
+	return assets, nil
+}
+
+// windowsVars returns the variables for rendering a Windows manifest of release.
+func (r *release) windowsVars(s spec.Spec, name, description, homepage, license string, archs map[string]string, vars releaseVars, dry bool) (windowsVars, error) {
+	binary := filepath.Base(s.Build.BinaryFile)
+
+	wv := windowsVars{
+		Name:        name,
+		Binary:      binary,
+		Description: description,
+		Homepage:    homepage,
+		License:     license,
+		Version:     vars.Version,
+		Tag:         vars.Tag,
+	}
+
+	if wv.Name == "" {
+		wv.Name = binary
+	}
+
+	if wv.Homepage == "" {
+		wv.Homepage = fmt.Sprintf("https://%s/%s", r.step1.Result.Host, r.step1.Result.Repo)
+	}
+
+	var err error
+	if wv.Assets, err = r.windowsAssets(s, archs, dry); err != nil {
+		return windowsVars{}, err
+	}
+
+	if len(wv.Assets) == 0 {
+		return windowsVars{}, errors.New("no windows binary for the Windows manifests")
+	}
+
+	return wv, nil
+}
+
+type (
+	scoopArch struct {
+		URL  string `json:"url"`
+		Hash string `json:"hash,omitempty"`
+	}
+
+	scoopAutoupdate struct {
+		Architecture map[string]scoopArch `json:"architecture"`
+	}
+
+	scoopManifest struct {
+		Version      string               `json:"version"`
+		Description  string               `json:"description,omitempty"`
+		Homepage     string               `json:"homepage"`
+		License      string               `json:"license,omitempty"`
+		Architecture map[string]scoopArch `json:"architecture"`
+		Bin          string               `json:"bin"`
+		Checkver     interface{}          `json:"checkver"`
+		Autoupdate   scoopAutoupdate      `json:"autoupdate"`
+	}
+)
+
+// scoopCheckver returns the checkver of a Scoop manifest for finding the latest version of release.
+// For GitHub repositories with v-prefixed or plain tags, the github shortcut is used.
+// Otherwise, the tags are matched in the releases page of repository.
+func scoopCheckver(host, repo string, vars windowsVars) interface{} {
+	repoURL := fmt.Sprintf("https://%s/%s", host, repo)
+
+	if host == "github.com" && (vars.Tag == vars.Version || vars.Tag == "v"+vars.Version) {
+		return map[string]string{
+			"github": repoURL,
+		}
+	}
+
+	tagRegex := regexp.QuoteMeta(vars.Tag)
+	tagRegex = strings.Replace(tagRegex, regexp.QuoteMeta(vars.Version), `([\w.-]+)`, 1)
+
+	return map[string]string{
+		"url":   repoURL + "/releases",
+		"regex": "/releases/tag/" + tagRegex,
+	}
+}
+
+// renderScoop renders a Scoop manifest and its commit message.
+// The binaries do not have an extension, so they are renamed to .exe files when they are downloaded.
+// The autoupdate urls are derived from the release urls by replacing the version with $version.
+// The checksums are left out of autoupdate since no checksum file is uploaded and Scoop computes them instead.
+func renderScoop(sc spec.Scoop, host, repo string, vars windowsVars) (step.GitFile, string, error) {
+	message := sc.CommitMessage
+	if message == "" {
+		message = defaultScoopCommit
+	}
+
+	folder := sc.Folder
+	if folder == "" {
+		folder = defaultScoopFolder
+	}
+
+	exe := vars.Binary + ".exe"
+
+	m := scoopManifest{
+		Version:      vars.Version,
+		Description:  vars.Description,
+		Homepage:     vars.Homepage,
+		License:      vars.License,
+		Architecture: map[string]scoopArch{},
+		Bin:          exe,
+		Checkver:     scoopCheckver(host, repo, vars),
+		Autoupdate: scoopAutoupdate{
+			Architecture: map[string]scoopArch{},
+		},
+	}
+
+	for _, a := range vars.Assets {
+		arch := scoopArchs[a.Arch]
+		url := a.URL + "#/" + exe
+		m.Architecture[arch] = scoopArch{URL: url, Hash: a.SHA256}
+		m.Autoupdate.Architecture[arch] = scoopArch{URL: strings.Replace(url, vars.Version, "$version", -1)}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(m); err != nil {
+		return step.GitFile{}, "", err
+	}
+
+	message, err := renderText("commit_message", message, vars)
+	if err != nil {
+		return step.GitFile{}, "", err
+	}
+
+	file := step.GitFile{
+		Path:    path.Join(folder, vars.Name+".json"),
+		Content: buf.Bytes(),
+	}
+
+	return file, message, nil
+}
+
+type (
+	wingetVersionManifest struct {
+		PackageIdentifier string `yaml:"PackageIdentifier"`
+		PackageVersion    string `yaml:"PackageVersion"`
+		DefaultLocale     string `yaml:"DefaultLocale"`
+		ManifestType      string `yaml:"ManifestType"`
+		ManifestVersion   string `yaml:"ManifestVersion"`
+	}
+
+	wingetInstaller struct {
+		Architecture    string `yaml:"Architecture"`
+		InstallerURL    string `yaml:"InstallerUrl"`
+		InstallerSha256 string `yaml:"InstallerSha256"`
+	}
+
+	wingetInstallerManifest struct {
+		PackageIdentifier string            `yaml:"PackageIdentifier"`
+		PackageVersion    string            `yaml:"PackageVersion"`
+		InstallerType     string            `yaml:"InstallerType"`
+		Commands          []string          `yaml:"Commands"`
+		Installers        []wingetInstaller `yaml:"Installers"`
+		ManifestType      string            `yaml:"ManifestType"`
+		ManifestVersion   string            `yaml:"ManifestVersion"`
+	}
+
+	wingetLocaleManifest struct {
+		PackageIdentifier string `yaml:"PackageIdentifier"`
+		PackageVersion    string `yaml:"PackageVersion"`
+		PackageLocale     string `yaml:"PackageLocale"`
+		Publisher         string `yaml:"Publisher"`
+		PackageName       string `yaml:"PackageName"`
+		PackageURL        string `yaml:"PackageUrl"`
+		License           string `yaml:"License"`
+		ShortDescription  string `yaml:"ShortDescription"`
+		ManifestType      string `yaml:"ManifestType"`
+		ManifestVersion   string `yaml:"ManifestVersion"`
+	}
+)
+
+// wingetIdentifier returns the package identifier and package name of a winget manifest set.
+func wingetIdentifier(w spec.Winget, binary string) (string, string, error) {
+	name := w.PackageName
+	if name == "" {
+		name = binary
+	}
+
+	if w.PackageIdentifier != "" {
+		return w.PackageIdentifier, name, nil
+	}
+
+	if w.Publisher == "" {
+		return "", "", errors.New("winget publisher is required for the package identifier")
+	}
+
+	return w.Publisher + "." + name, name, nil
+}
+
+// renderWinget renders a winget manifest set (version, installer, and default locale manifests) and its commit message.
+// The binaries are installed as portable packages and the manifests are placed in the same directory structure as winget-pkgs repository
+// (i.e. manifests/u/User/App/1.2.3/User.App.yaml).
+// vars.Name should be the package identifier.
+func renderWinget(w spec.Winget, name string, vars windowsVars) ([]step.GitFile, string, error) {
+	if w.Publisher == "" {
+		return nil, "", errors.New("winget publisher is required")
+	}
+
+	if vars.License == "" || vars.Description == "" {
+		return nil, "", errors.New("winget license and description are required")
+	}
+
+	message := w.CommitMessage
+	if message == "" {
+		message = defaultWingetCommit
+	}
+
+	folder := w.Folder
+	if folder == "" {
+		folder = defaultWingetFolder
+	}
+
+	id := vars.Name
+
+	versionManifest := wingetVersionManifest{
+		PackageIdentifier: id,
+		PackageVersion:    vars.Version,
+		DefaultLocale:     wingetLocale,
+		ManifestType:      "version",
+		ManifestVersion:   wingetManifestVersion,
+	}
+
+	installerManifest := wingetInstallerManifest{
+		PackageIdentifier: id,
+		PackageVersion:    vars.Version,
+		InstallerType:     "portable",
+		Commands:          []string{vars.Binary},
+		ManifestType:      "installer",
+		ManifestVersion:   wingetManifestVersion,
+	}
+
+	for _, a := range vars.Assets {
+		installerManifest.Installers = append(installerManifest.Installers, wingetInstaller{
+			Architecture:    wingetArchs[a.Arch],
+			InstallerURL:    a.URL,
+			InstallerSha256: strings.ToUpper(a.SHA256),
+		})
+	}
+
+	localeManifest := wingetLocaleManifest{
+		PackageIdentifier: id,
+		PackageVersion:    vars.Version,
+		PackageLocale:     wingetLocale,
+		Publisher:         w.Publisher,
+		PackageName:       name,
+		PackageURL:        vars.Homepage,
+		License:           vars.License,
+		ShortDescription:  vars.Description,
+		ManifestType:      "defaultLocale",
+		ManifestVersion:   wingetManifestVersion,
+	}
+
+	dir := path.Join(folder, strings.ToLower(id[:1]), strings.Replace(id, ".", "/", -1), vars.Version)
+
+	manifests := []struct {
+		file     string
+		schema   string
+		manifest interface{}
+	}{
+		{id + ".yaml", "version", versionManifest},
+		{id + ".installer.yaml", "installer", installerManifest},
+		{id + ".locale." + wingetLocale + ".yaml", "defaultLocale", localeManifest},
+	}
+
+	files := []step.GitFile{}
+	for _, m := range manifests {
+		data, err := yaml.Marshal(m.manifest)
+		if err != nil {
+			return nil, "", err
+		}
+
+		header := fmt.Sprintf("# yaml-language-server: $schema=https://aka.ms/winget-manifest.%s.%s.schema.json\n\n", m.schema, wingetManifestVersion)
+		files = append(files, step.GitFile{
+			Path:    path.Join(dir, m.file),
+			Content: append([]byte(header), data...),
+		})
+	}
+
+	message, err := renderText("commit_message", message, vars)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return files, message, nil
+}
+
+// gitRepoURL returns the git url of a repository on the same host as the release repository if url is not set.
+func (r *release) gitRepoURL(repo, url string) string {
+	if url != "" {
+		return url
+	}
+
+	return fmt.Sprintf("https://%s/%s.git", r.step1.Result.Host, repo)
+}
+
+// setScoop renders the Scoop manifest and sets the parameters of step for publishing it to the bucket repository.
+// step1 should be run first.
+func (r *release) setScoop(s spec.Spec, vars releaseVars, dry bool) error {
+	sc := s.Release.Scoop
+
+	wv, err := r.windowsVars(s, sc.Manifest, sc.Description, sc.Homepage, sc.License, scoopArchs, vars, dry)
+	if err != nil {
+		return err
+	}
+
+	file, message, err := renderScoop(sc, r.step1.Result.Host, r.step1.Result.Repo, wv)
+	if err != nil {
+		return err
+	}
+
+	r.step31.URL = r.gitRepoURL(sc.Bucket, sc.BucketURL)
+	r.step31.Branch = sc.Branch
+	r.step31.Files = []step.GitFile{file}
+	r.step31.Message = message
+
+	return nil
+}
+
+// setWinget renders the winget manifest set and sets the parameters of step for publishing it to the manifests repository.
+// step1 should be run first.
+func (r *release) setWinget(s spec.Spec, vars releaseVars, dry bool) error {
+	w := s.Release.Winget
+
+	id, name, err := wingetIdentifier(w, filepath.Base(s.Build.BinaryFile))
+	if err != nil {
+		return err
+	}
+
+	wv, err := r.windowsVars(s, id, w.Description, w.Homepage, w.License, wingetArchs, vars, dry)
+	if err != nil {
+		return err
+	}
+
+	files, message, err := renderWinget(w, name, wv)
+	if err != nil {
+		return err
+	}
+
+	r.step32.URL = r.gitRepoURL(w.Repo, w.RepoURL)
+	r.step32.Branch = w.Branch
+	r.step32.Files = files
+	r.step32.Message = message
+
+	return nil
+}
@@ -0,0 +1,439 @@
+package action
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWindowsAsset(t *testing.T) {
+	tests := []struct {
+		name          string
+		expectedAsset windowsAsset
+		expectedOK    bool
+	}{
+		{"app", windowsAsset{}, false},
+		{"app-linux-amd64", windowsAsset{}, false},
+		{"app-windows-amd64", windowsAsset{Arch: "amd64", Name: "app-windows-amd64"}, true},
+		{"my-app-windows-386", windowsAsset{Arch: "386", Name: "my-app-windows-386"}, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			asset, ok := newWindowsAsset(tc.name)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedAsset, asset)
+		})
+	}
+}
+
+func TestScoopCheckver(t *testing.T) {
+	tests := []struct {
+		name             string
+		host             string
+		vars             windowsVars
+		expectedCheckver interface{}
+	}{
+		{
+			name: "GitHub",
+			host: "github.com",
+			vars: windowsVars{Version: "0.2.0", Tag: "v0.2.0"},
+			expectedCheckver: map[string]string{
+				"github": "https://github.com/username/app",
+			},
+		},
+		{
+			name: "GitHubTagPrefix",
+			host: "github.com",
+			vars: windowsVars{Version: "0.2.0", Tag: "cli/v0.2.0"},
+			expectedCheckver: map[string]string{
+				"url":   "https://github.com/username/app/releases",
+				"regex": `/releases/tag/cli/v([\w.-]+)`,
+			},
+		},
+		{
+			name: "Gitea",
+			host: "gitea.example.com",
+			vars: windowsVars{Version: "0.2.0", Tag: "v0.2.0"},
+			expectedCheckver: map[string]string{
+				"url":   "https://gitea.example.com/username/app/releases",
+				"regex": `/releases/tag/v([\w.-]+)`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCheckver, scoopCheckver(tc.host, "username/app", tc.vars))
+		})
+	}
+}
+
+func TestRenderScoop(t *testing.T) {
+	vars := windowsVars{
+		Name:        "app",
+		Binary:      "app",
+		Description: "The app tool",
+		Homepage:    "https://github.com/username/app",
+		License:     "MIT",
+		Version:     "0.2.0",
+		Tag:         "v0.2.0",
+		Assets: []windowsAsset{
+			{Arch: "amd64", Name: "app-windows-amd64", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-amd64", SHA256: "aaaa"},
+			{Arch: "386", Name: "app-windows-386", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-386", SHA256: "bbbb"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		scoop           spec.Scoop
+		vars            windowsVars
+		expectedError   string
+		expectedFile    step.GitFile
+		expectedMessage string
+	}{
+		{
+			name:          "InvalidCommitMessage",
+			scoop:         spec.Scoop{CommitMessage: "{{.Manifest}}"},
+			vars:          vars,
+			expectedError: `invalid commit_message template: template: commit_message:1:2: executing "commit_message" at <.Manifest>: can't evaluate field Manifest in type action.windowsVars`,
+		},
+		{
+			name:  "Default",
+			scoop: spec.Scoop{},
+			vars:  vars,
+			expectedFile: step.GitFile{
+				Path: "bucket/app.json",
+				Content: []byte(`{
+    "version": "0.2.0",
+    "description": "The app tool",
+    "homepage": "https://github.com/username/app",
+    "license": "MIT",
+    "architecture": {
+        "32bit": {
+            "url": "https://github.com/username/app/releases/download/v0.2.0/app-windows-386#/app.exe",
+            "hash": "bbbb"
+        },
+        "64bit": {
+            "url": "https://github.com/username/app/releases/download/v0.2.0/app-windows-amd64#/app.exe",
+            "hash": "aaaa"
+        }
+    },
+    "bin": "app.exe",
+    "checkver": {
+        "github": "https://github.com/username/app"
+    },
+    "autoupdate": {
+        "architecture": {
+            "32bit": {
+                "url": "https://github.com/username/app/releases/download/v$version/app-windows-386#/app.exe"
+            },
+            "64bit": {
+                "url": "https://github.com/username/app/releases/download/v$version/app-windows-amd64#/app.exe"
+            }
+        }
+    }
+}
+`),
+			},
+			expectedMessage: "app: Update to version 0.2.0",
+		},
+		{
+			name: "Custom",
+			scoop: spec.Scoop{
+				Folder:        ".",
+				CommitMessage: "Update {{.Name}} to {{.Tag}}",
+			},
+			vars: windowsVars{
+				Name:     "my-app",
+				Binary:   "app",
+				Homepage: "https://app.example.com",
+				Version:  "0.2.0",
+				Tag:      "v0.2.0",
+				Assets: []windowsAsset{
+					{Arch: "arm64", Name: "app-windows-arm64", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-arm64", SHA256: "cccc"},
+				},
+			},
+			expectedFile: step.GitFile{
+				Path: "my-app.json",
+				Content: []byte(`{
+    "version": "0.2.0",
+    "homepage": "https://app.example.com",
+    "architecture": {
+        "arm64": {
+            "url": "https://github.com/username/app/releases/download/v0.2.0/app-windows-arm64#/app.exe",
+            "hash": "cccc"
+        }
+    },
+    "bin": "app.exe",
+    "checkver": {
+        "github": "https://github.com/username/app"
+    },
+    "autoupdate": {
+        "architecture": {
+            "arm64": {
+                "url": "https://github.com/username/app/releases/download/v$version/app-windows-arm64#/app.exe"
+            }
+        }
+    }
+}
+`),
+			},
+			expectedMessage: "Update my-app to v0.2.0",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			file, message, err := renderScoop(tc.scoop, "github.com", "username/app", tc.vars)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedFile.Path, file.Path)
+				assert.Equal(t, string(tc.expectedFile.Content), string(file.Content))
+				assert.Equal(t, tc.expectedMessage, message)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestWingetIdentifier(t *testing.T) {
+	tests := []struct {
+		name          string
+		winget        spec.Winget
+		expectedID    string
+		expectedName  string
+		expectedError error
+	}{
+		{
+			name:          "NoPublisher",
+			winget:        spec.Winget{},
+			expectedError: errors.New("winget publisher is required for the package identifier"),
+		},
+		{
+			name:         "Default",
+			winget:       spec.Winget{Publisher: "username"},
+			expectedID:   "username.app",
+			expectedName: "app",
+		},
+		{
+			name:         "PackageName",
+			winget:       spec.Winget{Publisher: "username", PackageName: "App"},
+			expectedID:   "username.App",
+			expectedName: "App",
+		},
+		{
+			name:         "PackageIdentifier",
+			winget:       spec.Winget{PackageIdentifier: "Example.App"},
+			expectedID:   "Example.App",
+			expectedName: "app",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			id, name, err := wingetIdentifier(tc.winget, "app")
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedID, id)
+			assert.Equal(t, tc.expectedName, name)
+		})
+	}
+}
+
+func TestRenderWinget(t *testing.T) {
+	vars := windowsVars{
+		Name:        "username.App",
+		Binary:      "app",
+		Description: "The app tool",
+		Homepage:    "https://github.com/username/app",
+		License:     "MIT",
+		Version:     "0.2.0",
+		Tag:         "v0.2.0",
+		Assets: []windowsAsset{
+			{Arch: "amd64", Name: "app-windows-amd64", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-amd64", SHA256: "abcd"},
+			{Arch: "386", Name: "app-windows-386", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-386", SHA256: "ef01"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		winget          spec.Winget
+		vars            windowsVars
+		expectedError   string
+		expectedFiles   []step.GitFile
+		expectedMessage string
+	}{
+		{
+			name:          "NoPublisher",
+			winget:        spec.Winget{PackageIdentifier: "username.App"},
+			vars:          vars,
+			expectedError: "winget publisher is required",
+		},
+		{
+			name:          "NoLicense",
+			winget:        spec.Winget{Publisher: "username"},
+			vars:          windowsVars{Name: "username.App", Description: "The app tool"},
+			expectedError: "winget license and description are required",
+		},
+		{
+			name:          "InvalidCommitMessage",
+			winget:        spec.Winget{Publisher: "username", CommitMessage: "{{.ID}}"},
+			vars:          vars,
+			expectedError: `invalid commit_message template: template: commit_message:1:2: executing "commit_message" at <.ID>: can't evaluate field ID in type action.windowsVars`,
+		},
+		{
+			name:   "Default",
+			winget: spec.Winget{Publisher: "username"},
+			vars:   vars,
+			expectedFiles: []step.GitFile{
+				{
+					Path: "manifests/u/username/App/0.2.0/username.App.yaml",
+					Content: []byte(`# yaml-language-server: $schema=https://aka.ms/winget-manifest.version.1.6.0.schema.json
+
+PackageIdentifier: username.App
+PackageVersion: 0.2.0
+DefaultLocale: en-US
+ManifestType: version
+ManifestVersion: 1.6.0
+`),
+				},
+				{
+					Path: "manifests/u/username/App/0.2.0/username.App.installer.yaml",
+					Content: []byte(`# yaml-language-server: $schema=https://aka.ms/winget-manifest.installer.1.6.0.schema.json
+
+PackageIdentifier: username.App
+PackageVersion: 0.2.0
+InstallerType: portable
+Commands:
+- app
+Installers:
+- Architecture: x64
+  InstallerUrl: https://github.com/username/app/releases/download/v0.2.0/app-windows-amd64
+  InstallerSha256: ABCD
+- Architecture: x86
+  InstallerUrl: https://github.com/username/app/releases/download/v0.2.0/app-windows-386
+  InstallerSha256: EF01
+ManifestType: installer
+ManifestVersion: 1.6.0
+`),
+				},
+				{
+					Path: "manifests/u/username/App/0.2.0/username.App.locale.en-US.yaml",
+					Content: []byte(`# yaml-language-server: $schema=https://aka.ms/winget-manifest.defaultLocale.1.6.0.schema.json
+
+PackageIdentifier: username.App
+PackageVersion: 0.2.0
+PackageLocale: en-US
+Publisher: username
+PackageName: App
+PackageUrl: https://github.com/username/app
+License: MIT
+ShortDescription: The app tool
+ManifestType: defaultLocale
+ManifestVersion: 1.6.0
+`),
+				},
+			},
+			expectedMessage: "New version: username.App version 0.2.0",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			files, message, err := renderWinget(tc.winget, "App", tc.vars)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Len(t, files, len(tc.expectedFiles))
+				for i, f := range tc.expectedFiles {
+					assert.Equal(t, f.Path, files[i].Path)
+					assert.Equal(t, string(f.Content), string(files[i].Content))
+				}
+				assert.Equal(t, tc.expectedMessage, message)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestReleaseWindowsVars(t *testing.T) {
+	td, err := ioutil.TempDir("", "cherry-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(td)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(td, "app-windows-amd64"), []byte("windows"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(td, "app-windows-arm"), []byte("windows"), 0755))
+
+	s := spec.Spec{
+		Build: spec.Build{
+			BinaryFile: "bin/app",
+			Platforms:  []string{"linux-amd64", "windows-amd64", "windows-arm"},
+		},
+	}
+
+	r := &release{
+		step1:  &step.GitGetRepo{},
+		step15: &step.GoBuild{WorkDir: td},
+		step16: &step.ReleaseUploadAssets{},
+		step25: &step.ReleaseEdit{},
+	}
+
+	r.step1.Result.Host = "github.com"
+	r.step1.Result.Repo = "username/app"
+	r.step15.Result.Binaries = []string{"app-windows-amd64", "app-windows-arm"}
+	r.step16.Result.Assets = []step.ReleaseAsset{
+		{Name: "app-windows-amd64", DownloadURL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-amd64"},
+		{Name: "app-windows-arm", DownloadURL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-arm"},
+	}
+
+	vars := releaseVars{Version: "0.2.0", Tag: "v0.2.0"}
+
+	t.Run("Dry", func(t *testing.T) {
+		wv, err := r.windowsVars(s, "", "The app tool", "", "MIT", scoopArchs, vars, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, windowsVars{
+			Name:        "app",
+			Binary:      "app",
+			Description: "The app tool",
+			Homepage:    "https://github.com/username/app",
+			License:     "MIT",
+			Version:     "0.2.0",
+			Tag:         "v0.2.0",
+			Assets: []windowsAsset{
+				{Arch: "amd64", Name: "app-windows-amd64", URL: "<download url of app-windows-amd64>", SHA256: "<sha256 of app-windows-amd64>"},
+			},
+		}, wv)
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		wv, err := r.windowsVars(s, "username.app", "", "https://app.example.com", "", wingetArchs, vars, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "username.app", wv.Name)
+		assert.Equal(t, "https://app.example.com", wv.Homepage)
+		assert.Equal(t, []windowsAsset{
+			{Arch: "amd64", Name: "app-windows-amd64", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-amd64", SHA256: "340d600392818df2413382dc7d8325c360d83ea49a262d31760348484bbc10b5"},
+			{Arch: "arm", Name: "app-windows-arm", URL: "https://github.com/username/app/releases/download/v0.2.0/app-windows-arm", SHA256: "340d600392818df2413382dc7d8325c360d83ea49a262d31760348484bbc10b5"},
+		}, wv.Assets)
+	})
+
+	t.Run("NoBinary", func(t *testing.T) {
+		s := s
+		s.Build.Platforms = []string{"linux-amd64"}
+
+		_, err := r.windowsVars(s, "", "", "", "", scoopArchs, vars, true)
+		assert.Equal(t, errors.New("no windows binary for the Windows manifests"), err)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"os"
+	"path"
 	"path/filepath"
 
 	"gopkg.in/yaml.v2"
@@ -17,6 +18,13 @@ const (
 	defaultMainFile       = "main.go"
 	defaultVersionPackage = "./cmd/version"
 	defaultModel          = "master"
+
+	defaultReleaseCommit = "Releasing {{.Version}}"
+	defaultNextCommit    = "Beginning {{.NextVersion}} [skip ci]"
+	defaultTag           = "v{{.Version}}"
+	defaultTagAnnotation = "Version {{.Version}}"
+	defaultReleaseName   = "{{.Version}}"
+	defaultReleaseBody   = "{{.Comment}}{{if .Changelog}}\n\n{{.Changelog}}{{end}}"
 )
 
 var (
@@ -90,10 +98,192 @@ func (b *Build) FlagSet() *flag.FlagSet {
 	return fs
 }
 
+// Templates has the text/template templates for the commit messages, tag, and release created by release command.
+// The available variables are .Version, .PreviousVersion, .NextVersion, .Tag, .PreviousTag, .Module, .Changelog, and .Comment.
+type Templates struct {
+	ReleaseCommit string `json:"releaseCommit" yaml:"release_commit"`
+	NextCommit    string `json:"nextCommit" yaml:"next_commit"`
+	Tag           string `json:"tag" yaml:"tag"`
+	TagAnnotation string `json:"tagAnnotation" yaml:"tag_annotation"`
+	ReleaseName   string `json:"releaseName" yaml:"release_name"`
+	ReleaseBody   string `json:"releaseBody" yaml:"release_body"`
+}
+
+// SetDefaults sets default values for empty fields.
+func (t *Templates) SetDefaults() {
+	if t.ReleaseCommit == "" {
+		t.ReleaseCommit = defaultReleaseCommit
+	}
+
+	if t.NextCommit == "" {
+		t.NextCommit = defaultNextCommit
+	}
+
+	if t.Tag == "" {
+		t.Tag = defaultTag
+	}
+
+	if t.TagAnnotation == "" {
+		t.TagAnnotation = defaultTagAnnotation
+	}
+
+	if t.ReleaseName == "" {
+		t.ReleaseName = defaultReleaseName
+	}
+
+	if t.ReleaseBody == "" {
+		t.ReleaseBody = defaultReleaseBody
+	}
+}
+
+// Homebrew has the specifications for publishing a Homebrew formula to a tap repository after a release.
+// Tap is the tap repository (i.e. username/homebrew-tap) and Method is either api (default) for the GitHub contents API
+// or git for cloning and pushing to TapURL (i.e. git@github.com:username/homebrew-tap.git).
+// If Formula is not set, the name of binary is used.
+// If Template is set, the formula is rendered from the text/template file instead of the default template.
+// CommitMessage is a text/template template for the commit message in tap repository.
+type Homebrew struct {
+	Tap           string `json:"tap" yaml:"tap"`
+	TapURL        string `json:"tapURL" yaml:"tap_url"`
+	Branch        string `json:"branch" yaml:"branch"`
+	Method        string `json:"method" yaml:"method"`
+	Formula       string `json:"formula" yaml:"formula"`
+	Folder        string `json:"folder" yaml:"folder"`
+	Description   string `json:"description" yaml:"description"`
+	Homepage      string `json:"homepage" yaml:"homepage"`
+	License       string `json:"license" yaml:"license"`
+	Install       string `json:"install" yaml:"install"`
+	Test          string `json:"test" yaml:"test"`
+	Template      string `json:"template" yaml:"template"`
+	CommitMessage string `json:"commitMessage" yaml:"commit_message"`
+}
+
+// Enabled determines whether or not a Homebrew formula should be published.
+func (h Homebrew) Enabled() bool {
+	return h.Tap != "" || h.TapURL != ""
+}
+
+// Scoop has the specifications for publishing a Scoop manifest to a bucket repository after a release.
+// Bucket is the bucket repository (i.e. username/scoop-bucket) and BucketURL is its git url if it is not on the same host.
+// If Manifest is not set, the name of binary is used.
+// CommitMessage is a text/template template for the commit message in bucket repository.
+type Scoop struct {
+	Bucket        string `json:"bucket" yaml:"bucket"`
+	BucketURL     string `json:"bucketURL" yaml:"bucket_url"`
+	Branch        string `json:"branch" yaml:"branch"`
+	Folder        string `json:"folder" yaml:"folder"`
+	Manifest      string `json:"manifest" yaml:"manifest"`
+	Description   string `json:"description" yaml:"description"`
+	Homepage      string `json:"homepage" yaml:"homepage"`
+	License       string `json:"license" yaml:"license"`
+	CommitMessage string `json:"commitMessage" yaml:"commit_message"`
+}
+
+// Enabled determines whether or not a Scoop manifest should be published.
+func (s Scoop) Enabled() bool {
+	return s.Bucket != "" || s.BucketURL != ""
+}
+
+// Winget has the specifications for publishing a winget manifest set to a repository after a release.
+// Repo is the manifests repository (i.e. username/winget-pkgs) and RepoURL is its git url if it is not on the same host.
+// If PackageIdentifier is not set, Publisher and PackageName (or the name of binary) are used (i.e. Publisher.PackageName).
+// CommitMessage is a text/template template for the commit message in manifests repository.
+type Winget struct {
+	Repo              string `json:"repo" yaml:"repo"`
+	RepoURL           string `json:"repoURL" yaml:"repo_url"`
+	Branch            string `json:"branch" yaml:"branch"`
+	Folder            string `json:"folder" yaml:"folder"`
+	Publisher         string `json:"publisher" yaml:"publisher"`
+	PackageName       string `json:"packageName" yaml:"package_name"`
+	PackageIdentifier string `json:"packageIdentifier" yaml:"package_identifier"`
+	Description       string `json:"description" yaml:"description"`
+	Homepage          string `json:"homepage" yaml:"homepage"`
+	License           string `json:"license" yaml:"license"`
+	CommitMessage     string `json:"commitMessage" yaml:"commit_message"`
+}
+
+// Enabled determines whether or not a winget manifest set should be published.
+func (w Winget) Enabled() bool {
+	return w.Repo != "" || w.RepoURL != ""
+}
+
+// PackageFile is a file from the repository installed by Linux packages or copied into images.
+// Mode is an octal file mode (i.e. 0644) and defaults to 0644.
+type PackageFile struct {
+	Src  string `json:"src" yaml:"src"`
+	Dst  string `json:"dst" yaml:"dst"`
+	Mode string `json:"mode" yaml:"mode"`
+}
+
+// Packages has the specifications for building Linux packages for the linux binaries of a release.
+// Formats are any of deb, rpm, and apk and the packages are uploaded as release assets.
+// If Name is not set, the name of binary is used and the binary is installed in BinDir (/usr/bin by default).
+// ConfigFiles are preserved on upgrades and SystemdUnits are installed in /lib/systemd/system.
+// PostInstall is a shell script that runs after the package is installed.
+type Packages struct {
+	Formats      []string      `json:"formats" yaml:"formats"`
+	Name         string        `json:"name" yaml:"name"`
+	Maintainer   string        `json:"maintainer" yaml:"maintainer"`
+	Description  string        `json:"description" yaml:"description"`
+	Homepage     string        `json:"homepage" yaml:"homepage"`
+	License      string        `json:"license" yaml:"license"`
+	Depends      []string      `json:"depends" yaml:"depends"`
+	BinDir       string        `json:"binDir" yaml:"bin_dir"`
+	Files        []PackageFile `json:"files" yaml:"files"`
+	ConfigFiles  []PackageFile `json:"configFiles" yaml:"config_files"`
+	SystemdUnits []string      `json:"systemdUnits" yaml:"systemd_units"`
+	PostInstall  string        `json:"postInstall" yaml:"post_install"`
+}
+
+// Enabled determines whether or not Linux packages should be built.
+func (p Packages) Enabled() bool {
+	return len(p.Formats) > 0
+}
+
+// Docker has the specifications for building a multi-platform OCI image for the linux binaries of a release.
+// Image is the image reference (i.e. ghcr.io/username/app) and Tags are text/template templates ({{.Version}} and latest by default).
+// The binary is copied to BinDir (/usr/local/bin by default) and is the entrypoint if Entrypoint and Cmd are not set.
+// User defaults to 65534:65534 (nobody) and Expose are the exposed ports (i.e. 8080/tcp).
+// The image is written to File as an OCI image layout tarball (<binary>-image.tar next to the binaries by default).
+// If Push is set, the image is pushed to the registry after the release and Insecure uses plain http for a local registry.
+type Docker struct {
+	Image      string            `json:"image" yaml:"image"`
+	Tags       []string          `json:"tags" yaml:"tags"`
+	BinDir     string            `json:"binDir" yaml:"bin_dir"`
+	Files      []PackageFile     `json:"files" yaml:"files"`
+	Entrypoint []string          `json:"entrypoint" yaml:"entrypoint"`
+	Cmd        []string          `json:"cmd" yaml:"cmd"`
+	Env        []string          `json:"env" yaml:"env"`
+	User       string            `json:"user" yaml:"user"`
+	WorkingDir string            `json:"workingDir" yaml:"working_dir"`
+	Labels     map[string]string `json:"labels" yaml:"labels"`
+	Expose     []string          `json:"expose" yaml:"expose"`
+	File       string            `json:"file" yaml:"file"`
+	Push       bool              `json:"push" yaml:"push"`
+	Insecure   bool              `json:"insecure" yaml:"insecure"`
+}
+
+// Enabled determines whether or not an image should be built.
+func (d Docker) Enabled() bool {
+	return d.Image != ""
+}
+
 // Release has the specifications for release command.
+// If Provider is not set, it will be determined from the remote repository url.
+// If Sign is set, the release commits and tag are signed using SigningFormat (gpg or ssh) and SigningKey.
 type Release struct {
-	Model string `json:"model" yaml:"model"`
-	Build bool   `json:"build" yaml:"build"`
+	Model         string    `json:"model" yaml:"model"`
+	Build         bool      `json:"build" yaml:"build"`
+	Provider      string    `json:"provider" yaml:"provider"`
+	Sign          bool      `json:"sign" yaml:"sign"`
+	SigningFormat string    `json:"signingFormat" yaml:"signing_format"`
+	SigningKey    string    `json:"signingKey" yaml:"signing_key"`
+	Templates     Templates `json:"templates" yaml:"templates"`
+	Homebrew      Homebrew  `json:"homebrew" yaml:"homebrew"`
+	Scoop         Scoop     `json:"scoop" yaml:"scoop"`
+	Winget        Winget    `json:"winget" yaml:"winget"`
+	Packages      Packages  `json:"packages" yaml:"packages"`
+	Docker        Docker    `json:"docker" yaml:"docker"`
 }
 
 // SetDefaults sets default values for empty fields.
@@ -101,6 +291,8 @@ func (r *Release) SetDefaults() {
 	if r.Model == "" {
 		r.Model = defaultModel
 	}
+
+	r.Templates.SetDefaults()
 }
 
 // FlagSet returns a flag set for input arguments for release command.
@@ -108,20 +300,83 @@ func (r *Release) FlagSet() *flag.FlagSet {
 	fs := flag.NewFlagSet("release", flag.ContinueOnError)
 	fs.StringVar(&r.Model, "model", r.Model, "")
 	fs.BoolVar(&r.Build, "build", r.Build, "")
+	fs.StringVar(&r.Provider, "provider", r.Provider, "")
+	fs.BoolVar(&r.Sign, "sign", r.Sign, "")
 
 	return fs
 }
 
+// VersionFile is an additional file that has the version in it and is updated on every release.
+// If Pattern is set, the first group of the regular expression matches the version.
+// Otherwise, the format of file is determined from its name.
+type VersionFile struct {
+	Path    string `json:"path" yaml:"path"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
+// Module has the specifications for a Go module in a monorepo.
+// Dir is the directory of module relative to the root of repository.
+// If Name is not set, the last element of Dir is used.
+// If TagPrefix is not set, Dir followed by a slash is used (e.g. sdk/v1.3.0).
+type Module struct {
+	Name           string        `json:"name" yaml:"name"`
+	Dir            string        `json:"dir" yaml:"dir"`
+	VersionFile    string        `json:"versionFile" yaml:"version_file"`
+	VersionPattern string        `json:"versionPattern" yaml:"version_pattern"`
+	VersionFiles   []VersionFile `json:"versionFiles" yaml:"version_files"`
+	TagPrefix      string        `json:"tagPrefix" yaml:"tag_prefix"`
+	Build          Build         `json:"build" yaml:"build"`
+}
+
+// SetDefaults sets default values for empty fields.
+func (m *Module) SetDefaults() {
+	dir := path.Clean(filepath.ToSlash(m.Dir))
+
+	if m.Name == "" {
+		m.Name = path.Base(dir)
+	}
+
+	if m.TagPrefix == "" && dir != "." {
+		m.TagPrefix = dir + "/"
+	}
+
+	if m.Build.BinaryFile == "" && dir != "." {
+		m.Build.BinaryFile = "bin/" + path.Base(dir)
+	}
+
+	m.Build.SetDefaults()
+}
+
+// GitHub has the specifications for GitHub and GitHub Enterprise Server.
+// If BaseURL is not set, it will be determined from the remote repository url.
+type GitHub struct {
+	BaseURL   string `json:"baseURL" yaml:"base_url"`
+	UploadURL string `json:"uploadURL" yaml:"upload_url"`
+	CABundle  string `json:"caBundle" yaml:"ca_bundle"`
+}
+
 // Spec has all the specifications for Cherry.
+// VersionSource is either file (default) or git for deriving the version from git tags instead of a version file.
+// Versioning is either semver (default) or calver for calendar versions in CalVerFormat (YYYY.0M.MICRO by default).
+// If VersionPattern is set, the first group of the regular expression matches the version in VersionFile.
+// VersionFiles are additional files that are updated along with VersionFile.
 type Spec struct {
 	ToolName    string `json:"-" yaml:"-"`
 	ToolVersion string `json:"-" yaml:"-"`
 
-	Version     string  `json:"version" yaml:"version"`
-	Language    string  `json:"language" yaml:"language"`
-	VersionFile string  `json:"versionFile" yaml:"version_file"`
-	Build       Build   `json:"build" yaml:"build"`
-	Release     Release `json:"release" yaml:"release"`
+	Version        string        `json:"version" yaml:"version"`
+	Language       string        `json:"language" yaml:"language"`
+	VersionSource  string        `json:"versionSource" yaml:"version_source"`
+	Versioning     string        `json:"versioning" yaml:"versioning"`
+	CalVerFormat   string        `json:"calverFormat" yaml:"calver_format"`
+	VersionFile    string        `json:"versionFile" yaml:"version_file"`
+	VersionPattern string        `json:"versionPattern" yaml:"version_pattern"`
+	VersionFiles   []VersionFile `json:"versionFiles" yaml:"version_files"`
+	Git            string        `json:"git" yaml:"git"`
+	Build          Build         `json:"build" yaml:"build"`
+	Release        Release       `json:"release" yaml:"release"`
+	GitHub         GitHub        `json:"github" yaml:"github"`
+	Modules        []Module      `json:"modules" yaml:"modules"`
 }
 
 // SetDefaults sets default values for empty fields.
@@ -140,6 +395,21 @@ func (s *Spec) SetDefaults() {
 
 	s.Build.SetDefaults()
 	s.Release.SetDefaults()
+
+	for i := range s.Modules {
+		s.Modules[i].SetDefaults()
+	}
+}
+
+// Module returns the specifications of a module by its name.
+func (s *Spec) Module(name string) (Module, bool) {
+	for _, m := range s.Modules {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return Module{}, false
 }
 
 // Read reads and returns specifications from a file.
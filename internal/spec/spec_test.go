@@ -99,6 +99,74 @@ func TestBuildFlagSet(t *testing.T) {
 	}
 }
 
+var defaultTemplates = Templates{
+	ReleaseCommit: defaultReleaseCommit,
+	NextCommit:    defaultNextCommit,
+	Tag:           defaultTag,
+	TagAnnotation: defaultTagAnnotation,
+	ReleaseName:   defaultReleaseName,
+	ReleaseBody:   defaultReleaseBody,
+}
+
+func TestTemplatesSetDefaults(t *testing.T) {
+	tests := []struct {
+		templates         Templates
+		expectedTemplates Templates
+	}{
+		{
+			Templates{},
+			defaultTemplates,
+		},
+		{
+			Templates{
+				ReleaseCommit: "chore: release {{.Version}}",
+				Tag:           "api/v{{.Version}}",
+			},
+			Templates{
+				ReleaseCommit: "chore: release {{.Version}}",
+				NextCommit:    defaultNextCommit,
+				Tag:           "api/v{{.Version}}",
+				TagAnnotation: defaultTagAnnotation,
+				ReleaseName:   defaultReleaseName,
+				ReleaseBody:   defaultReleaseBody,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc.templates.SetDefaults()
+		assert.Equal(t, tc.expectedTemplates, tc.templates)
+	}
+}
+
+func TestHomebrewEnabled(t *testing.T) {
+	assert.False(t, Homebrew{}.Enabled())
+	assert.True(t, Homebrew{Tap: "username/homebrew-tap"}.Enabled())
+	assert.True(t, Homebrew{TapURL: "git@github.com:username/homebrew-tap.git"}.Enabled())
+}
+
+func TestScoopEnabled(t *testing.T) {
+	assert.False(t, Scoop{}.Enabled())
+	assert.True(t, Scoop{Bucket: "username/scoop-bucket"}.Enabled())
+	assert.True(t, Scoop{BucketURL: "git@github.com:username/scoop-bucket.git"}.Enabled())
+}
+
+func TestWingetEnabled(t *testing.T) {
+	assert.False(t, Winget{}.Enabled())
+	assert.True(t, Winget{Repo: "username/winget-pkgs"}.Enabled())
+	assert.True(t, Winget{RepoURL: "git@github.com:username/winget-pkgs.git"}.Enabled())
+}
+
+func TestPackagesEnabled(t *testing.T) {
+	assert.False(t, Packages{}.Enabled())
+	assert.True(t, Packages{Formats: []string{"deb"}}.Enabled())
+}
+
+func TestDockerEnabled(t *testing.T) {
+	assert.False(t, Docker{}.Enabled())
+	assert.True(t, Docker{Image: "ghcr.io/username/app"}.Enabled())
+}
+
 func TestReleaseSetDefaults(t *testing.T) {
 	tests := []struct {
 		release         Release
@@ -107,18 +175,22 @@ func TestReleaseSetDefaults(t *testing.T) {
 		{
 			Release{},
 			Release{
-				Model: defaultModel,
-				Build: false,
+				Model:     defaultModel,
+				Build:     false,
+				Templates: defaultTemplates,
 			},
 		},
 		{
 			Release{
-				Model: "branch",
-				Build: true,
+				Model:    "branch",
+				Build:    true,
+				Provider: "gitlab",
 			},
 			Release{
-				Model: "branch",
-				Build: true,
+				Model:     "branch",
+				Build:     true,
+				Provider:  "gitlab",
+				Templates: defaultTemplates,
 			},
 		},
 	}
@@ -153,6 +225,80 @@ func TestReleaseFlagSet(t *testing.T) {
 	}
 }
 
+func TestModuleSetDefaults(t *testing.T) {
+	tests := []struct {
+		name           string
+		module         Module
+		expectedModule Module
+	}{
+		{
+			name:   "Nested",
+			module: Module{Dir: "tools/lint"},
+			expectedModule: Module{
+				Name:      "lint",
+				Dir:       "tools/lint",
+				TagPrefix: "tools/lint/",
+				Build: Build{
+					MainFile:       defaultMainFile,
+					BinaryFile:     "bin/lint",
+					VersionPackage: defaultVersionPackage,
+					GoVersions:     defaultGoVersions,
+					Platforms:      defaultPlatforms,
+				},
+			},
+		},
+		{
+			name: "Custom",
+			module: Module{
+				Name:        "software-development-kit",
+				Dir:         "./sdk/",
+				VersionFile: "version.json",
+				TagPrefix:   "sdk-",
+				Build: Build{
+					BinaryFile: "bin/sdk-cli",
+				},
+			},
+			expectedModule: Module{
+				Name:        "software-development-kit",
+				Dir:         "./sdk/",
+				VersionFile: "version.json",
+				TagPrefix:   "sdk-",
+				Build: Build{
+					MainFile:       defaultMainFile,
+					BinaryFile:     "bin/sdk-cli",
+					VersionPackage: defaultVersionPackage,
+					GoVersions:     defaultGoVersions,
+					Platforms:      defaultPlatforms,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.module.SetDefaults()
+			assert.Equal(t, tc.expectedModule, tc.module)
+		})
+	}
+}
+
+func TestSpecModule(t *testing.T) {
+	s := Spec{
+		Modules: []Module{
+			{Name: "sdk", Dir: "sdk"},
+			{Name: "tools", Dir: "tools"},
+		},
+	}
+
+	m, ok := s.Module("tools")
+	assert.True(t, ok)
+	assert.Equal(t, Module{Name: "tools", Dir: "tools"}, m)
+
+	m, ok = s.Module("api")
+	assert.False(t, ok)
+	assert.Equal(t, Module{}, m)
+}
+
 func TestSpecSetDefaults(t *testing.T) {
 	tests := []struct {
 		spec         Spec
@@ -174,8 +320,9 @@ func TestSpecSetDefaults(t *testing.T) {
 					Platforms:      defaultPlatforms,
 				},
 				Release: Release{
-					Model: defaultModel,
-					Build: false,
+					Model:     defaultModel,
+					Build:     false,
+					Templates: defaultTemplates,
 				},
 			},
 		},
@@ -212,8 +359,9 @@ func TestSpecSetDefaults(t *testing.T) {
 					Platforms:      []string{"linux-amd64", "darwin-amd64", "windows-amd64"},
 				},
 				Release: Release{
-					Model: "branch",
-					Build: true,
+					Model:     "branch",
+					Build:     true,
+					Templates: defaultTemplates,
 				},
 			},
 		},
@@ -290,9 +438,18 @@ func TestRead(t *testing.T) {
 			name:      "MaximumYAML",
 			specFiles: []string{"test/max.yaml"},
 			expectedSpec: &Spec{
-				Version:     "1.0",
-				Language:    "go",
-				VersionFile: "VERSION",
+				Version:        "1.0",
+				Language:       "go",
+				VersionSource:  "file",
+				Versioning:     "semver",
+				CalVerFormat:   "YYYY.0M.MICRO",
+				VersionFile:    "VERSION",
+				VersionPattern: `^(\S+)$`,
+				VersionFiles: []VersionFile{
+					{Path: "chart/Chart.yaml"},
+					{Path: "README.md", Pattern: `cherry-(\S+)\.tar\.gz`},
+				},
+				Git: "go",
 				Build: Build{
 					CrossCompile:   true,
 					MainFile:       "main.go",
@@ -302,8 +459,116 @@ func TestRead(t *testing.T) {
 					Platforms:      []string{"linux-386", "linux-amd64", "linux-arm", "linux-arm64", "darwin-386", "darwin-amd64", "windows-386", "windows-amd64"},
 				},
 				Release: Release{
-					Model: "master",
-					Build: true,
+					Model:         "master",
+					Build:         true,
+					Provider:      "github",
+					Sign:          true,
+					SigningFormat: "ssh",
+					SigningKey:    "~/.ssh/id_ed25519.pub",
+					Templates: Templates{
+						ReleaseCommit: "chore(release): {{.Version}}",
+						NextCommit:    "chore(release): begin {{.NextVersion}} [skip ci]",
+						Tag:           "release-{{.Version}}",
+						TagAnnotation: "Release {{.Version}}",
+						ReleaseName:   "Release {{.Version}}",
+						ReleaseBody:   "{{.Comment}}",
+					},
+					Homebrew: Homebrew{
+						Tap:           "moorara/homebrew-tap",
+						TapURL:        "git@github.com:moorara/homebrew-tap.git",
+						Branch:        "master",
+						Method:        "api",
+						Formula:       "cherry",
+						Folder:        "Formula",
+						Description:   "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:      "https://github.com/moorara/cherry",
+						License:       "ISC",
+						Install:       `bin.install "cherry"`,
+						Test:          `system "#{bin}/cherry", "-version"`,
+						Template:      "formula.rb.tmpl",
+						CommitMessage: "{{.Name}} {{.Version}}",
+					},
+					Scoop: Scoop{
+						Bucket:        "moorara/scoop-bucket",
+						BucketURL:     "git@github.com:moorara/scoop-bucket.git",
+						Branch:        "master",
+						Folder:        "bucket",
+						Manifest:      "cherry",
+						Description:   "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:      "https://github.com/moorara/cherry",
+						License:       "ISC",
+						CommitMessage: "{{.Name}}: update to {{.Version}}",
+					},
+					Winget: Winget{
+						Repo:              "moorara/winget-pkgs",
+						RepoURL:           "git@github.com:moorara/winget-pkgs.git",
+						Branch:            "master",
+						Folder:            "manifests",
+						Publisher:         "moorara",
+						PackageName:       "Cherry",
+						PackageIdentifier: "moorara.Cherry",
+						Description:       "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:          "https://github.com/moorara/cherry",
+						License:           "ISC",
+						CommitMessage:     "New version: {{.Name}} {{.Version}}",
+					},
+					Packages: Packages{
+						Formats:     []string{"deb", "rpm", "apk"},
+						Name:        "cherry",
+						Maintainer:  "Milad Irannejad <moorara@example.com>",
+						Description: "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:    "https://github.com/moorara/cherry",
+						License:     "ISC",
+						Depends:     []string{"git", "ca-certificates"},
+						BinDir:      "/usr/local/bin",
+						Files: []PackageFile{
+							{Src: "README.md", Dst: "/usr/share/doc/cherry/README.md"},
+						},
+						ConfigFiles: []PackageFile{
+							{Src: "packaging/cherry.yaml", Dst: "/etc/cherry/cherry.yaml", Mode: "0600"},
+						},
+						SystemdUnits: []string{"packaging/cherry.service"},
+						PostInstall:  "systemctl daemon-reload",
+					},
+					Docker: Docker{
+						Image:  "ghcr.io/moorara/cherry",
+						Tags:   []string{"{{.Version}}", "latest"},
+						BinDir: "/usr/bin",
+						Files: []PackageFile{
+							{Src: "packaging/cherry.yaml", Dst: "/etc/cherry/cherry.yaml"},
+						},
+						Entrypoint: []string{"/usr/bin/cherry"},
+						Cmd:        []string{"--help"},
+						Env:        []string{"CHERRY_LOG_LEVEL=info"},
+						User:       "1000:1000",
+						WorkingDir: "/workspace",
+						Labels:     map[string]string{"org.opencontainers.image.title": "cherry"},
+						Expose:     []string{"8080/tcp"},
+						File:       "bin/cherry-image.tar",
+						Push:       true,
+						Insecure:   true,
+					},
+				},
+				GitHub: GitHub{
+					BaseURL:   "https://github.example.com",
+					UploadURL: "https://github.example.com/api/uploads",
+					CABundle:  "/etc/ssl/certs/example.pem",
+				},
+				Modules: []Module{
+					{
+						Name:        "sdk",
+						Dir:         "sdk",
+						VersionFile: "VERSION",
+						VersionFiles: []VersionFile{
+							{Path: "version.go"},
+						},
+						TagPrefix: "sdk/",
+						Build: Build{
+							MainFile:       "main.go",
+							BinaryFile:     "bin/sdk",
+							VersionPackage: "./version",
+						},
+					},
 				},
 			},
 		},
@@ -311,9 +576,18 @@ func TestRead(t *testing.T) {
 			name:      "MaximumJSON",
 			specFiles: []string{"test/max.json"},
 			expectedSpec: &Spec{
-				Version:     "1.0",
-				Language:    "go",
-				VersionFile: "VERSION",
+				Version:        "1.0",
+				Language:       "go",
+				VersionSource:  "file",
+				Versioning:     "semver",
+				CalVerFormat:   "YYYY.0M.MICRO",
+				VersionFile:    "VERSION",
+				VersionPattern: `^(\S+)$`,
+				VersionFiles: []VersionFile{
+					{Path: "chart/Chart.yaml"},
+					{Path: "README.md", Pattern: `cherry-(\S+)\.tar\.gz`},
+				},
+				Git: "go",
 				Build: Build{
 					CrossCompile:   true,
 					MainFile:       "main.go",
@@ -323,8 +597,116 @@ func TestRead(t *testing.T) {
 					Platforms:      []string{"linux-386", "linux-amd64", "linux-arm", "linux-arm64", "darwin-386", "darwin-amd64", "windows-386", "windows-amd64"},
 				},
 				Release: Release{
-					Model: "master",
-					Build: true,
+					Model:         "master",
+					Build:         true,
+					Provider:      "github",
+					Sign:          true,
+					SigningFormat: "ssh",
+					SigningKey:    "~/.ssh/id_ed25519.pub",
+					Templates: Templates{
+						ReleaseCommit: "chore(release): {{.Version}}",
+						NextCommit:    "chore(release): begin {{.NextVersion}} [skip ci]",
+						Tag:           "release-{{.Version}}",
+						TagAnnotation: "Release {{.Version}}",
+						ReleaseName:   "Release {{.Version}}",
+						ReleaseBody:   "{{.Comment}}",
+					},
+					Homebrew: Homebrew{
+						Tap:           "moorara/homebrew-tap",
+						TapURL:        "git@github.com:moorara/homebrew-tap.git",
+						Branch:        "master",
+						Method:        "api",
+						Formula:       "cherry",
+						Folder:        "Formula",
+						Description:   "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:      "https://github.com/moorara/cherry",
+						License:       "ISC",
+						Install:       `bin.install "cherry"`,
+						Test:          `system "#{bin}/cherry", "-version"`,
+						Template:      "formula.rb.tmpl",
+						CommitMessage: "{{.Name}} {{.Version}}",
+					},
+					Scoop: Scoop{
+						Bucket:        "moorara/scoop-bucket",
+						BucketURL:     "git@github.com:moorara/scoop-bucket.git",
+						Branch:        "master",
+						Folder:        "bucket",
+						Manifest:      "cherry",
+						Description:   "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:      "https://github.com/moorara/cherry",
+						License:       "ISC",
+						CommitMessage: "{{.Name}}: update to {{.Version}}",
+					},
+					Winget: Winget{
+						Repo:              "moorara/winget-pkgs",
+						RepoURL:           "git@github.com:moorara/winget-pkgs.git",
+						Branch:            "master",
+						Folder:            "manifests",
+						Publisher:         "moorara",
+						PackageName:       "Cherry",
+						PackageIdentifier: "moorara.Cherry",
+						Description:       "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:          "https://github.com/moorara/cherry",
+						License:           "ISC",
+						CommitMessage:     "New version: {{.Name}} {{.Version}}",
+					},
+					Packages: Packages{
+						Formats:     []string{"deb", "rpm", "apk"},
+						Name:        "cherry",
+						Maintainer:  "Milad Irannejad <moorara@example.com>",
+						Description: "Cherry is an opinionated tool for unifying the build and release process",
+						Homepage:    "https://github.com/moorara/cherry",
+						License:     "ISC",
+						Depends:     []string{"git", "ca-certificates"},
+						BinDir:      "/usr/local/bin",
+						Files: []PackageFile{
+							{Src: "README.md", Dst: "/usr/share/doc/cherry/README.md"},
+						},
+						ConfigFiles: []PackageFile{
+							{Src: "packaging/cherry.yaml", Dst: "/etc/cherry/cherry.yaml", Mode: "0600"},
+						},
+						SystemdUnits: []string{"packaging/cherry.service"},
+						PostInstall:  "systemctl daemon-reload",
+					},
+					Docker: Docker{
+						Image:  "ghcr.io/moorara/cherry",
+						Tags:   []string{"{{.Version}}", "latest"},
+						BinDir: "/usr/bin",
+						Files: []PackageFile{
+							{Src: "packaging/cherry.yaml", Dst: "/etc/cherry/cherry.yaml"},
+						},
+						Entrypoint: []string{"/usr/bin/cherry"},
+						Cmd:        []string{"--help"},
+						Env:        []string{"CHERRY_LOG_LEVEL=info"},
+						User:       "1000:1000",
+						WorkingDir: "/workspace",
+						Labels:     map[string]string{"org.opencontainers.image.title": "cherry"},
+						Expose:     []string{"8080/tcp"},
+						File:       "bin/cherry-image.tar",
+						Push:       true,
+						Insecure:   true,
+					},
+				},
+				GitHub: GitHub{
+					BaseURL:   "https://github.example.com",
+					UploadURL: "https://github.example.com/api/uploads",
+					CABundle:  "/etc/ssl/certs/example.pem",
+				},
+				Modules: []Module{
+					{
+						Name:        "sdk",
+						Dir:         "sdk",
+						VersionFile: "VERSION",
+						VersionFiles: []VersionFile{
+							{Path: "version.go"},
+						},
+						TagPrefix: "sdk/",
+						Build: Build{
+							MainFile:       "main.go",
+							BinaryFile:     "bin/sdk",
+							VersionPackage: "./version",
+						},
+					},
 				},
 			},
 		},
@@ -0,0 +1,351 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	netURL "net/url"
+
+	"gopkg.in/yaml.v2"
+)
+
+// tokenSource provides access tokens that may expire.
+type tokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// tokenTransport is an http.RoundTripper that sets the Authorization header of requests using a token source.
+// The token is only sent to the given hosts, so it is not leaked when a request is redirected to another host.
+type tokenTransport struct {
+	Base   http.RoundTripper
+	Source tokenSource
+	Hosts  []string
+}
+
+// authorizes determines whether or not the access token should be added to a request.
+func (t *tokenTransport) authorizes(req *http.Request) bool {
+	if req.Header.Get("Authorization") != "" {
+		return false
+	}
+
+	for _, host := range t.Hosts {
+		if req.URL.Host == host {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoundTrip executes a single http transaction with a fresh access token.
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if !t.authorizes(req) {
+		return base.RoundTrip(req)
+	}
+
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// A RoundTripper should not modify the original request
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+
+	return base.RoundTrip(r)
+}
+
+// githubAppTokenSource provides installation access tokens for a GitHub App.
+// Tokens are cached and refreshed shortly before they expire.
+// See https://developer.github.com/apps/building-github-apps/authenticating-with-github-apps
+type githubAppTokenSource struct {
+	Client         *http.Client
+	APIURL         string
+	Repo           string
+	AppID          int64
+	InstallationID int64
+	PrivateKey     *rsa.PrivateKey
+
+	now       func() time.Time
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// Token returns a valid installation access token.
+func (s *githubAppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.now != nil {
+		now = s.now()
+	}
+
+	if s.token != "" && now.Add(time.Minute).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	jwt, err := githubAppJWT(s.AppID, s.PrivateKey, now)
+	if err != nil {
+		return "", err
+	}
+
+	// Find the installation of GitHub App for the repository
+	// See https://developer.github.com/v3/apps/#get-a-repository-installation
+	if s.InstallationID == 0 {
+		installation := struct {
+			ID int64 `json:"id"`
+		}{}
+
+		url := fmt.Sprintf("%s/repos/%s/installation", s.APIURL, s.Repo)
+		if err := s.do(ctx, jwt, "GET", url, 200, &installation); err != nil {
+			return "", err
+		}
+
+		s.InstallationID = installation.ID
+	}
+
+	// See https://developer.github.com/v3/apps/#create-a-new-installation-token
+	accessToken := struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", s.APIURL, s.InstallationID)
+	if err := s.do(ctx, jwt, "POST", url, 201, &accessToken); err != nil {
+		return "", err
+	}
+
+	s.token = accessToken.Token
+	s.expiresAt = accessToken.ExpiresAt
+
+	return s.token, nil
+}
+
+func (s *githubAppTokenSource) do(ctx context.Context, jwt, method, url string, statusCode int, v interface{}) error {
+	req, err := createGitHubRequest(ctx, "", method, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", jwt))
+	req.Header.Set("Accept", "application/vnd.github.machine-man-preview+json")
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != statusCode {
+		return newHTTPError(res)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// githubAppJWT creates a JSON Web Token signed by the private key of a GitHub App.
+// See https://developer.github.com/apps/building-github-apps/authenticating-with-github-apps/#authenticating-as-a-github-app
+func githubAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// iat is set in the past to allow for clock drift and exp can be at most 10 minutes in the future
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": appID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + enc.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded RSA private key in PKCS #1 or PKCS #8 form.
+func parseRSAPrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid private key: no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("invalid private key: not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// readCredentialsFile reads the token for a host from a gh-style hosts.yml file.
+//
+//	github.com:
+//	  user: octocat
+//	  oauth_token: xxxxxxxxxxxxxxxxxxxx
+func readCredentialsFile(path, host string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	hosts := map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}{}
+
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", err
+	}
+
+	h, ok := hosts[host]
+	if !ok || h.OAuthToken == "" {
+		return "", fmt.Errorf("no token found for %s in %s", host, path)
+	}
+
+	return h.OAuthToken, nil
+}
+
+// runCredentialHelper runs a credential helper command and returns the token for a host.
+// The host is passed to the command in git-credential format on standard input.
+// The command can either print the token or a password=<token> line in git-credential format.
+func runCredentialHelper(ctx context.Context, command, host string) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.New("empty credential helper")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("credential helper: %s %s", err, strings.Trim(stderr.String(), "\n"))
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "password=") {
+			return strings.TrimPrefix(line, "password="), nil
+		}
+	}
+
+	if output == "" || strings.Contains(output, "\n") {
+		return "", errors.New("credential helper: no token returned")
+	}
+
+	return output, nil
+}
+
+// authenticate sets up the authentication for a GitHub provider.
+// A personal access token takes precedence over a GitHub App, a credential helper, and a credentials file respectively.
+func (p *GitHubProvider) authenticate(config ProviderConfig, host string) error {
+	if host == "" {
+		host = "github.com"
+	}
+
+	switch {
+	case config.GitHubToken != "":
+		p.Token = config.GitHubToken
+
+	case config.GitHubAppID != 0:
+		key, err := parseRSAPrivateKey(config.GitHubAppPrivateKey)
+		if err != nil {
+			return err
+		}
+
+		if p.Client == nil {
+			p.Client = &http.Client{}
+		}
+
+		p.tokenSource = &githubAppTokenSource{
+			Client:         p.Client,
+			APIURL:         p.APIURL,
+			Repo:           p.Repo,
+			AppID:          config.GitHubAppID,
+			InstallationID: config.GitHubAppInstallationID,
+			PrivateKey:     key,
+		}
+
+		uploadURL := p.UploadURL
+		if uploadURL == "" {
+			uploadURL = GitHubUploadURL
+		}
+
+		hosts := []string{}
+		for _, rawURL := range []string{p.URL, p.APIURL, uploadURL} {
+			if u, err := netURL.Parse(rawURL); err == nil && u.Host != "" {
+				hosts = append(hosts, u.Host)
+			}
+		}
+
+		client := *p.Client
+		client.Transport = &tokenTransport{
+			Base:   p.Client.Transport,
+			Source: p.tokenSource,
+			Hosts:  hosts,
+		}
+		p.Client = &client
+
+	case config.GitHubCredentialHelper != "":
+		token, err := runCredentialHelper(context.Background(), config.GitHubCredentialHelper, host)
+		if err != nil {
+			return err
+		}
+		p.Token = token
+
+	case config.GitHubCredentialsFile != "":
+		token, err := readCredentialsFile(config.GitHubCredentialsFile, host)
+		if err != nil {
+			return err
+		}
+		p.Token = token
+	}
+
+	return nil
+}
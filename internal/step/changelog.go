@@ -19,6 +19,8 @@ type ChangelogGenerate struct {
 	Mock        Step
 	WorkDir     string
 	GitHubToken string
+	BaseURL     string
+	APIURL      string
 	Repo        string
 	Tag         string
 	Result      struct {
@@ -38,7 +40,7 @@ func (s *ChangelogGenerate) Dry(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf(
 			"ChangelogGenerate.Dry: %s %s %s",
 			err.Error(),
@@ -60,19 +62,28 @@ func (s *ChangelogGenerate) Run(ctx context.Context) error {
 
 	var stdout, stderr bytes.Buffer
 
-	cmd := exec.CommandContext(ctx,
-		"github_changelog_generator",
+	args := []string{
 		"--token", s.GitHubToken,
 		"--no-filter-by-milestone",
 		"--exclude-labels", "question,duplicate,invalid,wontfix",
 		"--future-release", s.Tag,
-		s.Repo,
-	)
+	}
+
+	// GitHub Enterprise Server
+	if s.BaseURL != "" && s.BaseURL != GitHubURL {
+		args = append(args, "--github-site", s.BaseURL)
+	}
+	if s.APIURL != "" && s.APIURL != GitHubAPIURL {
+		args = append(args, "--github-api", s.APIURL)
+	}
+
+	args = append(args, s.Repo)
+	cmd := exec.CommandContext(ctx, "github_changelog_generator", args...)
 
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf(
 			"ChangelogGenerate.Run: %s %s %s",
 			err.Error(),
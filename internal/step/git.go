@@ -5,46 +5,55 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strings"
 )
 
-func parseGitURL(output string) (string, string, error) {
+func parseGitURL(output string) (string, string, string, error) {
 	// origin  git@github.com:USERNAME/REPOSITORY.git (push)     --> git@github.com:USERNAME/REPOSITORY.git
 	// origin  https://github.com/USERNAME/REPOSITORY.git (push) --> https://github.com/USERNAME/REPOSITORY.git
 	re := regexp.MustCompile(`origin[[:blank:]]+(.*)[[:blank:]]\(push\)`)
 	subs := re.FindStringSubmatch(output)
 	if len(subs) != 2 {
-		return "", "", errors.New("failed to get git repository url")
+		return "", "", "", errors.New("failed to get git repository url")
 	}
 
-	gitURL := subs[1]
+	return parseRemoteURL(subs[1])
+}
 
-	// git@github.com:USERNAME/REPOSITORY.git     --> USERNAME/REPOSITORY.git
-	// https://github.com/USERNAME/REPOSITORY.git --> USERNAME/REPOSITORY.git
-	re = regexp.MustCompile(`(git@[^/]+:|https://[^/]+/)([^/]+/[^/]+)`)
-	subs = re.FindStringSubmatch(gitURL)
-	if len(subs) != 3 {
-		return "", "", errors.New("failed to get git repository name")
+func parseRemoteURL(gitURL string) (string, string, string, error) {
+	// git@github.com:USERNAME/REPOSITORY.git          --> github.com, USERNAME/REPOSITORY.git
+	// https://github.com/USERNAME/REPOSITORY.git      --> github.com, USERNAME/REPOSITORY.git
+	// https://gitlab.com/GROUP/SUBGROUP/PROJECT.git   --> gitlab.com, GROUP/SUBGROUP/PROJECT.git
+	re := regexp.MustCompile(`(git@([^/:]+):|https://([^/]+)/)([^/]+(/[^/]+)+)`)
+	subs := re.FindStringSubmatch(gitURL)
+	if len(subs) != 6 {
+		return "", "", "", errors.New("failed to get git repository name")
 	}
 
+	host := subs[2] + subs[3]
+
 	// USERNAME/REPOSITORY.git --> USERNAME/REPOSITORY
-	repo := subs[2]
+	repo := subs[4]
 	repo = strings.TrimSuffix(repo, ".git")
 
-	// Split repo owner and name
-	subs = strings.Split(repo, "/")
-	owner := subs[0]
-	name := subs[1]
+	// Split repo owner (user, organization, or group) and name
+	i := strings.LastIndex(repo, "/")
+	owner := repo[:i]
+	name := repo[i+1:]
 
-	return owner, name, nil
+	return host, owner, name, nil
 }
 
 // GitStatus runs `git status --porcelain` command.
 type GitStatus struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Result  struct {
 		IsClean bool
 	}
@@ -56,12 +65,19 @@ func (s *GitStatus) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.IsClean(ctx); err != nil {
+			return fmt.Errorf("GitStatus.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "status")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitStatus.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -74,12 +90,21 @@ func (s *GitStatus) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		clean, err := s.GoGit.IsClean(ctx)
+		if err != nil {
+			return fmt.Errorf("GitStatus.Run: %s", err)
+		}
+		s.Result.IsClean = clean
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitStatus.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -101,7 +126,9 @@ func (s *GitStatus) Revert(ctx context.Context) error {
 type GitGetRepo struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Result  struct {
+		Host  string
 		Owner string
 		Name  string
 		Repo  string
@@ -114,12 +141,19 @@ func (s *GitGetRepo) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.RemoteURL(ctx); err != nil {
+			return fmt.Errorf("GitGetRepo.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "remote")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitGetRepo.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -132,26 +166,44 @@ func (s *GitGetRepo) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		url, err := s.GoGit.RemoteURL(ctx)
+		if err != nil {
+			return fmt.Errorf("GitGetRepo.Run: %s", err)
+		}
+		host, owner, name, err := parseRemoteURL(url)
+		if err != nil {
+			return err
+		}
+		s.setResult(host, owner, name)
+		return nil
+	}
+
 	var err error
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "remote", "-v")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitGetRepo.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
-	owner, name, err := parseGitURL(stdout.String())
+	host, owner, name, err := parseGitURL(stdout.String())
 	if err != nil {
 		return err
 	}
 
+	s.setResult(host, owner, name)
+
+	return nil
+}
+
+func (s *GitGetRepo) setResult(host, owner, name string) {
+	s.Result.Host = host
 	s.Result.Owner = owner
 	s.Result.Name = name
 	s.Result.Repo = owner + "/" + name
-
-	return nil
 }
 
 // Revert reverts back an executed step.
@@ -167,6 +219,7 @@ func (s *GitGetRepo) Revert(ctx context.Context) error {
 type GitGetBranch struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Result  struct {
 		Name string
 	}
@@ -178,12 +231,19 @@ func (s *GitGetBranch) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.Branch(ctx); err != nil {
+			return fmt.Errorf("GitGetBranch.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitGetBranch.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -196,12 +256,21 @@ func (s *GitGetBranch) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		name, err := s.GoGit.Branch(ctx)
+		if err != nil {
+			return fmt.Errorf("GitGetBranch.Run: %s", err)
+		}
+		s.Result.Name = name
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitGetBranch.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -223,6 +292,7 @@ func (s *GitGetBranch) Revert(ctx context.Context) error {
 type GitGetHEAD struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Result  struct {
 		SHA      string
 		ShortSHA string
@@ -235,12 +305,19 @@ func (s *GitGetHEAD) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.HEAD(ctx); err != nil {
+			return fmt.Errorf("GitGetHEAD.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitGetHEAD.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -253,12 +330,22 @@ func (s *GitGetHEAD) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		sha, err := s.GoGit.HEAD(ctx)
+		if err != nil {
+			return fmt.Errorf("GitGetHEAD.Run: %s", err)
+		}
+		s.Result.SHA = sha
+		s.Result.ShortSHA = sha[:7]
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "rev-parse", "HEAD")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitGetHEAD.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -277,10 +364,176 @@ func (s *GitGetHEAD) Revert(ctx context.Context) error {
 	return nil
 }
 
+// GitLatestTag runs `git describe --tags --abbrev=0` command.
+// If Match is set, only tags matching the glob pattern (i.e. sdk/*) are considered.
+// If there is no tag reachable from HEAD, the result is empty.
+type GitLatestTag struct {
+	Mock    Step
+	WorkDir string
+	GoGit   *GoGit
+	Match   string
+	Result  struct {
+		Tag string
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *GitLatestTag) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if s.GoGit != nil {
+		if _, err := s.GoGit.Tags(ctx); err != nil {
+			return fmt.Errorf("GitLatestTag.Dry: %s", err)
+		}
+		return nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "tag")
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("GitLatestTag.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *GitLatestTag) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	if s.GoGit != nil {
+		tag, err := s.GoGit.LatestTag(ctx, s.Match)
+		if err != nil {
+			return fmt.Errorf("GitLatestTag.Run: %s", err)
+		}
+		s.Result.Tag = tag
+		return nil
+	}
+
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	if s.Match != "" {
+		args = append(args, "--match", s.Match)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		// No tag is reachable from HEAD
+		if msg := stderr.String(); strings.Contains(msg, "No names found") || strings.Contains(msg, "No tags can describe") {
+			s.Result.Tag = ""
+			return nil
+		}
+		return fmt.Errorf("GitLatestTag.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	s.Result.Tag = strings.Trim(stdout.String(), "\n")
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *GitLatestTag) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	return nil
+}
+
+// GitChangedFiles runs `git diff --name-only <since> HEAD` command.
+type GitChangedFiles struct {
+	Mock    Step
+	WorkDir string
+	GoGit   *GoGit
+	Since   string
+	Result  struct {
+		Files []string
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *GitChangedFiles) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if s.GoGit != nil {
+		if _, err := s.GoGit.ChangedFiles(ctx, s.Since); err != nil {
+			return fmt.Errorf("GitChangedFiles.Dry: %s", err)
+		}
+		return nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", s.Since+"^{commit}")
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("GitChangedFiles.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *GitChangedFiles) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	if s.GoGit != nil {
+		files, err := s.GoGit.ChangedFiles(ctx, s.Since)
+		if err != nil {
+			return fmt.Errorf("GitChangedFiles.Run: %s", err)
+		}
+		s.Result.Files = files
+		return nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "diff", "--name-only", s.Since, "HEAD")
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("GitChangedFiles.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	s.Result.Files = []string{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line != "" {
+			s.Result.Files = append(s.Result.Files, line)
+		}
+	}
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *GitChangedFiles) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	return nil
+}
+
 // GitAdd runs `git add <files>` command.
 type GitAdd struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Files   []string
 }
 
@@ -290,13 +543,20 @@ func (s *GitAdd) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.CheckFiles(ctx, s.Files...); err != nil {
+			return fmt.Errorf("GitAdd.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	args := append([]string{"add", "--dry-run"}, s.Files...)
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitAdd.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -309,13 +569,20 @@ func (s *GitAdd) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.Add(ctx, s.Files...); err != nil {
+			return fmt.Errorf("GitAdd.Run: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	args := append([]string{"add"}, s.Files...)
 	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitAdd.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -328,6 +595,13 @@ func (s *GitAdd) Revert(ctx context.Context) error {
 		return s.Mock.Revert(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.Reset(ctx, s.Files...); err != nil {
+			return fmt.Errorf("GitAdd.Revert: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 
 	// git reset <files>
@@ -336,18 +610,20 @@ func (s *GitAdd) Revert(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitAdd.Revert: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
 	return nil
 }
 
-// GitCommit runs `git commit -m <message>` command.
+// GitCommit runs `git commit -m <message>` or `git commit -S -m <message>` command.
 type GitCommit struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Message string
+	Sign    *GitSigning
 }
 
 // Dry is a dry run of the step.
@@ -356,12 +632,28 @@ func (s *GitCommit) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.Sign != nil {
+		if s.GoGit != nil {
+			return fmt.Errorf("GitCommit.Dry: %s", errGoGitSigning)
+		}
+		if err := s.Sign.check(ctx, s.WorkDir); err != nil {
+			return fmt.Errorf("GitCommit.Dry: %s", err)
+		}
+	}
+
+	if s.GoGit != nil {
+		if _, err := s.GoGit.HEAD(ctx); err != nil {
+			return fmt.Errorf("GitCommit.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "log", "--oneline")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitCommit.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -374,12 +666,27 @@ func (s *GitCommit) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		if s.Sign != nil {
+			return fmt.Errorf("GitCommit.Run: %s", errGoGitSigning)
+		}
+		if err := s.GoGit.Commit(ctx, s.Message); err != nil {
+			return fmt.Errorf("GitCommit.Run: %s", err)
+		}
+		return nil
+	}
+
+	args := []string{"commit", "-m", s.Message}
+	if s.Sign != nil {
+		args = append(s.Sign.configArgs(), "commit", "-S", "-m", s.Message)
+	}
+
 	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, "git", "commit", "-m", s.Message)
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitCommit.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -392,6 +699,13 @@ func (s *GitCommit) Revert(ctx context.Context) error {
 		return s.Mock.Revert(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.UndoCommit(ctx); err != nil {
+			return fmt.Errorf("GitCommit.Revert: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 
 	// git reset --soft HEAD~1
@@ -399,19 +713,21 @@ func (s *GitCommit) Revert(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitCommit.Revert: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
 	return nil
 }
 
-// GitTag runs `git tag` or `git tag -a <tag> -m <message>` command.
+// GitTag runs `git tag`, `git tag -a <tag> -m <message>`, or `git tag -s <tag> -m <message>` command.
 type GitTag struct {
 	Mock       Step
 	WorkDir    string
+	GoGit      *GoGit
 	Tag        string
 	Annotation string
+	Sign       *GitSigning
 }
 
 // Dry is a dry run of the step.
@@ -420,12 +736,28 @@ func (s *GitTag) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.Sign != nil {
+		if s.GoGit != nil {
+			return fmt.Errorf("GitTag.Dry: %s", errGoGitSigning)
+		}
+		if err := s.Sign.check(ctx, s.WorkDir); err != nil {
+			return fmt.Errorf("GitTag.Dry: %s", err)
+		}
+	}
+
+	if s.GoGit != nil {
+		if _, err := s.GoGit.Tags(ctx); err != nil {
+			return fmt.Errorf("GitTag.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "tag")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitTag.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -438,10 +770,28 @@ func (s *GitTag) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		if s.Sign != nil {
+			return fmt.Errorf("GitTag.Run: %s", errGoGitSigning)
+		}
+		if err := s.GoGit.Tag(ctx, s.Tag, s.Annotation); err != nil {
+			return fmt.Errorf("GitTag.Run: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 
 	var cmd *exec.Cmd
-	if s.Annotation == "" {
+	if s.Sign != nil {
+		// A signed tag is always annotated
+		message := s.Annotation
+		if message == "" {
+			message = s.Tag
+		}
+		args := append(s.Sign.configArgs(), "tag", "-s", s.Tag, "-m", message)
+		cmd = exec.CommandContext(ctx, "git", args...)
+	} else if s.Annotation == "" {
 		cmd = exec.CommandContext(ctx, "git", "tag", s.Tag)
 	} else {
 		cmd = exec.CommandContext(ctx, "git", "tag", "-a", s.Tag, "-m", s.Annotation)
@@ -450,7 +800,7 @@ func (s *GitTag) Run(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitTag.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -463,6 +813,13 @@ func (s *GitTag) Revert(ctx context.Context) error {
 		return s.Mock.Revert(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.DeleteTag(ctx, s.Tag); err != nil {
+			return fmt.Errorf("GitTag.Revert: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 
 	// git tag --delete <tag>
@@ -470,7 +827,7 @@ func (s *GitTag) Revert(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitTag.Revert: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -481,6 +838,7 @@ func (s *GitTag) Revert(ctx context.Context) error {
 type GitPush struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 }
 
 // Dry is a dry run of the step.
@@ -489,12 +847,19 @@ func (s *GitPush) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.RemoteURL(ctx); err != nil {
+			return fmt.Errorf("GitPush.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitPush.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -507,12 +872,19 @@ func (s *GitPush) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.Push(ctx); err != nil {
+			return fmt.Errorf("GitPush.Run: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "push")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitPush.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -533,6 +905,7 @@ func (s *GitPush) Revert(ctx context.Context) error {
 type GitPushTag struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 	Tag     string
 }
 
@@ -542,12 +915,19 @@ func (s *GitPushTag) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.RemoteURL(ctx); err != nil {
+			return fmt.Errorf("GitPushTag.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitPushTag.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -560,12 +940,19 @@ func (s *GitPushTag) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.PushTag(ctx, s.Tag); err != nil {
+			return fmt.Errorf("GitPushTag.Run: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "push", "origin", s.Tag)
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitPushTag.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -586,6 +973,7 @@ func (s *GitPushTag) Revert(ctx context.Context) error {
 type GitPull struct {
 	Mock    Step
 	WorkDir string
+	GoGit   *GoGit
 }
 
 // Dry is a dry run of the step.
@@ -594,12 +982,19 @@ func (s *GitPull) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
+	if s.GoGit != nil {
+		if _, err := s.GoGit.RemoteURL(ctx); err != nil {
+			return fmt.Errorf("GitPull.Dry: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitPull.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -612,12 +1007,19 @@ func (s *GitPull) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
+	if s.GoGit != nil {
+		if err := s.GoGit.Pull(ctx); err != nil {
+			return fmt.Errorf("GitPull.Run: %s", err)
+		}
+		return nil
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd := exec.CommandContext(ctx, "git", "pull")
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GitPull.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -633,3 +1035,164 @@ func (s *GitPull) Revert(ctx context.Context) error {
 	// TODO: implement revert
 	return errors.New("cannot revert git pull")
 }
+
+// GitFile is a file to be committed to a repository.
+// Path is relative to the root of repository and uses forward slashes.
+type GitFile struct {
+	Path    string
+	Content []byte
+}
+
+// GitPublishFiles clones a remote repository, commits a set of files to it, and pushes the commit.
+// The repository is cloned into a temporary directory which is removed afterwards.
+// If all files already have the same content, no commit is created.
+type GitPublishFiles struct {
+	Mock    Step
+	URL     string
+	Branch  string
+	Files   []GitFile
+	Message string
+	Result  struct {
+		Commit string
+	}
+}
+
+// git runs a git command in a directory and returns its output.
+func (s *GitPublishFiles) git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("%s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	return strings.Trim(stdout.String(), "\n"), nil
+}
+
+// clone clones the remote repository into a new temporary directory.
+func (s *GitPublishFiles) clone(ctx context.Context, shallow bool) (string, error) {
+	dir, err := ioutil.TempDir("", "cherry-")
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"clone"}
+	if shallow {
+		args = append(args, "--depth", "1")
+	}
+	if s.Branch != "" {
+		args = append(args, "--branch", s.Branch)
+	}
+	args = append(args, s.URL, dir)
+
+	if _, err := s.git(ctx, "", args...); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// Dry is a dry run of the step.
+func (s *GitPublishFiles) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	args := []string{"ls-remote", "--exit-code", s.URL}
+	if s.Branch != "" {
+		args = append(args, "refs/heads/"+s.Branch)
+	}
+
+	if _, err := s.git(ctx, "", args...); err != nil {
+		return fmt.Errorf("GitPublishFiles.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *GitPublishFiles) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	dir, err := s.clone(ctx, true)
+	if err != nil {
+		return fmt.Errorf("GitPublishFiles.Run: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, f := range s.Files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("GitPublishFiles.Run: %s", err)
+		}
+
+		if err := ioutil.WriteFile(path, f.Content, 0644); err != nil {
+			return fmt.Errorf("GitPublishFiles.Run: %s", err)
+		}
+
+		if _, err := s.git(ctx, dir, "add", f.Path); err != nil {
+			return fmt.Errorf("GitPublishFiles.Run: %s", err)
+		}
+	}
+
+	status, err := s.git(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return fmt.Errorf("GitPublishFiles.Run: %s", err)
+	}
+
+	// None of the files has been changed
+	if status == "" {
+		return nil
+	}
+
+	if _, err := s.git(ctx, dir, "commit", "-m", s.Message); err != nil {
+		return fmt.Errorf("GitPublishFiles.Run: %s", err)
+	}
+
+	commit, err := s.git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("GitPublishFiles.Run: %s", err)
+	}
+
+	if _, err := s.git(ctx, dir, "push", "origin", "HEAD"); err != nil {
+		return fmt.Errorf("GitPublishFiles.Run: %s", err)
+	}
+
+	s.Result.Commit = commit
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *GitPublishFiles) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	// No commit has been pushed
+	if s.Result.Commit == "" {
+		return nil
+	}
+
+	// The pushed commit may not be the latest one anymore, so the full history is cloned
+	dir, err := s.clone(ctx, false)
+	if err != nil {
+		return fmt.Errorf("GitPublishFiles.Revert: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := s.git(ctx, dir, "revert", "--no-edit", s.Result.Commit); err != nil {
+		return fmt.Errorf("GitPublishFiles.Revert: %s", err)
+	}
+
+	if _, err := s.git(ctx, dir, "push", "origin", "HEAD"); err != nil {
+		return fmt.Errorf("GitPublishFiles.Revert: %s", err)
+	}
+
+	return nil
+}
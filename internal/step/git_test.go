@@ -3,7 +3,10 @@ package step
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,6 +16,7 @@ func TestParseGitURL(t *testing.T) {
 	tests := []struct {
 		name          string
 		output        string
+		expectedHost  string
 		expectedOwner string
 		expectedName  string
 		expectedError string
@@ -20,8 +24,6 @@ func TestParseGitURL(t *testing.T) {
 		{
 			name:          "Empty",
 			output:        ``,
-			expectedOwner: "",
-			expectedName:  "",
 			expectedError: "failed to get git repository url",
 		},
 		{
@@ -30,8 +32,6 @@ func TestParseGitURL(t *testing.T) {
 			origin	moorara/cherry (fetch)
 			origin	moorara/cherry (push)
 			`,
-			expectedOwner: "",
-			expectedName:  "",
 			expectedError: "failed to get git repository name",
 		},
 		{
@@ -40,9 +40,9 @@ func TestParseGitURL(t *testing.T) {
 			origin	https://github.com/moorara/cherry (fetch)
 			origin	https://github.com/moorara/cherry (push)
 			`,
+			expectedHost:  "github.com",
 			expectedOwner: "moorara",
 			expectedName:  "cherry",
-			expectedError: "",
 		},
 		{
 			name: "HTTPSSchemaWithGit",
@@ -50,9 +50,9 @@ func TestParseGitURL(t *testing.T) {
 			origin	https://github.com/moorara/cherry.git (fetch)
 			origin	https://github.com/moorara/cherry.git (push)
 			`,
+			expectedHost:  "github.com",
 			expectedOwner: "moorara",
 			expectedName:  "cherry",
-			expectedError: "",
 		},
 		{
 			name: "SSHSchema",
@@ -60,9 +60,9 @@ func TestParseGitURL(t *testing.T) {
 			origin	git@github.com:moorara/cherry (fetch)
 			origin	git@github.com:moorara/cherry (push)
 			`,
+			expectedHost:  "github.com",
 			expectedOwner: "moorara",
 			expectedName:  "cherry",
-			expectedError: "",
 		},
 		{
 			name: "SSHSchemaWithGit",
@@ -70,22 +70,44 @@ func TestParseGitURL(t *testing.T) {
 			origin	git@github.com:moorara/cherry.git (fetch)
 			origin	git@github.com:moorara/cherry.git (push)
 			`,
+			expectedHost:  "github.com",
 			expectedOwner: "moorara",
 			expectedName:  "cherry",
-			expectedError: "",
+		},
+		{
+			name: "HTTPSSchemaWithSubgroup",
+			output: `
+			origin	https://gitlab.com/group/subgroup/project.git (fetch)
+			origin	https://gitlab.com/group/subgroup/project.git (push)
+			`,
+			expectedHost:  "gitlab.com",
+			expectedOwner: "group/subgroup",
+			expectedName:  "project",
+		},
+		{
+			name: "SSHSchemaWithSubgroup",
+			output: `
+			origin	git@gitlab.example.com:group/subgroup/project.git (fetch)
+			origin	git@gitlab.example.com:group/subgroup/project.git (push)
+			`,
+			expectedHost:  "gitlab.example.com",
+			expectedOwner: "group/subgroup",
+			expectedName:  "project",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			owner, name, err := parseGitURL(tc.output)
+			host, owner, name, err := parseGitURL(tc.output)
 
 			if tc.expectedError != "" {
 				assert.Equal(t, tc.expectedError, err.Error())
+				assert.Empty(t, host)
 				assert.Empty(t, owner)
 				assert.Empty(t, name)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedHost, host)
 				assert.Equal(t, tc.expectedOwner, owner)
 				assert.Equal(t, tc.expectedName, name)
 			}
@@ -699,6 +721,234 @@ func TestGitGetHEADRevert(t *testing.T) {
 	}
 }
 
+func TestGitLatestTagMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "Error",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitLatestTag{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestGitLatestTagDry(t *testing.T) {
+	tests := []struct {
+		name          string
+		workDir       string
+		expectedError string
+	}{
+		{
+			name:          "Error",
+			workDir:       os.TempDir(),
+			expectedError: `GitLatestTag.Dry: exit status 128 fatal: not a git repository (or any of the parent directories): .git`,
+		},
+		{
+			name:    "Success",
+			workDir: ".",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitLatestTag{
+				WorkDir: tc.workDir,
+			}
+
+			ctx := context.Background()
+			err := step.Dry(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLatestTagRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cherry-git-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=octocat", "-c", "user.email=octocat@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	ctx := context.Background()
+
+	t.Run("Error", func(t *testing.T) {
+		step := GitLatestTag{WorkDir: os.TempDir()}
+		err := step.Run(ctx)
+		assert.EqualError(t, err, `GitLatestTag.Run: exit status 128 fatal: not a git repository (or any of the parent directories): .git`)
+	})
+
+	t.Run("NoTag", func(t *testing.T) {
+		step := GitLatestTag{WorkDir: dir}
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, "", step.Result.Tag)
+	})
+
+	git("tag", "-a", "v0.1.0", "-m", "Version 0.1.0")
+	git("commit", "-q", "--allow-empty", "-m", "Beginning 0.1.1-0")
+	git("tag", "v0.1.1")
+	git("commit", "-q", "--allow-empty", "-m", "Fix")
+
+	t.Run("Success", func(t *testing.T) {
+		step := GitLatestTag{WorkDir: dir}
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, "v0.1.1", step.Result.Tag)
+	})
+
+	git("tag", "sdk/v1.3.0")
+	git("commit", "-q", "--allow-empty", "-m", "Fix SDK")
+
+	t.Run("Match", func(t *testing.T) {
+		step := GitLatestTag{WorkDir: dir, Match: "v*"}
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, "v0.1.1", step.Result.Tag)
+
+		step = GitLatestTag{WorkDir: dir, Match: "sdk/*"}
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, "sdk/v1.3.0", step.Result.Tag)
+
+		step = GitLatestTag{WorkDir: dir, Match: "tools/*"}
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, "", step.Result.Tag)
+	})
+}
+
+func TestGitChangedFilesMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "Error",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitChangedFiles{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestGitChangedFilesRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cherry-git-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=octocat", "-c", "user.email=octocat@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "Initial commit")
+	git("tag", "sdk/v1.3.0")
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sdk"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sdk", "VERSION"), []byte("1.3.1-0\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("# Hello\n"), 0644))
+	git("add", ".")
+	git("commit", "-q", "-m", "Update")
+
+	ctx := context.Background()
+
+	t.Run("InvalidRevision", func(t *testing.T) {
+		step := GitChangedFiles{WorkDir: dir, Since: "tools/v0.1.0"}
+		err := step.Dry(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "GitChangedFiles.Dry: ")
+
+		err = step.Run(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "GitChangedFiles.Run: ")
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		step := GitChangedFiles{WorkDir: dir, Since: "sdk/v1.3.0"}
+		assert.NoError(t, step.Dry(ctx))
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, []string{"README.md", "sdk/VERSION"}, step.Result.Files)
+		assert.NoError(t, step.Revert(ctx))
+	})
+}
+
 func TestGitAddMock(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -1610,3 +1860,252 @@ func TestGitPullRevert(t *testing.T) {
 		})
 	}
 }
+
+// newBareRepo creates a bare repository with one commit on master branch and returns its path.
+// The identity for committing is set using environment variables.
+func newBareRepo(t *testing.T) string {
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		os.Setenv(name, "octocat")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		os.Setenv(name, "octocat@example.com")
+	}
+
+	dir, err := ioutil.TempDir("", "cherry-test-")
+	assert.NoError(t, err)
+
+	remote := filepath.Join(dir, "tap.git")
+	local := filepath.Join(dir, "tap")
+
+	git := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	git(dir, "init", "--bare", remote)
+	git(remote, "symbolic-ref", "HEAD", "refs/heads/master")
+	git(dir, "init", local)
+	git(local, "checkout", "-b", "master")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(local, "README.md"), []byte("# Tap\n"), 0644))
+	git(local, "add", "README.md")
+	git(local, "commit", "-m", "Initial commit")
+	git(local, "remote", "add", "origin", remote)
+	git(local, "push", "origin", "master")
+
+	return remote
+}
+
+// remoteFile returns the content of a file in the latest commit of a repository.
+func remoteFile(t *testing.T, repo, path string) (string, bool) {
+	cmd := exec.Command("git", "show", "HEAD:"+path)
+	cmd.Dir = repo
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+
+	return string(out), true
+}
+
+func TestGitPublishFilesMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "OK",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitPublishFiles{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestGitPublishFilesDry(t *testing.T) {
+	remote := newBareRepo(t)
+	defer os.RemoveAll(filepath.Dir(remote))
+
+	tests := []struct {
+		name          string
+		url           string
+		branch        string
+		expectedError bool
+	}{
+		{
+			name:          "NoRepo",
+			url:           filepath.Join(filepath.Dir(remote), "unknown.git"),
+			expectedError: true,
+		},
+		{
+			name:          "NoBranch",
+			url:           remote,
+			branch:        "main",
+			expectedError: true,
+		},
+		{
+			name: "Success",
+			url:  remote,
+		},
+		{
+			name:   "SuccessBranch",
+			url:    remote,
+			branch: "master",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitPublishFiles{
+				URL:    tc.url,
+				Branch: tc.branch,
+			}
+
+			ctx := context.Background()
+			err := step.Dry(ctx)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGitPublishFiles(t *testing.T) {
+	remote := newBareRepo(t)
+	defer os.RemoveAll(filepath.Dir(remote))
+
+	ctx := context.Background()
+
+	t.Run("NoRepo", func(t *testing.T) {
+		step := GitPublishFiles{
+			URL: filepath.Join(filepath.Dir(remote), "unknown.git"),
+			Files: []GitFile{
+				{Path: "Formula/app.rb", Content: []byte("class App < Formula\nend\n")},
+			},
+			Message: "app 0.1.0",
+		}
+
+		assert.Error(t, step.Run(ctx))
+		assert.NoError(t, step.Revert(ctx))
+	})
+
+	t.Run("CreateAndRevert", func(t *testing.T) {
+		step := GitPublishFiles{
+			URL:    remote,
+			Branch: "master",
+			Files: []GitFile{
+				{Path: "Formula/app.rb", Content: []byte("class App < Formula\nend\n")},
+			},
+			Message: "app 0.1.0",
+		}
+
+		assert.NoError(t, step.Run(ctx))
+		assert.NotEmpty(t, step.Result.Commit)
+
+		content, ok := remoteFile(t, remote, "Formula/app.rb")
+		assert.True(t, ok)
+		assert.Equal(t, "class App < Formula\nend\n", content)
+
+		assert.NoError(t, step.Revert(ctx))
+
+		_, ok = remoteFile(t, remote, "Formula/app.rb")
+		assert.False(t, ok)
+	})
+
+	t.Run("CreateManyAndRevert", func(t *testing.T) {
+		step := GitPublishFiles{
+			URL: remote,
+			Files: []GitFile{
+				{Path: "manifests/u/User/App/0.1.0/User.App.yaml", Content: []byte("ManifestType: version\n")},
+				{Path: "manifests/u/User/App/0.1.0/User.App.installer.yaml", Content: []byte("ManifestType: installer\n")},
+			},
+			Message: "User.App 0.1.0",
+		}
+
+		assert.NoError(t, step.Run(ctx))
+		assert.NotEmpty(t, step.Result.Commit)
+
+		content, ok := remoteFile(t, remote, "manifests/u/User/App/0.1.0/User.App.yaml")
+		assert.True(t, ok)
+		assert.Equal(t, "ManifestType: version\n", content)
+
+		content, ok = remoteFile(t, remote, "manifests/u/User/App/0.1.0/User.App.installer.yaml")
+		assert.True(t, ok)
+		assert.Equal(t, "ManifestType: installer\n", content)
+
+		assert.NoError(t, step.Revert(ctx))
+
+		_, ok = remoteFile(t, remote, "manifests/u/User/App/0.1.0/User.App.yaml")
+		assert.False(t, ok)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		step := GitPublishFiles{
+			URL: remote,
+			Files: []GitFile{
+				{Path: "README.md", Content: []byte("# Tap\n")},
+			},
+			Message: "Update README",
+		}
+
+		assert.NoError(t, step.Run(ctx))
+		assert.Empty(t, step.Result.Commit)
+		assert.NoError(t, step.Revert(ctx))
+	})
+
+	t.Run("UpdateAndRevert", func(t *testing.T) {
+		step := GitPublishFiles{
+			URL: remote,
+			Files: []GitFile{
+				{Path: "README.md", Content: []byte("# Homebrew Tap\n")},
+			},
+			Message: "Update README",
+		}
+
+		assert.NoError(t, step.Run(ctx))
+		assert.NotEmpty(t, step.Result.Commit)
+
+		content, _ := remoteFile(t, remote, "README.md")
+		assert.Equal(t, "# Homebrew Tap\n", content)
+
+		assert.NoError(t, step.Revert(ctx))
+
+		content, _ = remoteFile(t, remote, "README.md")
+		assert.Equal(t, "# Tap\n", content)
+	})
+}
@@ -0,0 +1,460 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	netURL "net/url"
+)
+
+type (
+	// GiteaReleaseData is used for creating or modifying a release.
+	GiteaReleaseData struct {
+		Name       string `json:"name"`
+		TagName    string `json:"tag_name"`
+		Target     string `json:"target_commitish"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+		Body       string `json:"body"`
+	}
+
+	// GiteaRelease represents a Gitea release.
+	GiteaRelease struct {
+		ID         int               `json:"id"`
+		Name       string            `json:"name"`
+		TagName    string            `json:"tag_name"`
+		Target     string            `json:"target_commitish"`
+		Draft      bool              `json:"draft"`
+		Prerelease bool              `json:"prerelease"`
+		Body       string            `json:"body"`
+		URL        string            `json:"url"`
+		HTMLURL    string            `json:"html_url"`
+		Assets     []GiteaAttachment `json:"assets"`
+	}
+
+	// GiteaAttachment represents an asset for a Gitea release.
+	GiteaAttachment struct {
+		ID            int    `json:"id"`
+		Name          string `json:"name"`
+		Size          int    `json:"size"`
+		DownloadCount int    `json:"download_count"`
+		UUID          string `json:"uuid"`
+		DownloadURL   string `json:"browser_download_url"`
+	}
+
+	// GiteaProtectedBranch represents the push settings of a Gitea branch protection.
+	GiteaProtectedBranch struct {
+		BranchName              string   `json:"branch_name"`
+		EnablePush              bool     `json:"enable_push"`
+		EnablePushWhitelist     bool     `json:"enable_push_whitelist"`
+		PushWhitelistUsernames  []string `json:"push_whitelist_usernames"`
+		PushWhitelistTeams      []string `json:"push_whitelist_teams"`
+		PushWhitelistDeployKeys bool     `json:"push_whitelist_deploy_keys"`
+	}
+)
+
+func createGiteaRequest(ctx context.Context, token, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "cherry")
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+func (r GiteaRelease) release() Release {
+	release := Release{
+		ID:         r.ID,
+		Name:       r.Name,
+		TagName:    r.TagName,
+		Target:     r.Target,
+		Draft:      r.Draft,
+		Prerelease: r.Prerelease,
+		Body:       r.Body,
+		URL:        r.HTMLURL,
+		Assets:     make([]ReleaseAsset, len(r.Assets)),
+	}
+
+	for i, a := range r.Assets {
+		release.Assets[i] = a.asset()
+	}
+
+	return release
+}
+
+func (a GiteaAttachment) asset() ReleaseAsset {
+	return ReleaseAsset{
+		ID:          a.ID,
+		Name:        a.Name,
+		Size:        a.Size,
+		DownloadURL: a.DownloadURL,
+	}
+}
+
+// GiteaProvider is the release provider for Gitea and Forgejo.
+// URL is the web URL of the Gitea instance and APIURL is the URL of its API.
+type GiteaProvider struct {
+	Client *http.Client
+	Token  string
+	URL    string
+	APIURL string
+	Repo   string
+
+	// protectedBranches keeps the settings of unprotected branches for protecting them again.
+	protectedBranches map[string]GiteaProtectedBranch
+}
+
+func (p *GiteaProvider) get(ctx context.Context, url string, v interface{}) (string, error) {
+	req, err := createGiteaRequest(ctx, p.Token, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return getPage(p.Client, req, v)
+}
+
+// send makes a request with in as the JSON body and decodes the response into out if it is not nil.
+func (p *GiteaProvider) send(ctx context.Context, method, url string, statusCode int, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf := new(bytes.Buffer)
+		_ = json.NewEncoder(buf).Encode(in)
+		body = buf
+	}
+
+	req, err := createGiteaRequest(ctx, p.Token, method, url, body)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != statusCode {
+		return newHTTPError(res)
+	}
+
+	if out != nil {
+		return json.NewDecoder(res.Body).Decode(out)
+	}
+
+	return nil
+}
+
+// Name returns the name of release provider.
+func (p *GiteaProvider) Name() string {
+	return ProviderGitea
+}
+
+// ListReleases returns the releases of the repository.
+// See https://try.gitea.io/api/swagger#/repository/repoListReleases
+func (p *GiteaProvider) ListReleases(ctx context.Context) ([]Release, error) {
+	releases := []Release{}
+	url := fmt.Sprintf("%s/repos/%s/releases?limit=50", p.APIURL, p.Repo)
+
+	// Follow the Link headers for the next pages
+	for url != "" {
+		var err error
+		giteaReleases := []GiteaRelease{}
+		if url, err = p.get(ctx, url, &giteaReleases); err != nil {
+			return nil, err
+		}
+
+		for _, r := range giteaReleases {
+			releases = append(releases, r.release())
+		}
+	}
+
+	return releases, nil
+}
+
+// GetLatestRelease returns the latest published release of the repository.
+// See https://try.gitea.io/api/swagger#/repository/repoGetLatestRelease
+func (p *GiteaProvider) GetLatestRelease(ctx context.Context) (Release, error) {
+	giteaRelease := GiteaRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", p.APIURL, p.Repo)
+	if _, err := p.get(ctx, url, &giteaRelease); err != nil {
+		return Release{}, err
+	}
+
+	return giteaRelease.release(), nil
+}
+
+// GetReleaseByTag returns the release for a tag.
+// See https://try.gitea.io/api/swagger#/repository/repoGetReleaseByTag
+func (p *GiteaProvider) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	giteaRelease := GiteaRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", p.APIURL, p.Repo, netURL.PathEscape(tag))
+	if _, err := p.get(ctx, url, &giteaRelease); err != nil {
+		return Release{}, err
+	}
+
+	return giteaRelease.release(), nil
+}
+
+// CreateRelease creates a new release.
+// See https://try.gitea.io/api/swagger#/repository/repoCreateRelease
+func (p *GiteaProvider) CreateRelease(ctx context.Context, data ReleaseData) (Release, error) {
+	giteaRelease := GiteaRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases", p.APIURL, p.Repo)
+	if err := p.send(ctx, "POST", url, 201, GiteaReleaseData(data), &giteaRelease); err != nil {
+		return Release{}, err
+	}
+
+	return giteaRelease.release(), nil
+}
+
+// EditRelease modifies an existing release.
+// See https://try.gitea.io/api/swagger#/repository/repoEditRelease
+func (p *GiteaProvider) EditRelease(ctx context.Context, release Release, data ReleaseData) (Release, error) {
+	giteaRelease := GiteaRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases/%d", p.APIURL, p.Repo, release.ID)
+	if err := p.send(ctx, "PATCH", url, 200, GiteaReleaseData(data), &giteaRelease); err != nil {
+		return Release{}, err
+	}
+
+	return giteaRelease.release(), nil
+}
+
+// DeleteRelease deletes an existing release.
+// See https://try.gitea.io/api/swagger#/repository/repoDeleteRelease
+func (p *GiteaProvider) DeleteRelease(ctx context.Context, release Release) error {
+	url := fmt.Sprintf("%s/repos/%s/releases/%d", p.APIURL, p.Repo, release.ID)
+	return p.send(ctx, "DELETE", url, 204, nil, nil)
+}
+
+// uploadAsset uploads a file as a multipart form file to a release.
+// See https://try.gitea.io/api/swagger#/repository/repoCreateReleaseAttachment
+func (p *GiteaProvider) uploadAsset(ctx context.Context, releaseID int, file string) (GiteaAttachment, error) {
+	assetPath := filepath.Clean(file)
+	assetName := filepath.Base(assetPath)
+
+	f, err := os.Open(assetPath)
+	if err != nil {
+		return GiteaAttachment{}, err
+	}
+	defer f.Close()
+
+	// Gitea expects the asset as a multipart form file
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		part, err := mw.CreateFormFile("attachment", assetName)
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	url := fmt.Sprintf("%s/repos/%s/releases/%d/assets?name=%s", p.APIURL, p.Repo, releaseID, netURL.QueryEscape(assetName))
+	req, err := createGiteaRequest(ctx, p.Token, "POST", url, pr)
+	if err != nil {
+		return GiteaAttachment{}, err
+	}
+
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return GiteaAttachment{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 201 {
+		return GiteaAttachment{}, newHTTPError(res)
+	}
+
+	asset := GiteaAttachment{}
+	if err = json.NewDecoder(res.Body).Decode(&asset); err != nil {
+		return GiteaAttachment{}, err
+	}
+
+	return asset, nil
+}
+
+// UploadAssets uploads files to a release.
+func (p *GiteaProvider) UploadAssets(ctx context.Context, release Release, files []string) ([]ReleaseAsset, error) {
+	assets := make([]ReleaseAsset, 0)
+
+	for _, file := range files {
+		asset, err := p.uploadAsset(ctx, release.ID, file)
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, asset.asset())
+	}
+
+	return assets, nil
+}
+
+// DeleteAssets deletes uploaded assets of a release.
+// See https://try.gitea.io/api/swagger#/repository/repoDeleteReleaseAttachment
+func (p *GiteaProvider) DeleteAssets(ctx context.Context, release Release, assets []ReleaseAsset) error {
+	for _, a := range assets {
+		url := fmt.Sprintf("%s/repos/%s/releases/%d/assets/%d", p.APIURL, p.Repo, release.ID, a.ID)
+		if err := p.send(ctx, "DELETE", url, 204, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DownloadAsset downloads an asset of a release and writes it to a local file.
+func (p *GiteaProvider) DownloadAsset(ctx context.Context, tag, assetName, filepath string) (int64, error) {
+	url := fmt.Sprintf("%s/%s/releases/download/%s/%s", p.URL, p.Repo, tag, assetName)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", p.Token))
+	req.Header.Set("User-Agent", "cherry")
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return 0, newHTTPError(res)
+	}
+
+	file, err := os.OpenFile(filepath, os.O_WRONLY, 0755)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return io.Copy(file, res.Body)
+}
+
+// getProtectedBranch returns the branch protection of a branch.
+// See https://try.gitea.io/api/swagger#/repository/repoGetBranchProtection
+func (p *GiteaProvider) getProtectedBranch(ctx context.Context, branch string) (GiteaProtectedBranch, error) {
+	protectedBranch := GiteaProtectedBranch{}
+	url := fmt.Sprintf("%s/repos/%s/branch_protections/%s", p.APIURL, p.Repo, netURL.PathEscape(branch))
+	if _, err := p.get(ctx, url, &protectedBranch); err != nil {
+		return GiteaProtectedBranch{}, err
+	}
+
+	return protectedBranch, nil
+}
+
+// editProtectedBranch updates the push settings of a branch protection.
+// See https://try.gitea.io/api/swagger#/repository/repoEditBranchProtection
+func (p *GiteaProvider) editProtectedBranch(ctx context.Context, branch string, settings GiteaProtectedBranch) error {
+	data := map[string]interface{}{
+		"enable_push":                settings.EnablePush,
+		"enable_push_whitelist":      settings.EnablePushWhitelist,
+		"push_whitelist_usernames":   settings.PushWhitelistUsernames,
+		"push_whitelist_teams":       settings.PushWhitelistTeams,
+		"push_whitelist_deploy_keys": settings.PushWhitelistDeployKeys,
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/branch_protections/%s", p.APIURL, p.Repo, netURL.PathEscape(branch))
+	return p.send(ctx, "PATCH", url, 200, data, nil)
+}
+
+// BranchProtected determines whether or not pushing to a branch is restricted.
+func (p *GiteaProvider) BranchProtected(ctx context.Context, branch string) (bool, error) {
+	protectedBranch, err := p.getProtectedBranch(ctx, branch)
+	if err != nil {
+		return false, err
+	}
+
+	return !protectedBranch.EnablePush || protectedBranch.EnablePushWhitelist, nil
+}
+
+// ProtectBranch restores the push settings of a branch that was unprotected before.
+func (p *GiteaProvider) ProtectBranch(ctx context.Context, branch string) error {
+	return p.editProtectedBranch(ctx, branch, p.protectedBranches[branch])
+}
+
+// currentUser returns the username of the authenticated user.
+// See https://try.gitea.io/api/swagger#/user/userGetCurrent
+func (p *GiteaProvider) currentUser(ctx context.Context) (string, error) {
+	user := struct {
+		Login string `json:"login"`
+	}{}
+
+	url := fmt.Sprintf("%s/user", p.APIURL)
+	if _, err := p.get(ctx, url, &user); err != nil {
+		return "", err
+	}
+
+	return user.Login, nil
+}
+
+// UnprotectBranch allows the authenticated user to push to a branch.
+// Gitea does not have an admin enforcement toggle for protected branches,
+// so the authenticated user is added to the push whitelist of the branch.
+// The current push settings of the branch are kept for protecting it again.
+func (p *GiteaProvider) UnprotectBranch(ctx context.Context, branch string) error {
+	protectedBranch, err := p.getProtectedBranch(ctx, branch)
+	if err != nil {
+		return err
+	}
+
+	// Everyone with write access can already push to the branch
+	unprotected := protectedBranch
+
+	if !protectedBranch.EnablePush || protectedBranch.EnablePushWhitelist {
+		username, err := p.currentUser(ctx)
+		if err != nil {
+			return err
+		}
+
+		unprotected = GiteaProtectedBranch{
+			BranchName:             branch,
+			EnablePush:             true,
+			EnablePushWhitelist:    true,
+			PushWhitelistUsernames: []string{username},
+		}
+
+		// Keep the whitelist only if pushing was enabled for it
+		if protectedBranch.EnablePush {
+			unprotected.PushWhitelistTeams = protectedBranch.PushWhitelistTeams
+			unprotected.PushWhitelistDeployKeys = protectedBranch.PushWhitelistDeployKeys
+			for _, u := range protectedBranch.PushWhitelistUsernames {
+				if u != username {
+					unprotected.PushWhitelistUsernames = append(unprotected.PushWhitelistUsernames, u)
+				}
+			}
+		}
+	}
+
+	if err := p.editProtectedBranch(ctx, branch, unprotected); err != nil {
+		return err
+	}
+
+	if p.protectedBranches == nil {
+		p.protectedBranches = map[string]GiteaProtectedBranch{}
+	}
+	p.protectedBranches[branch] = protectedBranch
+
+	return nil
+}
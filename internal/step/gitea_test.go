@@ -0,0 +1,682 @@
+package step
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGiteaProviderListReleases(t *testing.T) {
+	tests := []struct {
+		name             string
+		mockResponses    []mockHTTP
+		expectedError    string
+		expectedReleases []Release
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases", 401, ``},
+			},
+			expectedError: `GET /repos/username/repo/releases 401: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases", 200, `[
+					{ "id": 2, "name": "0.2.0", "tag_name": "v0.2.0", "draft": true },
+					{ "id": 1, "name": "0.1.0", "tag_name": "v0.1.0" }
+				]`},
+			},
+			expectedReleases: []Release{
+				{ID: 2, Name: "0.2.0", TagName: "v0.2.0", Draft: true, Assets: []ReleaseAsset{}},
+				{ID: 1, Name: "0.1.0", TagName: "v0.1.0", Assets: []ReleaseAsset{}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			releases, err := provider.ListReleases(context.Background())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedReleases, releases)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderGetLatestRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockResponses   []mockHTTP
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/latest", 404, ``},
+			},
+			expectedError: `GET /repos/username/repo/releases/latest 404: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/latest", 200, `{
+					"id": 1,
+					"name": "0.1.0",
+					"tag_name": "v0.1.0",
+					"target_commitish": "master",
+					"draft": false,
+					"prerelease": false,
+					"html_url": "https://gitea.com/username/repo/releases/tag/v0.1.0",
+					"assets": [
+						{ "id": 3, "name": "app-linux-amd64", "size": 39, "browser_download_url": "https://gitea.com/attachments/3" }
+					]
+				}`},
+			},
+			expectedRelease: Release{
+				ID:      1,
+				Name:    "0.1.0",
+				TagName: "v0.1.0",
+				Target:  "master",
+				URL:     "https://gitea.com/username/repo/releases/tag/v0.1.0",
+				Assets: []ReleaseAsset{
+					{ID: 3, Name: "app-linux-amd64", Size: 39, DownloadURL: "https://gitea.com/attachments/3"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			release, err := provider.GetLatestRelease(context.Background())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderCreateRelease(t *testing.T) {
+	tests := []struct {
+		name                string
+		mockResponses       []mockHTTP
+		releaseData         ReleaseData
+		expectedCreateError string
+		expectedDeleteError string
+		expectedRelease     Release
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"POST", "/repos/{owner}/{repo}/releases", 401, ``},
+				{"DELETE", "/repos/{owner}/{repo}/releases/{id}", 401, ``},
+			},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Draft:   true,
+			},
+			expectedCreateError: `POST /repos/username/repo/releases 401: `,
+			expectedDeleteError: `DELETE /repos/username/repo/releases/0 401: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"POST", "/repos/{owner}/{repo}/releases", 201, `{
+					"id": 2,
+					"name": "0.2.0",
+					"tag_name": "v0.2.0",
+					"target_commitish": "master",
+					"draft": true
+				}`},
+				{"DELETE", "/repos/{owner}/{repo}/releases/{id}", 204, ``},
+			},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Draft:   true,
+			},
+			expectedRelease: Release{
+				ID:      2,
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Draft:   true,
+				Assets:  []ReleaseAsset{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			ctx := context.Background()
+
+			release, err := provider.CreateRelease(ctx, tc.releaseData)
+			if tc.expectedCreateError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedCreateError, err.Error())
+			}
+
+			err = provider.DeleteRelease(ctx, release)
+			if tc.expectedDeleteError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedDeleteError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderEditRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockResponses   []mockHTTP
+		release         Release
+		releaseData     ReleaseData
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"PATCH", "/repos/{owner}/{repo}/releases/{id}", 401, ``},
+			},
+			release: Release{ID: 2},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+			},
+			expectedError: `PATCH /repos/username/repo/releases/2 401: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"PATCH", "/repos/{owner}/{repo}/releases/{id}", 200, `{
+					"id": 2,
+					"name": "0.2.0",
+					"tag_name": "v0.2.0",
+					"target_commitish": "master",
+					"draft": false,
+					"body": "comment"
+				}`},
+			},
+			release: Release{ID: 2},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Body:    "comment",
+			},
+			expectedRelease: Release{
+				ID:      2,
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Body:    "comment",
+				Assets:  []ReleaseAsset{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			release, err := provider.EditRelease(context.Background(), tc.release, tc.releaseData)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderUploadAssets(t *testing.T) {
+	tests := []struct {
+		name                string
+		mockResponses       []mockHTTP
+		files               []string
+		expectedUploadError string
+		expectedDeleteError string
+		expectedAssets      []ReleaseAsset
+	}{
+		{
+			name:                "NoFile",
+			mockResponses:       []mockHTTP{},
+			files:               []string{"./test/null"},
+			expectedUploadError: `open test/null: no such file or directory`,
+		},
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 403, ``},
+			},
+			files:               []string{"./test/asset"},
+			expectedUploadError: `POST /repos/username/repo/releases/2/assets 403: `,
+		},
+		{
+			name: "DeleteFails",
+			mockResponses: []mockHTTP{
+				{"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 201, `{ "id": 3, "name": "asset", "size": 39 }`},
+				{"DELETE", "/repos/{owner}/{repo}/releases/{id}/assets/{asset_id}", 404, ``},
+			},
+			files: []string{"./test/asset"},
+			expectedAssets: []ReleaseAsset{
+				{ID: 3, Name: "asset", Size: 39},
+			},
+			expectedDeleteError: `DELETE /repos/username/repo/releases/2/assets/3 404: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 201, `{ "id": 3, "name": "asset", "size": 39 }`},
+				{"DELETE", "/repos/{owner}/{repo}/releases/{id}/assets/{asset_id}", 204, ``},
+			},
+			files: []string{"./test/asset"},
+			expectedAssets: []ReleaseAsset{
+				{ID: 3, Name: "asset", Size: 39},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			ctx := context.Background()
+			release := Release{ID: 2}
+
+			assets, err := provider.UploadAssets(ctx, release, tc.files)
+			if tc.expectedUploadError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedUploadError, err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedAssets, assets)
+
+			err = provider.DeleteAssets(ctx, release, assets)
+			if tc.expectedDeleteError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedDeleteError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderDownloadAsset(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponses []mockHTTP
+		tag           string
+		assetName     string
+		expectedError string
+		expectedSize  int64
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/{owner}/{repo}/releases/download/{tag}/{asset}", 404, ``},
+			},
+			tag:           "v0.2.0",
+			assetName:     "cherry-linux-amd64",
+			expectedError: `GET /username/repo/releases/download/v0.2.0/cherry-linux-amd64 404: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/{owner}/{repo}/releases/download/{tag}/{asset}", 200, `file content`},
+			},
+			tag:          "v0.2.0",
+			assetName:    "cherry-linux-amd64",
+			expectedSize: 12,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				URL:    ts.URL,
+				Repo:   "username/repo",
+			}
+
+			tf, err := ioutil.TempFile("", "cherry-test-")
+			assert.NoError(t, err)
+			tf.Close()
+			defer os.Remove(tf.Name())
+
+			size, err := provider.DownloadAsset(context.Background(), tc.tag, tc.assetName, tf.Name())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedSize, size)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderBranchProtected(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockResponses     []mockHTTP
+		expectedError     string
+		expectedProtected bool
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 404, `{"message":"Not Found"}`},
+			},
+			expectedError: `GET /repos/username/repo/branch_protections/master 404: Not Found`,
+		},
+		{
+			name: "PushDisabled",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{ "branch_name": "master", "enable_push": false }`},
+			},
+			expectedProtected: true,
+		},
+		{
+			name: "PushWhitelist",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{ "branch_name": "master", "enable_push": true, "enable_push_whitelist": true }`},
+			},
+			expectedProtected: true,
+		},
+		{
+			name: "PushEnabled",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{ "branch_name": "master", "enable_push": true }`},
+			},
+			expectedProtected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			protected, err := provider.BranchProtected(context.Background(), "master")
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedProtected, protected)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGiteaProviderUnprotectBranch(t *testing.T) {
+	tests := []struct {
+		name                      string
+		mockResponses             []mockHTTP
+		expectedUnprotectError    string
+		expectedProtectedBranches map[string]GiteaProtectedBranch
+	}{
+		{
+			name: "GetFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 403, ``},
+			},
+			expectedUnprotectError: `GET /repos/username/repo/branch_protections/master 403: `,
+		},
+		{
+			name: "UserFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{ "branch_name": "master" }`},
+				{"GET", "/user", 401, ``},
+			},
+			expectedUnprotectError: `GET /user 401: `,
+		},
+		{
+			name: "EditFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{ "branch_name": "master" }`},
+				{"GET", "/user", 200, `{ "login": "cherry" }`},
+				{"PATCH", "/repos/{owner}/{repo}/branch_protections/{branch}", 403, ``},
+			},
+			expectedUnprotectError: `PATCH /repos/username/repo/branch_protections/master 403: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{
+					"branch_name": "master",
+					"enable_push": true,
+					"enable_push_whitelist": true,
+					"push_whitelist_usernames": ["octocat"]
+				}`},
+				{"GET", "/user", 200, `{ "login": "cherry" }`},
+				{"PATCH", "/repos/{owner}/{repo}/branch_protections/{branch}", 200, `{ "branch_name": "master" }`},
+			},
+			expectedProtectedBranches: map[string]GiteaProtectedBranch{
+				"master": {
+					BranchName:             "master",
+					EnablePush:             true,
+					EnablePushWhitelist:    true,
+					PushWhitelistUsernames: []string{"octocat"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			ctx := context.Background()
+
+			err := provider.UnprotectBranch(ctx, "master")
+			if tc.expectedUnprotectError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedUnprotectError, err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedProtectedBranches, provider.protectedBranches)
+
+			err = provider.ProtectBranch(ctx, "master")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGiteaProviderUnprotectBranchSettings(t *testing.T) {
+	tests := []struct {
+		name                string
+		protectedBranch     string
+		expectedUnprotected GiteaProtectedBranch
+		expectedProtected   GiteaProtectedBranch
+	}{
+		{
+			name:            "PushDisabled",
+			protectedBranch: `{ "branch_name": "master", "enable_push": false, "push_whitelist_usernames": ["octocat"] }`,
+			expectedUnprotected: GiteaProtectedBranch{
+				EnablePush:             true,
+				EnablePushWhitelist:    true,
+				PushWhitelistUsernames: []string{"cherry"},
+			},
+			expectedProtected: GiteaProtectedBranch{
+				EnablePush:             false,
+				PushWhitelistUsernames: []string{"octocat"},
+			},
+		},
+		{
+			name: "PushWhitelist",
+			protectedBranch: `{
+				"branch_name": "master",
+				"enable_push": true,
+				"enable_push_whitelist": true,
+				"push_whitelist_usernames": ["octocat", "cherry"],
+				"push_whitelist_teams": ["maintainers"],
+				"push_whitelist_deploy_keys": true
+			}`,
+			expectedUnprotected: GiteaProtectedBranch{
+				EnablePush:              true,
+				EnablePushWhitelist:     true,
+				PushWhitelistUsernames:  []string{"cherry", "octocat"},
+				PushWhitelistTeams:      []string{"maintainers"},
+				PushWhitelistDeployKeys: true,
+			},
+			expectedProtected: GiteaProtectedBranch{
+				EnablePush:              true,
+				EnablePushWhitelist:     true,
+				PushWhitelistUsernames:  []string{"octocat", "cherry"},
+				PushWhitelistTeams:      []string{"maintainers"},
+				PushWhitelistDeployKeys: true,
+			},
+		},
+		{
+			name:            "PushEnabled",
+			protectedBranch: `{ "branch_name": "master", "enable_push": true }`,
+			expectedUnprotected: GiteaProtectedBranch{
+				EnablePush: true,
+			},
+			expectedProtected: GiteaProtectedBranch{
+				EnablePush: true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			edits := []GiteaProtectedBranch{}
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == "GET" && r.URL.Path == "/user":
+					w.Write([]byte(`{ "login": "cherry" }`))
+				case r.Method == "GET":
+					w.Write([]byte(tc.protectedBranch))
+				case r.Method == "PATCH":
+					edit := GiteaProtectedBranch{}
+					assert.NoError(t, json.NewDecoder(r.Body).Decode(&edit))
+					edits = append(edits, edit)
+					w.Write([]byte(`{ "branch_name": "master" }`))
+				default:
+					w.WriteHeader(http.StatusMethodNotAllowed)
+				}
+			}))
+			defer ts.Close()
+
+			provider := &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				APIURL: ts.URL,
+				Repo:   "username/repo",
+			}
+
+			ctx := context.Background()
+			assert.NoError(t, provider.UnprotectBranch(ctx, "master"))
+			assert.NoError(t, provider.ProtectBranch(ctx, "master"))
+
+			assert.Equal(t, []GiteaProtectedBranch{tc.expectedUnprotected, tc.expectedProtected}, edits)
+		})
+	}
+}
@@ -3,26 +3,40 @@ package step
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	netURL "net/url"
+
+	"github.com/moorara/cherry/pkg/cui"
 )
 
 const (
+	defaultUploadConcurrency = 4
+	uploadMaxRetries         = 3
+
 	// GitHubURL is the BaseURL for GitHub.
 	GitHubURL = "https://github.com"
 
 	// GitHubAPIURL is the BaseURL for GitHub API.
 	GitHubAPIURL = "https://api.github.com"
+
+	// GitHubUploadURL is the BaseURL for uploading release assets to GitHub.
+	GitHubUploadURL = "https://uploads.github.com"
 )
 
+// uploadRetryBackoff is the initial wait time before retrying a failed upload.
+var uploadRetryBackoff = 2 * time.Second
+
 type (
 	// GitHubReleaseData is used for creating or modifying a release.
 	GitHubReleaseData struct {
@@ -61,34 +75,24 @@ type (
 		URL         string `json:"url"`
 		DownloadURL string `json:"browser_download_url"`
 	}
-)
-
-// httpError is an http error.
-type httpError struct {
-	Request    *http.Request
-	StatusCode int
-	Message    string
-}
 
-// newHTTPError creates a new instance of httpError.
-func newHTTPError(res *http.Response) *httpError {
-	err := &httpError{
-		Request:    res.Request,
-		StatusCode: res.StatusCode,
+	// GitHubContent represents a file in a GitHub repository.
+	GitHubContent struct {
+		Type     string `json:"type"`
+		Encoding string `json:"encoding"`
+		Path     string `json:"path"`
+		SHA      string `json:"sha"`
+		Content  string `json:"content"`
 	}
 
-	if res.Body != nil {
-		if data, e := ioutil.ReadAll(res.Body); e == nil {
-			err.Message = string(data)
-		}
+	// GitHubFileData is used for creating, updating, or deleting a file in a GitHub repository.
+	GitHubFileData struct {
+		Message string `json:"message"`
+		Content string `json:"content,omitempty"`
+		SHA     string `json:"sha,omitempty"`
+		Branch  string `json:"branch,omitempty"`
 	}
-
-	return err
-}
-
-func (e *httpError) Error() string {
-	return fmt.Sprintf("%s %s %d: %s", e.Request.Method, e.Request.URL.Path, e.StatusCode, e.Message)
-}
+)
 
 func createGitHubRequest(ctx context.Context, token, method, url string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, url, body)
@@ -97,7 +101,9 @@ func createGitHubRequest(ctx context.Context, token, method, url string, body io
 	}
 
 	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "cherry") // ref: https://developer.github.com/v3/#user-agent-required
 	req.Header.Set("Content-Type", "application/json")
@@ -475,8 +481,13 @@ func (s *GitHubEditRelease) Revert(ctx context.Context) error {
 }
 
 // GitHubUploadAssets uploads assets (files) to GitHub for a release.
+// Assets are uploaded concurrently and an existing asset with the same name will be replaced.
+// A replacing asset is uploaded under a temporary name and the existing asset is renamed aside while swapping them.
+// The existing assets are deleted only after all of them are swapped and they are restored if swapping fails.
+// Failed uploads are retried and if an asset cannot be uploaded, all uploaded assets will be deleted.
 // See https://developer.github.com/v3/repos/releases/#list-assets-for-a-release
 // See https://developer.github.com/v3/repos/releases/#upload-a-release-asset
+// See https://developer.github.com/v3/repos/releases/#update-a-release-asset
 // See https://developer.github.com/v3/repos/releases/#delete-a-release-asset
 type GitHubUploadAssets struct {
 	Mock             Step
@@ -487,6 +498,8 @@ type GitHubUploadAssets struct {
 	ReleaseID        int
 	ReleaseUploadURL string
 	AssetFiles       []string
+	Concurrency      int
+	UI               cui.CUI
 	Result           struct {
 		Assets []GitHubAsset
 	}
@@ -517,6 +530,163 @@ func (s *GitHubUploadAssets) Dry(ctx context.Context) error {
 	return nil
 }
 
+// list returns the assets of the release.
+func (s *GitHubUploadAssets) list(ctx context.Context) ([]GitHubAsset, error) {
+	assets := []GitHubAsset{}
+	url := fmt.Sprintf("%s/repos/%s/releases/%d/assets?per_page=100", s.BaseURL, s.Repo, s.ReleaseID)
+
+	// Follow the Link headers for the next pages
+	for url != "" {
+		req, err := createGitHubRequest(ctx, s.Token, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []GitHubAsset{}
+		if url, err = getPage(s.Client, req, &page); err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, page...)
+	}
+
+	return assets, nil
+}
+
+// delete deletes an asset of the release.
+func (s *GitHubUploadAssets) delete(ctx context.Context, id int) error {
+	url := fmt.Sprintf("%s/repos/%s/releases/assets/%d", s.BaseURL, s.Repo, id)
+	req, err := createGitHubRequest(ctx, s.Token, "DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 204 {
+		return newHTTPError(res)
+	}
+
+	return nil
+}
+
+// rename renames an asset of the release.
+func (s *GitHubUploadAssets) rename(ctx context.Context, id int, name string) (GitHubAsset, error) {
+	body := new(bytes.Buffer)
+	_ = json.NewEncoder(body).Encode(map[string]string{"name": name})
+
+	url := fmt.Sprintf("%s/repos/%s/releases/assets/%d", s.BaseURL, s.Repo, id)
+	req, err := createGitHubRequest(ctx, s.Token, "PATCH", url, body)
+	if err != nil {
+		return GitHubAsset{}, err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return GitHubAsset{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return GitHubAsset{}, newHTTPError(res)
+	}
+
+	asset := GitHubAsset{}
+	if err = json.NewDecoder(res.Body).Decode(&asset); err != nil {
+		return GitHubAsset{}, err
+	}
+
+	return asset, nil
+}
+
+// upload uploads a single file as an asset with the given name.
+// If transfer is not nil, the uploaded bytes are reported to it.
+func (s *GitHubUploadAssets) upload(ctx context.Context, assetPath, assetName string, transfer *cui.Transfer) (GitHubAsset, error) {
+	re := regexp.MustCompile(`\{\?[0-9A-Za-z_,]+\}`)
+	url := re.ReplaceAllLiteralString(s.ReleaseUploadURL, "")
+	url = fmt.Sprintf("%s?name=%s", url, netURL.QueryEscape(assetName))
+
+	content, err := getUploadContent(assetPath)
+	if err != nil {
+		return GitHubAsset{}, err
+	}
+	defer content.Body.Close()
+
+	var body io.Reader = content.Body
+	if transfer != nil {
+		body = transfer.Reader(body)
+	}
+
+	req, err := createGitHubRequest(ctx, s.Token, "POST", url, body)
+	if err != nil {
+		return GitHubAsset{}, err
+	}
+
+	req.Header.Set("Content-Type", content.MIMEType)
+	req.ContentLength = content.Length
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return GitHubAsset{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 201 {
+		return GitHubAsset{}, newHTTPError(res)
+	}
+
+	asset := GitHubAsset{}
+	if err = json.NewDecoder(res.Body).Decode(&asset); err != nil {
+		return GitHubAsset{}, err
+	}
+
+	return asset, nil
+}
+
+// uploadWithRetry uploads a single file as an asset and retries if the upload fails due to a transient error.
+// A partially uploaded asset from a failed attempt is deleted before retrying.
+func (s *GitHubUploadAssets) uploadWithRetry(ctx context.Context, assetPath, assetName string, progress *cui.Progress) (GitHubAsset, error) {
+	var transfer *cui.Transfer
+	if progress != nil {
+		size := int64(-1)
+		if stat, err := os.Stat(assetPath); err == nil {
+			size = stat.Size()
+		}
+
+		transfer = progress.Add(filepath.Base(assetPath), size)
+		defer transfer.Done()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if transfer != nil {
+			transfer.Reset()
+		}
+
+		asset, err := s.upload(ctx, assetPath, assetName, transfer)
+		if err == nil || attempt >= uploadMaxRetries || !isRetryable(err) {
+			return asset, err
+		}
+
+		if assets, e := s.list(ctx); e == nil {
+			for _, a := range assets {
+				if a.Name == assetName {
+					_ = s.delete(ctx, a.ID)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return GitHubAsset{}, ctx.Err()
+		case <-time.After(uploadRetryBackoff << uint(attempt)):
+		}
+	}
+}
+
 // Run executes the step
 func (s *GitHubUploadAssets) Run(ctx context.Context) error {
 	if s.Mock != nil {
@@ -525,67 +695,189 @@ func (s *GitHubUploadAssets) Run(ctx context.Context) error {
 
 	s.Result.Assets = make([]GitHubAsset, 0)
 
-	doneCh := make(chan error, len(s.AssetFiles))
+	existing, err := s.list(ctx)
+	if err != nil {
+		return fmt.Errorf("GitHubUploadAssets.Run: %s", err)
+	}
 
+	// An asset replacing an existing one is uploaded under a temporary name first
+	uploadNames := map[string]string{}
 	for _, file := range s.AssetFiles {
-		go func(file string) {
-			assetPath := filepath.Clean(file)
-			assetName := filepath.Base(assetPath)
-
-			method := "POST"
-			re := regexp.MustCompile(`\{\?[0-9A-Za-z_,]+\}`)
-			url := re.ReplaceAllLiteralString(s.ReleaseUploadURL, "")
-			url = fmt.Sprintf("%s?name=%s", url, netURL.QueryEscape(assetName))
-
-			content, err := getUploadContent(assetPath)
-			if err != nil {
-				doneCh <- err
-				return
-			}
-			defer content.Body.Close()
+		name := filepath.Base(filepath.Clean(file))
+		uploadNames[name] = name
+	}
+
+	replaced := map[string]GitHubAsset{}
+	stale := map[string]bool{}
+	for _, asset := range existing {
+		if _, ok := uploadNames[asset.Name]; ok {
+			tmpName := asset.Name + ".tmp"
+			uploadNames[asset.Name] = tmpName
+			replaced[tmpName] = asset
+			stale[tmpName] = true
+			stale[asset.Name+".old"] = true
+		}
+	}
+
+	names := map[string]bool{}
+	for _, name := range uploadNames {
+		names[name] = true
+	}
 
-			req, err := createGitHubRequest(ctx, s.Token, method, url, content.Body)
-			if err != nil {
-				doneCh <- err
-				return
+	// Delete temporary assets left over from a previous failed run
+	for _, asset := range existing {
+		if stale[asset.Name] {
+			if err := s.delete(ctx, asset.ID); err != nil {
+				return fmt.Errorf("GitHubUploadAssets.Run: %s", err)
 			}
+		}
+	}
+
+	concurrency := s.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultUploadConcurrency
+	}
 
-			req.Header.Set("Content-Type", content.MIMEType)
-			req.ContentLength = content.Length
+	var progress *cui.Progress
+	if s.UI != nil {
+		progress = cui.NewProgress(s.UI, "Uploading")
+	}
 
-			res, err := s.Client.Do(req)
-			if err != nil {
-				doneCh <- err
-				return
-			}
-			defer res.Body.Close()
+	// Uploads are canceled as soon as one of them fails
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-			if res.StatusCode != 201 {
-				doneCh <- newHTTPError(res)
-				return
-			}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var uploadErr error
+
+	fileCh := make(chan string)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range fileCh {
+				file = filepath.Clean(file)
+				asset, err := s.uploadWithRetry(uploadCtx, file, uploadNames[filepath.Base(file)], progress)
 
-			asset := GitHubAsset{}
-			err = json.NewDecoder(res.Body).Decode(&asset)
-			if err != nil {
-				doneCh <- err
-				return
+				mu.Lock()
+				if err != nil {
+					if uploadErr == nil {
+						uploadErr = err
+						cancel()
+					}
+				} else {
+					s.Result.Assets = append(s.Result.Assets, asset)
+				}
+				mu.Unlock()
 			}
+		}()
+	}
+
+	for _, file := range s.AssetFiles {
+		select {
+		case fileCh <- file:
+		case <-uploadCtx.Done():
+		}
+	}
+
+	close(fileCh)
+	wg.Wait()
 
-			s.Result.Assets = append(s.Result.Assets, asset)
-			doneCh <- nil
-		}(file)
+	if progress != nil {
+		progress.Finish()
 	}
 
-	for range s.AssetFiles {
-		if err := <-doneCh; err != nil {
-			return fmt.Errorf("GitHubUploadAssets.Run: %s", err)
+	if uploadErr != nil {
+		s.cleanup(ctx, names)
+		return fmt.Errorf("GitHubUploadAssets.Run: %s", uploadErr)
+	}
+
+	if err := s.replace(ctx, replaced); err != nil {
+		return fmt.Errorf("GitHubUploadAssets.Run: %s", err)
+	}
+
+	return nil
+}
+
+// assetSwap is an existing asset that is being replaced by an uploaded asset.
+type assetSwap struct {
+	index    int
+	tmpName  string
+	existing GitHubAsset
+}
+
+// replace swaps the existing assets with the uploaded ones.
+// An existing asset is renamed aside first and the uploaded asset is renamed to its name.
+// The existing assets are deleted once all of them are swapped, otherwise the swapped ones are restored.
+func (s *GitHubUploadAssets) replace(ctx context.Context, replaced map[string]GitHubAsset) error {
+	swaps := []assetSwap{}
+
+	for i, asset := range s.Result.Assets {
+		existing, ok := replaced[asset.Name]
+		if !ok {
+			continue
 		}
+
+		aside, err := s.rename(ctx, existing.ID, existing.Name+".old")
+		if err != nil {
+			s.restore(ctx, swaps)
+			return err
+		}
+
+		swaps = append(swaps, assetSwap{i, asset.Name, aside})
+
+		renamed, err := s.rename(ctx, asset.ID, existing.Name)
+		if err != nil {
+			s.restore(ctx, swaps)
+			return err
+		}
+
+		s.Result.Assets[i] = renamed
+	}
+
+	// A leftover asset is deleted on the next run, so failing to delete it does not fail the step
+	for _, swap := range swaps {
+		_ = s.delete(ctx, swap.existing.ID)
 	}
 
 	return nil
 }
 
+// restore renames back the swapped assets after a failure.
+// The uploaded assets get their temporary names back, so they can be deleted on revert.
+func (s *GitHubUploadAssets) restore(ctx context.Context, swaps []assetSwap) {
+	for i := len(swaps) - 1; i >= 0; i-- {
+		swap := swaps[i]
+		name := strings.TrimSuffix(swap.existing.Name, ".old")
+
+		if asset := s.Result.Assets[swap.index]; asset.Name == name {
+			if renamed, err := s.rename(ctx, asset.ID, swap.tmpName); err == nil {
+				s.Result.Assets[swap.index] = renamed
+			}
+		}
+
+		_, _ = s.rename(ctx, swap.existing.ID, name)
+	}
+}
+
+// cleanup deletes the uploaded and partially uploaded assets after a failure.
+func (s *GitHubUploadAssets) cleanup(ctx context.Context, names map[string]bool) {
+	assets, err := s.list(ctx)
+	if err != nil {
+		assets = s.Result.Assets
+	}
+
+	for _, asset := range assets {
+		if names[asset.Name] {
+			_ = s.delete(ctx, asset.ID)
+		}
+	}
+
+	s.Result.Assets = nil
+}
+
 // Revert reverts back an executed step
 func (s *GitHubUploadAssets) Revert(ctx context.Context) error {
 	if s.Mock != nil {
@@ -593,22 +885,9 @@ func (s *GitHubUploadAssets) Revert(ctx context.Context) error {
 	}
 
 	for _, asset := range s.Result.Assets {
-		method := "DELETE"
-		url := fmt.Sprintf("%s/repos/%s/releases/assets/%d", s.BaseURL, s.Repo, asset.ID)
-		req, err := createGitHubRequest(ctx, s.Token, method, url, nil)
-		if err != nil {
-			return err
-		}
-
-		res, err := s.Client.Do(req)
-		if err != nil {
+		if err := s.delete(ctx, asset.ID); err != nil {
 			return fmt.Errorf("GitHubUploadAssets.Revert: %s", err)
 		}
-		defer res.Body.Close()
-
-		if res.StatusCode != 204 {
-			return fmt.Errorf("GitHubUploadAssets.Revert: %s", newHTTPError(res))
-		}
 	}
 
 	return nil
@@ -665,12 +944,13 @@ type GitHubDownloadAsset struct {
 	Tag       string
 	AssetName string
 	Filepath  string
+	UI        cui.CUI
 	Result    struct {
 		Size int64
 	}
 }
 
-func (s *GitHubDownloadAsset) makeRequest(ctx context.Context) (io.ReadCloser, error) {
+func (s *GitHubDownloadAsset) makeRequest(ctx context.Context) (*http.Response, error) {
 	url := fmt.Sprintf("%s/%s/releases/download/%s/%s", s.BaseURL, s.Repo, s.Tag, s.AssetName)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -678,7 +958,9 @@ func (s *GitHubDownloadAsset) makeRequest(ctx context.Context) (io.ReadCloser, e
 	}
 
 	req = req.WithContext(ctx)
-	req.Header.Set("Authorization", fmt.Sprintf("token %s", s.Token))
+	if s.Token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", s.Token))
+	}
 	req.Header.Set("User-Agent", "cherry") // ref: https://developer.github.com/v3/#user-agent-required
 
 	res, err := s.Client.Do(req)
@@ -690,7 +972,7 @@ func (s *GitHubDownloadAsset) makeRequest(ctx context.Context) (io.ReadCloser, e
 		return nil, newHTTPError(res)
 	}
 
-	return res.Body, nil
+	return res, nil
 }
 
 // Dry is a dry run of the step.
@@ -699,11 +981,11 @@ func (s *GitHubDownloadAsset) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
-	body, err := s.makeRequest(ctx)
+	res, err := s.makeRequest(ctx)
 	if err != nil {
 		return fmt.Errorf("GitHubDownloadAsset.Dry: %s", err)
 	}
-	defer body.Close()
+	defer res.Body.Close()
 
 	return nil
 }
@@ -714,22 +996,36 @@ func (s *GitHubDownloadAsset) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
-	body, err := s.makeRequest(ctx)
+	res, err := s.makeRequest(ctx)
 	if err != nil {
 		return fmt.Errorf("GitHubDownloadAsset.Run: %s", err)
 	}
-	defer body.Close()
+	defer res.Body.Close()
 
 	file, err := os.OpenFile(s.Filepath, os.O_WRONLY, 0755)
 	if err != nil {
 		return fmt.Errorf("GitHubDownloadAsset.Run: %s", err)
 	}
 
+	var body io.Reader = res.Body
+	var progress *cui.Progress
+	var transfer *cui.Transfer
+	if s.UI != nil {
+		progress = cui.NewProgress(s.UI, "Downloading")
+		transfer = progress.Add(s.AssetName, res.ContentLength)
+		body = transfer.Reader(body)
+	}
+
 	size, err := io.Copy(file, body)
 	if err != nil {
 		return fmt.Errorf("GitHubDownloadAsset.Run: %s", err)
 	}
 
+	if progress != nil {
+		transfer.Done()
+		progress.Finish()
+	}
+
 	s.Result.Size = size
 
 	return nil
@@ -748,3 +1044,482 @@ func (s *GitHubDownloadAsset) Revert(ctx context.Context) error {
 
 	return nil
 }
+
+// GitHubUpdateFile creates or updates a file in a GitHub repository using the contents API.
+// If the file already has the same content, no commit is created.
+// See https://developer.github.com/v3/repos/contents/#get-contents
+// See https://developer.github.com/v3/repos/contents/#create-or-update-a-file
+// See https://developer.github.com/v3/repos/contents/#delete-a-file
+type GitHubUpdateFile struct {
+	Mock    Step
+	Client  *http.Client
+	Token   string
+	BaseURL string
+	Repo    string
+	Branch  string
+	Path    string
+	Content []byte
+	Message string
+	Result  struct {
+		Created bool
+		// Previous is the content of file before updating it.
+		Previous []byte
+		// SHA is the blob SHA of the created or updated file.
+		SHA    string
+		Commit string
+	}
+}
+
+func (s *GitHubUpdateFile) url() string {
+	return fmt.Sprintf("%s/repos/%s/contents/%s", s.BaseURL, s.Repo, s.Path)
+}
+
+// get returns the file in the repository and false if the file does not exist.
+func (s *GitHubUpdateFile) get(ctx context.Context) (GitHubContent, bool, error) {
+	url := s.url()
+	if s.Branch != "" {
+		url += "?ref=" + netURL.QueryEscape(s.Branch)
+	}
+
+	req, err := createGitHubRequest(ctx, s.Token, "GET", url, nil)
+	if err != nil {
+		return GitHubContent{}, false, err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return GitHubContent{}, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return GitHubContent{}, false, nil
+	}
+
+	if res.StatusCode != 200 {
+		return GitHubContent{}, false, newHTTPError(res)
+	}
+
+	content := GitHubContent{}
+	if err = json.NewDecoder(res.Body).Decode(&content); err != nil {
+		return GitHubContent{}, false, err
+	}
+
+	return content, true, nil
+}
+
+// send creates, updates, or deletes the file in the repository and returns the new blob and commit SHAs.
+func (s *GitHubUpdateFile) send(ctx context.Context, method string, data GitHubFileData) (string, string, error) {
+	body := new(bytes.Buffer)
+	_ = json.NewEncoder(body).Encode(data)
+	req, err := createGitHubRequest(ctx, s.Token, method, s.url(), body)
+	if err != nil {
+		return "", "", err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 && res.StatusCode != 201 {
+		return "", "", newHTTPError(res)
+	}
+
+	result := struct {
+		Content *GitHubContent `json:"content"`
+		Commit  struct {
+			SHA string `json:"sha"`
+		} `json:"commit"`
+	}{}
+
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", "", err
+	}
+
+	// The content is null when the file is deleted
+	var sha string
+	if result.Content != nil {
+		sha = result.Content.SHA
+	}
+
+	return sha, result.Commit.SHA, nil
+}
+
+// Dry is a dry run of the step.
+func (s *GitHubUpdateFile) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s", s.BaseURL, s.Repo)
+	req, err := createGitHubRequest(ctx, s.Token, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHubUpdateFile.Dry: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return fmt.Errorf("GitHubUpdateFile.Dry: %s", newHTTPError(res))
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *GitHubUpdateFile) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	file, exists, err := s.get(ctx)
+	if err != nil {
+		return fmt.Errorf("GitHubUpdateFile.Run: %s", err)
+	}
+
+	var previous []byte
+	if exists {
+		// The content is base64-encoded with line breaks
+		previous, err = base64.StdEncoding.DecodeString(strings.Replace(file.Content, "\n", "", -1))
+		if err != nil {
+			return fmt.Errorf("GitHubUpdateFile.Run: %s", err)
+		}
+
+		if bytes.Equal(previous, s.Content) {
+			return nil
+		}
+	}
+
+	sha, commit, err := s.send(ctx, "PUT", GitHubFileData{
+		Message: s.Message,
+		Content: base64.StdEncoding.EncodeToString(s.Content),
+		SHA:     file.SHA,
+		Branch:  s.Branch,
+	})
+
+	if err != nil {
+		return fmt.Errorf("GitHubUpdateFile.Run: %s", err)
+	}
+
+	s.Result.Created = !exists
+	s.Result.Previous = previous
+	s.Result.SHA = sha
+	s.Result.Commit = commit
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *GitHubUpdateFile) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	// The file has not been changed
+	if s.Result.SHA == "" {
+		return nil
+	}
+
+	data := GitHubFileData{
+		Message: fmt.Sprintf("Revert %q", s.Message),
+		SHA:     s.Result.SHA,
+		Branch:  s.Branch,
+	}
+
+	var err error
+	if s.Result.Created {
+		_, _, err = s.send(ctx, "DELETE", data)
+	} else {
+		data.Content = base64.StdEncoding.EncodeToString(s.Result.Previous)
+		_, _, err = s.send(ctx, "PUT", data)
+	}
+
+	if err != nil {
+		return fmt.Errorf("GitHubUpdateFile.Revert: %s", err)
+	}
+
+	return nil
+}
+
+func (r GitHubRelease) release() Release {
+	release := Release{
+		ID:         r.ID,
+		Name:       r.Name,
+		TagName:    r.TagName,
+		Target:     r.Target,
+		Draft:      r.Draft,
+		Prerelease: r.Prerelease,
+		Body:       r.Body,
+		URL:        r.HTMLURL,
+		UploadURL:  r.UploadURL,
+		Assets:     make([]ReleaseAsset, len(r.Assets)),
+	}
+
+	for i, a := range r.Assets {
+		release.Assets[i] = a.asset()
+	}
+
+	return release
+}
+
+func (a GitHubAsset) asset() ReleaseAsset {
+	return ReleaseAsset{
+		ID:          a.ID,
+		Name:        a.Name,
+		Size:        a.Size,
+		DownloadURL: a.DownloadURL,
+	}
+}
+
+// GitHubProvider is the release provider for GitHub.
+type GitHubProvider struct {
+	Client    *http.Client
+	Token     string
+	URL       string
+	APIURL    string
+	UploadURL string
+	Repo      string
+	// UI is used for reporting the progress of uploads and downloads if set.
+	UI cui.CUI
+
+	// tokenSource provides access tokens when authenticating as a GitHub App.
+	tokenSource tokenSource
+}
+
+// AccessToken returns the token for accessing GitHub API.
+func (p *GitHubProvider) AccessToken(ctx context.Context) (string, error) {
+	if p.tokenSource != nil {
+		return p.tokenSource.Token(ctx)
+	}
+
+	return p.Token, nil
+}
+
+func (p *GitHubProvider) get(ctx context.Context, url string, v interface{}) (string, error) {
+	req, err := createGitHubRequest(ctx, p.Token, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return getPage(p.Client, req, v)
+}
+
+// Name returns the name of release provider.
+func (p *GitHubProvider) Name() string {
+	return ProviderGitHub
+}
+
+// ListReleases returns the releases of the repository.
+// See https://developer.github.com/v3/repos/releases/#list-releases-for-a-repository
+func (p *GitHubProvider) ListReleases(ctx context.Context) ([]Release, error) {
+	releases := []Release{}
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=100", p.APIURL, p.Repo)
+
+	// Follow the Link headers for the next pages
+	for url != "" {
+		var err error
+		githubReleases := []GitHubRelease{}
+		if url, err = p.get(ctx, url, &githubReleases); err != nil {
+			return nil, err
+		}
+
+		for _, r := range githubReleases {
+			releases = append(releases, r.release())
+		}
+	}
+
+	return releases, nil
+}
+
+// GetLatestRelease returns the latest published release of the repository.
+func (p *GitHubProvider) GetLatestRelease(ctx context.Context) (Release, error) {
+	s := &GitHubGetLatestRelease{
+		Client:  p.Client,
+		Token:   p.Token,
+		BaseURL: p.APIURL,
+		Repo:    p.Repo,
+	}
+
+	if err := s.Run(ctx); err != nil {
+		return Release{}, err
+	}
+
+	return s.Result.LatestRelease.release(), nil
+}
+
+// GetReleaseByTag returns the release for a tag.
+// See https://developer.github.com/v3/repos/releases/#get-a-release-by-tag-name
+func (p *GitHubProvider) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	githubRelease := GitHubRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", p.APIURL, p.Repo, tag)
+	if _, err := p.get(ctx, url, &githubRelease); err != nil {
+		return Release{}, err
+	}
+
+	return githubRelease.release(), nil
+}
+
+// CreateRelease creates a new release.
+func (p *GitHubProvider) CreateRelease(ctx context.Context, data ReleaseData) (Release, error) {
+	s := &GitHubCreateRelease{
+		Client:      p.Client,
+		Token:       p.Token,
+		BaseURL:     p.APIURL,
+		Repo:        p.Repo,
+		ReleaseData: GitHubReleaseData(data),
+	}
+
+	if err := s.Run(ctx); err != nil {
+		return Release{}, err
+	}
+
+	return s.Result.Release.release(), nil
+}
+
+// EditRelease modifies an existing release.
+func (p *GitHubProvider) EditRelease(ctx context.Context, release Release, data ReleaseData) (Release, error) {
+	s := &GitHubEditRelease{
+		Client:      p.Client,
+		Token:       p.Token,
+		BaseURL:     p.APIURL,
+		Repo:        p.Repo,
+		ReleaseID:   release.ID,
+		ReleaseData: GitHubReleaseData(data),
+	}
+
+	if err := s.Run(ctx); err != nil {
+		return Release{}, err
+	}
+
+	return s.Result.Release.release(), nil
+}
+
+// DeleteRelease deletes an existing release.
+func (p *GitHubProvider) DeleteRelease(ctx context.Context, release Release) error {
+	s := &GitHubCreateRelease{
+		Client:  p.Client,
+		Token:   p.Token,
+		BaseURL: p.APIURL,
+		Repo:    p.Repo,
+	}
+
+	s.Result.Release.ID = release.ID
+
+	return s.Revert(ctx)
+}
+
+// UploadAssets uploads files to a release.
+func (p *GitHubProvider) UploadAssets(ctx context.Context, release Release, files []string) ([]ReleaseAsset, error) {
+	// GitHub Enterprise Server may be behind a proxy with a different upload url.
+	if p.UploadURL != "" {
+		release.UploadURL = fmt.Sprintf("%s/repos/%s/releases/%d/assets{?name,label}", p.UploadURL, p.Repo, release.ID)
+	}
+
+	s := &GitHubUploadAssets{
+		Client:           p.Client,
+		Token:            p.Token,
+		BaseURL:          p.APIURL,
+		Repo:             p.Repo,
+		ReleaseID:        release.ID,
+		ReleaseUploadURL: release.UploadURL,
+		AssetFiles:       files,
+		UI:               p.UI,
+	}
+
+	if err := s.Run(ctx); err != nil {
+		return nil, err
+	}
+
+	assets := make([]ReleaseAsset, len(s.Result.Assets))
+	for i, a := range s.Result.Assets {
+		assets[i] = a.asset()
+	}
+
+	return assets, nil
+}
+
+// DeleteAssets deletes uploaded assets of a release.
+func (p *GitHubProvider) DeleteAssets(ctx context.Context, release Release, assets []ReleaseAsset) error {
+	s := &GitHubUploadAssets{
+		Client:    p.Client,
+		Token:     p.Token,
+		BaseURL:   p.APIURL,
+		Repo:      p.Repo,
+		ReleaseID: release.ID,
+	}
+
+	for _, a := range assets {
+		s.Result.Assets = append(s.Result.Assets, GitHubAsset{ID: a.ID, Name: a.Name})
+	}
+
+	return s.Revert(ctx)
+}
+
+// DownloadAsset downloads an asset of a release and writes it to a local file.
+func (p *GitHubProvider) DownloadAsset(ctx context.Context, tag, assetName, filepath string) (int64, error) {
+	s := &GitHubDownloadAsset{
+		Client:    p.Client,
+		Token:     p.Token,
+		BaseURL:   p.URL,
+		Repo:      p.Repo,
+		Tag:       tag,
+		AssetName: assetName,
+		Filepath:  filepath,
+		UI:        p.UI,
+	}
+
+	if err := s.Run(ctx); err != nil {
+		return 0, err
+	}
+
+	return s.Result.Size, nil
+}
+
+// BranchProtected determines whether or not the branch protection is enforced for administrators.
+// See https://developer.github.com/v3/repos/branches/#get-admin-enforcement-of-protected-branch
+func (p *GitHubProvider) BranchProtected(ctx context.Context, branch string) (bool, error) {
+	enforcement := struct {
+		Enabled bool `json:"enabled"`
+	}{}
+
+	url := fmt.Sprintf("%s/repos/%s/branches/%s/protection/enforce_admins", p.APIURL, p.Repo, branch)
+	if _, err := p.get(ctx, url, &enforcement); err != nil {
+		return false, err
+	}
+
+	return enforcement.Enabled, nil
+}
+
+// ProtectBranch enables the branch protection for administrators.
+func (p *GitHubProvider) ProtectBranch(ctx context.Context, branch string) error {
+	s := &GitHubBranchProtection{
+		Client:  p.Client,
+		Token:   p.Token,
+		BaseURL: p.APIURL,
+		Repo:    p.Repo,
+		Branch:  branch,
+		Enabled: true,
+	}
+
+	return s.Run(ctx)
+}
+
+// UnprotectBranch disables the branch protection for administrators.
+func (p *GitHubProvider) UnprotectBranch(ctx context.Context, branch string) error {
+	s := &GitHubBranchProtection{
+		Client:  p.Client,
+		Token:   p.Token,
+		BaseURL: p.APIURL,
+		Repo:    p.Repo,
+		Branch:  branch,
+		Enabled: false,
+	}
+
+	return s.Run(ctx)
+}
@@ -2,14 +2,17 @@ package step
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
-	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -23,8 +26,9 @@ type mockHTTP struct {
 }
 
 func createMockHTTPServer(mocks ...mockHTTP) *httptest.Server {
-	r := mux.NewRouter()
+	r := mux.NewRouter().UseEncodedPath()
 	for _, m := range mocks {
+		m := m
 		r.Methods(m.Method).Path(m.Path).HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 			w.WriteHeader(m.StatusCode)
 			w.Write([]byte(m.ResponseBody))
@@ -34,60 +38,6 @@ func createMockHTTPServer(mocks ...mockHTTP) *httptest.Server {
 	return httptest.NewServer(r)
 }
 
-func TestHTTPError(t *testing.T) {
-	tests := []struct {
-		name          string
-		request       *http.Request
-		statusCode    int
-		body          string
-		expectedError string
-	}{
-		{
-			"400",
-			&http.Request{
-				Method: "GET",
-				URL: &url.URL{
-					Path: "/",
-				},
-			},
-			http.StatusBadRequest,
-			"Invalid request",
-			"GET / 400: Invalid request",
-		},
-		{
-			"500",
-			&http.Request{
-				Method: "POST",
-				URL: &url.URL{
-					Path: "/",
-				},
-			},
-			http.StatusInternalServerError,
-			"Internal error",
-			"POST / 500: Internal error",
-		},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			br := strings.NewReader(tc.body)
-			rc := ioutil.NopCloser(br)
-
-			res := &http.Response{
-				Request:    tc.request,
-				StatusCode: tc.statusCode,
-				Body:       rc,
-			}
-
-			err := newHTTPError(res)
-			assert.Equal(t, tc.request, err.Request)
-			assert.Equal(t, tc.statusCode, err.StatusCode)
-			assert.Equal(t, tc.body, err.Message)
-			assert.Equal(t, tc.expectedError, err.Error())
-		})
-	}
-}
-
 func TestGitHubBranchProtectionMock(t *testing.T) {
 	tests := []struct {
 		name                string
@@ -1281,11 +1231,37 @@ func TestGitHubUploadAssetsRun(t *testing.T) {
 			releaseID:          2,
 			releaseUploadURL:   "/repos/username/repo/releases/2/assets{?name,label}",
 			assetFiles:         []string{"./test/asset"},
-			expectedErrorRegex: `GitHubUploadAssets.Run: Post /repos/username/repo/releases/2/assets?name=asset: unsupported protocol scheme ""`,
+			expectedErrorRegex: `GitHubUploadAssets.Run: Get /repos/username/repo/releases/2/assets?per_page=100: unsupported protocol scheme ""`,
+		},
+		{
+			name: "ListFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/{id}/assets", 401, `{"message": "Bad credentials"}`},
+			},
+			token:              "github-token",
+			repo:               "username/repo",
+			releaseID:          2,
+			releaseUploadURL:   "https://uploads.github.com/repos/username/repo/releases/2/assets{?name,label}",
+			assetFiles:         []string{"./test/asset"},
+			expectedErrorRegex: `GitHubUploadAssets.Run: GET /repos/username/repo/releases/2/assets 401: Bad credentials`,
+		},
+		{
+			name: "DeleteTemporaryFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/{id}/assets", 200, `[{"id": 9, "name": "asset"}, {"id": 8, "name": "asset.tmp"}]`},
+				{"DELETE", "/repos/{owner}/{repo}/releases/assets/{id}", 403, ``},
+			},
+			token:              "github-token",
+			repo:               "username/repo",
+			releaseID:          2,
+			releaseUploadURL:   "https://uploads.github.com/repos/username/repo/releases/2/assets{?name,label}",
+			assetFiles:         []string{"./test/asset"},
+			expectedErrorRegex: `GitHubUploadAssets.Run: DELETE /repos/username/repo/releases/assets/8 403: `,
 		},
 		{
 			name: "BadStatusCode",
 			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/{id}/assets", 200, `[]`},
 				{"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 403, ``},
 			},
 			token:              "github-token",
@@ -1295,9 +1271,41 @@ func TestGitHubUploadAssetsRun(t *testing.T) {
 			assetFiles:         []string{"./test/asset"},
 			expectedErrorRegex: `GitHubUploadAssets.Run: POST /repos/username/repo/releases/2/assets 403: `,
 		},
+		{
+			name: "RenameFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/{id}/assets", 200, `[{"id": 9, "name": "asset"}]`},
+				{"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 201, `{"id": 11, "name": "asset.tmp", "state": "uploaded"}`},
+				{"PATCH", "/repos/{owner}/{repo}/releases/assets/{id}", 500, ``},
+			},
+			token:              "github-token",
+			repo:               "username/repo",
+			releaseID:          2,
+			releaseUploadURL:   "https://uploads.github.com/repos/username/repo/releases/2/assets{?name,label}",
+			assetFiles:         []string{"./test/asset"},
+			expectedErrorRegex: `GitHubUploadAssets.Run: PATCH /repos/username/repo/releases/assets/9 500: `,
+		},
+		{
+			name: "ReplaceExisting",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/{id}/assets", 200, `[{"id": 9, "name": "asset"}, {"id": 10, "name": "other"}]`},
+				{"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 201, `{"id": 11, "name": "asset.tmp", "state": "uploaded"}`},
+				{"DELETE", "/repos/{owner}/{repo}/releases/assets/{id}", 204, ``},
+				{"PATCH", "/repos/{owner}/{repo}/releases/assets/{id}", 200, `{"id": 11, "name": "asset", "state": "uploaded"}`},
+			},
+			token:            "github-token",
+			repo:             "username/repo",
+			releaseID:        2,
+			releaseUploadURL: "https://uploads.github.com/repos/username/repo/releases/2/assets{?name,label}",
+			assetFiles:       []string{"./test/asset"},
+			expectedAssets: []GitHubAsset{
+				{ID: 11, Name: "asset", State: "uploaded"},
+			},
+		},
 		{
 			name: "Success",
 			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/releases/{id}/assets", 200, `[]`},
 				{
 					"POST", "/repos/{owner}/{repo}/releases/{id}/assets", 201, `{
 						"id": 1,
@@ -1351,6 +1359,7 @@ func TestGitHubUploadAssetsRun(t *testing.T) {
 
 			if tc.expectedErrorRegex == "" {
 				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedAssets, step.Result.Assets)
 			} else {
 				assert.Error(t, err)
 				assert.Equal(t, tc.expectedErrorRegex, err.Error())
@@ -1359,6 +1368,226 @@ func TestGitHubUploadAssetsRun(t *testing.T) {
 	}
 }
 
+func TestGitHubUploadAssetsRunRetry(t *testing.T) {
+	ui := &mockCUI{}
+	uploadRetryBackoff = time.Millisecond
+	defer func() { uploadRetryBackoff = 2 * time.Second }()
+
+	var mu sync.Mutex
+	var uploads int
+	names := []string{}
+	deleted := []string{}
+
+	r := mux.NewRouter()
+	r.Methods("GET").Path("/repos/username/repo/releases/2/assets").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if uploads == 0 {
+			w.Write([]byte(`[{"id": 7, "name": "asset", "state": "uploaded"}]`))
+		} else {
+			w.Write([]byte(`[{"id": 7, "name": "asset", "state": "uploaded"}, {"id": 8, "name": "asset.tmp", "state": "starter"}]`))
+		}
+	})
+	r.Methods("PATCH").Path("/repos/username/repo/releases/assets/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&in)
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		json.NewEncoder(w).Encode(GitHubAsset{ID: id, Name: in["name"], State: "uploaded"})
+	})
+	r.Methods("DELETE").Path("/repos/username/repo/releases/assets/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		deleted = append(deleted, mux.Vars(r)["id"])
+		mu.Unlock()
+		w.WriteHeader(204)
+	})
+	r.Methods("POST").Path("/repos/username/repo/releases/2/assets").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		uploads++
+		n := uploads
+		names = append(names, r.URL.Query().Get("name"))
+		mu.Unlock()
+
+		if n == 1 {
+			w.WriteHeader(502)
+			return
+		}
+		w.WriteHeader(201)
+		w.Write([]byte(`{"id": 9, "name": "asset.tmp", "state": "uploaded"}`))
+	})
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	step := &GitHubUploadAssets{
+		Client:           &http.Client{},
+		Token:            "github-token",
+		BaseURL:          ts.URL,
+		Repo:             "username/repo",
+		ReleaseID:        2,
+		ReleaseUploadURL: ts.URL + "/repos/username/repo/releases/2/assets{?name,label}",
+		AssetFiles:       []string{"./test/asset"},
+		UI:               ui,
+	}
+
+	err := step.Run(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []GitHubAsset{{ID: 9, Name: "asset", State: "uploaded"}}, step.Result.Assets)
+	assert.Equal(t, "Uploaded", ui.OutputfOutVals[0])
+	assert.Equal(t, 1, ui.OutputfOutVals[1])
+	assert.Equal(t, 2, uploads)
+	assert.Equal(t, []string{"asset.tmp", "asset.tmp"}, names)
+	// The partial asset is deleted before retrying and the existing asset is deleted after uploading
+	assert.Equal(t, []string{"8", "7"}, deleted)
+}
+
+func TestGitHubUploadAssetsRunCleanup(t *testing.T) {
+	var mu sync.Mutex
+	uploaded := []GitHubAsset{
+		{ID: 100, Name: "asset", State: "uploaded"},
+	}
+	deleted := []string{}
+
+	r := mux.NewRouter()
+	r.Methods("GET").Path("/repos/username/repo/releases/2/assets").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(uploaded)
+	})
+	r.Methods("DELETE").Path("/repos/username/repo/releases/assets/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		deleted = append(deleted, mux.Vars(r)["id"])
+		mu.Unlock()
+		w.WriteHeader(204)
+	})
+	r.Methods("POST").Path("/repos/username/repo/releases/2/assets").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		if name == "VERSION" {
+			w.WriteHeader(422)
+			w.Write([]byte(`{"message": "Validation Failed"}`))
+			return
+		}
+
+		mu.Lock()
+		asset := GitHubAsset{ID: len(uploaded), Name: name, State: "uploaded"}
+		uploaded = append(uploaded, asset)
+		mu.Unlock()
+
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(asset)
+	})
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	step := &GitHubUploadAssets{
+		Client:           &http.Client{},
+		Token:            "github-token",
+		BaseURL:          ts.URL,
+		Repo:             "username/repo",
+		ReleaseID:        2,
+		ReleaseUploadURL: ts.URL + "/repos/username/repo/releases/2/assets{?name,label}",
+		AssetFiles:       []string{"./test/asset", "./test/VERSION"},
+		Concurrency:      1,
+	}
+
+	err := step.Run(context.Background())
+	assert.EqualError(t, err, "GitHubUploadAssets.Run: POST /repos/username/repo/releases/2/assets 422: Validation Failed")
+	assert.Nil(t, step.Result.Assets)
+	// The existing asset is kept and only the asset uploaded under the temporary name is deleted
+	assert.Equal(t, []GitHubAsset{{ID: 100, Name: "asset", State: "uploaded"}, {ID: 1, Name: "asset.tmp", State: "uploaded"}}, uploaded)
+	assert.Equal(t, []string{"1"}, deleted)
+}
+
+func TestGitHubUploadAssetsRunReplace(t *testing.T) {
+	var mu sync.Mutex
+	nextID := 10
+	assets := map[int]string{1: "asset", 2: "VERSION"}
+
+	list := func() []GitHubAsset {
+		res := []GitHubAsset{}
+		for id, name := range assets {
+			res = append(res, GitHubAsset{ID: id, Name: name, State: "uploaded"})
+		}
+		return res
+	}
+
+	r := mux.NewRouter()
+	r.Methods("GET").Path("/repos/username/repo/releases/2/assets").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		json.NewEncoder(w).Encode(list())
+	})
+	r.Methods("POST").Path("/repos/username/repo/releases/2/assets").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		asset := GitHubAsset{ID: nextID, Name: r.URL.Query().Get("name"), State: "uploaded"}
+		assets[asset.ID] = asset.Name
+		nextID++
+		w.WriteHeader(201)
+		json.NewEncoder(w).Encode(asset)
+	})
+	r.Methods("PATCH").Path("/repos/username/repo/releases/assets/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		in := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&in)
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+		// Swapping the uploaded VERSION asset fails
+		if id >= 10 && in["name"] == "VERSION" {
+			w.WriteHeader(500)
+			return
+		}
+
+		for _, a := range list() {
+			if a.Name == in["name"] {
+				w.WriteHeader(422)
+				return
+			}
+		}
+
+		assets[id] = in["name"]
+		json.NewEncoder(w).Encode(GitHubAsset{ID: id, Name: in["name"], State: "uploaded"})
+	})
+	r.Methods("DELETE").Path("/repos/username/repo/releases/assets/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id, _ := strconv.Atoi(mux.Vars(r)["id"])
+		delete(assets, id)
+		w.WriteHeader(204)
+	})
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	step := &GitHubUploadAssets{
+		Client:           &http.Client{},
+		Token:            "github-token",
+		BaseURL:          ts.URL,
+		Repo:             "username/repo",
+		ReleaseID:        2,
+		ReleaseUploadURL: ts.URL + "/repos/username/repo/releases/2/assets{?name,label}",
+		AssetFiles:       []string{"./test/asset", "./test/VERSION"},
+		Concurrency:      1,
+	}
+
+	ctx := context.Background()
+	err := step.Run(ctx)
+	assert.EqualError(t, err, "GitHubUploadAssets.Run: PATCH /repos/username/repo/releases/assets/11 500: ")
+
+	// The existing assets are restored and the uploaded assets have their temporary names back
+	assert.Equal(t, map[int]string{1: "asset", 2: "VERSION", 10: "asset.tmp", 11: "VERSION.tmp"}, assets)
+	assert.Equal(t, []GitHubAsset{
+		{ID: 10, Name: "asset.tmp", State: "uploaded"},
+		{ID: 11, Name: "VERSION.tmp", State: "uploaded"},
+	}, step.Result.Assets)
+
+	err = step.Revert(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "asset", 2: "VERSION"}, assets)
+}
+
 func TestGitHubUploadAssetsRevert(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -1556,6 +1785,7 @@ func TestGitHubDownloadAssetRun(t *testing.T) {
 		repo          string
 		tag           string
 		assetName     string
+		ui            *mockCUI
 		expectedError string
 		expectedSize  int64
 	}{
@@ -1589,6 +1819,18 @@ func TestGitHubDownloadAssetRun(t *testing.T) {
 			assetName:    "cherry-linux-amd64",
 			expectedSize: 12,
 		},
+		{
+			name: "SuccessWithProgress",
+			mockResponses: []mockHTTP{
+				{"GET", "/{owner}/{repo}/releases/download/{tag}/{asset}", 200, `file content`},
+			},
+			token:        "github-token",
+			repo:         "username/repo",
+			tag:          "v0.2.0",
+			assetName:    "cherry-linux-amd64",
+			ui:           &mockCUI{},
+			expectedSize: 12,
+		},
 	}
 
 	for _, tc := range tests {
@@ -1601,6 +1843,10 @@ func TestGitHubDownloadAssetRun(t *testing.T) {
 				AssetName: tc.assetName,
 			}
 
+			if tc.ui != nil {
+				step.UI = tc.ui
+			}
+
 			if len(tc.mockResponses) > 0 {
 				ts := createMockHTTPServer(tc.mockResponses...)
 				defer ts.Close()
@@ -1621,6 +1867,10 @@ func TestGitHubDownloadAssetRun(t *testing.T) {
 			if tc.expectedError == "" {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.expectedSize, step.Result.Size)
+				if tc.ui != nil {
+					assert.Equal(t, "Downloaded", tc.ui.OutputfOutVals[0])
+					assert.Equal(t, "12 B", tc.ui.OutputfOutVals[2])
+				}
 			} else {
 				assert.Error(t, err)
 				assert.Equal(t, tc.expectedError, err.Error())
@@ -1675,3 +1925,282 @@ func TestGitHubDownloadAssetRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestGitHubUpdateFileMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "OK",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitHubUpdateFile{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestGitHubUpdateFileDry(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponses []mockHTTP
+		token         string
+		repo          string
+		expectedError string
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}", 404, `{ "message": "Not Found" }`},
+			},
+			token:         "github-token",
+			repo:          "username/homebrew-tap",
+			expectedError: `GitHubUpdateFile.Dry: GET /repos/username/homebrew-tap 404: Not Found`,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}", 200, `{ "full_name": "username/homebrew-tap" }`},
+			},
+			token: "github-token",
+			repo:  "username/homebrew-tap",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := &GitHubUpdateFile{
+				Client: &http.Client{},
+				Token:  tc.token,
+				Repo:   tc.repo,
+				Path:   "Formula/app.rb",
+			}
+
+			if len(tc.mockResponses) > 0 {
+				ts := createMockHTTPServer(tc.mockResponses...)
+				defer ts.Close()
+
+				step.BaseURL = ts.URL
+			}
+
+			ctx := context.Background()
+			err := step.Dry(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitHubUpdateFileRun(t *testing.T) {
+	tests := []struct {
+		name             string
+		mockResponses    []mockHTTP
+		content          string
+		expectedError    string
+		expectedCreated  bool
+		expectedPrevious string
+		expectedSHA      string
+		expectedCommit   string
+	}{
+		{
+			name: "GetFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/contents/Formula/app.rb", 403, `{ "message": "Forbidden" }`},
+			},
+			content:       "class App < Formula",
+			expectedError: `GitHubUpdateFile.Run: GET /repos/username/homebrew-tap/contents/Formula/app.rb 403: Forbidden`,
+		},
+		{
+			name: "InvalidContent",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/contents/Formula/app.rb", 200, `{ "sha": "aaaaaaa", "content": "invalid" }`},
+			},
+			content:       "class App < Formula",
+			expectedError: `GitHubUpdateFile.Run: illegal base64 data at input byte 4`,
+		},
+		{
+			name: "PutFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/contents/Formula/app.rb", 404, `{ "message": "Not Found" }`},
+				{"PUT", "/repos/{owner}/{repo}/contents/Formula/app.rb", 409, `{ "message": "Conflict" }`},
+			},
+			content:       "class App < Formula",
+			expectedError: `GitHubUpdateFile.Run: PUT /repos/username/homebrew-tap/contents/Formula/app.rb 409: Conflict`,
+		},
+		{
+			name: "Created",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/contents/Formula/app.rb", 404, `{ "message": "Not Found" }`},
+				{"PUT", "/repos/{owner}/{repo}/contents/Formula/app.rb", 201, `{ "content": { "sha": "bbbbbbb" }, "commit": { "sha": "ccccccc" } }`},
+			},
+			content:         "class App < Formula",
+			expectedCreated: true,
+			expectedSHA:     "bbbbbbb",
+			expectedCommit:  "ccccccc",
+		},
+		{
+			name: "Updated",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/contents/Formula/app.rb", 200, `{ "sha": "aaaaaaa", "encoding": "base64", "content": "Y2xhc3MgT2xk\nIDwgRm9ybXVsYQ==\n" }`},
+				{"PUT", "/repos/{owner}/{repo}/contents/Formula/app.rb", 200, `{ "content": { "sha": "bbbbbbb" }, "commit": { "sha": "ccccccc" } }`},
+			},
+			content:          "class App < Formula",
+			expectedPrevious: "class Old < Formula",
+			expectedSHA:      "bbbbbbb",
+			expectedCommit:   "ccccccc",
+		},
+		{
+			name: "Unchanged",
+			mockResponses: []mockHTTP{
+				{"GET", "/repos/{owner}/{repo}/contents/Formula/app.rb", 200, `{ "sha": "aaaaaaa", "encoding": "base64", "content": "Y2xhc3MgQXBw\nIDwgRm9ybXVsYQ==\n" }`},
+			},
+			content: "class App < Formula",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			step := &GitHubUpdateFile{
+				Client:  &http.Client{},
+				Token:   "github-token",
+				BaseURL: ts.URL,
+				Repo:    "username/homebrew-tap",
+				Branch:  "master",
+				Path:    "Formula/app.rb",
+				Content: []byte(tc.content),
+				Message: "app 0.1.0",
+			}
+
+			ctx := context.Background()
+			err := step.Run(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedCreated, step.Result.Created)
+				assert.Equal(t, tc.expectedPrevious, string(step.Result.Previous))
+				assert.Equal(t, tc.expectedSHA, step.Result.SHA)
+				assert.Equal(t, tc.expectedCommit, step.Result.Commit)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitHubUpdateFileRevert(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponses []mockHTTP
+		created       bool
+		sha           string
+		expectedError string
+	}{
+		{
+			name: "Unchanged",
+		},
+		{
+			name: "DeleteFails",
+			mockResponses: []mockHTTP{
+				{"DELETE", "/repos/{owner}/{repo}/contents/Formula/app.rb", 404, `{ "message": "Not Found" }`},
+			},
+			created:       true,
+			sha:           "bbbbbbb",
+			expectedError: `GitHubUpdateFile.Revert: DELETE /repos/username/homebrew-tap/contents/Formula/app.rb 404: Not Found`,
+		},
+		{
+			name: "DeleteSuccess",
+			mockResponses: []mockHTTP{
+				{"DELETE", "/repos/{owner}/{repo}/contents/Formula/app.rb", 200, `{ "content": null, "commit": { "sha": "ddddddd" } }`},
+			},
+			created: true,
+			sha:     "bbbbbbb",
+		},
+		{
+			name: "RestoreFails",
+			mockResponses: []mockHTTP{
+				{"PUT", "/repos/{owner}/{repo}/contents/Formula/app.rb", 409, `{ "message": "Conflict" }`},
+			},
+			sha:           "bbbbbbb",
+			expectedError: `GitHubUpdateFile.Revert: PUT /repos/username/homebrew-tap/contents/Formula/app.rb 409: Conflict`,
+		},
+		{
+			name: "RestoreSuccess",
+			mockResponses: []mockHTTP{
+				{"PUT", "/repos/{owner}/{repo}/contents/Formula/app.rb", 200, `{ "content": { "sha": "aaaaaaa" }, "commit": { "sha": "ddddddd" } }`},
+			},
+			sha: "bbbbbbb",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			step := &GitHubUpdateFile{
+				Client:  &http.Client{},
+				Token:   "github-token",
+				BaseURL: ts.URL,
+				Repo:    "username/homebrew-tap",
+				Branch:  "master",
+				Path:    "Formula/app.rb",
+				Content: []byte("class App < Formula"),
+				Message: "app 0.1.0",
+			}
+
+			step.Result.Created = tc.created
+			step.Result.Previous = []byte("class Old < Formula")
+			step.Result.SHA = tc.sha
+
+			ctx := context.Background()
+			err := step.Revert(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
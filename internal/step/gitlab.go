@@ -0,0 +1,562 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+
+	netURL "net/url"
+)
+
+const (
+	// GitLabAPIURL is the BaseURL for GitLab API.
+	GitLabAPIURL = "https://gitlab.com/api/v4"
+)
+
+type (
+	// GitLabReleaseData is used for creating a release.
+	GitLabReleaseData struct {
+		Name        string              `json:"name"`
+		TagName     string              `json:"tag_name"`
+		Ref         string              `json:"ref,omitempty"`
+		Description string              `json:"description"`
+		Assets      GitLabReleaseAssets `json:"assets"`
+	}
+
+	// GitLabRelease represents a GitLab release.
+	GitLabRelease struct {
+		Name        string              `json:"name"`
+		TagName     string              `json:"tag_name"`
+		Description string              `json:"description"`
+		CreatedAt   string              `json:"created_at"`
+		ReleasedAt  string              `json:"released_at"`
+		Assets      GitLabReleaseAssets `json:"assets"`
+	}
+
+	// GitLabReleaseAssets represents the assets of a GitLab release.
+	GitLabReleaseAssets struct {
+		Links []GitLabReleaseLink `json:"links"`
+	}
+
+	// GitLabReleaseLink represents an asset link for a GitLab release.
+	GitLabReleaseLink struct {
+		ID       int    `json:"id,omitempty"`
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		LinkType string `json:"link_type,omitempty"`
+	}
+
+	// GitLabPackageFile represents a file in a GitLab package.
+	GitLabPackageFile struct {
+		ID         int    `json:"id"`
+		PackageID  int    `json:"package_id"`
+		FileName   string `json:"file_name"`
+		Size       int    `json:"size"`
+		FileSHA256 string `json:"file_sha256"`
+	}
+
+	// GitLabAccessLevel represents an access level for a GitLab protected branch.
+	// An access level is either for a role or for a user, a group, or a deploy key.
+	GitLabAccessLevel struct {
+		AccessLevel            int    `json:"access_level"`
+		AccessLevelDescription string `json:"access_level_description"`
+		UserID                 int    `json:"user_id"`
+		GroupID                int    `json:"group_id"`
+		DeployKeyID            int    `json:"deploy_key_id"`
+	}
+
+	// GitLabProtectedBranch represents a GitLab protected branch.
+	GitLabProtectedBranch struct {
+		Name                      string              `json:"name"`
+		PushAccessLevels          []GitLabAccessLevel `json:"push_access_levels"`
+		MergeAccessLevels         []GitLabAccessLevel `json:"merge_access_levels"`
+		UnprotectAccessLevels     []GitLabAccessLevel `json:"unprotect_access_levels"`
+		AllowForcePush            bool                `json:"allow_force_push"`
+		CodeOwnerApprovalRequired bool                `json:"code_owner_approval_required"`
+	}
+)
+
+// gitlabProject returns the URL-encoded path of a GitLab project to be used as project id.
+// See https://docs.gitlab.com/ee/api/README.html#namespaced-path-encoding
+func gitlabProject(repo string) string {
+	return netURL.PathEscape(repo)
+}
+
+func createGitLabRequest(ctx context.Context, token, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("PRIVATE-TOKEN", token)
+	req.Header.Set("User-Agent", "cherry")
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+func (r GitLabRelease) release() Release {
+	release := Release{
+		Name:    r.Name,
+		TagName: r.TagName,
+		Body:    r.Description,
+		Assets:  make([]ReleaseAsset, len(r.Assets.Links)),
+	}
+
+	for i, l := range r.Assets.Links {
+		release.Assets[i] = ReleaseAsset{
+			ID:          l.ID,
+			Name:        l.Name,
+			DownloadURL: l.URL,
+		}
+	}
+
+	return release
+}
+
+// GitLabProvider is the release provider for GitLab.
+// GitLab does not support draft releases, so creating a draft release only keeps the release data
+// and the actual release is created when the release is edited to not be a draft anymore.
+// Assets are uploaded to the generic package registry of the project and linked to the release.
+type GitLabProvider struct {
+	Client *http.Client
+	Token  string
+	APIURL string
+	Repo   string
+
+	// links keeps the links to uploaded assets for each release tag.
+	links map[string][]GitLabReleaseLink
+	// packageFiles keeps the uploaded package files for each release tag.
+	packageFiles map[string][]GitLabPackageFile
+	// protectedBranches keeps the settings of unprotected branches for protecting them again.
+	protectedBranches map[string]GitLabProtectedBranch
+}
+
+func (p *GitLabProvider) get(ctx context.Context, url string, v interface{}) (string, error) {
+	req, err := createGitLabRequest(ctx, p.Token, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return getPage(p.Client, req, v)
+}
+
+// send makes a request with in as the JSON body and decodes the response into out if it is not nil.
+func (p *GitLabProvider) send(ctx context.Context, method, url string, statusCode int, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf := new(bytes.Buffer)
+		_ = json.NewEncoder(buf).Encode(in)
+		body = buf
+	}
+
+	req, err := createGitLabRequest(ctx, p.Token, method, url, body)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != statusCode {
+		return newHTTPError(res)
+	}
+
+	if out != nil {
+		return json.NewDecoder(res.Body).Decode(out)
+	}
+
+	return nil
+}
+
+// listReleases gets a list of releases.
+// If allPages is true, the Link headers will be followed for the next pages.
+func (p *GitLabProvider) listReleases(ctx context.Context, url string, allPages bool) ([]Release, error) {
+	releases := []Release{}
+
+	for url != "" {
+		gitlabReleases := []GitLabRelease{}
+		next, err := p.get(ctx, url, &gitlabReleases)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range gitlabReleases {
+			releases = append(releases, r.release())
+		}
+
+		if url = next; !allPages {
+			break
+		}
+	}
+
+	return releases, nil
+}
+
+// Name returns the name of release provider.
+func (p *GitLabProvider) Name() string {
+	return ProviderGitLab
+}
+
+// ListReleases returns the releases of the repository.
+// See https://docs.gitlab.com/ee/api/releases/#list-releases
+func (p *GitLabProvider) ListReleases(ctx context.Context) ([]Release, error) {
+	url := fmt.Sprintf("%s/projects/%s/releases?per_page=100", p.APIURL, gitlabProject(p.Repo))
+	return p.listReleases(ctx, url, true)
+}
+
+// GetLatestRelease returns the latest published release of the repository.
+// Releases are sorted by their release dates in descending order.
+// See https://docs.gitlab.com/ee/api/releases/#list-releases
+func (p *GitLabProvider) GetLatestRelease(ctx context.Context) (Release, error) {
+	url := fmt.Sprintf("%s/projects/%s/releases?per_page=1", p.APIURL, gitlabProject(p.Repo))
+	releases, err := p.listReleases(ctx, url, false)
+	if err != nil {
+		return Release{}, err
+	}
+
+	if len(releases) == 0 {
+		return Release{}, fmt.Errorf("no release found for %s", p.Repo)
+	}
+
+	return releases[0], nil
+}
+
+// GetReleaseByTag returns the release for a tag.
+// See https://docs.gitlab.com/ee/api/releases/#get-a-release-by-a-tag-name
+func (p *GitLabProvider) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	gitlabRelease := GitLabRelease{}
+	url := fmt.Sprintf("%s/projects/%s/releases/%s", p.APIURL, gitlabProject(p.Repo), netURL.PathEscape(tag))
+	if _, err := p.get(ctx, url, &gitlabRelease); err != nil {
+		return Release{}, err
+	}
+
+	return gitlabRelease.release(), nil
+}
+
+// CreateRelease creates a new release.
+// If the release is a draft, it will be only kept locally.
+// See https://docs.gitlab.com/ee/api/releases/#create-a-release
+func (p *GitLabProvider) CreateRelease(ctx context.Context, data ReleaseData) (Release, error) {
+	if data.Draft {
+		return Release{
+			Name:       data.Name,
+			TagName:    data.TagName,
+			Target:     data.Target,
+			Draft:      true,
+			Prerelease: data.Prerelease,
+			Body:       data.Body,
+		}, nil
+	}
+
+	releaseData := GitLabReleaseData{
+		Name:        data.Name,
+		TagName:     data.TagName,
+		Ref:         data.Target,
+		Description: data.Body,
+		Assets: GitLabReleaseAssets{
+			Links: p.links[data.TagName],
+		},
+	}
+
+	gitlabRelease := GitLabRelease{}
+	url := fmt.Sprintf("%s/projects/%s/releases", p.APIURL, gitlabProject(p.Repo))
+	if err := p.send(ctx, "POST", url, 201, releaseData, &gitlabRelease); err != nil {
+		return Release{}, err
+	}
+
+	return gitlabRelease.release(), nil
+}
+
+// EditRelease modifies an existing release.
+// If the release is a draft and it is not a draft anymore, it will be created.
+// See https://docs.gitlab.com/ee/api/releases/#update-a-release
+func (p *GitLabProvider) EditRelease(ctx context.Context, release Release, data ReleaseData) (Release, error) {
+	if release.Draft {
+		return p.CreateRelease(ctx, data)
+	}
+
+	releaseData := map[string]string{
+		"name":        data.Name,
+		"description": data.Body,
+	}
+
+	gitlabRelease := GitLabRelease{}
+	url := fmt.Sprintf("%s/projects/%s/releases/%s", p.APIURL, gitlabProject(p.Repo), netURL.PathEscape(release.TagName))
+	if err := p.send(ctx, "PUT", url, 200, releaseData, &gitlabRelease); err != nil {
+		return Release{}, err
+	}
+
+	return gitlabRelease.release(), nil
+}
+
+// DeleteRelease deletes an existing release.
+// Draft releases are only kept locally, so there is nothing to delete.
+// See https://docs.gitlab.com/ee/api/releases/#delete-a-release
+func (p *GitLabProvider) DeleteRelease(ctx context.Context, release Release) error {
+	if release.Draft {
+		return nil
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/releases/%s", p.APIURL, gitlabProject(p.Repo), netURL.PathEscape(release.TagName))
+	return p.send(ctx, "DELETE", url, 200, nil, nil)
+}
+
+// uploadAsset uploads a file to the generic package registry.
+// It returns the uploaded package file and a link to it for adding to a release.
+// See https://docs.gitlab.com/ee/user/packages/generic_packages/#publish-a-package-file
+func (p *GitLabProvider) uploadAsset(ctx context.Context, packageName, packageVersion, file string) (GitLabPackageFile, GitLabReleaseLink, error) {
+	assetPath := filepath.Clean(file)
+	assetName := filepath.Base(assetPath)
+
+	url := fmt.Sprintf("%s/projects/%s/packages/generic/%s/%s/%s",
+		p.APIURL,
+		gitlabProject(p.Repo),
+		netURL.PathEscape(packageName),
+		netURL.PathEscape(packageVersion),
+		netURL.PathEscape(assetName),
+	)
+
+	content, err := getUploadContent(assetPath)
+	if err != nil {
+		return GitLabPackageFile{}, GitLabReleaseLink{}, err
+	}
+	defer content.Body.Close()
+
+	req, err := createGitLabRequest(ctx, p.Token, "PUT", url+"?select=package_file", content.Body)
+	if err != nil {
+		return GitLabPackageFile{}, GitLabReleaseLink{}, err
+	}
+
+	req.Header.Set("Content-Type", content.MIMEType)
+	req.ContentLength = content.Length
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return GitLabPackageFile{}, GitLabReleaseLink{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 201 {
+		return GitLabPackageFile{}, GitLabReleaseLink{}, newHTTPError(res)
+	}
+
+	packageFile := GitLabPackageFile{}
+	if err = json.NewDecoder(res.Body).Decode(&packageFile); err != nil {
+		return GitLabPackageFile{}, GitLabReleaseLink{}, err
+	}
+
+	link := GitLabReleaseLink{
+		Name:     assetName,
+		URL:      url,
+		LinkType: "package",
+	}
+
+	return packageFile, link, nil
+}
+
+// UploadAssets uploads files to the generic package registry.
+// The package is named after the project and versioned after the release name.
+// The uploaded files will be linked to the release when it is created.
+func (p *GitLabProvider) UploadAssets(ctx context.Context, release Release, files []string) ([]ReleaseAsset, error) {
+	if p.links == nil {
+		p.links = map[string][]GitLabReleaseLink{}
+		p.packageFiles = map[string][]GitLabPackageFile{}
+	}
+
+	assets := make([]ReleaseAsset, 0)
+
+	for _, file := range files {
+		packageFile, link, err := p.uploadAsset(ctx, path.Base(p.Repo), release.Name, file)
+		if err != nil {
+			return nil, err
+		}
+
+		p.links[release.TagName] = append(p.links[release.TagName], link)
+		p.packageFiles[release.TagName] = append(p.packageFiles[release.TagName], packageFile)
+
+		assets = append(assets, ReleaseAsset{
+			ID:          packageFile.ID,
+			Name:        packageFile.FileName,
+			Size:        packageFile.Size,
+			DownloadURL: link.URL,
+		})
+	}
+
+	return assets, nil
+}
+
+// DeleteAssets deletes uploaded files of a release from the generic package registry.
+// See https://docs.gitlab.com/ee/api/packages.html#delete-a-package-file
+func (p *GitLabProvider) DeleteAssets(ctx context.Context, release Release, assets []ReleaseAsset) error {
+	ids := map[int]bool{}
+	for _, a := range assets {
+		ids[a.ID] = true
+	}
+
+	var links []GitLabReleaseLink
+	var packageFiles []GitLabPackageFile
+
+	for i, f := range p.packageFiles[release.TagName] {
+		if !ids[f.ID] {
+			links = append(links, p.links[release.TagName][i])
+			packageFiles = append(packageFiles, f)
+			continue
+		}
+
+		url := fmt.Sprintf("%s/projects/%s/packages/%d/package_files/%d", p.APIURL, gitlabProject(p.Repo), f.PackageID, f.ID)
+		if err := p.send(ctx, "DELETE", url, 204, nil, nil); err != nil {
+			return err
+		}
+	}
+
+	if p.links != nil {
+		p.links[release.TagName] = links
+		p.packageFiles[release.TagName] = packageFiles
+	}
+
+	return nil
+}
+
+// DownloadAsset downloads an asset linked to a release and writes it to a local file.
+func (p *GitLabProvider) DownloadAsset(ctx context.Context, tag, assetName, filepath string) (int64, error) {
+	release, err := p.GetReleaseByTag(ctx, tag)
+	if err != nil {
+		return 0, err
+	}
+
+	asset, ok := findAsset(release, assetName)
+	if !ok {
+		return 0, fmt.Errorf("asset %s not found for release %s", assetName, tag)
+	}
+
+	req, err := createGitLabRequest(ctx, p.Token, "GET", asset.DownloadURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return 0, newHTTPError(res)
+	}
+
+	file, err := os.OpenFile(filepath, os.O_WRONLY, 0755)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return io.Copy(file, res.Body)
+}
+
+// getProtectedBranch returns the settings of a protected branch.
+// See https://docs.gitlab.com/ee/api/protected_branches.html#get-a-single-protected-branch-or-wildcard-protected-branch
+func (p *GitLabProvider) getProtectedBranch(ctx context.Context, branch string) (GitLabProtectedBranch, error) {
+	protectedBranch := GitLabProtectedBranch{}
+	url := fmt.Sprintf("%s/projects/%s/protected_branches/%s", p.APIURL, gitlabProject(p.Repo), netURL.PathEscape(branch))
+	if _, err := p.get(ctx, url, &protectedBranch); err != nil {
+		return GitLabProtectedBranch{}, err
+	}
+
+	return protectedBranch, nil
+}
+
+// BranchProtected determines whether or not a branch is protected.
+func (p *GitLabProvider) BranchProtected(ctx context.Context, branch string) (bool, error) {
+	if _, err := p.getProtectedBranch(ctx, branch); err != nil {
+		if e, ok := err.(*httpError); ok && e.StatusCode == 404 {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// setAccessLevels sets the parameters for protecting a branch with a list of access levels.
+// A single role is set with the <action>_access_level parameter which is available in all GitLab tiers.
+// Otherwise, all access levels are set with the allowed_to_<action> parameter.
+func setAccessLevels(data map[string]interface{}, action string, levels []GitLabAccessLevel) {
+	if len(levels) == 0 {
+		return
+	}
+
+	if l := levels[0]; len(levels) == 1 && l.UserID == 0 && l.GroupID == 0 && l.DeployKeyID == 0 {
+		data[action+"_access_level"] = l.AccessLevel
+		return
+	}
+
+	allowed := make([]map[string]int, len(levels))
+	for i, l := range levels {
+		switch {
+		case l.UserID != 0:
+			allowed[i] = map[string]int{"user_id": l.UserID}
+		case l.GroupID != 0:
+			allowed[i] = map[string]int{"group_id": l.GroupID}
+		case l.DeployKeyID != 0:
+			allowed[i] = map[string]int{"deploy_key_id": l.DeployKeyID}
+		default:
+			allowed[i] = map[string]int{"access_level": l.AccessLevel}
+		}
+	}
+
+	data["allowed_to_"+action] = allowed
+}
+
+// ProtectBranch protects a branch again with the settings it had before being unprotected.
+// All access levels for pushing, merging, and unprotecting are restored.
+// See https://docs.gitlab.com/ee/api/protected_branches.html#protect-repository-branches
+func (p *GitLabProvider) ProtectBranch(ctx context.Context, branch string) error {
+	settings := p.protectedBranches[branch]
+
+	data := map[string]interface{}{
+		"name":                         branch,
+		"allow_force_push":             settings.AllowForcePush,
+		"code_owner_approval_required": settings.CodeOwnerApprovalRequired,
+	}
+
+	setAccessLevels(data, "push", settings.PushAccessLevels)
+	setAccessLevels(data, "merge", settings.MergeAccessLevels)
+	setAccessLevels(data, "unprotect", settings.UnprotectAccessLevels)
+
+	url := fmt.Sprintf("%s/projects/%s/protected_branches", p.APIURL, gitlabProject(p.Repo))
+	return p.send(ctx, "POST", url, 201, data, nil)
+}
+
+// UnprotectBranch unprotects a branch.
+// GitLab does not have an admin enforcement toggle for protected branches,
+// so the current settings of the branch are kept for protecting it again.
+// See https://docs.gitlab.com/ee/api/protected_branches.html#unprotect-repository-branches
+func (p *GitLabProvider) UnprotectBranch(ctx context.Context, branch string) error {
+	protectedBranch, err := p.getProtectedBranch(ctx, branch)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/projects/%s/protected_branches/%s", p.APIURL, gitlabProject(p.Repo), netURL.PathEscape(branch))
+	if err := p.send(ctx, "DELETE", url, 204, nil, nil); err != nil {
+		return err
+	}
+
+	if p.protectedBranches == nil {
+		p.protectedBranches = map[string]GitLabProtectedBranch{}
+	}
+	p.protectedBranches[branch] = protectedBranch
+
+	return nil
+}
@@ -0,0 +1,724 @@
+package step
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLabProviderGetLatestRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockResponses   []mockHTTP
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/releases", 401, `{"message":"401 Unauthorized"}`},
+			},
+			expectedError: `GET /projects/group/project/releases 401: 401 Unauthorized`,
+		},
+		{
+			name: "NoRelease",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/releases", 200, `[]`},
+			},
+			expectedError: `no release found for group/project`,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/releases", 200, `[
+					{ "name": "0.1.0", "tag_name": "v0.1.0", "description": "comment" }
+				]`},
+			},
+			expectedRelease: Release{
+				Name:    "0.1.0",
+				TagName: "v0.1.0",
+				Body:    "comment",
+				Assets:  []ReleaseAsset{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+			}
+
+			release, err := provider.GetLatestRelease(context.Background())
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLabProviderCreateRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockResponses   []mockHTTP
+		links           map[string][]GitLabReleaseLink
+		releaseData     ReleaseData
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "Draft",
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Draft:   true,
+			},
+			expectedRelease: Release{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Target:  "master",
+				Draft:   true,
+			},
+		},
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"POST", "/projects/{id}/releases", 409, `{"message":"Release already exists"}`},
+			},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+			},
+			expectedError: `POST /projects/group/project/releases 409: Release already exists`,
+		},
+		{
+			name: "InvalidResponse",
+			mockResponses: []mockHTTP{
+				{"POST", "/projects/{id}/releases", 201, `{`},
+			},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+			},
+			expectedError: `unexpected EOF`,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"POST", "/projects/{id}/releases", 201, `{
+					"name": "0.2.0",
+					"tag_name": "v0.2.0",
+					"description": "comment",
+					"created_at": "2019-11-01T12:00:00.000Z",
+					"released_at": "2019-11-01T12:00:00.000Z",
+					"assets": {
+						"links": [
+							{
+								"id": 1,
+								"name": "app-linux-amd64",
+								"url": "https://gitlab.com/api/v4/projects/group%2Fproject/packages/generic/project/0.2.0/app-linux-amd64",
+								"link_type": "package"
+							}
+						]
+					}
+				}`},
+			},
+			links: map[string][]GitLabReleaseLink{
+				"v0.2.0": {
+					{
+						Name:     "app-linux-amd64",
+						URL:      "https://gitlab.com/api/v4/projects/group%2Fproject/packages/generic/project/0.2.0/app-linux-amd64",
+						LinkType: "package",
+					},
+				},
+			},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+			},
+			expectedRelease: Release{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+				Assets: []ReleaseAsset{
+					{
+						ID:          1,
+						Name:        "app-linux-amd64",
+						DownloadURL: "https://gitlab.com/api/v4/projects/group%2Fproject/packages/generic/project/0.2.0/app-linux-amd64",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+				links:  tc.links,
+			}
+
+			release, err := provider.CreateRelease(context.Background(), tc.releaseData)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLabProviderEditRelease(t *testing.T) {
+	tests := []struct {
+		name            string
+		mockResponses   []mockHTTP
+		release         Release
+		releaseData     ReleaseData
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"PUT", "/projects/{id}/releases/{tag}", 403, ``},
+			},
+			release: Release{Name: "0.2.0", TagName: "v0.2.0"},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+			},
+			expectedError: `PUT /projects/group/project/releases/v0.2.0 403: `,
+		},
+		{
+			name: "Draft",
+			mockResponses: []mockHTTP{
+				{"POST", "/projects/{id}/releases", 201, `{ "name": "0.2.0", "tag_name": "v0.2.0", "description": "comment" }`},
+			},
+			release: Release{Name: "0.2.0", TagName: "v0.2.0", Draft: true},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+			},
+			expectedRelease: Release{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+				Assets:  []ReleaseAsset{},
+			},
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"PUT", "/projects/{id}/releases/{tag}", 200, `{ "name": "0.2.0", "tag_name": "v0.2.0", "description": "comment" }`},
+			},
+			release: Release{Name: "0.2.0", TagName: "v0.2.0"},
+			releaseData: ReleaseData{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+			},
+			expectedRelease: Release{
+				Name:    "0.2.0",
+				TagName: "v0.2.0",
+				Body:    "comment",
+				Assets:  []ReleaseAsset{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+			}
+
+			release, err := provider.EditRelease(context.Background(), tc.release, tc.releaseData)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLabProviderDeleteRelease(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockResponses []mockHTTP
+		release       Release
+		expectedError string
+	}{
+		{
+			name:    "Draft",
+			release: Release{TagName: "v0.2.0", Draft: true},
+		},
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"DELETE", "/projects/{id}/releases/{tag}", 403, ``},
+			},
+			release:       Release{TagName: "v0.2.0"},
+			expectedError: `DELETE /projects/group/project/releases/v0.2.0 403: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"DELETE", "/projects/{id}/releases/{tag}", 200, `{ "tag_name": "v0.2.0" }`},
+			},
+			release: Release{TagName: "v0.2.0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+			}
+
+			err := provider.DeleteRelease(context.Background(), tc.release)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLabProviderUploadAssets(t *testing.T) {
+	tests := []struct {
+		name                 string
+		mockResponses        []mockHTTP
+		files                []string
+		expectedUploadError  string
+		expectedDeleteError  string
+		expectedAssets       []ReleaseAsset
+		expectedPackageFiles []GitLabPackageFile
+	}{
+		{
+			name:                "NoFile",
+			files:               []string{"./test/null"},
+			expectedUploadError: `open test/null: no such file or directory`,
+		},
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"PUT", "/projects/{id}/packages/generic/{name}/{version}/{file}", 403, ``},
+			},
+			files:               []string{"./test/asset"},
+			expectedUploadError: `PUT /projects/group/project/packages/generic/project/0.2.0/asset 403: `,
+		},
+		{
+			name: "DeleteFails",
+			mockResponses: []mockHTTP{
+				{"PUT", "/projects/{id}/packages/generic/{name}/{version}/{file}", 201, `{
+					"id": 2,
+					"package_id": 1,
+					"file_name": "asset",
+					"size": 39,
+					"file_sha256": "0123456789abcdef"
+				}`},
+				{"DELETE", "/projects/{id}/packages/{package_id}/package_files/{file_id}", 403, ``},
+			},
+			files: []string{"./test/asset"},
+			expectedAssets: []ReleaseAsset{
+				{ID: 2, Name: "asset", Size: 39, DownloadURL: "/projects/group%2Fproject/packages/generic/project/0.2.0/asset"},
+			},
+			expectedPackageFiles: []GitLabPackageFile{
+				{ID: 2, PackageID: 1, FileName: "asset", Size: 39, FileSHA256: "0123456789abcdef"},
+			},
+			expectedDeleteError: `DELETE /projects/group/project/packages/1/package_files/2 403: `,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"PUT", "/projects/{id}/packages/generic/{name}/{version}/{file}", 201, `{
+					"id": 2,
+					"package_id": 1,
+					"file_name": "asset",
+					"size": 39,
+					"file_sha256": "0123456789abcdef"
+				}`},
+				{"DELETE", "/projects/{id}/packages/{package_id}/package_files/{file_id}", 204, ``},
+			},
+			files: []string{"./test/asset"},
+			expectedAssets: []ReleaseAsset{
+				{ID: 2, Name: "asset", Size: 39, DownloadURL: "/projects/group%2Fproject/packages/generic/project/0.2.0/asset"},
+			},
+			expectedPackageFiles: []GitLabPackageFile{
+				{ID: 2, PackageID: 1, FileName: "asset", Size: 39, FileSHA256: "0123456789abcdef"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+			}
+
+			ctx := context.Background()
+			release := Release{Name: "0.2.0", TagName: "v0.2.0"}
+
+			assets, err := provider.UploadAssets(ctx, release, tc.files)
+			if tc.expectedUploadError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedUploadError, err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			for i, asset := range assets {
+				assert.Equal(t, tc.expectedAssets[i].ID, asset.ID)
+				assert.Equal(t, tc.expectedAssets[i].Name, asset.Name)
+				assert.Equal(t, tc.expectedAssets[i].Size, asset.Size)
+				assert.Equal(t, ts.URL+tc.expectedAssets[i].DownloadURL, asset.DownloadURL)
+			}
+
+			assert.Equal(t, tc.expectedPackageFiles, provider.packageFiles["v0.2.0"])
+			for i, link := range provider.links["v0.2.0"] {
+				assert.Equal(t, tc.expectedAssets[i].Name, link.Name)
+				assert.Equal(t, "package", link.LinkType)
+				assert.Equal(t, ts.URL+tc.expectedAssets[i].DownloadURL, link.URL)
+			}
+
+			err = provider.DeleteAssets(ctx, release, assets)
+			if tc.expectedDeleteError == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, provider.packageFiles["v0.2.0"])
+				assert.Empty(t, provider.links["v0.2.0"])
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedDeleteError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLabProviderBranchProtected(t *testing.T) {
+	tests := []struct {
+		name              string
+		mockResponses     []mockHTTP
+		expectedError     string
+		expectedProtected bool
+	}{
+		{
+			name: "BadStatusCode",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 401, `{"message":"401 Unauthorized"}`},
+			},
+			expectedError: `GET /projects/group/project/protected_branches/master 401: 401 Unauthorized`,
+		},
+		{
+			name: "NotProtected",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 404, `{"message":"404 Not found"}`},
+			},
+			expectedProtected: false,
+		},
+		{
+			name: "Protected",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 200, `{ "name": "master" }`},
+			},
+			expectedProtected: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+			}
+
+			protected, err := provider.BranchProtected(context.Background(), "master")
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedProtected, protected)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestGitLabProviderUnprotectBranch(t *testing.T) {
+	tests := []struct {
+		name                      string
+		mockResponses             []mockHTTP
+		expectedUnprotectError    string
+		expectedProtectError      string
+		expectedProtectedBranches map[string]GitLabProtectedBranch
+	}{
+		{
+			name: "GetFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 403, ``},
+			},
+			expectedUnprotectError: `GET /projects/group/project/protected_branches/master 403: `,
+		},
+		{
+			name: "UnprotectFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 200, `{ "name": "master" }`},
+				{"DELETE", "/projects/{id}/protected_branches/{branch}", 403, ``},
+			},
+			expectedUnprotectError: `DELETE /projects/group/project/protected_branches/master 403: `,
+		},
+		{
+			name: "ProtectFails",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 200, `{ "name": "master" }`},
+				{"DELETE", "/projects/{id}/protected_branches/{branch}", 204, ``},
+				{"POST", "/projects/{id}/protected_branches", 409, `{"message":"Protected branch 'master' already exists"}`},
+			},
+			expectedProtectedBranches: map[string]GitLabProtectedBranch{
+				"master": {Name: "master"},
+			},
+			expectedProtectError: `POST /projects/group/project/protected_branches 409: Protected branch 'master' already exists`,
+		},
+		{
+			name: "Success",
+			mockResponses: []mockHTTP{
+				{"GET", "/projects/{id}/protected_branches/{branch}", 200, `{
+					"name": "master",
+					"push_access_levels": [
+						{ "access_level": 40, "access_level_description": "Maintainers" }
+					],
+					"merge_access_levels": [
+						{ "access_level": 30, "access_level_description": "Developers + Maintainers" }
+					],
+					"allow_force_push": false,
+					"code_owner_approval_required": true
+				}`},
+				{"DELETE", "/projects/{id}/protected_branches/{branch}", 204, ``},
+				{"POST", "/projects/{id}/protected_branches", 201, `{ "name": "master" }`},
+			},
+			expectedProtectedBranches: map[string]GitLabProtectedBranch{
+				"master": {
+					Name: "master",
+					PushAccessLevels: []GitLabAccessLevel{
+						{AccessLevel: 40, AccessLevelDescription: "Maintainers"},
+					},
+					MergeAccessLevels: []GitLabAccessLevel{
+						{AccessLevel: 30, AccessLevelDescription: "Developers + Maintainers"},
+					},
+					AllowForcePush:            false,
+					CodeOwnerApprovalRequired: true,
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := createMockHTTPServer(tc.mockResponses...)
+			defer ts.Close()
+
+			provider := &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: ts.URL,
+				Repo:   "group/project",
+			}
+
+			ctx := context.Background()
+
+			err := provider.UnprotectBranch(ctx, "master")
+			if tc.expectedUnprotectError != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedUnprotectError, err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedProtectedBranches, provider.protectedBranches)
+
+			err = provider.ProtectBranch(ctx, "master")
+			if tc.expectedProtectError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedProtectError, err.Error())
+			}
+		})
+	}
+}
+
+func TestSetAccessLevels(t *testing.T) {
+	tests := []struct {
+		name         string
+		levels       []GitLabAccessLevel
+		expectedData map[string]interface{}
+	}{
+		{
+			name:         "None",
+			levels:       nil,
+			expectedData: map[string]interface{}{},
+		},
+		{
+			name: "Role",
+			levels: []GitLabAccessLevel{
+				{AccessLevel: 40},
+			},
+			expectedData: map[string]interface{}{
+				"push_access_level": 40,
+			},
+		},
+		{
+			name: "User",
+			levels: []GitLabAccessLevel{
+				{AccessLevel: 40, UserID: 7},
+			},
+			expectedData: map[string]interface{}{
+				"allowed_to_push": []map[string]int{
+					{"user_id": 7},
+				},
+			},
+		},
+		{
+			name: "Multiple",
+			levels: []GitLabAccessLevel{
+				{AccessLevel: 40},
+				{AccessLevel: 30, GroupID: 3},
+				{AccessLevel: 40, DeployKeyID: 5},
+			},
+			expectedData: map[string]interface{}{
+				"allowed_to_push": []map[string]int{
+					{"access_level": 40},
+					{"group_id": 3},
+					{"deploy_key_id": 5},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			data := map[string]interface{}{}
+			setAccessLevels(data, "push", tc.levels)
+
+			assert.Equal(t, tc.expectedData, data)
+		})
+	}
+}
+
+func TestGitLabProviderUnprotectBranchSettings(t *testing.T) {
+	protectedBranch := `{
+		"name": "master",
+		"push_access_levels": [
+			{ "access_level": 40, "access_level_description": "Maintainers" },
+			{ "access_level": 40, "access_level_description": "octocat", "user_id": 7 }
+		],
+		"merge_access_levels": [
+			{ "access_level": 30, "access_level_description": "Developers + Maintainers" }
+		],
+		"unprotect_access_levels": [
+			{ "access_level": 40, "access_level_description": "Maintainers" }
+		],
+		"allow_force_push": true,
+		"code_owner_approval_required": true
+	}`
+
+	var deleted bool
+	var protected map[string]interface{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(protectedBranch))
+		case "DELETE":
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		case "POST":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&protected))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{ "name": "master" }`))
+		}
+	}))
+	defer ts.Close()
+
+	provider := &GitLabProvider{
+		Client: &http.Client{},
+		Token:  "gitlab-token",
+		APIURL: ts.URL,
+		Repo:   "group/project",
+	}
+
+	ctx := context.Background()
+	assert.NoError(t, provider.UnprotectBranch(ctx, "master"))
+	assert.True(t, deleted)
+	assert.NoError(t, provider.ProtectBranch(ctx, "master"))
+
+	assert.Equal(t, map[string]interface{}{
+		"name":                         "master",
+		"allow_force_push":             true,
+		"code_owner_approval_required": true,
+		"allowed_to_push": []interface{}{
+			map[string]interface{}{"access_level": float64(40)},
+			map[string]interface{}{"user_id": float64(7)},
+		},
+		"merge_access_level":     float64(30),
+		"unprotect_access_level": float64(40),
+	}, protected)
+}
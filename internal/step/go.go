@@ -3,12 +3,17 @@ package step
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -32,7 +37,7 @@ func (s *GoVersion) Dry(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GoVersion.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -50,7 +55,7 @@ func (s *GoVersion) Run(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GoVersion.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -93,7 +98,7 @@ func (s *GoList) Dry(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GoList.Dry: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -111,7 +116,7 @@ func (s *GoList) Run(ctx context.Context) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("GoList.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -161,7 +166,7 @@ func (s *GoBuild) build(ctx context.Context, binaryFile string) error {
 	cmd.Dir = s.WorkDir
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	if err := runCommand(ctx, cmd); err != nil {
 		return fmt.Errorf("%s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
@@ -244,3 +249,239 @@ func (s *GoBuild) Revert(ctx context.Context) error {
 
 	return nil
 }
+
+var (
+	moduleRegex      = regexp.MustCompile(`(?m)^module\s+"?([^"\s]+)"?`)
+	majorSuffixRegex = regexp.MustCompile(`^(.+)/v(\d+)$`)
+)
+
+// majorModulePath returns the module path for a major version.
+// For major versions 0 and 1, the module path does not have a major version suffix.
+func majorModulePath(path string, major uint) (string, error) {
+	if strings.HasPrefix(path, "gopkg.in/") {
+		return "", fmt.Errorf("module path %s cannot be rewritten for major version %d", path, major)
+	}
+
+	base := path
+	if m := majorSuffixRegex.FindStringSubmatch(path); m != nil {
+		if n, _ := strconv.ParseUint(m[2], 10, 64); n >= 2 {
+			base = m[1]
+		}
+	}
+
+	if major < 2 {
+		return base, nil
+	}
+
+	return fmt.Sprintf("%s/v%d", base, major), nil
+}
+
+// rewriteImports replaces the import paths of a module in a Go source file.
+func rewriteImports(filename string, src []byte, oldPath, newPath string) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly)
+	if err != nil {
+		return nil, false, err
+	}
+
+	out := src
+	changed := false
+
+	// Replace import paths from the end, so the offsets of previous ones remain valid
+	for i := len(f.Imports) - 1; i >= 0; i-- {
+		lit := f.Imports[i].Path
+		path, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if path != oldPath && !strings.HasPrefix(path, oldPath+"/") {
+			continue
+		}
+
+		start, end := fset.Position(lit.Pos()).Offset, fset.Position(lit.End()).Offset
+		quoted := strconv.Quote(newPath + strings.TrimPrefix(path, oldPath))
+
+		buf := make([]byte, 0, len(out)+len(quoted)-(end-start))
+		buf = append(buf, out[:start]...)
+		buf = append(buf, quoted...)
+		buf = append(buf, out[end:]...)
+		out = buf
+		changed = true
+	}
+
+	return out, changed, nil
+}
+
+// GoModMajor rewrites the module path in go.mod file and all import paths of the module
+// for a major version (i.e. github.com/octocat/hello to github.com/octocat/hello/v2).
+// If there is no go.mod file or the module path is already for the major version, nothing is rewritten.
+// Files in vendor and testdata directories and nested modules are not rewritten.
+type GoModMajor struct {
+	Mock    Step
+	WorkDir string
+	Major   uint
+	Result  struct {
+		Path    string
+		NewPath string
+		Files   []string
+	}
+
+	originals map[string][]byte
+}
+
+// rewrite returns the new contents of files for the major version relative to WorkDir.
+func (s *GoModMajor) rewrite() (map[string][]byte, error) {
+	s.Result.Path, s.Result.NewPath, s.Result.Files = "", "", nil
+
+	data, err := ioutil.ReadFile(filepath.Join(s.WorkDir, "go.mod"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	m := moduleRegex.FindSubmatchIndex(data)
+	if m == nil {
+		return nil, errors.New("no module path found in go.mod")
+	}
+
+	oldPath := string(data[m[2]:m[3]])
+	newPath, err := majorModulePath(oldPath, s.Major)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Result.Path, s.Result.NewPath = oldPath, newPath
+	if newPath == oldPath {
+		return nil, nil
+	}
+
+	files := map[string][]byte{}
+
+	gomod := make([]byte, 0, len(data)+len(newPath)-len(oldPath))
+	gomod = append(gomod, data[:m[2]]...)
+	gomod = append(gomod, newPath...)
+	gomod = append(gomod, data[m[3]:]...)
+	files["go.mod"] = gomod
+
+	err = filepath.Walk(s.WorkDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if path == s.WorkDir {
+				return nil
+			}
+
+			name := info.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+
+			// Nested modules have their own module paths
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		out, changed, err := rewriteImports(path, src, oldPath, newPath)
+		if err != nil {
+			return err
+		}
+
+		if changed {
+			rel, err := filepath.Rel(s.WorkDir, path)
+			if err != nil {
+				return err
+			}
+			files[rel] = out
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for file := range files {
+		s.Result.Files = append(s.Result.Files, file)
+	}
+	sort.Strings(s.Result.Files)
+
+	return files, nil
+}
+
+// Dry is a dry run of the step.
+func (s *GoModMajor) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if _, err := s.rewrite(); err != nil {
+		return fmt.Errorf("GoModMajor.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *GoModMajor) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	files, err := s.rewrite()
+	if err != nil {
+		return fmt.Errorf("GoModMajor.Run: %s", err)
+	}
+
+	s.originals = map[string][]byte{}
+
+	for _, file := range s.Result.Files {
+		path := filepath.Join(s.WorkDir, file)
+
+		original, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("GoModMajor.Run: %s", err)
+		}
+		s.originals[file] = original
+
+		if err := ioutil.WriteFile(path, files[file], 0644); err != nil {
+			return fmt.Errorf("GoModMajor.Run: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *GoModMajor) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	for file, original := range s.originals {
+		if err := ioutil.WriteFile(filepath.Join(s.WorkDir, file), original, 0644); err != nil {
+			return fmt.Errorf("GoModMajor.Revert: %s", err)
+		}
+	}
+
+	s.originals = nil
+
+	return nil
+}
@@ -489,3 +489,215 @@ func TestGoBuildRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestMajorModulePath(t *testing.T) {
+	tests := []struct {
+		path          string
+		major         uint
+		expectedPath  string
+		expectedError string
+	}{
+		{"github.com/octocat/hello", 1, "github.com/octocat/hello", ""},
+		{"github.com/octocat/hello", 2, "github.com/octocat/hello/v2", ""},
+		{"github.com/octocat/hello/v2", 3, "github.com/octocat/hello/v3", ""},
+		{"github.com/octocat/hello/v2", 2, "github.com/octocat/hello/v2", ""},
+		{"github.com/octocat/v1", 2, "github.com/octocat/v1/v2", ""},
+		{"gopkg.in/yaml.v2", 3, "", "module path gopkg.in/yaml.v2 cannot be rewritten for major version 3"},
+	}
+
+	for _, tc := range tests {
+		path, err := majorModulePath(tc.path, tc.major)
+
+		if tc.expectedError == "" {
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedPath, path)
+		} else {
+			assert.EqualError(t, err, tc.expectedError)
+		}
+	}
+}
+
+func TestRewriteImports(t *testing.T) {
+	src := `package main
+
+import (
+	"fmt"
+
+	"github.com/octocat/hello/internal/greet"
+	hello "github.com/octocat/hello"
+	"github.com/octocat/hello-world"
+)
+
+// github.com/octocat/hello is not an import
+func main() {
+	fmt.Println(greet.Hello(), hello.World(), "github.com/octocat/hello")
+}
+`
+
+	expected := `package main
+
+import (
+	"fmt"
+
+	"github.com/octocat/hello/v2/internal/greet"
+	hello "github.com/octocat/hello/v2"
+	"github.com/octocat/hello-world"
+)
+
+// github.com/octocat/hello is not an import
+func main() {
+	fmt.Println(greet.Hello(), hello.World(), "github.com/octocat/hello")
+}
+`
+
+	out, changed, err := rewriteImports("main.go", []byte(src), "github.com/octocat/hello", "github.com/octocat/hello/v2")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, expected, string(out))
+
+	_, changed, err = rewriteImports("main.go", []byte(src), "github.com/octocat/world", "github.com/octocat/world/v2")
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	_, _, err = rewriteImports("main.go", []byte("package"), "github.com/octocat/hello", "github.com/octocat/hello/v2")
+	assert.Error(t, err)
+}
+
+func TestGoModMajorMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "Error",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GoModMajor{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestGoModMajor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cherry-gomod-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"go.mod":                  "module github.com/octocat/hello\n\ngo 1.13\n",
+		"main.go":                 "package main\n\nimport \"github.com/octocat/hello/greet\"\n\nfunc main() { greet.Hello() }\n",
+		"greet/greet.go":          "package greet\n\nfunc Hello() {}\n",
+		"vendor/lib/lib.go":       "package lib\n\nimport _ \"github.com/octocat/hello/greet\"\n",
+		"testdata/main.go":        "package main\n\nimport _ \"github.com/octocat/hello/greet\"\n",
+		"tools/go.mod":            "module github.com/octocat/hello/tools\n",
+		"tools/main.go":           "package main\n\nimport _ \"github.com/octocat/hello/greet\"\n",
+		"README.md":               "github.com/octocat/hello\n",
+		"cmd/hello/main_test.go":  "package main\n\nimport (\n\t\"testing\"\n\n\t\"github.com/octocat/hello\"\n)\n",
+		"cmd/hello/doc.go":        "// Package main\npackage main\n",
+		"internal/version/doc.go": "package version\n",
+	}
+
+	for file, content := range files {
+		path := filepath.Join(dir, file)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	read := func(file string) string {
+		data, err := ioutil.ReadFile(filepath.Join(dir, file))
+		assert.NoError(t, err)
+		return string(data)
+	}
+
+	ctx := context.Background()
+
+	t.Run("NoGoMod", func(t *testing.T) {
+		step := GoModMajor{WorkDir: filepath.Join(dir, "greet"), Major: 2}
+		assert.NoError(t, step.Dry(ctx))
+		assert.NoError(t, step.Run(ctx))
+		assert.Empty(t, step.Result.Files)
+		assert.NoError(t, step.Revert(ctx))
+	})
+
+	t.Run("SameMajor", func(t *testing.T) {
+		step := GoModMajor{WorkDir: dir, Major: 1}
+		assert.NoError(t, step.Dry(ctx))
+		assert.Equal(t, "github.com/octocat/hello", step.Result.Path)
+		assert.Equal(t, "github.com/octocat/hello", step.Result.NewPath)
+		assert.Empty(t, step.Result.Files)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		step := GoModMajor{WorkDir: dir, Major: 2}
+		assert.NoError(t, step.Dry(ctx))
+		assert.Equal(t, "github.com/octocat/hello", step.Result.Path)
+		assert.Equal(t, "github.com/octocat/hello/v2", step.Result.NewPath)
+		assert.Equal(t, []string{"cmd/hello/main_test.go", "go.mod", "main.go"}, step.Result.Files)
+
+		// Dry does not change any file
+		assert.Equal(t, files["go.mod"], read("go.mod"))
+
+		assert.NoError(t, step.Run(ctx))
+		assert.Equal(t, "module github.com/octocat/hello/v2\n\ngo 1.13\n", read("go.mod"))
+		assert.Equal(t, "package main\n\nimport \"github.com/octocat/hello/v2/greet\"\n\nfunc main() { greet.Hello() }\n", read("main.go"))
+		assert.Equal(t, "package main\n\nimport (\n\t\"testing\"\n\n\t\"github.com/octocat/hello/v2\"\n)\n", read("cmd/hello/main_test.go"))
+		for _, file := range []string{"vendor/lib/lib.go", "testdata/main.go", "tools/go.mod", "tools/main.go", "README.md"} {
+			assert.Equal(t, files[file], read(file))
+		}
+
+		assert.NoError(t, step.Revert(ctx))
+		for file, content := range files {
+			assert.Equal(t, content, read(file))
+		}
+	})
+
+	t.Run("InvalidGoFile", func(t *testing.T) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.go"), []byte("package"), 0644))
+		defer os.Remove(filepath.Join(dir, "invalid.go"))
+
+		step := GoModMajor{WorkDir: dir, Major: 2}
+		err := step.Dry(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "GoModMajor.Dry: ")
+
+		err = step.Run(ctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "GoModMajor.Run: ")
+	})
+
+	t.Run("NoModulePath", func(t *testing.T) {
+		step := GoModMajor{WorkDir: filepath.Join(dir, "internal"), Major: 2}
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "internal", "go.mod"), []byte("go 1.13\n"), 0644))
+		assert.EqualError(t, step.Dry(ctx), "GoModMajor.Dry: no module path found in go.mod")
+	})
+}
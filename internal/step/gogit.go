@@ -0,0 +1,624 @@
+package step
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+const (
+	// GitBackendAuto uses the git binary if it is available and the pure-Go implementation otherwise.
+	GitBackendAuto = "auto"
+	// GitBackendCLI uses the git binary.
+	GitBackendCLI = "cli"
+	// GitBackendGo uses the pure-Go implementation of git.
+	GitBackendGo = "go"
+
+	gitRemote   = "origin"
+	gitAuthUser = "x-access-token"
+)
+
+// lookPath is used for finding the git binary.
+var lookPath = exec.LookPath
+
+// UseGoGit determines whether or not the pure-Go implementation of git should be used for a backend.
+// If backend is empty or auto, the pure-Go implementation is used only if the git binary cannot be found.
+func UseGoGit(backend string) bool {
+	switch backend {
+	case GitBackendCLI:
+		return false
+	case GitBackendGo:
+		return true
+	default:
+		_, err := lookPath("git")
+		return err != nil
+	}
+}
+
+// GoGit is a pure-Go implementation of git operations that does not need the git binary.
+// If Repo is not set, the repository is opened from WorkDir or any of its parent directories.
+type GoGit struct {
+	WorkDir string
+	Repo    *git.Repository
+	// Token is used for authenticating push and pull over https.
+	Token string
+}
+
+// NewGoGit creates a pure-Go git for the repository of a directory.
+func NewGoGit(workDir string) *GoGit {
+	return &GoGit{
+		WorkDir: workDir,
+	}
+}
+
+func (g *GoGit) debugf(ctx context.Context, format string, v ...interface{}) {
+	if ui := uiFromContext(ctx); ui != nil {
+		ui.Debugf("go-git: "+format, v...)
+	}
+}
+
+func (g *GoGit) repository() (*git.Repository, error) {
+	if g.Repo != nil {
+		return g.Repo, nil
+	}
+
+	workDir := g.WorkDir
+	if workDir == "" {
+		workDir = "."
+	}
+
+	repo, err := git.PlainOpenWithOptions(workDir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	g.Repo = repo
+
+	return repo, nil
+}
+
+func (g *GoGit) worktree() (*git.Worktree, error) {
+	repo, err := g.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.Worktree()
+}
+
+// auth returns the authentication method for a remote url.
+// For ssh remotes, nil is returned so the ssh agent is used.
+func (g *GoGit) auth(url string) transport.AuthMethod {
+	if g.Token == "" || !strings.HasPrefix(url, "http") {
+		return nil
+	}
+
+	return &http.BasicAuth{
+		Username: gitAuthUser,
+		Password: g.Token,
+	}
+}
+
+// IsClean determines whether or not the working tree has any change.
+func (g *GoGit) IsClean(ctx context.Context) (bool, error) {
+	g.debugf(ctx, "status")
+
+	wt, err := g.worktree()
+	if err != nil {
+		return false, err
+	}
+
+	status, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+
+	return status.IsClean(), nil
+}
+
+// RemoteURL returns the url of origin remote.
+func (g *GoGit) RemoteURL(ctx context.Context) (string, error) {
+	g.debugf(ctx, "remote get-url %s", gitRemote)
+
+	repo, err := g.repository()
+	if err != nil {
+		return "", err
+	}
+
+	remote, err := repo.Remote(gitRemote)
+	if err != nil {
+		return "", err
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", errors.New("failed to get git repository url")
+	}
+
+	return urls[0], nil
+}
+
+// Branch returns the name of current branch.
+// If HEAD is detached, HEAD is returned similar to `git rev-parse --abbrev-ref HEAD`.
+func (g *GoGit) Branch(ctx context.Context) (string, error) {
+	g.debugf(ctx, "rev-parse --abbrev-ref HEAD")
+
+	repo, err := g.repository()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	if !head.Name().IsBranch() {
+		return "HEAD", nil
+	}
+
+	return head.Name().Short(), nil
+}
+
+// HEAD returns the commit SHA of HEAD.
+func (g *GoGit) HEAD(ctx context.Context) (string, error) {
+	g.debugf(ctx, "rev-parse HEAD")
+
+	repo, err := g.repository()
+	if err != nil {
+		return "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+
+	return head.Hash().String(), nil
+}
+
+// CheckFiles makes sure files exist in the working tree.
+func (g *GoGit) CheckFiles(ctx context.Context, files ...string) error {
+	g.debugf(ctx, "add --dry-run %s", strings.Join(files, " "))
+
+	wt, err := g.worktree()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if _, err := wt.Filesystem.Lstat(file); err != nil {
+			return fmt.Errorf("pathspec '%s' did not match any files", file)
+		}
+	}
+
+	return nil
+}
+
+// Add adds files to the index.
+func (g *GoGit) Add(ctx context.Context, files ...string) error {
+	g.debugf(ctx, "add %s", strings.Join(files, " "))
+
+	wt, err := g.worktree()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if _, err := wt.Add(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Reset resets the index entries of files to HEAD similar to `git reset <files>`.
+func (g *GoGit) Reset(ctx context.Context, files ...string) error {
+	g.debugf(ctx, "reset %s", strings.Join(files, " "))
+
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return err
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		f, err := tree.File(file)
+		if err != nil {
+			// The file is not in HEAD
+			_, _ = idx.Remove(file)
+			continue
+		}
+
+		e, err := idx.Entry(file)
+		if err != nil {
+			e = idx.Add(file)
+		}
+
+		e.Hash = f.Hash
+		e.Mode = f.Mode
+		e.Size = uint32(f.Size)
+	}
+
+	return repo.Storer.SetIndex(idx)
+}
+
+// Commit records the changes in the index.
+// The author is read from the git configurations.
+func (g *GoGit) Commit(ctx context.Context, message string) error {
+	g.debugf(ctx, "commit -m %q", message)
+
+	wt, err := g.worktree()
+	if err != nil {
+		return err
+	}
+
+	_, err = wt.Commit(message, &git.CommitOptions{})
+
+	return err
+}
+
+// UndoCommit moves the current branch to the parent of HEAD and keeps the index similar to `git reset --soft HEAD~1`.
+func (g *GoGit) UndoCommit(ctx context.Context) error {
+	g.debugf(ctx, "reset --soft HEAD~1")
+
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return err
+	}
+
+	if commit.NumParents() == 0 {
+		return errors.New("cannot undo the initial commit")
+	}
+
+	ref := plumbing.NewHashReference(head.Name(), commit.ParentHashes[0])
+
+	return repo.Storer.SetReference(ref)
+}
+
+// Tags returns the list of tags.
+func (g *GoGit) Tags(ctx context.Context) ([]string, error) {
+	g.debugf(ctx, "tag")
+
+	repo, err := g.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		tags = append(tags, ref.Name().Short())
+		return nil
+	})
+
+	return tags, err
+}
+
+// LatestTag returns the most recent tag reachable from HEAD similar to `git describe --tags --abbrev=0`.
+// If match is not empty, only tags matching the glob pattern are considered.
+// If there is no tag reachable from HEAD, an empty string is returned.
+func (g *GoGit) LatestTag(ctx context.Context, match string) (string, error) {
+	g.debugf(ctx, "describe --tags --abbrev=0 %s", match)
+
+	tag, _, _, err := g.describe(match, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return tag, nil
+}
+
+// Describe returns the most recent tag reachable from HEAD, the number of commits since the tag,
+// and the abbreviated hash of HEAD similar to `git describe --tags --long --always`.
+// If match is not empty, only tags matching the glob pattern are considered.
+// If valid is not nil, only tags for which it returns true are considered.
+// If there is no tag reachable from HEAD, an empty tag and the number of all commits reachable from HEAD are returned.
+func (g *GoGit) Describe(ctx context.Context, match string, valid func(string) bool) (string, int, string, error) {
+	g.debugf(ctx, "describe --tags --long --always %s", match)
+
+	return g.describe(match, valid)
+}
+
+func (g *GoGit) describe(match string, valid func(string) bool) (string, int, string, error) {
+	repo, err := g.repository()
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	// Map commit hashes to tag names
+	iter, err := repo.Tags()
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	tags := map[plumbing.Hash][]string{}
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+
+		if match != "" {
+			if ok, _ := path.Match(match, name); !ok {
+				return nil
+			}
+		}
+
+		if valid != nil && !valid(name) {
+			return nil
+		}
+
+		hash := ref.Hash()
+		// Annotated tags point to tag objects
+		if tag, err := repo.TagObject(hash); err == nil {
+			hash = tag.Target
+		}
+		tags[hash] = append(tags[hash], name)
+		return nil
+	})
+
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	commits, err := repo.Log(&git.LogOptions{From: head.Hash()})
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	var latest string
+	var count int
+	err = commits.ForEach(func(c *object.Commit) error {
+		if names, ok := tags[c.Hash]; ok {
+			sort.Strings(names)
+			latest = names[len(names)-1]
+			return storer.ErrStop
+		}
+		count++
+		return nil
+	})
+
+	if err != nil {
+		return "", 0, "", err
+	}
+
+	return latest, count, head.Hash().String()[:7], nil
+}
+
+// commitTree returns the tree of a commit specified by a revision (i.e. a tag).
+func commitTree(repo *git.Repository, rev string) (*object.Tree, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", rev, err)
+	}
+
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return commit.Tree()
+}
+
+// ChangedFiles returns the files changed between a revision and HEAD similar to `git diff --name-only <rev> HEAD`.
+func (g *GoGit) ChangedFiles(ctx context.Context, rev string) ([]string, error) {
+	g.debugf(ctx, "diff --name-only %s HEAD", rev)
+
+	repo, err := g.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := commitTree(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := commitTree(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := object.DiffTree(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	for _, c := range changes {
+		if c.From.Name != "" {
+			set[c.From.Name] = true
+		}
+		if c.To.Name != "" {
+			set[c.To.Name] = true
+		}
+	}
+
+	files := []string{}
+	for f := range set {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// Tag creates a tag for HEAD.
+// If annotation is empty, a lightweight tag is created.
+func (g *GoGit) Tag(ctx context.Context, tag, annotation string) error {
+	g.debugf(ctx, "tag %s", tag)
+
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	var opts *git.CreateTagOptions
+	if annotation != "" {
+		opts = &git.CreateTagOptions{
+			Message: annotation,
+		}
+	}
+
+	_, err = repo.CreateTag(tag, head.Hash(), opts)
+
+	return err
+}
+
+// DeleteTag deletes a tag.
+func (g *GoGit) DeleteTag(ctx context.Context, tag string) error {
+	g.debugf(ctx, "tag --delete %s", tag)
+
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	return repo.DeleteTag(tag)
+}
+
+func (g *GoGit) push(ctx context.Context, refSpec config.RefSpec) error {
+	g.debugf(ctx, "push %s %s", gitRemote, refSpec)
+
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	url, err := g.RemoteURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: gitRemote,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       g.auth(url),
+	})
+
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+
+	return err
+}
+
+// Push pushes the current branch to origin remote.
+func (g *GoGit) Push(ctx context.Context) error {
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	if !head.Name().IsBranch() {
+		return errors.New("cannot push a detached HEAD")
+	}
+
+	return g.push(ctx, config.RefSpec(fmt.Sprintf("%s:%s", head.Name(), head.Name())))
+}
+
+// PushTag pushes a tag to origin remote.
+func (g *GoGit) PushTag(ctx context.Context, tag string) error {
+	ref := plumbing.NewTagReferenceName(tag)
+	return g.push(ctx, config.RefSpec(fmt.Sprintf("%s:%s", ref, ref)))
+}
+
+// Pull fetches the current branch from origin remote and merges it into the working tree.
+// Only fast-forward merges are supported.
+func (g *GoGit) Pull(ctx context.Context) error {
+	g.debugf(ctx, "pull %s", gitRemote)
+
+	repo, err := g.repository()
+	if err != nil {
+		return err
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+
+	url, err := g.RemoteURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = wt.PullContext(ctx, &git.PullOptions{
+		RemoteName:    gitRemote,
+		ReferenceName: head.Name(),
+		Auth:          g.auth(url),
+	})
+
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,329 @@
+package step
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMemRepo creates an in-memory repository with one commit and an origin remote.
+func newMemRepo(t *testing.T, remoteURL string) *git.Repository {
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	assert.NoError(t, err)
+
+	cfg, err := repo.Config()
+	assert.NoError(t, err)
+	cfg.User.Name = "octocat"
+	cfg.User.Email = "octocat@example.com"
+	assert.NoError(t, repo.SetConfig(cfg))
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{remoteURL},
+	})
+	assert.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, util.WriteFile(wt.Filesystem, "VERSION", []byte("0.1.0-0\n"), 0644))
+	_, err = wt.Add("VERSION")
+	assert.NoError(t, err)
+	_, err = wt.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "octocat", Email: "octocat@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+
+	return repo
+}
+
+func TestUseGoGit(t *testing.T) {
+	defer func(f func(string) (string, error)) {
+		lookPath = f
+	}(lookPath)
+
+	tests := []struct {
+		name          string
+		backend       string
+		gitFound      bool
+		expectedGoGit bool
+	}{
+		{"CLI", GitBackendCLI, false, false},
+		{"Go", GitBackendGo, true, true},
+		{"AutoWithGit", GitBackendAuto, true, false},
+		{"AutoWithoutGit", GitBackendAuto, false, true},
+		{"EmptyWithoutGit", "", false, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gitFound := tc.gitFound
+			lookPath = func(file string) (string, error) {
+				if gitFound {
+					return "/usr/bin/git", nil
+				}
+				return "", errors.New("executable file not found")
+			}
+
+			assert.Equal(t, tc.expectedGoGit, UseGoGit(tc.backend))
+		})
+	}
+}
+
+func TestNewGoGit(t *testing.T) {
+	g := NewGoGit("/repo")
+	assert.Equal(t, "/repo", g.WorkDir)
+	assert.Nil(t, g.Repo)
+}
+
+func TestGoGitAuth(t *testing.T) {
+	g := &GoGit{}
+	assert.Nil(t, g.auth("https://github.com/octocat/Hello-World.git"))
+
+	g.Token = "token"
+	assert.Nil(t, g.auth("git@github.com:octocat/Hello-World.git"))
+	assert.Equal(t, &http.BasicAuth{Username: "x-access-token", Password: "token"}, g.auth("https://github.com/octocat/Hello-World.git"))
+}
+
+func TestGoGitOpenFails(t *testing.T) {
+	ctx := context.Background()
+	g := NewGoGit("/")
+
+	_, err := g.IsClean(ctx)
+	assert.Error(t, err)
+
+	s := &GitGetBranch{GoGit: g}
+	assert.Error(t, s.Dry(ctx))
+	assert.Error(t, s.Run(ctx))
+}
+
+func TestGoGitSteps(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemRepo(t, "https://github.com/octocat/Hello-World.git")
+	g := &GoGit{Repo: repo}
+
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+
+	t.Run("GitLatestTagNoTag", func(t *testing.T) {
+		s := &GitLatestTag{GoGit: g}
+		assert.NoError(t, s.Run(ctx))
+		assert.Equal(t, "", s.Result.Tag)
+	})
+
+	t.Run("GitStatus", func(t *testing.T) {
+		s := &GitStatus{GoGit: g}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+		assert.True(t, s.Result.IsClean)
+	})
+
+	t.Run("GitGetRepo", func(t *testing.T) {
+		s := &GitGetRepo{GoGit: g}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+		assert.Equal(t, "github.com", s.Result.Host)
+		assert.Equal(t, "octocat", s.Result.Owner)
+		assert.Equal(t, "Hello-World", s.Result.Name)
+		assert.Equal(t, "octocat/Hello-World", s.Result.Repo)
+	})
+
+	t.Run("GitGetBranch", func(t *testing.T) {
+		s := &GitGetBranch{GoGit: g}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+		assert.Equal(t, "master", s.Result.Name)
+	})
+
+	t.Run("GitGetHEAD", func(t *testing.T) {
+		s := &GitGetHEAD{GoGit: g}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+		assert.Equal(t, head.Hash().String(), s.Result.SHA)
+		assert.Equal(t, head.Hash().String()[:7], s.Result.ShortSHA)
+	})
+
+	t.Run("GitAdd", func(t *testing.T) {
+		assert.NoError(t, util.WriteFile(wt.Filesystem, "VERSION", []byte("0.1.0\n"), 0644))
+		assert.NoError(t, util.WriteFile(wt.Filesystem, "CHANGELOG.md", []byte("# Changelog\n"), 0644))
+
+		s := &GitAdd{GoGit: g, Files: []string{"VERSION", "CHANGELOG.md"}}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+
+		status, err := wt.Status()
+		assert.NoError(t, err)
+		assert.Equal(t, git.Modified, status.File("VERSION").Staging)
+		assert.Equal(t, git.Added, status.File("CHANGELOG.md").Staging)
+
+		assert.NoError(t, s.Revert(ctx))
+
+		status, err = wt.Status()
+		assert.NoError(t, err)
+		assert.Equal(t, git.Unmodified, status.File("VERSION").Staging)
+		assert.Equal(t, git.Untracked, status.File("CHANGELOG.md").Staging)
+
+		s = &GitAdd{GoGit: g, Files: []string{"README.md"}}
+		assert.EqualError(t, s.Dry(ctx), "GitAdd.Dry: pathspec 'README.md' did not match any files")
+	})
+
+	t.Run("GitCommit", func(t *testing.T) {
+		assert.NoError(t, g.Add(ctx, "VERSION", "CHANGELOG.md"))
+
+		s := &GitCommit{GoGit: g, Message: "Releasing 0.1.0"}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+
+		ref, err := repo.Head()
+		assert.NoError(t, err)
+		commit, err := repo.CommitObject(ref.Hash())
+		assert.NoError(t, err)
+		assert.Equal(t, "Releasing 0.1.0", commit.Message)
+		assert.Equal(t, "octocat", commit.Author.Name)
+
+		assert.NoError(t, s.Revert(ctx))
+
+		ref, err = repo.Head()
+		assert.NoError(t, err)
+		assert.Equal(t, head.Hash(), ref.Hash())
+
+		// The changes are kept in the index
+		status, err := wt.Status()
+		assert.NoError(t, err)
+		assert.Equal(t, git.Modified, status.File("VERSION").Staging)
+
+		assert.NoError(t, s.Run(ctx))
+	})
+
+	t.Run("GitTag", func(t *testing.T) {
+		s := &GitTag{GoGit: g, Tag: "v0.1.0", Annotation: "Version 0.1.0"}
+		assert.NoError(t, s.Dry(ctx))
+		assert.NoError(t, s.Run(ctx))
+
+		ref, err := repo.Tag("v0.1.0")
+		assert.NoError(t, err)
+		tag, err := repo.TagObject(ref.Hash())
+		assert.NoError(t, err)
+		assert.Equal(t, "Version 0.1.0\n", tag.Message)
+
+		tags, err := g.Tags(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"v0.1.0"}, tags)
+
+		latest := &GitLatestTag{GoGit: g}
+		assert.NoError(t, latest.Dry(ctx))
+		assert.NoError(t, latest.Run(ctx))
+		assert.Equal(t, "v0.1.0", latest.Result.Tag)
+
+		latest = &GitLatestTag{GoGit: g, Match: "sdk/*"}
+		assert.NoError(t, latest.Run(ctx))
+		assert.Equal(t, "", latest.Result.Tag)
+
+		changed := &GitChangedFiles{GoGit: g, Since: "v0.1.0"}
+		assert.NoError(t, changed.Dry(ctx))
+		assert.NoError(t, changed.Run(ctx))
+		assert.Equal(t, []string{}, changed.Result.Files)
+
+		changed = &GitChangedFiles{GoGit: g, Since: head.Hash().String()}
+		assert.NoError(t, changed.Run(ctx))
+		assert.Equal(t, []string{"CHANGELOG.md", "VERSION"}, changed.Result.Files)
+
+		changed = &GitChangedFiles{GoGit: g, Since: "sdk/v1.3.0"}
+		assert.Error(t, changed.Dry(ctx))
+		assert.Error(t, changed.Run(ctx))
+
+		assert.NoError(t, s.Revert(ctx))
+
+		_, err = repo.Tag("v0.1.0")
+		assert.Equal(t, git.ErrTagNotFound, err)
+
+		s = &GitTag{GoGit: g, Tag: "v0.1.0"}
+		assert.NoError(t, s.Run(ctx))
+
+		ref, err = repo.Tag("v0.1.0")
+		assert.NoError(t, err)
+		assert.Equal(t, plumbing.HashReference, ref.Type())
+	})
+
+	t.Run("GitStatusNotClean", func(t *testing.T) {
+		assert.NoError(t, util.WriteFile(wt.Filesystem, "README.md", []byte("# Hello\n"), 0644))
+
+		s := &GitStatus{GoGit: g}
+		assert.NoError(t, s.Run(ctx))
+		assert.False(t, s.Result.IsClean)
+	})
+}
+
+func TestGoGitPushPull(t *testing.T) {
+	ctx := context.Background()
+	remoteURL := "file:///octocat/Hello-World.git"
+
+	// Serve the remote repository from memory
+	remote := memory.NewStorage()
+	assert.NoError(t, remote.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.Master)))
+	prev := client.Protocols["file"]
+	client.InstallProtocol("file", server.NewClient(server.MapLoader{remoteURL: remote}))
+	defer client.InstallProtocol("file", prev)
+
+	// Push from one clone
+	repo := newMemRepo(t, remoteURL)
+	g := &GoGit{Repo: repo}
+
+	push := &GitPush{GoGit: g}
+	assert.NoError(t, push.Dry(ctx))
+	assert.NoError(t, push.Run(ctx))
+	assert.NoError(t, push.Run(ctx)) // already up-to-date
+
+	assert.NoError(t, g.Tag(ctx, "v0.1.0", "Version 0.1.0"))
+	pushTag := &GitPushTag{GoGit: g, Tag: "v0.1.0"}
+	assert.NoError(t, pushTag.Dry(ctx))
+	assert.NoError(t, pushTag.Run(ctx))
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+
+	ref, err := remote.Reference(plumbing.NewBranchReferenceName("master"))
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash(), ref.Hash())
+
+	_, err = remote.Reference(plumbing.NewTagReferenceName("v0.1.0"))
+	assert.NoError(t, err)
+
+	// Pull into another clone
+	clone, err := git.Clone(memory.NewStorage(), memfs.New(), &git.CloneOptions{URL: remoteURL})
+	assert.NoError(t, err)
+
+	wt, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, util.WriteFile(wt.Filesystem, "VERSION", []byte("0.1.1-0\n"), 0644))
+	assert.NoError(t, g.Add(ctx, "VERSION"))
+	assert.NoError(t, g.Commit(ctx, "Beginning 0.1.1-0 [skip ci]"))
+	assert.NoError(t, push.Run(ctx))
+
+	pull := &GitPull{GoGit: &GoGit{Repo: clone}}
+	assert.NoError(t, pull.Dry(ctx))
+	assert.NoError(t, pull.Run(ctx))
+
+	head, err = repo.Head()
+	assert.NoError(t, err)
+	cloneHead, err := clone.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, head.Hash(), cloneHead.Hash())
+
+	assert.EqualError(t, pull.Revert(ctx), "cannot revert git pull")
+}
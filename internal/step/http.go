@@ -0,0 +1,312 @@
+package step
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries       = 4
+	defaultMinBackoff       = 1 * time.Second
+	defaultMaxBackoff       = 30 * time.Second
+	defaultMaxRateLimitWait = 1 * time.Minute
+)
+
+// linkNextRE matches the url of next page in a Link header.
+// See https://developer.github.com/v3/#pagination
+var linkNextRE = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// NewHTTPClient creates a new http client with timeouts and retries for talking to release providers.
+// Idempotent requests are retried with exponential backoff on network errors and server errors
+// and all requests are retried on rate limits.
+// Every attempt is logged if the request context has a user interface (see ContextWithUI).
+func NewHTTPClient() *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 2 * time.Minute,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	return &http.Client{
+		Transport: &retryTransport{
+			Base:             &debugTransport{Base: transport},
+			MaxRetries:       defaultMaxRetries,
+			MinBackoff:       defaultMinBackoff,
+			MaxBackoff:       defaultMaxBackoff,
+			MaxRateLimitWait: defaultMaxRateLimitWait,
+		},
+	}
+}
+
+// retryTransport is an http.RoundTripper that retries failed requests.
+// Requests with a body are only retried if the body can be read again.
+// Non-idempotent requests (i.e. POST) may have been processed by the server when a network error or a server error happens,
+// so they are only retried on rate limits when the server has not processed them.
+type retryTransport struct {
+	Base             http.RoundTripper
+	MaxRetries       int
+	MinBackoff       time.Duration
+	MaxBackoff       time.Duration
+	MaxRateLimitWait time.Duration
+}
+
+// RoundTrip executes a single http transaction and retries it if needed.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req
+	for attempt := 0; ; attempt++ {
+		res, err := t.Base.RoundTrip(r)
+
+		wait, retry := t.shouldRetry(attempt, req, res, err)
+		if !retry || (req.Body != nil && req.GetBody == nil) {
+			return res, err
+		}
+
+		// A RoundTripper should not modify the original request
+		r = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, e := req.GetBody()
+			if e != nil {
+				return res, err
+			}
+			r.Body = body
+		}
+
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// shouldRetry determines whether or not a request should be retried and how long to wait before retrying it.
+func (t *retryTransport) shouldRetry(attempt int, req *http.Request, res *http.Response, err error) (time.Duration, bool) {
+	if attempt >= t.MaxRetries {
+		return 0, false
+	}
+
+	backoff := t.MinBackoff << uint(attempt)
+	if backoff > t.MaxBackoff {
+		backoff = t.MaxBackoff
+	}
+
+	if err != nil {
+		if isIdempotent(req.Method) && isRetryable(err) {
+			return backoff, true
+		}
+		return 0, false
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests,
+		res.StatusCode == http.StatusForbidden && isRateLimited(res):
+		wait, ok := rateLimitWait(res, time.Now())
+		if !ok {
+			wait = backoff
+		}
+		// Do not wait too long for a rate limit to reset
+		if wait > t.MaxRateLimitWait {
+			return 0, false
+		}
+		return wait, true
+
+	case res.StatusCode >= 500 && isIdempotent(req.Method):
+		return backoff, true
+
+	default:
+		return 0, false
+	}
+}
+
+// isIdempotent determines whether or not sending a request with a method more than once has the same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case "", "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryable determines whether or not an error is transient and the request can be retried.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// url.Error implements net.Error, so the underlying error is checked
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		err = ue.Err
+	}
+
+	var ne net.Error
+	if errors.As(err, &ne) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var he *httpError
+	if errors.As(err, &he) {
+		return he.StatusCode >= 500 || he.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// isRateLimited determines whether or not a 403 response is due to rate limiting.
+// See https://developer.github.com/v3/#rate-limiting
+// See https://developer.github.com/v3/#abuse-rate-limits
+func isRateLimited(res *http.Response) bool {
+	return res.Header.Get("Retry-After") != "" || res.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// rateLimitWait returns the duration to wait for a rate limit using Retry-After and X-RateLimit-Reset headers.
+func rateLimitWait(res *http.Response, now time.Time) (time.Duration, bool) {
+	if v := res.Header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+
+	if v := res.Header.Get("X-RateLimit-Reset"); v != "" {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return nonNegative(time.Unix(epoch, 0).Sub(now)), true
+		}
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// nextPageURL returns the url of next page from the Link header of a response.
+// If there is no next page, an empty string will be returned.
+func nextPageURL(res *http.Response) string {
+	for _, link := range res.Header["Link"] {
+		if subs := linkNextRE.FindStringSubmatch(link); len(subs) == 2 {
+			return subs[1]
+		}
+	}
+
+	return ""
+}
+
+// getPage sends a GET request and decodes the JSON response into v.
+// It returns the url of the next page if the response is paginated.
+func getPage(client *http.Client, req *http.Request, v interface{}) (string, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return "", newHTTPError(res)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return "", err
+	}
+
+	return nextPageURL(res), nil
+}
+
+// httpErrorDetail is a detailed error returned by an API.
+// See https://developer.github.com/v3/#client-errors
+type httpErrorDetail struct {
+	Resource string `json:"resource"`
+	Field    string `json:"field"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+func (d httpErrorDetail) String() string {
+	if d.Message != "" {
+		return d.Message
+	}
+	return fmt.Sprintf("%s %s %s", d.Resource, d.Field, d.Code)
+}
+
+// httpError is an http error.
+// If the response body is a JSON error, Message, Errors, and DocumentationURL are read from it.
+type httpError struct {
+	Request          *http.Request
+	StatusCode       int
+	Message          string
+	Errors           []httpErrorDetail
+	DocumentationURL string
+}
+
+// newHTTPError creates a new instance of httpError.
+func newHTTPError(res *http.Response) *httpError {
+	err := &httpError{
+		Request:    res.Request,
+		StatusCode: res.StatusCode,
+	}
+
+	if res.Body != nil {
+		if data, e := ioutil.ReadAll(res.Body); e == nil {
+			err.Message = string(data)
+
+			body := struct {
+				Message          string            `json:"message"`
+				Errors           []httpErrorDetail `json:"errors"`
+				DocumentationURL string            `json:"documentation_url"`
+			}{}
+
+			if e := json.Unmarshal(data, &body); e == nil && body.Message != "" {
+				err.Message = body.Message
+				err.Errors = body.Errors
+				err.DocumentationURL = body.DocumentationURL
+			}
+		}
+	}
+
+	return err
+}
+
+func (e *httpError) Error() string {
+	msg := e.Message
+	if len(e.Errors) > 0 {
+		details := make([]string, len(e.Errors))
+		for i, d := range e.Errors {
+			details[i] = d.String()
+		}
+		msg = fmt.Sprintf("%s (%s)", msg, strings.Join(details, ", "))
+	}
+
+	return fmt.Sprintf("%s %s %d: %s", e.Request.Method, e.Request.URL.Path, e.StatusCode, msg)
+}
@@ -0,0 +1,421 @@
+package step
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	client := NewHTTPClient()
+	assert.NotNil(t, client)
+
+	rt, ok := client.Transport.(*retryTransport)
+	assert.True(t, ok)
+	assert.Equal(t, defaultMaxRetries, rt.MaxRetries)
+	assert.IsType(t, &debugTransport{}, rt.Base)
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name               string
+		method             string
+		responses          []int
+		headers            map[string]string
+		body               string
+		expectedStatusCode int
+		expectedRequests   int32
+	}{
+		{
+			name:               "OK",
+			method:             "GET",
+			responses:          []int{200},
+			expectedStatusCode: 200,
+			expectedRequests:   1,
+		},
+		{
+			name:               "ClientError",
+			method:             "GET",
+			responses:          []int{404},
+			expectedStatusCode: 404,
+			expectedRequests:   1,
+		},
+		{
+			name:               "ForbiddenNotRateLimited",
+			method:             "GET",
+			responses:          []int{403},
+			expectedStatusCode: 403,
+			expectedRequests:   1,
+		},
+		{
+			name:               "ServerErrorThenOK",
+			method:             "GET",
+			responses:          []int{500, 502, 200},
+			expectedStatusCode: 200,
+			expectedRequests:   3,
+		},
+		{
+			name:               "ServerErrorWithBody",
+			method:             "PUT",
+			responses:          []int{503, 201},
+			body:               `{"name":"0.1.0"}`,
+			expectedStatusCode: 201,
+			expectedRequests:   2,
+		},
+		{
+			name:               "ServerErrorMaxRetries",
+			method:             "GET",
+			responses:          []int{500, 500, 500, 500},
+			expectedStatusCode: 500,
+			expectedRequests:   3,
+		},
+		{
+			name:               "PostServerError",
+			method:             "POST",
+			responses:          []int{502, 201},
+			body:               `{"name":"0.1.0"}`,
+			expectedStatusCode: 502,
+			expectedRequests:   1,
+		},
+		{
+			name:               "TooManyRequests",
+			method:             "POST",
+			responses:          []int{429, 200},
+			headers:            map[string]string{"Retry-After": "0"},
+			expectedStatusCode: 200,
+			expectedRequests:   2,
+		},
+		{
+			name:               "RateLimited",
+			method:             "POST",
+			responses:          []int{403, 200},
+			headers:            map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": "0"},
+			expectedStatusCode: 200,
+			expectedRequests:   2,
+		},
+		{
+			name:               "RateLimitTooLong",
+			method:             "GET",
+			responses:          []int{403, 200},
+			headers:            map[string]string{"Retry-After": "3600"},
+			expectedStatusCode: 403,
+			expectedRequests:   1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				i := atomic.AddInt32(&requests, 1) - 1
+				body, _ := ioutil.ReadAll(r.Body)
+				assert.Equal(t, tc.body, string(body))
+
+				for k, v := range tc.headers {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tc.responses[i])
+			}))
+			defer ts.Close()
+
+			client := &http.Client{
+				Transport: &retryTransport{
+					Base:             &http.Transport{},
+					MaxRetries:       2,
+					MinBackoff:       time.Millisecond,
+					MaxBackoff:       2 * time.Millisecond,
+					MaxRateLimitWait: time.Second,
+				},
+			}
+
+			req, err := http.NewRequest(tc.method, ts.URL, strings.NewReader(tc.body))
+			assert.NoError(t, err)
+			body := req.Body
+
+			res, err := client.Do(req)
+			assert.NoError(t, err)
+			res.Body.Close()
+
+			assert.Equal(t, tc.expectedStatusCode, res.StatusCode)
+			assert.Equal(t, tc.expectedRequests, atomic.LoadInt32(&requests))
+			// The original request is not modified
+			assert.True(t, req.Body == body)
+		})
+	}
+}
+
+func TestRetryTransportNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	ts.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{
+			Base:       &http.Transport{},
+			MaxRetries: 2,
+			MinBackoff: time.Millisecond,
+			MaxBackoff: time.Millisecond,
+		},
+	}
+
+	_, err := client.Get(ts.URL)
+	assert.Error(t, err)
+}
+
+func TestRetryTransportContextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{
+		Transport: &retryTransport{
+			Base:       &http.Transport{},
+			MaxRetries: 2,
+			MinBackoff: time.Minute,
+			MaxBackoff: time.Minute,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	assert.NoError(t, err)
+
+	_, err = client.Do(req.WithContext(ctx))
+	assert.Error(t, err)
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		method   string
+		expected bool
+	}{
+		{"GET", true},
+		{"HEAD", true},
+		{"PUT", true},
+		{"DELETE", true},
+		{"POST", false},
+		{"PATCH", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.method, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isIdempotent(tc.method))
+		})
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	req := &http.Request{Method: "POST", URL: &url.URL{Path: "/"}}
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Canceled", context.Canceled, false},
+		{"DeadlineExceeded", &url.Error{Op: "Post", URL: "/", Err: context.DeadlineExceeded}, false},
+		{"UnexpectedEOF", &url.Error{Op: "Post", URL: "/", Err: io.ErrUnexpectedEOF}, true},
+		{"NetworkError", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"ServerError", &httpError{Request: req, StatusCode: 502}, true},
+		{"TooManyRequests", &httpError{Request: req, StatusCode: 429}, true},
+		{"ClientError", &httpError{Request: req, StatusCode: 422}, false},
+		{"OtherError", errors.New("open asset: no such file or directory"), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isRetryable(tc.err))
+		})
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	tests := []struct {
+		name         string
+		headers      map[string]string
+		expectedWait time.Duration
+		expectedOK   bool
+	}{
+		{"NoHeader", nil, 0, false},
+		{"RetryAfterSeconds", map[string]string{"Retry-After": "5"}, 5 * time.Second, true},
+		{"RetryAfterDate", map[string]string{"Retry-After": time.Unix(1010, 0).UTC().Format(http.TimeFormat)}, 10 * time.Second, true},
+		{"RateLimitReset", map[string]string{"X-RateLimit-Reset": "1030"}, 30 * time.Second, true},
+		{"RateLimitResetPassed", map[string]string{"X-RateLimit-Reset": "900"}, 0, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			for k, v := range tc.headers {
+				res.Header.Set(k, v)
+			}
+
+			wait, ok := rateLimitWait(res, now)
+			assert.Equal(t, tc.expectedWait, wait)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		link        string
+		expectedURL string
+	}{
+		{"NoLink", "", ""},
+		{"LastPage", `<https://api.github.com/repositories/1/releases?page=1>; rel="first", <https://api.github.com/repositories/1/releases?page=2>; rel="prev"`, ""},
+		{"NextPage", `<https://api.github.com/repositories/1/releases?page=3>; rel="next", <https://api.github.com/repositories/1/releases?page=5>; rel="last"`, "https://api.github.com/repositories/1/releases?page=3"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := &http.Response{Header: http.Header{}}
+			if tc.link != "" {
+				res.Header.Set("Link", tc.link)
+			}
+
+			assert.Equal(t, tc.expectedURL, nextPageURL(res))
+		})
+	}
+}
+
+func TestGetPage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", `<http://`+r.Host+`/releases?page=2>; rel="next"`)
+			w.Write([]byte(`[{"id": 1}]`))
+		case "2":
+			w.Write([]byte(`[{"id": 2}]`))
+		default:
+			w.WriteHeader(404)
+			w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := &http.Client{}
+
+	var page []GitHubRelease
+	req, _ := http.NewRequest("GET", ts.URL+"/releases", nil)
+	next, err := getPage(client, req, &page)
+	assert.NoError(t, err)
+	assert.Equal(t, ts.URL+"/releases?page=2", next)
+	assert.Equal(t, []GitHubRelease{{ID: 1}}, page)
+
+	page = nil
+	req, _ = http.NewRequest("GET", next, nil)
+	next, err = getPage(client, req, &page)
+	assert.NoError(t, err)
+	assert.Equal(t, "", next)
+	assert.Equal(t, []GitHubRelease{{ID: 2}}, page)
+
+	req, _ = http.NewRequest("GET", ts.URL+"/releases?page=3", nil)
+	_, err = getPage(client, req, &page)
+	assert.EqualError(t, err, "GET /releases 404: Not Found")
+}
+
+func TestHTTPError(t *testing.T) {
+	tests := []struct {
+		name                     string
+		request                  *http.Request
+		statusCode               int
+		body                     string
+		expectedMessage          string
+		expectedDocumentationURL string
+		expectedError            string
+	}{
+		{
+			"400",
+			&http.Request{
+				Method: "GET",
+				URL: &url.URL{
+					Path: "/",
+				},
+			},
+			http.StatusBadRequest,
+			"Invalid request",
+			"Invalid request",
+			"",
+			"GET / 400: Invalid request",
+		},
+		{
+			"500",
+			&http.Request{
+				Method: "POST",
+				URL: &url.URL{
+					Path: "/",
+				},
+			},
+			http.StatusInternalServerError,
+			"Internal error",
+			"Internal error",
+			"",
+			"POST / 500: Internal error",
+		},
+		{
+			"JSON",
+			&http.Request{
+				Method: "GET",
+				URL: &url.URL{
+					Path: "/repos/octocat/Hello-World",
+				},
+			},
+			http.StatusNotFound,
+			`{"message": "Not Found", "documentation_url": "https://developer.github.com/v3"}`,
+			"Not Found",
+			"https://developer.github.com/v3",
+			"GET /repos/octocat/Hello-World 404: Not Found",
+		},
+		{
+			"JSONWithErrors",
+			&http.Request{
+				Method: "POST",
+				URL: &url.URL{
+					Path: "/repos/octocat/Hello-World/releases",
+				},
+			},
+			http.StatusUnprocessableEntity,
+			`{"message": "Validation Failed", "errors": [{"resource": "Release", "field": "tag_name", "code": "already_exists"}, {"message": "body is too long"}]}`,
+			"Validation Failed",
+			"",
+			"POST /repos/octocat/Hello-World/releases 422: Validation Failed (Release tag_name already_exists, body is too long)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			br := strings.NewReader(tc.body)
+			rc := ioutil.NopCloser(br)
+
+			res := &http.Response{
+				Request:    tc.request,
+				StatusCode: tc.statusCode,
+				Body:       rc,
+			}
+
+			err := newHTTPError(res)
+			assert.Equal(t, tc.request, err.Request)
+			assert.Equal(t, tc.statusCode, err.StatusCode)
+			assert.Equal(t, tc.expectedMessage, err.Message)
+			assert.Equal(t, tc.expectedDocumentationURL, err.DocumentationURL)
+			assert.Equal(t, tc.expectedError, err.Error())
+		})
+	}
+}
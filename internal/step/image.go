@@ -0,0 +1,188 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/moorara/cherry/pkg/ociimage"
+)
+
+// ImageFile is a file from the working directory copied into images.
+type ImageFile struct {
+	Src  string
+	Dst  string
+	Mode os.FileMode
+}
+
+// ImageBuild builds a multi-platform OCI image for linux binaries built by GoBuild.
+// Binaries are named <binary>-linux-<arch> and other binaries and unsupported architectures are skipped.
+// Each binary is copied to BinDir of the image for its platform as <binary> and is the entrypoint if Config has no entrypoint and command.
+// The image is written to File as an OCI image layout tarball with Tags.
+type ImageBuild struct {
+	Mock        Step
+	WorkDir     string
+	Binaries    []string
+	BinDir      string
+	Files       []ImageFile
+	Config      ociimage.Config
+	Annotations map[string]string
+	Created     time.Time
+	Tags        []string
+	File        string
+	Result      struct {
+		Image     *ociimage.Bundle
+		Platforms []string
+		File      string
+	}
+}
+
+func (s *ImageBuild) path(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+
+	return filepath.Join(s.WorkDir, file)
+}
+
+// build builds the image for linux binaries.
+// If read is false, the binaries are not read and an image with empty binaries is built.
+func (s *ImageBuild) build(read bool) (*ociimage.Bundle, []string, error) {
+	if s.BinDir == "" {
+		s.BinDir = "/usr/local/bin"
+	}
+
+	files := []ociimage.File{}
+	for _, f := range s.Files {
+		content, err := ioutil.ReadFile(s.path(f.Src))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files = append(files, ociimage.File{
+			Path:    f.Dst,
+			Content: content,
+			Mode:    f.Mode,
+		})
+	}
+
+	images := []ociimage.Image{}
+	platforms := []string{}
+
+	for _, bin := range s.Binaries {
+		name, arch, ok := linuxBinary(bin)
+		if !ok {
+			continue
+		}
+
+		platform, ok := ociimage.LinuxPlatform(arch)
+		if !ok {
+			continue
+		}
+
+		var content []byte
+		if read {
+			var err error
+			if content, err = ioutil.ReadFile(s.path(bin)); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		binPath := path.Join(s.BinDir, name)
+		config := s.Config
+		if len(config.Entrypoint) == 0 && len(config.Cmd) == 0 {
+			config.Entrypoint = []string{binPath}
+		}
+
+		images = append(images, ociimage.Image{
+			Platform: platform,
+			Config:   config,
+			Files: append([]ociimage.File{
+				{Path: binPath, Content: content, Mode: 0755},
+			}, files...),
+		})
+
+		platforms = append(platforms, platform.String())
+	}
+
+	if len(images) == 0 {
+		return nil, nil, errors.New("no linux binary for the image")
+	}
+
+	image, err := ociimage.Build(images, s.Created, s.Annotations)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return image, platforms, nil
+}
+
+// Dry is a dry run of the step.
+func (s *ImageBuild) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	_, platforms, err := s.build(false)
+	if err != nil {
+		return fmt.Errorf("ImageBuild.Dry: %s", err)
+	}
+
+	s.Result.Platforms = platforms
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ImageBuild) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	image, platforms, err := s.build(true)
+	if err != nil {
+		return fmt.Errorf("ImageBuild.Run: %s", err)
+	}
+
+	var buf bytes.Buffer
+	if err := image.WriteLayout(&buf, s.Tags); err != nil {
+		return fmt.Errorf("ImageBuild.Run: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path(s.File)), 0755); err != nil {
+		return fmt.Errorf("ImageBuild.Run: %s", err)
+	}
+
+	if err := ioutil.WriteFile(s.path(s.File), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("ImageBuild.Run: %s", err)
+	}
+
+	s.Result.Image = image
+	s.Result.Platforms = platforms
+	s.Result.File = s.File
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ImageBuild) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	if s.Result.File == "" {
+		return nil
+	}
+
+	if err := os.Remove(s.path(s.Result.File)); err != nil {
+		return fmt.Errorf("ImageBuild.Revert: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package step
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moorara/cherry/pkg/ociimage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageBuildMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "OK",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := ImageBuild{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestImageBuildDry(t *testing.T) {
+	tests := []struct {
+		name              string
+		workDir           string
+		binaries          []string
+		files             []ImageFile
+		expectedPlatforms []string
+		expectedError     string
+	}{
+		{
+			name:          "FileNotFound",
+			workDir:       "./test",
+			binaries:      []string{"bin/app-linux-amd64"},
+			files:         []ImageFile{{Src: "missing.conf", Dst: "/etc/app/app.conf"}},
+			expectedError: "ImageBuild.Dry: open test/missing.conf: no such file or directory",
+		},
+		{
+			name:          "NoLinuxBinary",
+			workDir:       "./test",
+			binaries:      []string{"bin/app-darwin-amd64", "bin/app-linux-mips"},
+			expectedError: "ImageBuild.Dry: no linux binary for the image",
+		},
+		{
+			name:              "Success",
+			workDir:           "./test",
+			binaries:          []string{"bin/app-linux-amd64", "bin/app-linux-arm64", "bin/app-linux-arm", "bin/app-darwin-amd64", "bin/app-windows-amd64"},
+			files:             []ImageFile{{Src: "VERSION", Dst: "/usr/share/app/VERSION"}},
+			expectedPlatforms: []string{"linux/amd64", "linux/arm64/v8", "linux/arm/v7"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := ImageBuild{
+				WorkDir:  tc.workDir,
+				Binaries: tc.binaries,
+				Files:    tc.files,
+			}
+
+			ctx := context.Background()
+			err := step.Dry(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedPlatforms, step.Result.Platforms)
+				assert.Nil(t, step.Result.Image)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestImageBuildRun(t *testing.T) {
+	tests := []struct {
+		name               string
+		binaries           []string
+		config             ociimage.Config
+		expectedPlatforms  []string
+		expectedEntrypoint []string
+		expectedError      string
+	}{
+		{
+			name:          "BinaryNotFound",
+			binaries:      []string{"bin/app-linux-arm64"},
+			expectedError: "ImageBuild.Run: open %s/bin/app-linux-arm64: no such file or directory",
+		},
+		{
+			name:               "DefaultEntrypoint",
+			binaries:           []string{"bin/app-linux-amd64", "bin/app-linux-arm", "bin/app-darwin-amd64"},
+			expectedPlatforms:  []string{"linux/amd64", "linux/arm/v7"},
+			expectedEntrypoint: []string{"/usr/local/bin/app"},
+		},
+		{
+			name:               "Entrypoint",
+			binaries:           []string{"bin/app-linux-amd64"},
+			config:             ociimage.Config{Entrypoint: []string{"/usr/local/bin/app", "serve"}},
+			expectedPlatforms:  []string{"linux/amd64"},
+			expectedEntrypoint: []string{"/usr/local/bin/app", "serve"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cherry-")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			assert.NoError(t, os.Mkdir(filepath.Join(dir, "bin"), 0755))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin/app-linux-amd64"), []byte("amd64"), 0755))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin/app-linux-arm"), []byte("arm"), 0755))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.conf"), []byte("log: info\n"), 0644))
+
+			step := ImageBuild{
+				WorkDir:  dir,
+				Binaries: tc.binaries,
+				Files:    []ImageFile{{Src: "app.conf", Dst: "/etc/app/app.conf", Mode: 0644}},
+				Config:   tc.config,
+				Created:  time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC),
+				Tags:     []string{"0.2.0", "latest"},
+				File:     "bin/app-image.tar",
+			}
+
+			ctx := context.Background()
+			err = step.Run(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedPlatforms, step.Result.Platforms)
+				assert.Equal(t, "bin/app-image.tar", step.Result.File)
+				assert.NotNil(t, step.Result.Image)
+
+				for _, d := range step.Result.Image.Manifests {
+					manifest, err := step.Result.Image.Manifest(d)
+					assert.NoError(t, err)
+
+					config := struct {
+						Config ociimage.Config `json:"config"`
+					}{}
+					assert.NoError(t, json.Unmarshal(step.Result.Image.Blobs[manifest.Config.Digest], &config))
+					assert.Equal(t, tc.expectedEntrypoint, config.Config.Entrypoint)
+				}
+
+				f, err := os.Open(filepath.Join(dir, "bin/app-image.tar"))
+				assert.NoError(t, err)
+				defer f.Close()
+
+				var index ociimage.Index
+				tr := tar.NewReader(f)
+				for {
+					h, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+					assert.NoError(t, err)
+					if h.Name == "index.json" {
+						assert.NoError(t, json.NewDecoder(tr).Decode(&index))
+					}
+				}
+
+				assert.Len(t, index.Manifests, 2)
+				for _, m := range index.Manifests {
+					assert.Equal(t, step.Result.Image.Index.Digest, m.Digest)
+				}
+
+				err = step.Revert(ctx)
+				assert.NoError(t, err)
+				_, err = os.Stat(filepath.Join(dir, "bin/app-image.tar"))
+				assert.True(t, os.IsNotExist(err))
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, fmt.Sprintf(tc.expectedError, dir), err.Error())
+			}
+		})
+	}
+}
+
+func TestImageBuildRevert(t *testing.T) {
+	tests := []struct {
+		name          string
+		file          string
+		expectedError string
+	}{
+		{
+			name: "NotBuilt",
+			file: "",
+		},
+		{
+			name:          "FileNotFound",
+			file:          "bin/app-image.tar",
+			expectedError: "ImageBuild.Revert: remove test/bin/app-image.tar: no such file or directory",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := ImageBuild{
+				WorkDir: "./test",
+			}
+			step.Result.File = tc.file
+
+			ctx := context.Background()
+			err := step.Revert(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
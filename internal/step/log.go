@@ -0,0 +1,121 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/moorara/cherry/pkg/cui"
+)
+
+// contextKey is the type for the keys added to context.
+type contextKey string
+
+const uiKey = contextKey("UI")
+
+// ContextWithUI returns a new context that has a user interface for logging commands and http requests.
+func ContextWithUI(ctx context.Context, ui cui.CUI) context.Context {
+	return context.WithValue(ctx, uiKey, ui)
+}
+
+// uiFromContext returns the user interface from context.
+// If no user interface found, nil will be returned.
+func uiFromContext(ctx context.Context) cui.CUI {
+	ui, _ := ctx.Value(uiKey).(cui.CUI)
+	return ui
+}
+
+// runCommand runs a command and logs the command line, working directory, and duration at debug level.
+// The captured standard output and error of the command are logged at trace level.
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	ui := uiFromContext(ctx)
+	if ui == nil {
+		return cmd.Run()
+	}
+
+	dir := cmd.Dir
+	if dir == "" {
+		dir = "."
+	}
+
+	ui.Debugf("$ %s  (dir: %s)", strings.Join(cmd.Args, " "), dir)
+
+	start := time.Now()
+	err := cmd.Run()
+	duration := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		ui.Debugf("  %s failed after %s: %s", cmd.Args[0], duration, err)
+	} else {
+		ui.Debugf("  %s finished in %s", cmd.Args[0], duration)
+	}
+
+	if b, ok := cmd.Stdout.(*bytes.Buffer); ok && b.Len() > 0 {
+		ui.Tracef("  stdout: %s", strings.TrimRight(b.String(), "\n"))
+	}
+
+	if b, ok := cmd.Stderr.(*bytes.Buffer); ok && b.Len() > 0 {
+		ui.Tracef("  stderr: %s", strings.TrimRight(b.String(), "\n"))
+	}
+
+	return err
+}
+
+// sensitiveHeaders are not logged even at trace level.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Private-Token":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// debugTransport is an http.RoundTripper that logs requests using the user interface from request context.
+// Method, url, status code, and duration are logged at debug level and headers are logged at trace level.
+type debugTransport struct {
+	Base http.RoundTripper
+}
+
+// RoundTrip executes a single http transaction and logs it.
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ui := uiFromContext(req.Context())
+	if ui == nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	traceHeaders(ui, ">", req.Header)
+
+	start := time.Now()
+	res, err := t.Base.RoundTrip(req)
+	duration := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		ui.Debugf("%s %s failed after %s: %s", req.Method, req.URL, duration, err)
+		return res, err
+	}
+
+	ui.Debugf("%s %s %d (%s)", req.Method, req.URL, res.StatusCode, duration)
+	traceHeaders(ui, "<", res.Header)
+
+	return res, nil
+}
+
+func traceHeaders(ui cui.CUI, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := strings.Join(header[k], ", ")
+		if sensitiveHeaders[k] {
+			v = "***"
+		}
+		ui.Tracef("  %s %s: %s", prefix, k, v)
+	}
+}
@@ -0,0 +1,141 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/moorara/cherry/pkg/cui"
+	"github.com/stretchr/testify/assert"
+)
+
+// readMessages decodes the messages of JSON events written by a JSON user interface.
+func readMessages(t *testing.T, buf *bytes.Buffer) []string {
+	msgs := []string{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var e cui.Event
+		assert.NoError(t, dec.Decode(&e))
+		msgs = append(msgs, e.Level+": "+e.Message)
+	}
+
+	return msgs
+}
+
+func TestContextWithUI(t *testing.T) {
+	assert.Nil(t, uiFromContext(context.Background()))
+
+	ui := &mockCUI{}
+	ctx := ContextWithUI(context.Background(), ui)
+	assert.Equal(t, ui, uiFromContext(ctx))
+}
+
+func TestRunCommand(t *testing.T) {
+	tests := []struct {
+		name             string
+		args             []string
+		level            cui.Level
+		expectedError    string
+		expectedMessages []string
+	}{
+		{
+			name:             "Normal",
+			args:             []string{"echo", "hello"},
+			level:            cui.Normal,
+			expectedMessages: []string{},
+		},
+		{
+			name:  "Verbose",
+			args:  []string{"echo", "hello"},
+			level: cui.Verbose,
+			expectedMessages: []string{
+				"debug: $ echo hello  (dir: test)",
+				"debug: echo finished in ",
+			},
+		},
+		{
+			name:  "Trace",
+			args:  []string{"echo", "--token", "0123456789abcdef"},
+			level: cui.Trace,
+			expectedMessages: []string{
+				"debug: $ echo --token ***  (dir: test)",
+				"debug: echo finished in ",
+				"trace: stdout: --token ***",
+			},
+		},
+		{
+			name:          "Fails",
+			args:          []string{"ls", "null"},
+			level:         cui.Trace,
+			expectedError: "exit status",
+			expectedMessages: []string{
+				"debug: $ ls null  (dir: test)",
+				"debug: ls failed after ",
+				"trace: stderr: ls: ",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			ctx := ContextWithUI(context.Background(), cui.NewJSON(buf, tc.level))
+
+			var stdout, stderr bytes.Buffer
+			cmd := exec.CommandContext(ctx, tc.args[0], tc.args[1:]...)
+			cmd.Dir = "test"
+			cmd.Stdout = &stdout
+			cmd.Stderr = &stderr
+
+			err := runCommand(ctx, cmd)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+
+			msgs := readMessages(t, buf)
+			assert.Len(t, msgs, len(tc.expectedMessages))
+			for i, msg := range msgs {
+				assert.Contains(t, msg, tc.expectedMessages[i])
+			}
+		})
+	}
+}
+
+func TestDebugTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(201)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{
+		Transport: &debugTransport{Base: &http.Transport{}},
+	}
+
+	buf := &bytes.Buffer{}
+	ctx := ContextWithUI(context.Background(), cui.NewJSON(buf, cui.Trace))
+
+	req, _ := http.NewRequest("POST", ts.URL+"/repos/octocat/Hello-World/releases", nil)
+	req.Header.Set("Authorization", "token 0123456789abcdef")
+	res, err := client.Do(req.WithContext(ctx))
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	msgs := readMessages(t, buf)
+	assert.Contains(t, msgs, "trace: > Authorization: ***")
+	assert.Contains(t, msgs, "trace: < X-Ratelimit-Remaining: 4999")
+	assert.Regexp(t, `^debug: POST http://127.0.0.1:\d+/repos/octocat/Hello-World/releases 201 \(\d+m?s\)$`, msgs[1])
+
+	// Requests without a user interface are not logged
+	buf.Reset()
+	res, err = client.Do(req.WithContext(context.Background()))
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Empty(t, buf.String())
+}
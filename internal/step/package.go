@@ -0,0 +1,182 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moorara/cherry/pkg/linuxpkg"
+)
+
+// PackageFile is a file from the working directory installed by Linux packages.
+// If Config is set, the file is marked as a configuration file and is preserved on upgrades.
+type PackageFile struct {
+	Src    string
+	Dst    string
+	Mode   os.FileMode
+	Config bool
+}
+
+// LinuxPackages builds Linux packages (deb, rpm, and apk) for linux binaries built by GoBuild.
+// Binaries are named <binary>-linux-<arch> and other binaries are skipped.
+// Each binary is installed in BinDir as <binary> and the packages are written next to the binaries.
+// Package has the metadata shared by all packages.
+type LinuxPackages struct {
+	Mock     Step
+	WorkDir  string
+	Formats  []string
+	Binaries []string
+	BinDir   string
+	Package  linuxpkg.Package
+	Files    []PackageFile
+	Result   struct {
+		Files []string
+	}
+}
+
+// linuxBinary returns the name and architecture of a linux binary.
+func linuxBinary(bin string) (string, string, bool) {
+	base := filepath.Base(bin)
+	i := strings.LastIndex(base, "-linux-")
+	if i <= 0 {
+		return "", "", false
+	}
+
+	return base[:i], base[i+len("-linux-"):], true
+}
+
+func (s *LinuxPackages) path(file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+
+	return filepath.Join(s.WorkDir, file)
+}
+
+// files reads the files installed by packages in addition to the binary.
+func (s *LinuxPackages) files() ([]linuxpkg.File, error) {
+	files := []linuxpkg.File{}
+	for _, f := range s.Files {
+		content, err := ioutil.ReadFile(s.path(f.Src))
+		if err != nil {
+			return nil, err
+		}
+
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+
+		files = append(files, linuxpkg.File{
+			Path:    f.Dst,
+			Content: content,
+			Mode:    mode,
+			Config:  f.Config,
+		})
+	}
+
+	return files, nil
+}
+
+// build builds the packages for every linux binary and format.
+// If write is false, the binaries are not read and the packages are only validated.
+func (s *LinuxPackages) build(write bool) error {
+	if s.BinDir == "" {
+		s.BinDir = "/usr/bin"
+	}
+
+	files, err := s.files()
+	if err != nil {
+		return err
+	}
+
+	for _, bin := range s.Binaries {
+		name, arch, ok := linuxBinary(bin)
+		if !ok {
+			continue
+		}
+
+		var content []byte
+		if write {
+			if content, err = ioutil.ReadFile(s.path(bin)); err != nil {
+				return err
+			}
+		}
+
+		p := s.Package
+		p.Arch = arch
+		p.Files = append([]linuxpkg.File{
+			{Path: path.Join(s.BinDir, name), Content: content, Mode: 0755},
+		}, files...)
+
+		for _, format := range s.Formats {
+			if !write {
+				if err := p.Validate(format); err != nil {
+					return err
+				}
+				continue
+			}
+
+			var buf bytes.Buffer
+			if err := p.Write(&buf, format); err != nil {
+				return err
+			}
+
+			pkgFile := filepath.Join(filepath.Dir(bin), p.Filename(format))
+			if err := ioutil.WriteFile(s.path(pkgFile), buf.Bytes(), 0644); err != nil {
+				return err
+			}
+
+			s.Result.Files = append(s.Result.Files, pkgFile)
+		}
+	}
+
+	return nil
+}
+
+// Dry is a dry run of the step.
+func (s *LinuxPackages) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if err := s.build(false); err != nil {
+		return fmt.Errorf("LinuxPackages.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *LinuxPackages) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	s.Result.Files = []string{}
+	if err := s.build(true); err != nil {
+		return fmt.Errorf("LinuxPackages.Run: %s", err)
+	}
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *LinuxPackages) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	for _, file := range s.Result.Files {
+		if err := os.Remove(s.path(file)); err != nil {
+			return fmt.Errorf("LinuxPackages.Revert: %s", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,263 @@
+package step
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moorara/cherry/pkg/linuxpkg"
+	"github.com/stretchr/testify/assert"
+)
+
+var testLinuxPackage = linuxpkg.Package{
+	Name:        "app",
+	Version:     "0.1.0",
+	Maintainer:  "Jane Doe <jane@example.com>",
+	Description: "The app tool",
+}
+
+func TestLinuxBinary(t *testing.T) {
+	tests := []struct {
+		bin          string
+		expectedName string
+		expectedArch string
+		expectedOK   bool
+	}{
+		{"bin/app-linux-amd64", "app", "amd64", true},
+		{"app-linux-arm64", "app", "arm64", true},
+		{"bin/my-linux-app-linux-386", "my-linux-app", "386", true},
+		{"bin/app-darwin-amd64", "", "", false},
+		{"bin/app", "", "", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.bin, func(t *testing.T) {
+			name, arch, ok := linuxBinary(tc.bin)
+
+			assert.Equal(t, tc.expectedName, name)
+			assert.Equal(t, tc.expectedArch, arch)
+			assert.Equal(t, tc.expectedOK, ok)
+		})
+	}
+}
+
+func TestLinuxPackagesMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "OK",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := LinuxPackages{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestLinuxPackagesDry(t *testing.T) {
+	tests := []struct {
+		name          string
+		workDir       string
+		formats       []string
+		binaries      []string
+		pkg           linuxpkg.Package
+		files         []PackageFile
+		expectedError string
+	}{
+		{
+			name:          "FileNotFound",
+			workDir:       "./test",
+			formats:       []string{"deb"},
+			binaries:      []string{"bin/app-linux-amd64"},
+			pkg:           testLinuxPackage,
+			files:         []PackageFile{{Src: "missing.conf", Dst: "/etc/app/app.conf"}},
+			expectedError: "LinuxPackages.Dry: open test/missing.conf: no such file or directory",
+		},
+		{
+			name:          "UnsupportedArch",
+			workDir:       "./test",
+			formats:       []string{"deb"},
+			binaries:      []string{"bin/app-linux-mips"},
+			pkg:           testLinuxPackage,
+			expectedError: "LinuxPackages.Dry: unsupported architecture for deb packages: mips",
+		},
+		{
+			name:          "NoMaintainer",
+			workDir:       "./test",
+			formats:       []string{"rpm"},
+			binaries:      []string{"bin/app-linux-amd64"},
+			pkg:           linuxpkg.Package{Name: "app", Version: "0.1.0", Description: "The app tool"},
+			expectedError: "LinuxPackages.Dry: package maintainer is required",
+		},
+		{
+			name:     "Success",
+			workDir:  "./test",
+			formats:  []string{"deb", "rpm", "apk"},
+			binaries: []string{"bin/app-linux-amd64", "bin/app-darwin-amd64", "bin/app-windows-amd64"},
+			pkg:      testLinuxPackage,
+			files:    []PackageFile{{Src: "VERSION", Dst: "/usr/share/app/VERSION", Config: true}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := LinuxPackages{
+				WorkDir:  tc.workDir,
+				Formats:  tc.formats,
+				Binaries: tc.binaries,
+				Package:  tc.pkg,
+				Files:    tc.files,
+			}
+
+			ctx := context.Background()
+			err := step.Dry(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, step.Result.Files)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestLinuxPackagesRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		formats       []string
+		binaries      []string
+		files         []PackageFile
+		expectedFiles []string
+		expectedError string
+	}{
+		{
+			name:          "BinaryNotFound",
+			formats:       []string{"deb"},
+			binaries:      []string{"bin/app-linux-arm64"},
+			expectedError: "LinuxPackages.Run: open %s/bin/app-linux-arm64: no such file or directory",
+		},
+		{
+			name:     "Success",
+			formats:  []string{"deb", "rpm", "apk"},
+			binaries: []string{"bin/app-linux-amd64", "bin/app-darwin-amd64"},
+			files:    []PackageFile{{Src: "app.conf", Dst: "/etc/app/app.conf", Config: true}},
+			expectedFiles: []string{
+				"bin/app_0.1.0_amd64.deb",
+				"bin/app-0.1.0-1.x86_64.rpm",
+				"bin/app_0.1.0_x86_64.apk",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "cherry-")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			assert.NoError(t, os.Mkdir(filepath.Join(dir, "bin"), 0755))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin/app-linux-amd64"), []byte("binary"), 0755))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.conf"), []byte("log: info\n"), 0644))
+
+			step := LinuxPackages{
+				WorkDir:  dir,
+				Formats:  tc.formats,
+				Binaries: tc.binaries,
+				Package:  testLinuxPackage,
+				Files:    tc.files,
+			}
+
+			ctx := context.Background()
+			err = step.Run(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedFiles, step.Result.Files)
+				for _, file := range tc.expectedFiles {
+					assert.FileExists(t, filepath.Join(dir, file))
+				}
+
+				err = step.Revert(ctx)
+				assert.NoError(t, err)
+				for _, file := range tc.expectedFiles {
+					_, err := os.Stat(filepath.Join(dir, file))
+					assert.True(t, os.IsNotExist(err))
+				}
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, fmt.Sprintf(tc.expectedError, dir), err.Error())
+			}
+		})
+	}
+}
+
+func TestLinuxPackagesRevert(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         []string
+		expectedError string
+	}{
+		{
+			name:          "FileNotFound",
+			files:         []string{"bin/app_0.1.0_amd64.deb"},
+			expectedError: "LinuxPackages.Revert: remove test/bin/app_0.1.0_amd64.deb: no such file or directory",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := LinuxPackages{
+				WorkDir: "./test",
+			}
+			step.Result.Files = tc.files
+
+			ctx := context.Background()
+			err := step.Revert(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package step
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	netURL "net/url"
+
+	"github.com/moorara/cherry/pkg/cui"
+)
+
+const (
+	// ProviderGitHub is the release provider for GitHub repositories.
+	ProviderGitHub = "github"
+	// ProviderGitLab is the release provider for GitLab repositories.
+	ProviderGitLab = "gitlab"
+	// ProviderGitea is the release provider for Gitea and Forgejo repositories.
+	ProviderGitea = "gitea"
+)
+
+type (
+	// ReleaseData is used for creating or modifying a release.
+	ReleaseData struct {
+		Name       string
+		TagName    string
+		Target     string
+		Draft      bool
+		Prerelease bool
+		Body       string
+	}
+
+	// Release represents a release independent of the release provider.
+	// ID and UploadURL are only set if the release provider supports them.
+	Release struct {
+		ID         int
+		Name       string
+		TagName    string
+		Target     string
+		Draft      bool
+		Prerelease bool
+		Body       string
+		URL        string
+		UploadURL  string
+		Assets     []ReleaseAsset
+	}
+
+	// ReleaseAsset represents an asset (file) of a release.
+	ReleaseAsset struct {
+		ID          int
+		Name        string
+		Size        int
+		DownloadURL string
+	}
+)
+
+// ReleaseProvider is the interface for a service hosting repositories and releases.
+type ReleaseProvider interface {
+	// Name returns the name of release provider.
+	Name() string
+	// ListReleases returns the releases of the repository.
+	ListReleases(ctx context.Context) ([]Release, error)
+	// GetLatestRelease returns the latest published release of the repository.
+	GetLatestRelease(ctx context.Context) (Release, error)
+	// GetReleaseByTag returns the release for a tag.
+	GetReleaseByTag(ctx context.Context, tag string) (Release, error)
+	// CreateRelease creates a new release.
+	CreateRelease(ctx context.Context, data ReleaseData) (Release, error)
+	// EditRelease modifies an existing release.
+	EditRelease(ctx context.Context, release Release, data ReleaseData) (Release, error)
+	// DeleteRelease deletes an existing release.
+	DeleteRelease(ctx context.Context, release Release) error
+	// UploadAssets uploads files to a release.
+	UploadAssets(ctx context.Context, release Release, files []string) ([]ReleaseAsset, error)
+	// DeleteAssets deletes uploaded assets of a release.
+	DeleteAssets(ctx context.Context, release Release, assets []ReleaseAsset) error
+	// DownloadAsset downloads an asset of a release and writes it to a local file.
+	DownloadAsset(ctx context.Context, tag, assetName, filepath string) (int64, error)
+	// BranchProtected determines whether or not pushing to a branch is restricted.
+	BranchProtected(ctx context.Context, branch string) (bool, error)
+	// ProtectBranch restricts pushing to a branch.
+	ProtectBranch(ctx context.Context, branch string) error
+	// UnprotectBranch temporarily allows pushing to a branch.
+	UnprotectBranch(ctx context.Context, branch string) error
+}
+
+// ProviderConfig has the configurations for creating release providers.
+type ProviderConfig struct {
+	GitHubToken     string
+	GitHubURL       string
+	GitHubUploadURL string
+	// GitHub App credentials for authenticating as an installation of the app.
+	// If the installation id is not set, it will be found for the repository.
+	GitHubAppID             int64
+	GitHubAppPrivateKey     string
+	GitHubAppInstallationID int64
+	// GitHubCredentialHelper is a command that prints a token for GitHub (i.e. gh auth token).
+	GitHubCredentialHelper string
+	// GitHubCredentialsFile is the path to a gh-style hosts.yml file.
+	GitHubCredentialsFile string
+	GitLabToken           string
+	GiteaURL              string
+	GiteaToken            string
+	// Docker credentials for pushing images to a container registry.
+	DockerUsername string
+	DockerPassword string
+	// CABundle is the path to a PEM file with additional trusted CA certificates.
+	CABundle string
+	// UI is used for reporting the progress of uploads and downloads if set.
+	UI cui.CUI
+}
+
+// NewReleaseProvider creates a release provider for a repository.
+// If name is empty, the release provider will be determined from the host of repository.
+func NewReleaseProvider(client *http.Client, config ProviderConfig, name, host, repo string) (ReleaseProvider, error) {
+	if name == "" {
+		name = detectProvider(config, host)
+	}
+
+	if config.CABundle != "" {
+		var err error
+		if client, err = withCABundle(client, config.CABundle); err != nil {
+			return nil, err
+		}
+	}
+
+	switch name {
+	case ProviderGitHub:
+		url, apiURL, uploadURL := githubURLs(config.GitHubURL, config.GitHubUploadURL, host)
+		p := &GitHubProvider{
+			Client:    client,
+			URL:       url,
+			APIURL:    apiURL,
+			UploadURL: uploadURL,
+			Repo:      repo,
+			UI:        config.UI,
+		}
+
+		if err := p.authenticate(config, host); err != nil {
+			return nil, err
+		}
+
+		return p, nil
+
+	case ProviderGitLab:
+		return &GitLabProvider{
+			Client: client,
+			Token:  config.GitLabToken,
+			APIURL: gitlabAPIURL(host),
+			Repo:   repo,
+		}, nil
+
+	case ProviderGitea:
+		baseURL := strings.TrimSuffix(config.GiteaURL, "/")
+		if baseURL == "" {
+			baseURL = "https://" + host
+		}
+
+		return &GiteaProvider{
+			Client: client,
+			Token:  config.GiteaToken,
+			URL:    baseURL,
+			APIURL: baseURL + "/api/v1",
+			Repo:   repo,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown release provider: %s", name)
+	}
+}
+
+// detectProvider determines the release provider from the host of a repository.
+func detectProvider(config ProviderConfig, host string) string {
+	if u, err := netURL.Parse(config.GitHubURL); err == nil && u.Host != "" && u.Host == host {
+		return ProviderGitHub
+	}
+
+	if u, err := netURL.Parse(config.GiteaURL); err == nil && u.Host != "" && u.Host == host {
+		return ProviderGitea
+	}
+
+	switch {
+	case strings.Contains(host, "gitlab"):
+		return ProviderGitLab
+	case strings.Contains(host, "gitea"), strings.Contains(host, "forgejo"), host == "codeberg.org":
+		return ProviderGitea
+	default:
+		return ProviderGitHub
+	}
+}
+
+// githubURLs returns the web, API, and upload urls for github.com or a GitHub Enterprise Server.
+// For github.com, the upload url is empty and the upload url of each release is used.
+func githubURLs(baseURL, uploadURL, host string) (string, string, string) {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	baseURL = strings.TrimSuffix(baseURL, "/api/v3")
+	uploadURL = strings.TrimSuffix(uploadURL, "/")
+
+	if baseURL == "" {
+		if host == "" || host == "github.com" {
+			return GitHubURL, GitHubAPIURL, uploadURL
+		}
+		baseURL = "https://" + host
+	}
+
+	if uploadURL == "" {
+		uploadURL = baseURL + "/api/uploads"
+	}
+
+	return baseURL, baseURL + "/api/v3", uploadURL
+}
+
+// withCABundle returns a copy of an http client that trusts the certificates in a PEM file in addition to system ones.
+func withCABundle(client *http.Client, caBundle string) (*http.Client, error) {
+	pem, err := ioutil.ReadFile(caBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", caBundle)
+	}
+
+	c := *client
+	c.Transport = withRootCAs(client.Transport, pool)
+
+	return &c, nil
+}
+
+// withRootCAs returns a copy of an http transport with a set of root certificate authorities.
+// The transports wrapping an http.Transport are copied too.
+// Any other transport is replaced by a copy of the default transport, so the proxy and timeouts are kept.
+func withRootCAs(rt http.RoundTripper, pool *x509.CertPool) http.RoundTripper {
+	switch t := rt.(type) {
+	case *retryTransport:
+		c := *t
+		c.Base = withRootCAs(t.Base, pool)
+		return &c
+
+	case *debugTransport:
+		c := *t
+		c.Base = withRootCAs(t.Base, pool)
+		return &c
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t, ok := rt.(*http.Transport); ok && t != nil {
+		transport = t.Clone()
+	}
+
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	transport.TLSClientConfig.RootCAs = pool
+
+	return transport
+}
+
+// gitlabAPIURL returns the GitLab API url for a GitLab host.
+func gitlabAPIURL(host string) string {
+	if host == "" || host == "gitlab.com" {
+		return GitLabAPIURL
+	}
+
+	return fmt.Sprintf("https://%s/api/v4", host)
+}
+
+// findAsset returns an asset of a release by name.
+func findAsset(release Release, assetName string) (ReleaseAsset, bool) {
+	for _, asset := range release.Assets {
+		if asset.Name == assetName {
+			return asset, true
+		}
+	}
+
+	return ReleaseAsset{}, false
+}
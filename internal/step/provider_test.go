@@ -0,0 +1,289 @@
+package step
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewReleaseProvider(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           ProviderConfig
+		providerName     string
+		host             string
+		repo             string
+		expectedError    error
+		expectedProvider ReleaseProvider
+	}{
+		{
+			name:          "UnknownProvider",
+			providerName:  "bitbucket",
+			host:          "bitbucket.org",
+			repo:          "octocat/Hello-World",
+			expectedError: errors.New("unknown release provider: bitbucket"),
+		},
+		{
+			name:   "GitHub",
+			config: ProviderConfig{GitHubToken: "github-token"},
+			host:   "github.com",
+			repo:   "octocat/Hello-World",
+			expectedProvider: &GitHubProvider{
+				Client: &http.Client{},
+				Token:  "github-token",
+				URL:    GitHubURL,
+				APIURL: GitHubAPIURL,
+				Repo:   "octocat/Hello-World",
+			},
+		},
+		{
+			name:   "GitHubEnterprise",
+			config: ProviderConfig{GitHubToken: "github-token"},
+			host:   "github.example.com",
+			repo:   "octocat/Hello-World",
+			expectedProvider: &GitHubProvider{
+				Client:    &http.Client{},
+				Token:     "github-token",
+				URL:       "https://github.example.com",
+				APIURL:    "https://github.example.com/api/v3",
+				UploadURL: "https://github.example.com/api/uploads",
+				Repo:      "octocat/Hello-World",
+			},
+		},
+		{
+			name:          "InvalidCABundle",
+			config:        ProviderConfig{CABundle: "test/null"},
+			host:          "github.example.com",
+			repo:          "octocat/Hello-World",
+			expectedError: errors.New("open test/null: no such file or directory"),
+		},
+		{
+			name:   "GitLab",
+			config: ProviderConfig{GitLabToken: "gitlab-token"},
+			host:   "gitlab.com",
+			repo:   "octocat/Hello-World",
+			expectedProvider: &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: GitLabAPIURL,
+				Repo:   "octocat/Hello-World",
+			},
+		},
+		{
+			name:   "SelfHostedGitLab",
+			config: ProviderConfig{GitLabToken: "gitlab-token"},
+			host:   "gitlab.example.com",
+			repo:   "octocat/Hello-World",
+			expectedProvider: &GitLabProvider{
+				Client: &http.Client{},
+				Token:  "gitlab-token",
+				APIURL: "https://gitlab.example.com/api/v4",
+				Repo:   "octocat/Hello-World",
+			},
+		},
+		{
+			name:   "Codeberg",
+			config: ProviderConfig{GiteaToken: "gitea-token"},
+			host:   "codeberg.org",
+			repo:   "octocat/Hello-World",
+			expectedProvider: &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				URL:    "https://codeberg.org",
+				APIURL: "https://codeberg.org/api/v1",
+				Repo:   "octocat/Hello-World",
+			},
+		},
+		{
+			name:   "GiteaURL",
+			config: ProviderConfig{GiteaURL: "https://git.example.com/", GiteaToken: "gitea-token"},
+			host:   "git.example.com",
+			repo:   "octocat/Hello-World",
+			expectedProvider: &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				URL:    "https://git.example.com",
+				APIURL: "https://git.example.com/api/v1",
+				Repo:   "octocat/Hello-World",
+			},
+		},
+		{
+			name:         "ExplicitProvider",
+			config:       ProviderConfig{GiteaToken: "gitea-token"},
+			providerName: "gitea",
+			host:         "git.example.com",
+			repo:         "octocat/Hello-World",
+			expectedProvider: &GiteaProvider{
+				Client: &http.Client{},
+				Token:  "gitea-token",
+				URL:    "https://git.example.com",
+				APIURL: "https://git.example.com/api/v1",
+				Repo:   "octocat/Hello-World",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, err := NewReleaseProvider(&http.Client{}, tc.config, tc.providerName, tc.host, tc.repo)
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedError.Error())
+			}
+			assert.Equal(t, tc.expectedProvider, provider)
+		})
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           ProviderConfig
+		host             string
+		expectedProvider string
+	}{
+		{"Empty", ProviderConfig{}, "", ProviderGitHub},
+		{"GitHub", ProviderConfig{}, "github.com", ProviderGitHub},
+		{"GitHubEnterprise", ProviderConfig{}, "git.example.com", ProviderGitHub},
+		{"GitLab", ProviderConfig{}, "gitlab.com", ProviderGitLab},
+		{"SelfHostedGitLab", ProviderConfig{}, "gitlab.example.com", ProviderGitLab},
+		{"Gitea", ProviderConfig{}, "gitea.example.com", ProviderGitea},
+		{"Forgejo", ProviderConfig{}, "forgejo.example.com", ProviderGitea},
+		{"Codeberg", ProviderConfig{}, "codeberg.org", ProviderGitea},
+		{"GiteaURL", ProviderConfig{GiteaURL: "https://git.example.com"}, "git.example.com", ProviderGitea},
+		{"GitHubURL", ProviderConfig{GitHubURL: "https://gitlab.example.com"}, "gitlab.example.com", ProviderGitHub},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedProvider, detectProvider(tc.config, tc.host))
+		})
+	}
+}
+
+func TestGitHubURLs(t *testing.T) {
+	tests := []struct {
+		name              string
+		baseURL           string
+		uploadURL         string
+		host              string
+		expectedURL       string
+		expectedAPIURL    string
+		expectedUploadURL string
+	}{
+		{"GitHub", "", "", "github.com", GitHubURL, GitHubAPIURL, ""},
+		{"EnterpriseFromHost", "", "", "github.example.com", "https://github.example.com", "https://github.example.com/api/v3", "https://github.example.com/api/uploads"},
+		{"EnterpriseFromBaseURL", "https://github.example.com/", "", "", "https://github.example.com", "https://github.example.com/api/v3", "https://github.example.com/api/uploads"},
+		{"EnterpriseFromAPIURL", "https://github.example.com/api/v3", "", "", "https://github.example.com", "https://github.example.com/api/v3", "https://github.example.com/api/uploads"},
+		{"EnterpriseWithUploadURL", "https://github.example.com", "https://uploads.example.com/", "", "https://github.example.com", "https://github.example.com/api/v3", "https://uploads.example.com"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			url, apiURL, uploadURL := githubURLs(tc.baseURL, tc.uploadURL, tc.host)
+			assert.Equal(t, tc.expectedURL, url)
+			assert.Equal(t, tc.expectedAPIURL, apiURL)
+			assert.Equal(t, tc.expectedUploadURL, uploadURL)
+		})
+	}
+}
+
+func TestWithCABundle(t *testing.T) {
+	tests := []struct {
+		name          string
+		caBundle      string
+		expectedError string
+	}{
+		{
+			name:          "NoFile",
+			caBundle:      "test/null",
+			expectedError: "open test/null: no such file or directory",
+		},
+		{
+			name:          "NoCertificate",
+			caBundle:      "test/empty",
+			expectedError: "no certificate found in test/empty",
+		},
+		{
+			name:     "Success",
+			caBundle: "test/ca.pem",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &http.Client{Transport: &http.Transport{}}
+			c, err := withCABundle(client, tc.caBundle)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.NotEqual(t, client.Transport, c.Transport)
+				assert.NotNil(t, c.Transport.(*http.Transport).TLSClientConfig.RootCAs)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.Nil(t, c)
+			}
+		})
+	}
+}
+
+func TestWithCABundleRetryTransport(t *testing.T) {
+	client := NewHTTPClient()
+	c, err := withCABundle(client, "test/ca.pem")
+	assert.NoError(t, err)
+
+	rt, ok := c.Transport.(*retryTransport)
+	assert.True(t, ok)
+	assert.Equal(t, defaultMaxRetries, rt.MaxRetries)
+	assert.NotNil(t, rt.Base.(*debugTransport).Base.(*http.Transport).TLSClientConfig.RootCAs)
+	assert.Nil(t, client.Transport.(*retryTransport).Base.(*debugTransport).Base.(*http.Transport).TLSClientConfig)
+}
+
+func TestWithCABundleDefaultTransport(t *testing.T) {
+	client := &http.Client{}
+	c, err := withCABundle(client, "test/ca.pem")
+	assert.NoError(t, err)
+
+	transport, ok := c.Transport.(*http.Transport)
+	assert.True(t, ok)
+	assert.NotNil(t, transport.Proxy)
+	assert.NotZero(t, transport.TLSHandshakeTimeout)
+	assert.NotNil(t, transport.TLSClientConfig.RootCAs)
+	assert.NotEqual(t, http.DefaultTransport, c.Transport)
+}
+
+func TestGitLabAPIURL(t *testing.T) {
+	tests := []struct {
+		host           string
+		expectedAPIURL string
+	}{
+		{"", GitLabAPIURL},
+		{"gitlab.com", GitLabAPIURL},
+		{"gitlab.example.com", "https://gitlab.example.com/api/v4"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.host, func(t *testing.T) {
+			assert.Equal(t, tc.expectedAPIURL, gitlabAPIURL(tc.host))
+		})
+	}
+}
+
+func TestFindAsset(t *testing.T) {
+	release := Release{
+		Assets: []ReleaseAsset{
+			{ID: 1, Name: "app-linux-amd64"},
+			{ID: 2, Name: "app-darwin-amd64"},
+		},
+	}
+
+	asset, ok := findAsset(release, "app-darwin-amd64")
+	assert.True(t, ok)
+	assert.Equal(t, ReleaseAsset{ID: 2, Name: "app-darwin-amd64"}, asset)
+
+	_, ok = findAsset(release, "app-windows-amd64")
+	assert.False(t, ok)
+}
@@ -0,0 +1,477 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/moorara/cherry/pkg/ociimage"
+
+	netURL "net/url"
+)
+
+// challengeParamRE matches the parameters of a WWW-Authenticate header.
+var challengeParamRE = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// manifestMediaTypes are the media types of manifests and indexes accepted when reading a manifest from a registry.
+var manifestMediaTypes = []string{
+	ociimage.MediaTypeImageIndex,
+	ociimage.MediaTypeImageManifest,
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// registryManifest is a manifest or an index read from a registry.
+type registryManifest struct {
+	mediaType string
+	digest    string
+	data      []byte
+}
+
+// registryClient talks to a container registry using the OCI distribution API.
+// Requests are authenticated with basic credentials or a bearer token when the registry asks for them.
+// See https://github.com/opencontainers/distribution-spec/blob/main/spec.md
+// See https://docs.docker.com/registry/spec/auth/token
+type registryClient struct {
+	client   *http.Client
+	baseURL  string
+	repo     string
+	username string
+	password string
+	auth     string
+}
+
+func newRegistryClient(client *http.Client, registry, repo, username, password string, insecure bool) *registryClient {
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+
+	return &registryClient{
+		client:   client,
+		baseURL:  fmt.Sprintf("%s://%s", scheme, registry),
+		repo:     repo,
+		username: username,
+		password: password,
+	}
+}
+
+// authenticate sets the authorization for next requests using the challenge of a 401 response.
+func (c *registryClient) authenticate(ctx context.Context, challenge string) error {
+	params := map[string]string{}
+	for _, m := range challengeParamRE.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+
+	switch scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0]); scheme {
+	case "basic":
+		if c.username == "" {
+			return errors.New("registry requires credentials")
+		}
+
+		c.auth = "Basic " + base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password))
+
+		return nil
+
+	case "bearer":
+		url, err := netURL.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return fmt.Errorf("invalid registry auth challenge: %s", challenge)
+		}
+
+		q := url.Query()
+		if params["service"] != "" {
+			q.Set("service", params["service"])
+		}
+		if params["scope"] != "" {
+			q.Set("scope", params["scope"])
+		}
+		url.RawQuery = q.Encode()
+
+		req, err := http.NewRequest("GET", url.String(), nil)
+		if err != nil {
+			return err
+		}
+
+		req = req.WithContext(ctx)
+		req.Header.Set("User-Agent", "cherry")
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		res, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != 200 {
+			return newHTTPError(res)
+		}
+
+		body := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			return err
+		}
+
+		token := body.Token
+		if token == "" {
+			token = body.AccessToken
+		}
+
+		c.auth = "Bearer " + token
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported registry auth scheme: %s", scheme)
+	}
+}
+
+// do sends a request to the registry and authenticates if the registry asks for it.
+// The url is either a path (/v2/...) or an absolute url returned by the registry.
+// The credentials are only sent to the registry and not to other hosts (i.e. a storage for uploads).
+func (c *registryClient) do(ctx context.Context, method, url string, header http.Header, body []byte) (*http.Response, error) {
+	if strings.HasPrefix(url, "/") {
+		url = c.baseURL + url
+	}
+
+	base, err := netURL.Parse(c.baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		var r io.Reader
+		if body != nil {
+			r = bytes.NewReader(body)
+		}
+
+		req, err := http.NewRequest(method, url, r)
+		if err != nil {
+			return nil, err
+		}
+
+		req = req.WithContext(ctx)
+		req.Header.Set("User-Agent", "cherry")
+		for key, vals := range header {
+			req.Header[key] = vals
+		}
+		if c.auth != "" && req.URL.Host == base.Host {
+			req.Header.Set("Authorization", c.auth)
+		}
+
+		res, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.StatusCode != 401 || attempt > 0 {
+			return res, nil
+		}
+
+		challenge := res.Header.Get("WWW-Authenticate")
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+
+		if challenge == "" {
+			return nil, errors.New("registry returned 401 with no auth challenge")
+		}
+
+		if err := c.authenticate(ctx, challenge); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// expect sends a request and makes sure the response has the expected status code.
+func (c *registryClient) expect(ctx context.Context, status int, method, url string, header http.Header, body []byte) (*http.Response, error) {
+	res, err := c.do(ctx, method, url, header, body)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != status {
+		return nil, newHTTPError(res)
+	}
+
+	return res, nil
+}
+
+// ping checks the registry supports the distribution API and the credentials are valid.
+func (c *registryClient) ping(ctx context.Context) error {
+	_, err := c.expect(ctx, 200, "GET", "/v2/", nil, nil)
+	return err
+}
+
+// hasBlob checks whether or not a blob already exists in the repository.
+func (c *registryClient) hasBlob(ctx context.Context, digest string) (bool, error) {
+	res, err := c.do(ctx, "HEAD", fmt.Sprintf("/v2/%s/blobs/%s", c.repo, digest), nil, nil)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+		return true, nil
+	case 404:
+		return false, nil
+	default:
+		return false, newHTTPError(res)
+	}
+}
+
+// uploadBlob uploads a blob to the repository in a single request after starting an upload session.
+func (c *registryClient) uploadBlob(ctx context.Context, digest string, data []byte) error {
+	res, err := c.expect(ctx, 202, "POST", fmt.Sprintf("/v2/%s/blobs/uploads/", c.repo), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	base, _ := netURL.Parse(c.baseURL)
+	location, err := base.Parse(res.Header.Get("Location"))
+	if err != nil {
+		return err
+	}
+
+	q := location.Query()
+	q.Set("digest", digest)
+	location.RawQuery = q.Encode()
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+
+	_, err = c.expect(ctx, 201, "PUT", location.String(), header, data)
+	return err
+}
+
+// getManifest reads a manifest or an index by a reference (a tag or a digest).
+// If the manifest does not exist, nil is returned.
+func (c *registryClient) getManifest(ctx context.Context, ref string) (*registryManifest, error) {
+	header := http.Header{}
+	header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	res, err := c.do(ctx, "GET", fmt.Sprintf("/v2/%s/manifests/%s", c.repo, ref), header, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200:
+	case 404:
+		return nil, nil
+	default:
+		return nil, newHTTPError(res)
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &registryManifest{
+		mediaType: res.Header.Get("Content-Type"),
+		digest:    fmt.Sprintf("sha256:%x", sha256.Sum256(data)),
+		data:      data,
+	}, nil
+}
+
+// putManifest uploads a manifest or an index with a reference (a tag or a digest).
+func (c *registryClient) putManifest(ctx context.Context, ref, mediaType string, data []byte) error {
+	header := http.Header{}
+	header.Set("Content-Type", mediaType)
+
+	_, err := c.expect(ctx, 201, "PUT", fmt.Sprintf("/v2/%s/manifests/%s", c.repo, ref), header, data)
+	return err
+}
+
+// deleteManifest deletes a manifest by its digest and the tags referencing it.
+// Many registries do not support deleting by tag and some have deleting disabled (405), which is not an error.
+func (c *registryClient) deleteManifest(ctx context.Context, digest string) error {
+	res, err := c.do(ctx, "DELETE", fmt.Sprintf("/v2/%s/manifests/%s", c.repo, digest), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 200, 202, 404, 405:
+		return nil
+	default:
+		return newHTTPError(res)
+	}
+}
+
+// ImagePush pushes a multi-platform image to a repository in a container registry and tags it.
+// Registry is the host (and port) of registry and Insecure uses plain http (i.e. for a local registry).
+// Reverting the step points the tags that existed before (i.e. latest) back to their previous manifests
+// and deletes the pushed image by digest if it is not referenced by a restored tag.
+// Deleting is best-effort: if the registry does not allow deleting manifests, the new tags are kept.
+type ImagePush struct {
+	Mock       Step
+	Client     *http.Client
+	Registry   string
+	Repository string
+	Insecure   bool
+	Username   string
+	Password   string
+	Image      *ociimage.Bundle
+	Tags       []string
+	Result     struct {
+		Digest string
+		Tags   []string
+	}
+	registry *registryClient
+	previous map[string]*registryManifest
+}
+
+func (s *ImagePush) client() *registryClient {
+	if s.registry == nil {
+		s.registry = newRegistryClient(s.Client, s.Registry, s.Repository, s.Username, s.Password, s.Insecure)
+	}
+
+	return s.registry
+}
+
+// pushBlob uploads a blob if it does not already exist in the repository.
+func (s *ImagePush) pushBlob(ctx context.Context, d ociimage.Descriptor) error {
+	c := s.client()
+
+	exists, err := c.hasBlob(ctx, d.Digest)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	data, ok := s.Image.Blobs[d.Digest]
+	if !ok {
+		return fmt.Errorf("blob not found: %s", d.Digest)
+	}
+
+	return c.uploadBlob(ctx, d.Digest, data)
+}
+
+// Dry is a dry run of the step.
+func (s *ImagePush) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if err := s.client().ping(ctx); err != nil {
+		return fmt.Errorf("ImagePush.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ImagePush) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	if s.Image == nil {
+		return errors.New("ImagePush.Run: no image to push")
+	}
+
+	c := s.client()
+	s.Result.Tags = []string{}
+	s.previous = map[string]*registryManifest{}
+
+	// The blobs of every manifest are pushed before the manifest and the manifests are pushed before the index
+	for _, d := range s.Image.Manifests {
+		manifest, err := s.Image.Manifest(d)
+		if err != nil {
+			return fmt.Errorf("ImagePush.Run: %s", err)
+		}
+
+		for _, blob := range append([]ociimage.Descriptor{manifest.Config}, manifest.Layers...) {
+			if err := s.pushBlob(ctx, blob); err != nil {
+				return fmt.Errorf("ImagePush.Run: %s", err)
+			}
+		}
+
+		if err := c.putManifest(ctx, d.Digest, d.MediaType, s.Image.Blobs[d.Digest]); err != nil {
+			return fmt.Errorf("ImagePush.Run: %s", err)
+		}
+	}
+
+	index := s.Image.Blobs[s.Image.Index.Digest]
+	for _, tag := range s.Tags {
+		// The current manifest of tag is kept, so the tag can be restored
+		prev, err := c.getManifest(ctx, tag)
+		if err != nil {
+			return fmt.Errorf("ImagePush.Run: %s", err)
+		}
+		s.previous[tag] = prev
+
+		if err := c.putManifest(ctx, tag, s.Image.Index.MediaType, index); err != nil {
+			return fmt.Errorf("ImagePush.Run: %s", err)
+		}
+
+		s.Result.Tags = append(s.Result.Tags, tag)
+	}
+
+	s.Result.Digest = s.Image.Index.Digest
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ImagePush) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	if len(s.Result.Tags) == 0 {
+		return nil
+	}
+
+	c := s.client()
+	created, existed := false, false
+
+	for _, tag := range s.Result.Tags {
+		prev := s.previous[tag]
+		if prev == nil {
+			created = true
+			continue
+		}
+
+		if err := c.putManifest(ctx, tag, prev.mediaType, prev.data); err != nil {
+			return fmt.Errorf("ImagePush.Revert: %s", err)
+		}
+
+		// The pushed image existed before, so it should not be deleted
+		if prev.digest == s.Result.Digest {
+			existed = true
+		}
+	}
+
+	// Deleting the pushed image removes the new tags
+	if created && !existed {
+		if err := c.deleteManifest(ctx, s.Result.Digest); err != nil {
+			return fmt.Errorf("ImagePush.Revert: %s", err)
+		}
+	}
+
+	return nil
+}
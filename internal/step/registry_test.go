@@ -0,0 +1,533 @@
+package step
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/moorara/cherry/pkg/ociimage"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	blobPathRE     = regexp.MustCompile(`^/v2/(.+)/blobs/(sha256:[0-9a-f]+)$`)
+	uploadPathRE   = regexp.MustCompile(`^/v2/(.+)/blobs/uploads/(\w*)$`)
+	manifestPathRE = regexp.MustCompile(`^/v2/(.+)/manifests/([\w.:-]+)$`)
+)
+
+// mockRegistry is an in-memory container registry implementing the OCI distribution API for tests.
+// If auth is basic or bearer, the requests should be authenticated with the username and password.
+// Like the reference registry, manifests can only be deleted by digest and only if deleting is enabled.
+type mockRegistry struct {
+	sync.Mutex
+	auth          string
+	username      string
+	password      string
+	deleteEnabled bool
+	server        *httptest.Server
+	blobs         map[string][]byte
+	manifests     map[string][]byte
+	mediaTypes    map[string]string
+	tags          map[string]string
+	requests      []string
+}
+
+func newMockRegistry(auth, username, password string) *mockRegistry {
+	r := &mockRegistry{
+		auth:          auth,
+		username:      username,
+		password:      password,
+		deleteEnabled: true,
+		blobs:         map[string][]byte{},
+		manifests:     map[string][]byte{},
+		mediaTypes:    map[string]string{},
+		tags:          map[string]string{},
+	}
+
+	r.server = httptest.NewServer(r)
+
+	return r
+}
+
+func (r *mockRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+func (r *mockRegistry) authorized(req *http.Request) bool {
+	switch r.auth {
+	case "basic":
+		username, password, ok := req.BasicAuth()
+		return ok && username == r.username && password == r.password
+	case "bearer":
+		return req.Header.Get("Authorization") == "Bearer secret-token"
+	default:
+		return true
+	}
+}
+
+func (r *mockRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+
+	if req.URL.Path == "/token" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != r.username || password != r.password {
+			w.WriteHeader(401)
+			return
+		}
+		fmt.Fprint(w, `{"token":"secret-token"}`)
+		return
+	}
+
+	if !r.authorized(req) {
+		switch r.auth {
+		case "basic":
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		case "bearer":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:username/app:pull,push"`, r.server.URL))
+		}
+		w.WriteHeader(401)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+
+	switch path := req.URL.Path; {
+	case path == "/v2/":
+		w.WriteHeader(200)
+
+	case blobPathRE.MatchString(path) && req.Method == "HEAD":
+		digest := blobPathRE.FindStringSubmatch(path)[2]
+		if _, ok := r.blobs[digest]; ok {
+			w.WriteHeader(200)
+		} else {
+			w.WriteHeader(404)
+		}
+
+	case uploadPathRE.MatchString(path) && req.Method == "POST":
+		repo := uploadPathRE.FindStringSubmatch(path)[1]
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/session1?state=abc", repo))
+		w.WriteHeader(202)
+
+	case uploadPathRE.MatchString(path) && req.Method == "PUT":
+		digest := req.URL.Query().Get("digest")
+		if req.URL.Query().Get("state") != "abc" || digest != fmt.Sprintf("sha256:%x", sha256.Sum256(body)) {
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"errors":[{"code":"DIGEST_INVALID"}]}`)
+			return
+		}
+		r.blobs[digest] = body
+		w.WriteHeader(201)
+
+	case manifestPathRE.MatchString(path) && req.Method == "PUT":
+		ref := manifestPathRE.FindStringSubmatch(path)[2]
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+		if strings.HasPrefix(ref, "sha256:") && ref != digest {
+			w.WriteHeader(400)
+			return
+		}
+
+		// All blobs and manifests referenced by a manifest or an index should exist
+		for _, d := range regexp.MustCompile(`sha256:[0-9a-f]{64}`).FindAllString(string(body), -1) {
+			if _, ok := r.blobs[d]; !ok {
+				if _, ok := r.manifests[d]; !ok {
+					w.WriteHeader(400)
+					fmt.Fprint(w, `{"errors":[{"code":"MANIFEST_BLOB_UNKNOWN"}]}`)
+					return
+				}
+			}
+		}
+
+		r.manifests[digest] = body
+		r.mediaTypes[digest] = req.Header.Get("Content-Type")
+		if !strings.HasPrefix(ref, "sha256:") {
+			r.tags[ref] = digest
+		}
+		w.WriteHeader(201)
+
+	case manifestPathRE.MatchString(path) && req.Method == "GET":
+		digest := manifestPathRE.FindStringSubmatch(path)[2]
+		if d, ok := r.tags[digest]; ok {
+			digest = d
+		}
+		body, ok := r.manifests[digest]
+		if !ok {
+			w.WriteHeader(404)
+			return
+		}
+		w.Header().Set("Content-Type", r.mediaTypes[digest])
+		_, _ = w.Write(body)
+
+	case manifestPathRE.MatchString(path) && req.Method == "DELETE":
+		digest := manifestPathRE.FindStringSubmatch(path)[2]
+		if !r.deleteEnabled || !strings.HasPrefix(digest, "sha256:") {
+			w.WriteHeader(405)
+			fmt.Fprint(w, `{"errors":[{"code":"UNSUPPORTED"}]}`)
+			return
+		}
+		if _, ok := r.manifests[digest]; !ok {
+			w.WriteHeader(404)
+			return
+		}
+		delete(r.manifests, digest)
+		for tag, d := range r.tags {
+			if d == digest {
+				delete(r.tags, tag)
+			}
+		}
+		w.WriteHeader(202)
+
+	default:
+		w.WriteHeader(405)
+	}
+}
+
+func testImage(t *testing.T, version string) *ociimage.Bundle {
+	image, err := ociimage.Build([]ociimage.Image{
+		{
+			Platform: ociimage.Platform{Architecture: "amd64", OS: "linux"},
+			Files:    []ociimage.File{{Path: "/usr/local/bin/app", Content: []byte("amd64 " + version), Mode: 0755}},
+		},
+		{
+			Platform: ociimage.Platform{Architecture: "arm64", OS: "linux", Variant: "v8"},
+			Files:    []ociimage.File{{Path: "/usr/local/bin/app", Content: []byte("arm64 " + version), Mode: 0755}},
+		},
+	}, time.Unix(1600000000, 0), nil)
+	assert.NoError(t, err)
+
+	return image
+}
+
+func TestRegistryClientDo(t *testing.T) {
+	var storageAuth string
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		storageAuth = r.Header.Get("Authorization")
+		w.WriteHeader(201)
+	}))
+	defer storage.Close()
+
+	registry := newMockRegistry("basic", "username", "password")
+	defer registry.server.Close()
+
+	c := newRegistryClient(&http.Client{}, registry.host(), "username/app", "username", "password", true)
+	ctx := context.Background()
+
+	assert.NoError(t, c.ping(ctx))
+	assert.NotEmpty(t, c.auth)
+
+	res, err := c.do(ctx, "PUT", storage.URL+"/upload?digest=sha256:0123", nil, []byte("blob"))
+	assert.NoError(t, err)
+	res.Body.Close()
+
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Empty(t, storageAuth)
+}
+
+func TestImagePushMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "OK",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := ImagePush{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
+
+func TestImagePushDry(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          string
+		username      string
+		password      string
+		expectedError string
+	}{
+		{
+			name:          "NoCredentials",
+			auth:          "basic",
+			expectedError: "ImagePush.Dry: registry requires credentials",
+		},
+		{
+			name:          "InvalidCredentials",
+			auth:          "bearer",
+			username:      "username",
+			password:      "wrong",
+			expectedError: "ImagePush.Dry: GET /token 401: ",
+		},
+		{
+			name: "Anonymous",
+		},
+		{
+			name:     "Basic",
+			auth:     "basic",
+			username: "username",
+			password: "password",
+		},
+		{
+			name:     "Bearer",
+			auth:     "bearer",
+			username: "username",
+			password: "password",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := newMockRegistry(tc.auth, "username", "password")
+			defer registry.server.Close()
+
+			step := ImagePush{
+				Client:     &http.Client{},
+				Registry:   registry.host(),
+				Repository: "username/app",
+				Insecure:   true,
+				Username:   tc.username,
+				Password:   tc.password,
+			}
+
+			ctx := context.Background()
+			err := step.Dry(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestImagePushRunRevert(t *testing.T) {
+	tests := []struct {
+		name string
+		auth string
+	}{
+		{"Anonymous", ""},
+		{"Basic", "basic"},
+		{"Bearer", "bearer"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := newMockRegistry(tc.auth, "username", "password")
+			defer registry.server.Close()
+
+			image := testImage(t, "0.2.0")
+
+			step := ImagePush{
+				Client:     &http.Client{},
+				Registry:   registry.host(),
+				Repository: "username/app",
+				Insecure:   true,
+				Username:   "username",
+				Password:   "password",
+				Image:      image,
+				Tags:       []string{"0.2.0", "latest"},
+			}
+
+			ctx := context.Background()
+
+			err := step.Run(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, image.Index.Digest, step.Result.Digest)
+			assert.Equal(t, []string{"0.2.0", "latest"}, step.Result.Tags)
+			assert.Equal(t, map[string]string{"0.2.0": image.Index.Digest, "latest": image.Index.Digest}, registry.tags)
+			assert.Len(t, registry.manifests, 3)
+			assert.Len(t, registry.blobs, 4)
+
+			// Existing blobs are not uploaded again
+			registry.requests = nil
+			err = step.Run(ctx)
+			assert.NoError(t, err)
+			for _, req := range registry.requests {
+				assert.False(t, strings.Contains(req, "/blobs/uploads/"), req)
+			}
+
+			// The image was pushed before the second run, so it is not deleted
+			err = step.Revert(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"0.2.0": image.Index.Digest, "latest": image.Index.Digest}, registry.tags)
+		})
+	}
+}
+
+func TestImagePushRevert(t *testing.T) {
+	prevImage := testImage(t, "0.1.0")
+	image := testImage(t, "0.2.0")
+
+	tests := []struct {
+		name          string
+		deleteEnabled bool
+		prevTags      []string
+		expectedTags  map[string]string
+	}{
+		{
+			name:          "NewTags",
+			deleteEnabled: true,
+			prevTags:      nil,
+			expectedTags:  map[string]string{},
+		},
+		{
+			name:          "RestoreLatest",
+			deleteEnabled: true,
+			prevTags:      []string{"0.1.0", "latest"},
+			expectedTags:  map[string]string{"0.1.0": prevImage.Index.Digest, "latest": prevImage.Index.Digest},
+		},
+		{
+			name:          "DeleteDisabled",
+			deleteEnabled: false,
+			prevTags:      []string{"0.1.0", "latest"},
+			expectedTags:  map[string]string{"0.1.0": prevImage.Index.Digest, "0.2.0": image.Index.Digest, "latest": prevImage.Index.Digest},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := newMockRegistry("", "", "")
+			registry.deleteEnabled = tc.deleteEnabled
+			defer registry.server.Close()
+
+			ctx := context.Background()
+
+			if tc.prevTags != nil {
+				prev := ImagePush{
+					Client:     &http.Client{},
+					Registry:   registry.host(),
+					Repository: "username/app",
+					Insecure:   true,
+					Image:      prevImage,
+					Tags:       tc.prevTags,
+				}
+				assert.NoError(t, prev.Run(ctx))
+			}
+
+			step := ImagePush{
+				Client:     &http.Client{},
+				Registry:   registry.host(),
+				Repository: "username/app",
+				Insecure:   true,
+				Image:      image,
+				Tags:       []string{"0.2.0", "latest"},
+			}
+
+			err := step.Run(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, image.Index.Digest, registry.tags["latest"])
+
+			err = step.Revert(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedTags, registry.tags)
+		})
+	}
+}
+
+func TestImagePushRun(t *testing.T) {
+	tests := []struct {
+		name          string
+		image         func(*testing.T) *ociimage.Bundle
+		expectedError string
+	}{
+		{
+			name:          "NoImage",
+			image:         func(*testing.T) *ociimage.Bundle { return nil },
+			expectedError: "ImagePush.Run: no image to push",
+		},
+		{
+			name: "MissingBlob",
+			image: func(t *testing.T) *ociimage.Bundle {
+				image := testImage(t, "0.2.0")
+				m, err := image.Manifest(image.Manifests[0])
+				assert.NoError(t, err)
+				delete(image.Blobs, m.Layers[0].Digest)
+				return image
+			},
+			expectedError: "ImagePush.Run: blob not found: ",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := newMockRegistry("", "", "")
+			defer registry.server.Close()
+
+			step := ImagePush{
+				Client:     &http.Client{},
+				Registry:   registry.host(),
+				Repository: "username/app",
+				Insecure:   true,
+				Image:      tc.image(t),
+				Tags:       []string{"latest"},
+			}
+
+			ctx := context.Background()
+			err := step.Run(ctx)
+
+			assert.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tc.expectedError), err.Error())
+			assert.Empty(t, registry.tags)
+		})
+	}
+}
+
+func TestImagePushRevertError(t *testing.T) {
+	registry := newMockRegistry("basic", "username", "password")
+	defer registry.server.Close()
+
+	step := ImagePush{
+		Client:     &http.Client{},
+		Registry:   registry.host(),
+		Repository: "username/app",
+		Insecure:   true,
+	}
+	step.Result.Digest = "sha256:0000"
+	step.Result.Tags = []string{"0.2.0"}
+
+	err := step.Revert(context.Background())
+	assert.EqualError(t, err, "ImagePush.Revert: registry requires credentials")
+}
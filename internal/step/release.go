@@ -0,0 +1,345 @@
+package step
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// ReleaseGetLatest gets the latest release from a release provider.
+type ReleaseGetLatest struct {
+	Mock     Step
+	Provider ReleaseProvider
+	Result   struct {
+		LatestRelease Release
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *ReleaseGetLatest) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if _, err := s.Provider.GetLatestRelease(ctx); err != nil {
+		return fmt.Errorf("ReleaseGetLatest.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ReleaseGetLatest) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	release, err := s.Provider.GetLatestRelease(ctx)
+	if err != nil {
+		return fmt.Errorf("ReleaseGetLatest.Run: %s", err)
+	}
+
+	s.Result.LatestRelease = release
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ReleaseGetLatest) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	return nil
+}
+
+// ReleaseCreate creates a new release using a release provider.
+type ReleaseCreate struct {
+	Mock        Step
+	Provider    ReleaseProvider
+	ReleaseData ReleaseData
+	Result      struct {
+		Release Release
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *ReleaseCreate) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if _, err := s.Provider.ListReleases(ctx); err != nil {
+		return fmt.Errorf("ReleaseCreate.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ReleaseCreate) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	release, err := s.Provider.CreateRelease(ctx, s.ReleaseData)
+	if err != nil {
+		return fmt.Errorf("ReleaseCreate.Run: %s", err)
+	}
+
+	s.Result.Release = release
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ReleaseCreate) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	// The step is not prepared and has not run
+	if s.Provider == nil {
+		return nil
+	}
+
+	if err := s.Provider.DeleteRelease(ctx, s.Result.Release); err != nil {
+		return fmt.Errorf("ReleaseCreate.Revert: %s", err)
+	}
+
+	return nil
+}
+
+// ReleaseEdit edits an existing release using a release provider.
+type ReleaseEdit struct {
+	Mock        Step
+	Provider    ReleaseProvider
+	Release     Release
+	ReleaseData ReleaseData
+	Result      struct {
+		Release Release
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *ReleaseEdit) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if _, err := s.Provider.ListReleases(ctx); err != nil {
+		return fmt.Errorf("ReleaseEdit.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ReleaseEdit) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	release, err := s.Provider.EditRelease(ctx, s.Release, s.ReleaseData)
+	if err != nil {
+		return fmt.Errorf("ReleaseEdit.Run: %s", err)
+	}
+
+	s.Result.Release = release
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ReleaseEdit) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	// TODO: how to revert an edited release?
+	return nil
+}
+
+// ReleaseUploadAssets uploads assets (files) to a release using a release provider.
+type ReleaseUploadAssets struct {
+	Mock       Step
+	Provider   ReleaseProvider
+	Release    Release
+	AssetFiles []string
+	Result     struct {
+		Assets []ReleaseAsset
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *ReleaseUploadAssets) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if _, err := s.Provider.ListReleases(ctx); err != nil {
+		return fmt.Errorf("ReleaseUploadAssets.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ReleaseUploadAssets) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	assets, err := s.Provider.UploadAssets(ctx, s.Release, s.AssetFiles)
+	if err != nil {
+		return fmt.Errorf("ReleaseUploadAssets.Run: %s", err)
+	}
+
+	s.Result.Assets = assets
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ReleaseUploadAssets) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	// The step is not prepared and has not run
+	if s.Provider == nil {
+		return nil
+	}
+
+	if err := s.Provider.DeleteAssets(ctx, s.Release, s.Result.Assets); err != nil {
+		return fmt.Errorf("ReleaseUploadAssets.Revert: %s", err)
+	}
+
+	return nil
+}
+
+// ReleaseDownloadAsset downloads an asset of a release using a release provider and writes to a local file.
+type ReleaseDownloadAsset struct {
+	Mock      Step
+	Provider  ReleaseProvider
+	Tag       string
+	AssetName string
+	Filepath  string
+	Result    struct {
+		Size int64
+	}
+}
+
+// Dry is a dry run of the step.
+func (s *ReleaseDownloadAsset) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	release, err := s.Provider.GetReleaseByTag(ctx, s.Tag)
+	if err != nil {
+		return fmt.Errorf("ReleaseDownloadAsset.Dry: %s", err)
+	}
+
+	if _, ok := findAsset(release, s.AssetName); !ok {
+		return fmt.Errorf("ReleaseDownloadAsset.Dry: asset %s not found for release %s", s.AssetName, s.Tag)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *ReleaseDownloadAsset) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	size, err := s.Provider.DownloadAsset(ctx, s.Tag, s.AssetName, s.Filepath)
+	if err != nil {
+		return fmt.Errorf("ReleaseDownloadAsset.Run: %s", err)
+	}
+
+	s.Result.Size = size
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *ReleaseDownloadAsset) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	err := os.Remove(s.Filepath)
+	if err != nil {
+		return fmt.Errorf("ReleaseDownloadAsset.Revert: %s", err)
+	}
+
+	return nil
+}
+
+// BranchProtection enables/disables the protection of a branch using a release provider.
+type BranchProtection struct {
+	Mock     Step
+	Provider ReleaseProvider
+	Branch   string
+	Enabled  bool
+}
+
+// Dry is a dry run of the step.
+func (s *BranchProtection) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if _, err := s.Provider.BranchProtected(ctx, s.Branch); err != nil {
+		return fmt.Errorf("BranchProtection.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *BranchProtection) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	var err error
+	if s.Enabled {
+		err = s.Provider.ProtectBranch(ctx, s.Branch)
+	} else {
+		err = s.Provider.UnprotectBranch(ctx, s.Branch)
+	}
+
+	if err != nil {
+		return fmt.Errorf("BranchProtection.Run: %s", err)
+	}
+
+	return nil
+}
+
+// Revert reverts back an executed step.
+func (s *BranchProtection) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	// The step is not prepared and has not run
+	if s.Provider == nil {
+		return nil
+	}
+
+	var err error
+	if s.Enabled {
+		err = s.Provider.UnprotectBranch(ctx, s.Branch)
+	} else {
+		err = s.Provider.ProtectBranch(ctx, s.Branch)
+	}
+
+	if err != nil {
+		return fmt.Errorf("BranchProtection.Revert: %s", err)
+	}
+
+	return nil
+}
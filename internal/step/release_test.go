@@ -0,0 +1,458 @@
+package step
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseMock(t *testing.T) {
+	tests := []struct {
+		name string
+		step Step
+	}{
+		{"ReleaseGetLatest", &ReleaseGetLatest{Mock: &mockStep{}}},
+		{"ReleaseCreate", &ReleaseCreate{Mock: &mockStep{}}},
+		{"ReleaseEdit", &ReleaseEdit{Mock: &mockStep{}}},
+		{"ReleaseUploadAssets", &ReleaseUploadAssets{Mock: &mockStep{}}},
+		{"ReleaseDownloadAsset", &ReleaseDownloadAsset{Mock: &mockStep{}}},
+		{"BranchProtection", &BranchProtection{Mock: &mockStep{}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.NoError(t, tc.step.Dry(ctx))
+			assert.NoError(t, tc.step.Run(ctx))
+			assert.NoError(t, tc.step.Revert(ctx))
+		})
+	}
+}
+
+func TestReleaseRevertNoProvider(t *testing.T) {
+	tests := []struct {
+		name string
+		step Step
+	}{
+		{"ReleaseCreate", &ReleaseCreate{}},
+		{"ReleaseEdit", &ReleaseEdit{}},
+		{"ReleaseUploadAssets", &ReleaseUploadAssets{}},
+		{"BranchProtection", &BranchProtection{Branch: "master"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.NoError(t, tc.step.Revert(ctx))
+		})
+	}
+}
+
+func TestReleaseGetLatest(t *testing.T) {
+	tests := []struct {
+		name                  string
+		provider              *mockReleaseProvider
+		expectedError         string
+		expectedLatestRelease Release
+	}{
+		{
+			name: "ProviderFails",
+			provider: &mockReleaseProvider{
+				GetLatestOutError: errors.New("provider error"),
+			},
+			expectedError: "ReleaseGetLatest.Run: provider error",
+		},
+		{
+			name: "Success",
+			provider: &mockReleaseProvider{
+				GetLatestOutRelease: Release{ID: 1, Name: "0.1.0", TagName: "v0.1.0"},
+			},
+			expectedLatestRelease: Release{ID: 1, Name: "0.1.0", TagName: "v0.1.0"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := &ReleaseGetLatest{
+				Provider: tc.provider,
+			}
+
+			ctx := context.Background()
+			assert.NoError(t, step.Revert(ctx))
+
+			err := step.Dry(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			err = step.Run(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedLatestRelease, step.Result.LatestRelease)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestReleaseCreate(t *testing.T) {
+	tests := []struct {
+		name            string
+		provider        *mockReleaseProvider
+		releaseData     ReleaseData
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "ProviderFails",
+			provider: &mockReleaseProvider{
+				ListReleasesOutError: errors.New("provider error"),
+				CreateOutError:       errors.New("provider error"),
+				DeleteOutError:       errors.New("provider error"),
+			},
+			releaseData:   ReleaseData{Name: "0.1.0", TagName: "v0.1.0", Target: "master", Draft: true},
+			expectedError: "ReleaseCreate.Run: provider error",
+		},
+		{
+			name: "Success",
+			provider: &mockReleaseProvider{
+				CreateOutRelease: Release{ID: 1, Name: "0.1.0", TagName: "v0.1.0", Target: "master", Draft: true},
+			},
+			releaseData:     ReleaseData{Name: "0.1.0", TagName: "v0.1.0", Target: "master", Draft: true},
+			expectedRelease: Release{ID: 1, Name: "0.1.0", TagName: "v0.1.0", Target: "master", Draft: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := &ReleaseCreate{
+				Provider:    tc.provider,
+				ReleaseData: tc.releaseData,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			err = step.Run(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.releaseData, tc.provider.CreateInData)
+				assert.Equal(t, tc.expectedRelease, step.Result.Release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			err = step.Revert(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedRelease, tc.provider.DeleteInRelease)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestReleaseEdit(t *testing.T) {
+	tests := []struct {
+		name            string
+		provider        *mockReleaseProvider
+		release         Release
+		releaseData     ReleaseData
+		expectedError   string
+		expectedRelease Release
+	}{
+		{
+			name: "ProviderFails",
+			provider: &mockReleaseProvider{
+				ListReleasesOutError: errors.New("provider error"),
+				EditOutError:         errors.New("provider error"),
+			},
+			release:       Release{ID: 1, Draft: true},
+			releaseData:   ReleaseData{Name: "0.1.0", TagName: "v0.1.0", Target: "master", Body: "comment"},
+			expectedError: "ReleaseEdit.Run: provider error",
+		},
+		{
+			name: "Success",
+			provider: &mockReleaseProvider{
+				EditOutRelease: Release{ID: 1, Name: "0.1.0", TagName: "v0.1.0", Target: "master", Body: "comment"},
+			},
+			release:         Release{ID: 1, Draft: true},
+			releaseData:     ReleaseData{Name: "0.1.0", TagName: "v0.1.0", Target: "master", Body: "comment"},
+			expectedRelease: Release{ID: 1, Name: "0.1.0", TagName: "v0.1.0", Target: "master", Body: "comment"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := &ReleaseEdit{
+				Provider:    tc.provider,
+				Release:     tc.release,
+				ReleaseData: tc.releaseData,
+			}
+
+			ctx := context.Background()
+			assert.NoError(t, step.Revert(ctx))
+
+			err := step.Dry(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			err = step.Run(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.release, tc.provider.EditInRelease)
+				assert.Equal(t, tc.releaseData, tc.provider.EditInData)
+				assert.Equal(t, tc.expectedRelease, step.Result.Release)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+		})
+	}
+}
+
+func TestReleaseUploadAssets(t *testing.T) {
+	tests := []struct {
+		name           string
+		provider       *mockReleaseProvider
+		assetFiles     []string
+		expectedError  string
+		expectedAssets []ReleaseAsset
+	}{
+		{
+			name: "ProviderFails",
+			provider: &mockReleaseProvider{
+				ListReleasesOutError: errors.New("provider error"),
+				UploadOutError:       errors.New("provider error"),
+				DeleteAssetsOutError: errors.New("provider error"),
+			},
+			assetFiles:    []string{"bin/app-linux-amd64"},
+			expectedError: "ReleaseUploadAssets.Run: provider error",
+		},
+		{
+			name: "Success",
+			provider: &mockReleaseProvider{
+				UploadOutAssets: []ReleaseAsset{{ID: 1, Name: "app-linux-amd64"}},
+			},
+			assetFiles:     []string{"bin/app-linux-amd64"},
+			expectedAssets: []ReleaseAsset{{ID: 1, Name: "app-linux-amd64"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := &ReleaseUploadAssets{
+				Provider:   tc.provider,
+				Release:    Release{ID: 1},
+				AssetFiles: tc.assetFiles,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			err = step.Run(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.assetFiles, tc.provider.UploadInFiles)
+				assert.Equal(t, tc.expectedAssets, step.Result.Assets)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			err = step.Revert(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedAssets, tc.provider.DeleteAssetsInAssets)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestReleaseDownloadAsset(t *testing.T) {
+	tests := []struct {
+		name          string
+		provider      *mockReleaseProvider
+		tag           string
+		assetName     string
+		expectedDry   string
+		expectedError string
+		expectedSize  int64
+	}{
+		{
+			name: "ProviderFails",
+			provider: &mockReleaseProvider{
+				GetByTagOutError: errors.New("provider error"),
+				DownloadOutError: errors.New("provider error"),
+			},
+			tag:           "v0.1.0",
+			assetName:     "app-linux-amd64",
+			expectedDry:   "ReleaseDownloadAsset.Dry: provider error",
+			expectedError: "ReleaseDownloadAsset.Run: provider error",
+		},
+		{
+			name: "AssetNotFound",
+			provider: &mockReleaseProvider{
+				GetByTagOutRelease: Release{TagName: "v0.1.0"},
+			},
+			tag:         "v0.1.0",
+			assetName:   "app-linux-amd64",
+			expectedDry: "ReleaseDownloadAsset.Dry: asset app-linux-amd64 not found for release v0.1.0",
+		},
+		{
+			name: "Success",
+			provider: &mockReleaseProvider{
+				GetByTagOutRelease: Release{
+					TagName: "v0.1.0",
+					Assets:  []ReleaseAsset{{ID: 1, Name: "app-linux-amd64"}},
+				},
+				DownloadOutSize: 1024,
+			},
+			tag:          "v0.1.0",
+			assetName:    "app-linux-amd64",
+			expectedSize: 1024,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "cherry-test-")
+			assert.NoError(t, err)
+			assert.NoError(t, f.Close())
+			defer os.Remove(f.Name())
+
+			step := &ReleaseDownloadAsset{
+				Provider:  tc.provider,
+				Tag:       tc.tag,
+				AssetName: tc.assetName,
+				Filepath:  f.Name(),
+			}
+
+			ctx := context.Background()
+
+			err = step.Dry(ctx)
+			if tc.expectedDry == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedDry, err.Error())
+			}
+
+			err = step.Run(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.tag, tc.provider.DownloadInTag)
+				assert.Equal(t, tc.assetName, tc.provider.DownloadInAssetName)
+				assert.Equal(t, tc.expectedSize, step.Result.Size)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			assert.NoError(t, step.Revert(ctx))
+		})
+	}
+}
+
+func TestBranchProtection(t *testing.T) {
+	tests := []struct {
+		name                    string
+		provider                *mockReleaseProvider
+		branch                  string
+		enabled                 bool
+		expectedError           string
+		expectedProtectBranch   string
+		expectedUnprotectBranch string
+	}{
+		{
+			name: "ProviderFails",
+			provider: &mockReleaseProvider{
+				BranchProtectedOutError: errors.New("provider error"),
+				UnprotectBranchOutError: errors.New("provider error"),
+				ProtectBranchOutError:   errors.New("provider error"),
+			},
+			branch:        "master",
+			enabled:       false,
+			expectedError: "BranchProtection.Run: provider error",
+		},
+		{
+			name:                    "Disable",
+			provider:                &mockReleaseProvider{},
+			branch:                  "master",
+			enabled:                 false,
+			expectedUnprotectBranch: "master",
+			expectedProtectBranch:   "master",
+		},
+		{
+			name:                    "Enable",
+			provider:                &mockReleaseProvider{},
+			branch:                  "master",
+			enabled:                 true,
+			expectedProtectBranch:   "master",
+			expectedUnprotectBranch: "master",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := &BranchProtection{
+				Provider: tc.provider,
+				Branch:   tc.branch,
+				Enabled:  tc.enabled,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			err = step.Run(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedError, err.Error())
+			}
+
+			err = step.Revert(ctx)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedProtectBranch, tc.provider.ProtectBranchInBranch)
+				assert.Equal(t, tc.expectedUnprotectBranch, tc.provider.UnprotectBranchInBranch)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -1,86 +1,515 @@
 package step
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/moorara/cherry/pkg/calver"
 	"github.com/moorara/cherry/pkg/semver"
 )
 
 const (
-	textFile = "VERSION"
-	jsonFile = "package.json"
+	// VersionSourceFile reads the version from a version file.
+	VersionSourceFile = "file"
+	// VersionSourceGit derives the version from the latest semantic version tag reachable from HEAD.
+	VersionSourceGit = "git"
 )
 
+const (
+	// VersioningSemVer uses semantic versions (MAJOR.MINOR.PATCH).
+	VersioningSemVer = "semver"
+	// VersioningCalVer uses calendar versions (e.g. YYYY.0M.MICRO).
+	VersioningCalVer = "calver"
+)
+
+// now returns the current time for deriving calendar versions.
+var now = time.Now
+
+const (
+	textFile      = "VERSION"
+	jsonFile      = "package.json"
+	chartFile     = "Chart.yaml"
+	cargoFile     = "Cargo.toml"
+	pyprojectFile = "pyproject.toml"
+	pomFile       = "pom.xml"
+	gradleFile    = "build.gradle"
+	gradleKtsFile = "build.gradle.kts"
+)
+
+var (
+	// versionFiles are the version files that are found automatically in the order of precedence.
+	versionFiles = []string{textFile, jsonFile, chartFile, cargoFile, pyprojectFile, pomFile, gradleFile, gradleKtsFile}
+
+	jsonVersionRegex       = regexp.MustCompile(`"version"\s*:\s*"([^"]*)"`)
+	goVersionRegex         = regexp.MustCompile(`\bVersion\s*(?:string\s*)?=\s*"([^"]*)"`)
+	chartVersionRegex      = regexp.MustCompile(`(?m)^version:[ \t]*["']?([^"'\s#]+)`)
+	chartAppVersionRegex   = regexp.MustCompile(`(?m)^appVersion:[ \t]*["']?([^"'\s#]+)`)
+	cargoVersionRegex      = regexp.MustCompile(`(?m)^\[package\][^\[]*?^version\s*=\s*"([^"]*)"`)
+	pyprojectVersionRegex  = regexp.MustCompile(`(?m)^\[(?:project|tool\.poetry)\][^\[]*?^version\s*=\s*"([^"]*)"`)
+	pomVersionRegex        = regexp.MustCompile(`<version>\s*([^<\s]*)\s*</version>`)
+	pomParentRegex         = regexp.MustCompile(`(?s)<parent>.*?</parent>`)
+	pomProjectSectionRegex = regexp.MustCompile(`<(?:dependencies|dependencyManagement|build|profiles|modules|reporting)>`)
+	gradleVersionRegex     = regexp.MustCompile(`(?m)^\s*version\s*=?\s*["']([^"']+)["']`)
+	describeRegex          = regexp.MustCompile(`^(.+)-(\d+)-g([0-9a-f]+)$`)
+)
+
+// VersionFile is a file that has the version in it.
+// If Pattern is set, the first group of the regular expression matches the version.
+// Otherwise, the format of file is determined from its name.
+type VersionFile struct {
+	Filename string
+	Pattern  string
+}
+
 func findVersionFile(workDir, filename string) string {
 	if filename != "" {
 		return filename
-	} else if _, err := os.Stat(filepath.Join(workDir, textFile)); err == nil {
-		return textFile
-	} else if _, err := os.Stat(filepath.Join(workDir, jsonFile)); err == nil {
-		return jsonFile
+	}
+
+	for _, file := range versionFiles {
+		if _, err := os.Stat(filepath.Join(workDir, file)); err == nil {
+			return file
+		}
 	}
 
 	return ""
 }
 
+// submatchSpans returns the locations of the first group for all matches of a regular expression.
+func submatchSpans(re *regexp.Regexp, data []byte, n int) [][]int {
+	spans := [][]int{}
+	for _, m := range re.FindAllSubmatchIndex(data, n) {
+		if m[2] >= 0 {
+			spans = append(spans, m[2:4])
+		}
+	}
+
+	return spans
+}
+
+// pomVersionSpans returns the location of the project version in a pom.xml file.
+// The version of parent project and the versions of dependencies and plugins are skipped.
+func pomVersionSpans(data []byte) [][]int {
+	start, end := 0, len(data)
+
+	if m := pomParentRegex.FindIndex(data); m != nil {
+		start = m[1]
+	}
+
+	if m := pomProjectSectionRegex.FindIndex(data[start:]); m != nil {
+		end = start + m[0]
+	}
+
+	spans := submatchSpans(pomVersionRegex, data[start:end], 1)
+	for _, span := range spans {
+		span[0], span[1] = span[0]+start, span[1]+start
+	}
+
+	return spans
+}
+
+// jsonVersionSpans returns the location of the top-level version in a package.json file.
+// The versions in nested objects (e.g. publishConfig or a workspace manifest) are skipped.
+func jsonVersionSpans(data []byte) [][]int {
+	depth, inString, escaped := 0, false, false
+	i := 0
+
+	for _, m := range jsonVersionRegex.FindAllSubmatchIndex(data, -1) {
+		for ; i < m[0]; i++ {
+			if c := data[i]; inString {
+				switch {
+				case escaped:
+					escaped = false
+				case c == '\\':
+					escaped = true
+				case c == '"':
+					inString = false
+				}
+			} else {
+				switch c {
+				case '"':
+					inString = true
+				case '{', '[':
+					depth++
+				case '}', ']':
+					depth--
+				}
+			}
+		}
+
+		if depth == 1 && !inString {
+			return [][]int{m[2:4]}
+		}
+	}
+
+	return [][]int{}
+}
+
+// isTextFile determines whether or not a version file only has the version in it.
+func isTextFile(filename, pattern string) bool {
+	if pattern != "" {
+		return false
+	}
+
+	switch base := filepath.Base(filename); {
+	case filepath.Ext(base) == ".json", filepath.Ext(base) == ".go":
+		return false
+	case base == chartFile, base == cargoFile, base == pyprojectFile, base == pomFile, base == gradleFile, base == gradleKtsFile:
+		return false
+	default:
+		return true
+	}
+}
+
+// versionSpans returns the locations of the version in the content of a version file.
+// The version is read from the first location and it is updated in all locations.
+func versionSpans(filename, pattern string, data []byte) ([][]int, error) {
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version pattern: %s", err)
+		}
+
+		if re.NumSubexp() == 0 {
+			return nil, fmt.Errorf("invalid version pattern: %s has no group", pattern)
+		}
+
+		return submatchSpans(re, data, -1), nil
+	}
+
+	if isTextFile(filename, pattern) {
+		content := strings.TrimRight(string(data), " \t\r\n")
+		start := len(content) - len(strings.TrimLeft(content, " \t\r\n"))
+		return [][]int{{start, len(content)}}, nil
+	}
+
+	switch base := filepath.Base(filename); {
+	case filepath.Ext(base) == ".json":
+		return jsonVersionSpans(data), nil
+	case filepath.Ext(base) == ".go":
+		return submatchSpans(goVersionRegex, data, 1), nil
+	case base == chartFile:
+		return append(submatchSpans(chartVersionRegex, data, 1), submatchSpans(chartAppVersionRegex, data, 1)...), nil
+	case base == cargoFile:
+		return submatchSpans(cargoVersionRegex, data, 1), nil
+	case base == pyprojectFile:
+		return submatchSpans(pyprojectVersionRegex, data, 1), nil
+	case base == pomFile:
+		return pomVersionSpans(data), nil
+	default: // build.gradle and build.gradle.kts
+		return submatchSpans(gradleVersionRegex, data, 1), nil
+	}
+}
+
+// readVersionFile reads the version string from a version file.
+func readVersionFile(path, pattern string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	if pattern == "" && filepath.Ext(path) == ".json" { // package.json file
+		packageJSON := struct {
+			Version string `json:"version"`
+		}{}
+
+		if err = json.Unmarshal(data, &packageJSON); err != nil {
+			return "", err
+		}
+
+		return packageJSON.Version, nil
+	}
+
+	spans, err := versionSpans(path, pattern, data)
+	if err != nil {
+		return "", err
+	}
+
+	if len(spans) == 0 {
+		return "", fmt.Errorf("no version found in %s", filepath.Base(path))
+	}
+
+	return string(data[spans[0][0]:spans[0][1]]), nil
+}
+
+// updateVersionFile returns the content of a version file with the version replaced in all locations.
+// The rest of the file is left intact.
+func updateVersionFile(path, pattern, version string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if isTextFile(path, pattern) && strings.TrimSpace(string(data)) == "" {
+		return []byte(version + "\n"), nil
+	}
+
+	spans, err := versionSpans(path, pattern, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// A package.json file without version is left unchanged
+	if len(spans) == 0 && (pattern != "" || filepath.Ext(path) != ".json") {
+		return nil, fmt.Errorf("no version found in %s", filepath.Base(path))
+	}
+
+	content := make([]byte, 0, len(data))
+	prev := 0
+	for _, span := range spans {
+		content = append(content, data[prev:span[0]]...)
+		content = append(content, version...)
+		prev = span[1]
+	}
+	content = append(content, data[prev:]...)
+
+	return content, nil
+}
+
+// tagName returns the part of a git tag starting with the version (e.g. 1.2.3 for v1.2.3, release-1.2.3, or api/v1.2.3).
+func tagName(tag string) string {
+	name := path.Base(tag)
+	if i := strings.IndexAny(name, "0123456789"); i >= 0 {
+		return name[i:]
+	}
+
+	return ""
+}
+
+// tagVersion returns the semantic version in a git tag (e.g. v1.2.3, release-1.2.3, or api/v1.2.3).
+func tagVersion(tag string) (semver.SemVer, bool) {
+	if v, err := semver.Parse(tagName(tag)); err == nil {
+		return v, true
+	}
+
+	return semver.SemVer{}, false
+}
+
+// describeVersion returns the version of a commit from the latest tag and the number of commits since the tag.
+// If there are commits since the tag, the next patch version (or the same prerelease) is used with the number of commits
+// as prerelease and the abbreviated commit hash as metadata similar to `git describe` (e.g. v0.1.0-3-gabcdef1 becomes 0.1.1-3+gabcdef1).
+// If there is no tag, the version is derived from 0.0.0.
+func describeVersion(tag string, commits int, hash string) semver.SemVer {
+	v, _ := tagVersion(tag)
+	if tag != "" && commits == 0 {
+		return v
+	}
+
+	if len(v.Prerelease) > 0 {
+		v.Prerelease = append(v.Prerelease, strconv.Itoa(commits))
+	} else {
+		v.Patch++
+		v.Prerelease = []string{strconv.Itoa(commits)}
+	}
+
+	v.Metadata = []string{"g" + hash}
+
+	return v
+}
+
+// describeCalVer returns the calendar version of a commit from the latest tag and the number of commits since the tag.
+// If there are commits since the tag, the next calendar version for the current date is used with the number of commits
+// and the abbreviated commit hash as modifier similar to `git describe` (e.g. v2020.01.0-3-gabcdef1 becomes 2020.02.0-3-gabcdef1).
+// If there is no tag, the version is derived from the current date.
+func describeCalVer(f calver.Format, tag string, commits int, hash string, t time.Time) calver.CalVer {
+	v, err := f.Parse(tagName(tag))
+	if tag != "" && err == nil && commits == 0 {
+		return v
+	}
+
+	next := f.Version(t)
+	if err == nil {
+		if n, err := v.Next(t); err == nil {
+			next = n
+		}
+	}
+
+	next.Modifier = fmt.Sprintf("%d-g%s", commits, hash)
+
+	return next
+}
+
 // SemVerRead reads version from version file.
+// If Pattern is set, the first group of the regular expression matches the version in version file.
+// If Source is git, the version is derived from the latest semantic version tag reachable from HEAD
+// and if Match is set, only tags matching the glob pattern are considered.
+// If Versioning is calver, the version is read as a calendar version in CalVerFormat (YYYY.0M.MICRO by default).
 type SemVerRead struct {
 	Mock     Step
 	WorkDir  string
+	GoGit    *GoGit
+	Source   string
 	Filename string
-	Result   struct {
+	Pattern  string
+	Match    string
+	// Versioning is either semver (default) or calver.
+	Versioning   string
+	CalVerFormat string
+	Result       struct {
 		Filename string
 		Version  semver.SemVer
+		CalVer   calver.CalVer
+		Tag      string
+		Commits  int
 	}
 }
 
-func (s *SemVerRead) readVersion() (string, semver.SemVer, error) {
-	var zero semver.SemVer
-	var versionFile, versionString string
+// calverFormat returns the calendar versioning format.
+func (s *SemVerRead) calverFormat() (calver.Format, error) {
+	format := s.CalVerFormat
+	if format == "" {
+		format = calver.DefaultFormat
+	}
+
+	return calver.ParseFormat(format)
+}
 
-	if versionFile = findVersionFile(s.WorkDir, s.Filename); versionFile == "" {
-		return "", zero, errors.New("no version file")
+// parse parses a version string using the versioning scheme.
+func (s *SemVerRead) parse(version string) (semver.SemVer, calver.CalVer, error) {
+	if s.Versioning == VersioningCalVer {
+		f, err := s.calverFormat()
+		if err != nil {
+			return semver.SemVer{}, calver.CalVer{}, err
+		}
+
+		v, err := f.Parse(version)
+		return semver.SemVer{}, v, err
 	}
 
-	versionFilePath := filepath.Join(s.WorkDir, versionFile)
-	data, err := ioutil.ReadFile(versionFilePath)
-	if err != nil {
-		return "", zero, err
+	v, err := semver.Parse(version)
+	return v, calver.CalVer{}, err
+}
+
+// git runs a git command and returns its output.
+func (s *SemVerRead) git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("%s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
 	}
 
-	if filepath.Ext(versionFilePath) == ".json" { // package.json file
-		packageJSON := struct {
-			Version string `json:"version"`
-		}{}
+	return strings.Trim(stdout.String(), "\n"), nil
+}
+
+// describe returns the latest version tag reachable from HEAD, the number of commits since the tag,
+// and the abbreviated hash of HEAD. Tags without a version of the versioning scheme are skipped.
+func (s *SemVerRead) describe(ctx context.Context) (string, int, string, error) {
+	valid := func(tag string) bool {
+		_, _, err := s.parse(tagName(tag))
+		return err == nil
+	}
+
+	if s.GoGit != nil {
+		return s.GoGit.Describe(ctx, s.Match, valid)
+	}
+
+	args := []string{"describe", "--tags", "--long", "--always"}
+	if s.Match != "" {
+		args = append(args, "--match", s.Match)
+	}
+
+	for {
+		out, err := s.git(ctx, args...)
+		if err != nil {
+			return "", 0, "", err
+		}
+
+		// No tag is reachable from HEAD and only the abbreviated hash is returned
+		subs := describeRegex.FindStringSubmatch(out)
+		if subs == nil {
+			count, err := s.git(ctx, "rev-list", "--count", "HEAD")
+			if err != nil {
+				return "", 0, "", err
+			}
+
+			commits, _ := strconv.Atoi(count)
+			return "", commits, out, nil
+		}
+
+		tag, hash := subs[1], subs[3]
+		commits, _ := strconv.Atoi(subs[2])
+
+		if valid(tag) {
+			return tag, commits, hash, nil
+		}
+
+		// Look for an older tag
+		args = append(args, "--exclude", tag)
+	}
+}
+
+func (s *SemVerRead) readGitVersion(ctx context.Context) error {
+	tag, commits, hash, err := s.describe(ctx)
+	if err != nil {
+		return err
+	}
 
-		err = json.Unmarshal(data, &packageJSON)
+	if s.Versioning == VersioningCalVer {
+		f, err := s.calverFormat()
 		if err != nil {
-			return "", zero, err
+			return err
 		}
+		s.Result.CalVer = describeCalVer(f, tag, commits, hash, now())
+	} else {
+		s.Result.Version = describeVersion(tag, commits, hash)
+	}
+
+	s.Result.Tag = tag
+	s.Result.Commits = commits
+
+	return nil
+}
+
+func (s *SemVerRead) readVersion() error {
+	var versionFile string
+
+	if versionFile = findVersionFile(s.WorkDir, s.Filename); versionFile == "" {
+		return errors.New("no version file")
+	}
 
-		versionString = packageJSON.Version
-	} else { // text file
-		versionString = strings.Trim(string(data), "\n")
+	versionString, err := readVersionFile(filepath.Join(s.WorkDir, versionFile), s.Pattern)
+	if err != nil {
+		return err
 	}
 
 	if versionString == "" {
-		return "", zero, errors.New("empty version")
+		return errors.New("empty version")
 	}
 
-	version, err := semver.Parse(versionString)
+	version, calVersion, err := s.parse(versionString)
 	if err != nil {
-		return "", zero, err
+		return err
 	}
 
-	return versionFile, version, nil
+	s.Result.Filename = versionFile
+	s.Result.Version = version
+	s.Result.CalVer = calVersion
+
+	return nil
+}
+
+// read reads the version either from version file or git tags.
+func (s *SemVerRead) read(ctx context.Context) error {
+	if s.Source == VersionSourceGit {
+		return s.readGitVersion(ctx)
+	}
+
+	return s.readVersion()
 }
 
 // Dry is a dry run of the step.
@@ -89,8 +518,9 @@ func (s *SemVerRead) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
-	_, _, err := s.readVersion()
-	if err != nil {
+	// The result is not kept for a dry run
+	t := *s
+	if err := t.read(ctx); err != nil {
 		return fmt.Errorf("SemVerRead.Dry: %s", err)
 	}
 
@@ -103,14 +533,10 @@ func (s *SemVerRead) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
-	filename, version, err := s.readVersion()
-	if err != nil {
+	if err := s.read(ctx); err != nil {
 		return fmt.Errorf("SemVerRead.Run: %s", err)
 	}
 
-	s.Result.Filename = filename
-	s.Result.Version = version
-
 	return nil
 }
 
@@ -123,49 +549,73 @@ func (s *SemVerRead) Revert(ctx context.Context) error {
 	return nil
 }
 
-// SemVerUpdate writes a version to version file.
+// SemVerUpdate writes a version to version file and additional version files.
+// All files are updated in place, so their formats are preserved.
+// If updating any file fails, none of the files are changed.
 type SemVerUpdate struct {
 	Mock     Step
 	WorkDir  string
 	Filename string
+	Pattern  string
+	Files    []VersionFile
 	Version  string
 	Result   struct {
 		Filename string
+		Files    []string
 	}
+
+	originals map[string][]byte
 }
 
-func (s *SemVerUpdate) writeVersion(dryRun bool) (string, error) {
-	var versionFile, content string
+// writeVersion returns the updated contents of version files relative to WorkDir.
+func (s *SemVerUpdate) writeVersion() (map[string][]byte, error) {
+	var versionFile string
 
 	if versionFile = findVersionFile(s.WorkDir, s.Filename); versionFile == "" {
-		return "", errors.New("no version file")
+		return nil, errors.New("no version file")
 	}
 
 	versionFilePath := filepath.Join(s.WorkDir, versionFile)
 	if _, err := os.Stat(versionFilePath); os.IsNotExist(err) {
-		return "", errors.New("version file not found")
+		return nil, errors.New("version file not found")
 	}
 
-	if filepath.Ext(versionFilePath) == ".json" { // package.json file
-		data, err := ioutil.ReadFile(versionFilePath)
+	content, err := updateVersionFile(versionFilePath, s.Pattern, s.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := map[string][]byte{
+		versionFile: content,
+	}
+
+	s.Result.Filename = versionFile
+	s.Result.Files = []string{}
+
+	for _, f := range s.Files {
+		content, err := updateVersionFile(filepath.Join(s.WorkDir, f.Filename), f.Pattern, s.Version)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		re := regexp.MustCompile(`"version":\s*"[^"]*"`)
-		content = re.ReplaceAllLiteralString(string(data), fmt.Sprintf(`"version": "%s"`, s.Version))
-	} else { // text file
-		content = s.Version + "\n"
+		contents[f.Filename] = content
+		s.Result.Files = append(s.Result.Files, f.Filename)
 	}
 
-	if !dryRun {
-		err := ioutil.WriteFile(versionFilePath, []byte(content), 0644)
-		if err != nil {
-			return "", err
+	return contents, nil
+}
+
+// restore writes back the original contents of version files.
+func (s *SemVerUpdate) restore() error {
+	for file, original := range s.originals {
+		if err := ioutil.WriteFile(filepath.Join(s.WorkDir, file), original, 0644); err != nil {
+			return err
 		}
 	}
 
-	return versionFile, nil
+	s.originals = nil
+
+	return nil
 }
 
 // Dry is a dry run of the step.
@@ -174,13 +624,10 @@ func (s *SemVerUpdate) Dry(ctx context.Context) error {
 		return s.Mock.Dry(ctx)
 	}
 
-	filename, err := s.writeVersion(true)
-	if err != nil {
+	if _, err := s.writeVersion(); err != nil {
 		return fmt.Errorf("SemVerUpdate.Dry: %s", err)
 	}
 
-	s.Result.Filename = filename
-
 	return nil
 }
 
@@ -190,12 +637,30 @@ func (s *SemVerUpdate) Run(ctx context.Context) error {
 		return s.Mock.Run(ctx)
 	}
 
-	filename, err := s.writeVersion(false)
+	contents, err := s.writeVersion()
 	if err != nil {
 		return fmt.Errorf("SemVerUpdate.Run: %s", err)
 	}
 
-	s.Result.Filename = filename
+	s.originals = map[string][]byte{}
+
+	for _, file := range append([]string{s.Result.Filename}, s.Result.Files...) {
+		path := filepath.Join(s.WorkDir, file)
+
+		original, err := ioutil.ReadFile(path)
+		if err == nil {
+			s.originals[file] = original
+			err = ioutil.WriteFile(path, contents[file], 0644)
+		}
+
+		if err != nil {
+			// Make sure either all or none of the files are updated
+			if rerr := s.restore(); rerr != nil {
+				return fmt.Errorf("SemVerUpdate.Run: %s %s", err, rerr)
+			}
+			return fmt.Errorf("SemVerUpdate.Run: %s", err)
+		}
+	}
 
 	return nil
 }
@@ -206,5 +671,9 @@ func (s *SemVerUpdate) Revert(ctx context.Context) error {
 		return s.Mock.Revert(ctx)
 	}
 
+	if err := s.restore(); err != nil {
+		return fmt.Errorf("SemVerUpdate.Revert: %s", err)
+	}
+
 	return nil
 }
@@ -5,9 +5,13 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/moorara/cherry/pkg/calver"
 	"github.com/moorara/cherry/pkg/semver"
 	"github.com/stretchr/testify/assert"
 )
@@ -143,9 +147,13 @@ func TestSemVerReadRun(t *testing.T) {
 		name             string
 		workDir          string
 		filename         string
+		pattern          string
+		versioning       string
+		calverFormat     string
 		expectedError    string
 		expectedFilename string
 		expectedSemver   semver.SemVer
+		expectedCalVer   string
 	}{
 		{
 			name:          "NoVersionFile",
@@ -211,13 +219,144 @@ func TestSemVerReadRun(t *testing.T) {
 				Patch: 0,
 			},
 		},
+		{
+			name:             "ChartFileSuccess",
+			workDir:          "./test",
+			filename:         "Chart.yaml",
+			expectedFilename: "Chart.yaml",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:             "CargoFileSuccess",
+			workDir:          "./test",
+			filename:         "Cargo.toml",
+			expectedFilename: "Cargo.toml",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:             "PyprojectFileSuccess",
+			workDir:          "./test",
+			filename:         "pyproject.toml",
+			expectedFilename: "pyproject.toml",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:             "PomFileSuccess",
+			workDir:          "./test",
+			filename:         "pom.xml",
+			expectedFilename: "pom.xml",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:             "GradleFileSuccess",
+			workDir:          "./test",
+			filename:         "build.gradle",
+			expectedFilename: "build.gradle",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:             "GoFileSuccess",
+			workDir:          "./test",
+			filename:         "version.go",
+			expectedFilename: "version.go",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:             "PatternSuccess",
+			workDir:          "./test",
+			filename:         "version.txt",
+			pattern:          `Release: (\S+)`,
+			expectedFilename: "version.txt",
+			expectedSemver: semver.SemVer{
+				Major: 0,
+				Minor: 1,
+				Patch: 0,
+			},
+		},
+		{
+			name:          "InvalidPattern",
+			workDir:       "./test",
+			filename:      "version.txt",
+			pattern:       `Release: (\S+`,
+			expectedError: "SemVerRead.Run: invalid version pattern: error parsing regexp: missing closing ): `Release: (\\S+`",
+		},
+		{
+			name:          "PatternWithoutGroup",
+			workDir:       "./test",
+			filename:      "version.txt",
+			pattern:       `Release: \S+`,
+			expectedError: `SemVerRead.Run: invalid version pattern: Release: \S+ has no group`,
+		},
+		{
+			name:          "NoVersionFound",
+			workDir:       "./test",
+			filename:      "version.txt",
+			pattern:       `Version: (\S+)`,
+			expectedError: `SemVerRead.Run: no version found in version.txt`,
+		},
+		{
+			name:          "NoGoVersion",
+			workDir:       "./test",
+			filename:      "main.go",
+			expectedError: `SemVerRead.Run: no version found in main.go`,
+		},
+		{
+			name:             "CalVerSuccess",
+			workDir:          "./test",
+			filename:         "CALVER",
+			versioning:       VersioningCalVer,
+			expectedFilename: "CALVER",
+			expectedCalVer:   "2020.01.2",
+		},
+		{
+			name:          "InvalidCalVerFormat",
+			workDir:       "./test",
+			filename:      "CALVER",
+			versioning:    VersioningCalVer,
+			calverFormat:  "YYYY.0M.PATCH",
+			expectedError: `SemVerRead.Run: invalid calendar versioning format "YYYY.0M.PATCH": unknown token "PATCH"`,
+		},
+		{
+			name:          "InvalidCalVer",
+			workDir:       "./test",
+			filename:      "VERSION",
+			versioning:    VersioningCalVer,
+			expectedError: `SemVerRead.Run: invalid calendar version "0.1.0" for format YYYY.0M.MICRO`,
+		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			step := SemVerRead{
-				WorkDir:  tc.workDir,
-				Filename: tc.filename,
+				WorkDir:      tc.workDir,
+				Filename:     tc.filename,
+				Pattern:      tc.pattern,
+				Versioning:   tc.versioning,
+				CalVerFormat: tc.calverFormat,
 			}
 
 			ctx := context.Background()
@@ -227,6 +366,9 @@ func TestSemVerReadRun(t *testing.T) {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.expectedFilename, step.Result.Filename)
 				assert.Equal(t, tc.expectedSemver, step.Result.Version)
+				if tc.expectedCalVer != "" {
+					assert.Equal(t, tc.expectedCalVer, step.Result.CalVer.Version())
+				}
 			} else {
 				assert.Error(t, err)
 				assert.Equal(t, tc.expectedError, err.Error())
@@ -235,6 +377,171 @@ func TestSemVerReadRun(t *testing.T) {
 	}
 }
 
+func TestDescribeVersion(t *testing.T) {
+	tests := []struct {
+		tag             string
+		commits         int
+		hash            string
+		expectedVersion string
+	}{
+		{"", 3, "abcdef1", "0.0.1-3+gabcdef1"},
+		{"v0.1.0", 0, "abcdef1", "0.1.0"},
+		{"v0.1.0", 3, "abcdef1", "0.1.1-3+gabcdef1"},
+		{"api/v1.2.3", 1, "abcdef1", "1.2.4-1+gabcdef1"},
+		{"v2.0.0-rc.1", 2, "abcdef1", "2.0.0-rc.1.2+gabcdef1"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expectedVersion, describeVersion(tc.tag, tc.commits, tc.hash).Version())
+	}
+}
+
+func TestDescribeCalVer(t *testing.T) {
+	date := time.Date(2020, time.February, 9, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format          string
+		tag             string
+		commits         int
+		hash            string
+		expectedVersion string
+	}{
+		{calver.DefaultFormat, "", 3, "abcdef1", "2020.02.0-3-gabcdef1"},
+		{calver.DefaultFormat, "v2020.01.2", 0, "abcdef1", "2020.01.2"},
+		{calver.DefaultFormat, "v2020.01.2", 3, "abcdef1", "2020.02.0-3-gabcdef1"},
+		{calver.DefaultFormat, "api/v2020.02.0", 1, "abcdef1", "2020.02.1-1-gabcdef1"},
+		{"YYYY.0M.0D", "v2020.02.09", 2, "abcdef1", "2020.02.09-2-gabcdef1"},
+	}
+
+	for _, tc := range tests {
+		f, err := calver.ParseFormat(tc.format)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectedVersion, describeCalVer(f, tc.tag, tc.commits, tc.hash, date).Version())
+	}
+}
+
+func TestSemVerReadGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir, err := ioutil.TempDir("", "cherry-semver-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=octocat", "-c", "user.email=octocat@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+		return strings.Trim(string(out), "\n")
+	}
+
+	ctx := context.Background()
+
+	// read reads the version using both git binary and pure-Go git and makes sure they are the same.
+	read := func(t *testing.T, match, versioning string) *SemVerRead {
+		steps := []*SemVerRead{}
+		for _, gogit := range []*GoGit{nil, NewGoGit(dir)} {
+			step := &SemVerRead{
+				WorkDir:    dir,
+				GoGit:      gogit,
+				Source:     VersionSourceGit,
+				Match:      match,
+				Versioning: versioning,
+			}
+
+			assert.NoError(t, step.Dry(ctx))
+			assert.NoError(t, step.Run(ctx))
+			assert.Equal(t, "", step.Result.Filename)
+			steps = append(steps, step)
+		}
+
+		assert.Equal(t, steps[0].Result, steps[1].Result)
+
+		return steps[1]
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "Initial commit")
+
+	t.Run("NoTag", func(t *testing.T) {
+		step := read(t, "", "")
+		assert.Equal(t, "0.0.1-1+g"+git("rev-parse", "--short=7", "HEAD"), step.Result.Version.Version())
+		assert.Equal(t, "", step.Result.Tag)
+		assert.Equal(t, 1, step.Result.Commits)
+	})
+
+	git("tag", "v0.1.0")
+	git("commit", "-q", "--allow-empty", "-m", "Add feature")
+	git("tag", "nightly")
+
+	t.Run("Untagged", func(t *testing.T) {
+		step := read(t, "", "")
+		assert.Equal(t, "0.1.1-1+g"+git("rev-parse", "--short=7", "HEAD"), step.Result.Version.Version())
+		assert.Equal(t, "v0.1.0", step.Result.Tag)
+		assert.Equal(t, 1, step.Result.Commits)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		step := read(t, "sdk/*", "")
+		assert.Equal(t, "0.0.1-2+g"+git("rev-parse", "--short=7", "HEAD"), step.Result.Version.Version())
+		assert.Equal(t, "", step.Result.Tag)
+		assert.Equal(t, 2, step.Result.Commits)
+	})
+
+	git("tag", "-a", "-m", "Version 0.2.0", "v0.2.0")
+
+	t.Run("Tagged", func(t *testing.T) {
+		step := read(t, "v*", "")
+		assert.Equal(t, "0.2.0", step.Result.Version.Version())
+		assert.Equal(t, "v0.2.0", step.Result.Tag)
+		assert.Equal(t, 0, step.Result.Commits)
+	})
+
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time {
+		return time.Date(2020, time.February, 9, 12, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("CalVerNoTag", func(t *testing.T) {
+		step := read(t, "", VersioningCalVer)
+		assert.Equal(t, "2020.02.0-2-g"+git("rev-parse", "--short=7", "HEAD"), step.Result.CalVer.Version())
+		assert.Equal(t, "", step.Result.Tag)
+		assert.Equal(t, 2, step.Result.Commits)
+	})
+
+	git("commit", "-q", "--allow-empty", "-m", "Add another feature")
+	git("tag", "v2020.01.3")
+	git("commit", "-q", "--allow-empty", "-m", "Fix bug")
+
+	t.Run("CalVerUntagged", func(t *testing.T) {
+		step := read(t, "v*", VersioningCalVer)
+		assert.Equal(t, "2020.02.0-1-g"+git("rev-parse", "--short=7", "HEAD"), step.Result.CalVer.Version())
+		assert.Equal(t, "v2020.01.3", step.Result.Tag)
+		assert.Equal(t, 1, step.Result.Commits)
+	})
+
+	git("tag", "v2020.02.0")
+
+	t.Run("CalVerTagged", func(t *testing.T) {
+		step := read(t, "v*", VersioningCalVer)
+		assert.Equal(t, "2020.02.0", step.Result.CalVer.Version())
+		assert.Equal(t, "v2020.02.0", step.Result.Tag)
+		assert.Equal(t, 0, step.Result.Commits)
+	})
+
+	t.Run("NoRepo", func(t *testing.T) {
+		step := &SemVerRead{
+			WorkDir: os.TempDir(),
+			Source:  VersionSourceGit,
+		}
+
+		assert.Error(t, step.Dry(ctx))
+		assert.Error(t, step.Run(ctx))
+	})
+}
+
 func TestSemVerReadRevert(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -479,3 +786,108 @@ func TestSemVerUpdateRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestSemVerUpdateFiles(t *testing.T) {
+	files := map[string]string{
+		"VERSION":            "0.1.0\n",
+		"Chart.yaml":         "apiVersion: v2\nname: cherry\nversion: 0.1.0 # chart version\nappVersion: \"0.1.0\"\ndependencies:\n  - name: postgresql\n    version: 8.6.4\n",
+		"Cargo.toml":         "[package]\nname = \"cherry\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+		"pyproject.toml":     "[tool.black]\nversion = \"19.10b0\"\n\n[tool.poetry]\nname = \"cherry\"\nversion = \"0.1.0\"\n",
+		"pom.xml":            "<project>\n  <parent>\n    <version>2.3.0</version>\n  </parent>\n  <version>0.1.0</version>\n  <dependencies>\n    <version>4.13</version>\n  </dependencies>\n</project>\n",
+		"build.gradle.kts":   "group = \"com.example\"\nversion = \"0.1.0\"\n",
+		"package.json":       "{\n  \"name\": \"cherry\",\n  \"publishConfig\": {\"version\": \"0.0.1\"},\n  \"version\":  \"0.1.0\",\n  \"workspaces\": [{\"version\": \"0.1.0\"}]\n}\n",
+		"version/version.go": "package version\n\n// Version is the semantic version.\nvar Version = \"0.1.0\"\n",
+		"docs/install.md":    "curl -LO cherry-0.1.0.tar.gz\ntar -xzf cherry-0.1.0.tar.gz\n",
+	}
+
+	expected := map[string]string{
+		"VERSION":            "0.2.0\n",
+		"Chart.yaml":         "apiVersion: v2\nname: cherry\nversion: 0.2.0 # chart version\nappVersion: \"0.2.0\"\ndependencies:\n  - name: postgresql\n    version: 8.6.4\n",
+		"Cargo.toml":         "[package]\nname = \"cherry\"\nversion = \"0.2.0\"\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+		"pyproject.toml":     "[tool.black]\nversion = \"19.10b0\"\n\n[tool.poetry]\nname = \"cherry\"\nversion = \"0.2.0\"\n",
+		"pom.xml":            "<project>\n  <parent>\n    <version>2.3.0</version>\n  </parent>\n  <version>0.2.0</version>\n  <dependencies>\n    <version>4.13</version>\n  </dependencies>\n</project>\n",
+		"build.gradle.kts":   "group = \"com.example\"\nversion = \"0.2.0\"\n",
+		"package.json":       "{\n  \"name\": \"cherry\",\n  \"publishConfig\": {\"version\": \"0.0.1\"},\n  \"version\":  \"0.2.0\",\n  \"workspaces\": [{\"version\": \"0.1.0\"}]\n}\n",
+		"version/version.go": "package version\n\n// Version is the semantic version.\nvar Version = \"0.2.0\"\n",
+		"docs/install.md":    "curl -LO cherry-0.2.0.tar.gz\ntar -xzf cherry-0.2.0.tar.gz\n",
+	}
+
+	setup := func(t *testing.T) string {
+		td, err := ioutil.TempDir("", "cherry-")
+		assert.NoError(t, err)
+
+		for file, content := range files {
+			path := filepath.Join(td, file)
+			assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		}
+
+		return td
+	}
+
+	verify := func(t *testing.T, td string, contents map[string]string) {
+		for file, content := range contents {
+			data, err := ioutil.ReadFile(filepath.Join(td, file))
+			assert.NoError(t, err)
+			assert.Equal(t, content, string(data), file)
+		}
+	}
+
+	versionFiles := []VersionFile{
+		{Filename: "Chart.yaml"},
+		{Filename: "Cargo.toml"},
+		{Filename: "pyproject.toml"},
+		{Filename: "pom.xml"},
+		{Filename: "build.gradle.kts"},
+		{Filename: "package.json"},
+		{Filename: "version/version.go"},
+		{Filename: "docs/install.md", Pattern: `cherry-(\S+)\.tar\.gz`},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		td := setup(t)
+		defer os.RemoveAll(td)
+
+		step := SemVerUpdate{
+			WorkDir: td,
+			Files:   versionFiles,
+			Version: "0.2.0",
+		}
+
+		ctx := context.Background()
+
+		err := step.Dry(ctx)
+		assert.NoError(t, err)
+		verify(t, td, files)
+
+		err = step.Run(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "VERSION", step.Result.Filename)
+		assert.Equal(t, []string{"Chart.yaml", "Cargo.toml", "pyproject.toml", "pom.xml", "build.gradle.kts", "package.json", "version/version.go", "docs/install.md"}, step.Result.Files)
+		verify(t, td, expected)
+
+		err = step.Revert(ctx)
+		assert.NoError(t, err)
+		verify(t, td, files)
+	})
+
+	t.Run("NoneUpdated", func(t *testing.T) {
+		td := setup(t)
+		defer os.RemoveAll(td)
+
+		step := SemVerUpdate{
+			WorkDir: td,
+			Files:   append(versionFiles, VersionFile{Filename: "docs/install.md", Pattern: `cherry_(\S+)\.zip`}),
+			Version: "0.2.0",
+		}
+
+		ctx := context.Background()
+
+		err := step.Dry(ctx)
+		assert.EqualError(t, err, "SemVerUpdate.Dry: no version found in install.md")
+
+		err = step.Run(ctx)
+		assert.EqualError(t, err, "SemVerUpdate.Run: no version found in install.md")
+		verify(t, td, files)
+	})
+}
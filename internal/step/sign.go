@@ -0,0 +1,268 @@
+package step
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// SigningFormatGPG signs commits and tags using a GPG key.
+	SigningFormatGPG = "gpg"
+	// SigningFormatSSH signs commits and tags using an SSH key.
+	SigningFormatSSH = "ssh"
+)
+
+var errGoGitSigning = errors.New("signing commits and tags is not supported by the pure-Go git backend")
+
+// GitSigning has the configurations for signing commits and tags.
+// If Format is not set, GPG is used.
+// If Key is not set, user.signingkey from git configurations (or the default GPG key) is used.
+type GitSigning struct {
+	Format string
+	Key    string
+}
+
+// configArgs returns the git options for signing with the format and key.
+// The format is always set, so gpg.format from git configurations does not override it.
+func (g *GitSigning) configArgs() []string {
+	args := []string{}
+
+	if g.Format == SigningFormatSSH {
+		args = append(args, "-c", "gpg.format=ssh")
+	} else {
+		args = append(args, "-c", "gpg.format=openpgp")
+	}
+
+	if g.Key != "" {
+		args = append(args, "-c", "user.signingkey="+g.Key)
+	}
+
+	return args
+}
+
+// signingKey returns the signing key or user.signingkey from git configurations.
+func (g *GitSigning) signingKey(ctx context.Context, workDir string) string {
+	if g.Key != "" {
+		return g.Key
+	}
+
+	return gitConfig(ctx, workDir, "user.signingkey")
+}
+
+// gitConfig returns the value of a git configuration or an empty string if it is not set.
+func gitConfig(ctx context.Context, workDir, name string) string {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "config", name)
+	cmd.Dir = workDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// git config exits with 1 if the key is not set
+	_ = runCommand(ctx, cmd)
+
+	return strings.TrimSpace(stdout.String())
+}
+
+// sshPublicKey returns the public key of an ssh signing key.
+// The key is either a literal public key (key::...), a public key file, or a private key file.
+func sshPublicKey(ctx context.Context, key string) (string, error) {
+	if strings.HasPrefix(key, "key::") {
+		return strings.TrimPrefix(key, "key::"), nil
+	}
+
+	// The public key of a private key file is read from the .pub file next to it if there is one
+	for _, file := range []string{key, key + ".pub"} {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if content := strings.TrimSpace(string(data)); !strings.Contains(content, "PRIVATE KEY") {
+			return content, nil
+		}
+	}
+
+	// An empty passphrase is used, so ssh-keygen does not prompt for it
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh-keygen", "-y", "-P", "", "-f", key)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("cannot read the public key of %q: %s %s", key, err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// verifyArgs returns the git options for verifying signatures.
+// git verifies ssh signatures against gpg.ssh.allowedSignersFile, so if it is not configured,
+// a temporary allowed signers file with the signing key for the email of signer is created.
+// The returned function removes the temporary file.
+func (g *GitSigning) verifyArgs(ctx context.Context, workDir, email string) ([]string, func(), error) {
+	args := g.configArgs()
+	cleanup := func() {}
+
+	if g.Format != SigningFormatSSH || gitConfig(ctx, workDir, "gpg.ssh.allowedSignersFile") != "" {
+		return args, cleanup, nil
+	}
+
+	pub, err := sshPublicKey(ctx, g.signingKey(ctx, workDir))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "cherry-signers-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup = func() {
+		os.RemoveAll(dir)
+	}
+
+	file := filepath.Join(dir, "allowed_signers")
+	if err := ioutil.WriteFile(file, []byte(fmt.Sprintf("%s %s\n", email, pub)), 0600); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return append(args, "-c", "gpg.ssh.allowedSignersFile="+file), cleanup, nil
+}
+
+// check makes sure the signing key is usable.
+func (g *GitSigning) check(ctx context.Context, workDir string) error {
+	key := g.signingKey(ctx, workDir)
+
+	var stdout, stderr bytes.Buffer
+	var cmd *exec.Cmd
+
+	switch g.Format {
+	case "", SigningFormatGPG:
+		args := []string{"--list-secret-keys", "--with-colons"}
+		if key != "" {
+			args = append(args, key)
+		}
+		cmd = exec.CommandContext(ctx, "gpg", args...)
+
+	case SigningFormatSSH:
+		if key == "" {
+			return errors.New("no ssh signing key is configured")
+		}
+		// The key is a literal public key
+		if strings.HasPrefix(key, "key::") {
+			return nil
+		}
+		cmd = exec.CommandContext(ctx, "ssh-keygen", "-l", "-f", key)
+
+	default:
+		return fmt.Errorf("unknown signing format: %s", g.Format)
+	}
+
+	cmd.Dir = workDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("signing key %q is not usable: %s %s", key, err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	if stdout.Len() == 0 {
+		return errors.New("no gpg secret key found")
+	}
+
+	// The public key is needed for verifying signatures if gpg.ssh.allowedSignersFile is not configured
+	if g.Format == SigningFormatSSH && gitConfig(ctx, workDir, "gpg.ssh.allowedSignersFile") == "" {
+		if _, err := sshPublicKey(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GitVerifyTag runs `git verify-tag <tag>` command.
+type GitVerifyTag struct {
+	Mock    Step
+	WorkDir string
+	Tag     string
+	Sign    *GitSigning
+}
+
+// Dry is a dry run of the step.
+func (s *GitVerifyTag) Dry(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Dry(ctx)
+	}
+
+	if s.Sign == nil {
+		return nil
+	}
+
+	if err := s.Sign.check(ctx, s.WorkDir); err != nil {
+		return fmt.Errorf("GitVerifyTag.Dry: %s", err)
+	}
+
+	return nil
+}
+
+// Run executes the step.
+func (s *GitVerifyTag) Run(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Run(ctx)
+	}
+
+	var signArgs []string
+	if s.Sign != nil {
+		email, err := s.taggerEmail(ctx)
+		if err != nil {
+			return fmt.Errorf("GitVerifyTag.Run: %s", err)
+		}
+
+		var cleanup func()
+		signArgs, cleanup, err = s.Sign.verifyArgs(ctx, s.WorkDir, email)
+		if err != nil {
+			return fmt.Errorf("GitVerifyTag.Run: %s", err)
+		}
+		defer cleanup()
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := append(signArgs, "verify-tag", s.Tag)
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return fmt.Errorf("GitVerifyTag.Run: %s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	return nil
+}
+
+// taggerEmail returns the email of the tagger of tag.
+func (s *GitVerifyTag) taggerEmail(ctx context.Context) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(taggeremail)", "refs/tags/"+s.Tag)
+	cmd.Dir = s.WorkDir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := runCommand(ctx, cmd); err != nil {
+		return "", fmt.Errorf("%s %s", err.Error(), strings.Trim(stderr.String(), "\n"))
+	}
+
+	return strings.Trim(strings.TrimSpace(stdout.String()), "<>"), nil
+}
+
+// Revert reverts back an executed step.
+func (s *GitVerifyTag) Revert(ctx context.Context) error {
+	if s.Mock != nil {
+		return s.Mock.Revert(ctx)
+	}
+
+	return nil
+}
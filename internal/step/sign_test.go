@@ -0,0 +1,282 @@
+package step
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newSignedRepo creates a git repository configured for signing with a new SSH key.
+// If allowedSigners is true, gpg.ssh.allowedSignersFile is configured for verifying signatures.
+func newSignedRepo(t *testing.T, allowedSigners bool) (string, string) {
+	for _, bin := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s is not available", bin)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "cherry-sign-")
+	assert.NoError(t, err)
+
+	key := filepath.Join(dir, "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "octocat@example.com", "-f", key).CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	pub, err := ioutil.ReadFile(key + ".pub")
+	assert.NoError(t, err)
+	signers := filepath.Join(dir, "allowed_signers")
+	assert.NoError(t, ioutil.WriteFile(signers, []byte("octocat@example.com "+string(pub)), 0644))
+
+	repo := filepath.Join(dir, "repo")
+	assert.NoError(t, os.Mkdir(repo, 0755))
+
+	commands := [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "octocat"},
+		{"config", "user.email", "octocat@example.com"},
+	}
+
+	if allowedSigners {
+		commands = append(commands, []string{"config", "gpg.ssh.allowedSignersFile", signers})
+	}
+
+	commands = append(commands, []string{"commit", "-q", "--allow-empty", "-m", "Initial commit"})
+
+	for _, args := range commands {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	return repo, key
+}
+
+func TestGitSigningConfigArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		sign         GitSigning
+		expectedArgs []string
+	}{
+		{"Default", GitSigning{}, []string{"-c", "gpg.format=openpgp"}},
+		{"GPG", GitSigning{Format: "gpg", Key: "ABCDEF"}, []string{"-c", "gpg.format=openpgp", "-c", "user.signingkey=ABCDEF"}},
+		{"SSH", GitSigning{Format: "ssh", Key: "~/.ssh/id_ed25519.pub"}, []string{"-c", "gpg.format=ssh", "-c", "user.signingkey=~/.ssh/id_ed25519.pub"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedArgs, tc.sign.configArgs())
+		})
+	}
+}
+
+func TestGitSigningCheck(t *testing.T) {
+	repo, key := newSignedRepo(t, true)
+	defer os.RemoveAll(filepath.Dir(repo))
+
+	tests := []struct {
+		name          string
+		sign          GitSigning
+		expectedError string
+	}{
+		{
+			name:          "UnknownFormat",
+			sign:          GitSigning{Format: "x509"},
+			expectedError: "unknown signing format: x509",
+		},
+		{
+			name:          "NoSSHKey",
+			sign:          GitSigning{Format: "ssh"},
+			expectedError: "no ssh signing key is configured",
+		},
+		{
+			name:          "InvalidSSHKey",
+			sign:          GitSigning{Format: "ssh", Key: filepath.Join(repo, "missing")},
+			expectedError: fmt.Sprintf("signing key %q is not usable", filepath.Join(repo, "missing")),
+		},
+		{
+			name: "LiteralSSHKey",
+			sign: GitSigning{Format: "ssh", Key: "key::ssh-ed25519 AAAA"},
+		},
+		{
+			name: "SSHKey",
+			sign: GitSigning{Format: "ssh", Key: key + ".pub"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.sign.check(context.Background(), repo)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSignedCommitAndTag(t *testing.T) {
+	repo, key := newSignedRepo(t, true)
+	defer os.RemoveAll(filepath.Dir(repo))
+
+	ctx := context.Background()
+	sign := &GitSigning{Format: "ssh", Key: key}
+
+	commit := &GitCommit{WorkDir: repo, Message: "Releasing 0.1.0", Sign: sign}
+	assert.NoError(t, commit.Dry(ctx))
+
+	// Stage a change for the release commit
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(repo, "VERSION"), []byte("0.1.0\n"), 0644))
+	add := &GitAdd{WorkDir: repo, Files: []string{"VERSION"}}
+	assert.NoError(t, add.Run(ctx))
+	assert.NoError(t, commit.Run(ctx))
+
+	cmd := exec.Command("git", "verify-commit", "HEAD")
+	cmd.Dir = repo
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	tag := &GitTag{WorkDir: repo, Tag: "v0.1.0", Annotation: "Version 0.1.0", Sign: sign}
+	assert.NoError(t, tag.Dry(ctx))
+	assert.NoError(t, tag.Run(ctx))
+
+	verify := &GitVerifyTag{WorkDir: repo, Tag: "v0.1.0", Sign: sign}
+	assert.NoError(t, verify.Dry(ctx))
+	assert.NoError(t, verify.Run(ctx))
+	assert.NoError(t, verify.Revert(ctx))
+
+	// An unsigned tag cannot be verified
+	unsigned := &GitTag{WorkDir: repo, Tag: "v0.1.1", Annotation: "Version 0.1.1"}
+	assert.NoError(t, unsigned.Run(ctx))
+
+	verify = &GitVerifyTag{WorkDir: repo, Tag: "v0.1.1", Sign: sign}
+	err = verify.Run(ctx)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GitVerifyTag.Run: ")
+}
+
+func TestGitVerifyTagSSH(t *testing.T) {
+	repo, key := newSignedRepo(t, false)
+	defer os.RemoveAll(filepath.Dir(repo))
+
+	pub, err := ioutil.ReadFile(key + ".pub")
+	assert.NoError(t, err)
+
+	other := filepath.Join(filepath.Dir(repo), "other")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", other).CombinedOutput()
+	assert.NoError(t, err, string(out))
+
+	ctx := context.Background()
+
+	tag := &GitTag{WorkDir: repo, Tag: "v0.1.0", Annotation: "Version 0.1.0", Sign: &GitSigning{Format: "ssh", Key: key}}
+	assert.NoError(t, tag.Run(ctx))
+
+	tests := []struct {
+		name          string
+		sign          *GitSigning
+		expectedError string
+	}{
+		{
+			name: "PrivateKey",
+			sign: &GitSigning{Format: "ssh", Key: key},
+		},
+		{
+			name: "PublicKey",
+			sign: &GitSigning{Format: "ssh", Key: key + ".pub"},
+		},
+		{
+			name: "LiteralKey",
+			sign: &GitSigning{Format: "ssh", Key: "key::" + string(pub)},
+		},
+		{
+			name:          "OtherKey",
+			sign:          &GitSigning{Format: "ssh", Key: other},
+			expectedError: "GitVerifyTag.Run: ",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			verify := &GitVerifyTag{WorkDir: repo, Tag: "v0.1.0", Sign: tc.sign}
+			assert.NoError(t, verify.Dry(ctx))
+
+			err := verify.Run(ctx)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSignedGoGit(t *testing.T) {
+	ctx := context.Background()
+	sign := &GitSigning{Format: "ssh", Key: "key::ssh-ed25519 AAAA"}
+	g := &GoGit{}
+
+	commit := &GitCommit{GoGit: g, Message: "Releasing 0.1.0", Sign: sign}
+	assert.EqualError(t, commit.Dry(ctx), "GitCommit.Dry: signing commits and tags is not supported by the pure-Go git backend")
+	assert.EqualError(t, commit.Run(ctx), "GitCommit.Run: signing commits and tags is not supported by the pure-Go git backend")
+
+	tag := &GitTag{GoGit: g, Tag: "v0.1.0", Sign: sign}
+	assert.EqualError(t, tag.Dry(ctx), "GitTag.Dry: signing commits and tags is not supported by the pure-Go git backend")
+	assert.EqualError(t, tag.Run(ctx), "GitTag.Run: signing commits and tags is not supported by the pure-Go git backend")
+}
+
+func TestGitVerifyTagMock(t *testing.T) {
+	tests := []struct {
+		name                string
+		mock                *mockStep
+		expectedDryError    error
+		expectedRunError    error
+		expectedRevertError error
+	}{
+		{
+			name: "OK",
+			mock: &mockStep{},
+		},
+		{
+			name: "Error",
+			mock: &mockStep{
+				DryOutError:    errors.New("dry error"),
+				RunOutError:    errors.New("run error"),
+				RevertOutError: errors.New("revert error"),
+			},
+			expectedDryError:    errors.New("dry error"),
+			expectedRunError:    errors.New("run error"),
+			expectedRevertError: errors.New("revert error"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			step := GitVerifyTag{
+				Mock: tc.mock,
+			}
+
+			ctx := context.Background()
+
+			err := step.Dry(ctx)
+			assert.Equal(t, tc.expectedDryError, err)
+
+			err = step.Run(ctx)
+			assert.Equal(t, tc.expectedRunError, err)
+
+			err = step.Revert(ctx)
+			assert.Equal(t, tc.expectedRevertError, err)
+		})
+	}
+}
@@ -2,8 +2,77 @@ package step
 
 import (
 	"context"
+
+	"github.com/moorara/cherry/pkg/cui"
 )
 
+type mockCUI struct {
+	OutputfInFormat string
+	OutputfOutVals  []interface{}
+	InfofInFormat   string
+	InfofOutVals    []interface{}
+	WarnfInFormat   string
+	WarnfOutVals    []interface{}
+	ErrorfInFormat  string
+	ErrorfOutVals   []interface{}
+	DebugfInFormat  string
+	DebugfOutVals   []interface{}
+	TracefInFormat  string
+	TracefOutVals   []interface{}
+	EventInEvents   []cui.Event
+
+	AskInQuery       string
+	AskOutAnswer     string
+	AskOutError      error
+	ConfirmInQuery   string
+	ConfirmOutResult bool
+	ConfirmOutError  error
+}
+
+func (m *mockCUI) Outputf(format string, vals ...interface{}) {
+	m.OutputfInFormat = format
+	m.OutputfOutVals = vals
+}
+
+func (m *mockCUI) Infof(format string, vals ...interface{}) {
+	m.InfofInFormat = format
+	m.InfofOutVals = vals
+}
+
+func (m *mockCUI) Warnf(format string, vals ...interface{}) {
+	m.WarnfInFormat = format
+	m.WarnfOutVals = vals
+}
+
+func (m *mockCUI) Errorf(format string, vals ...interface{}) {
+	m.ErrorfInFormat = format
+	m.ErrorfOutVals = vals
+}
+
+func (m *mockCUI) Debugf(format string, vals ...interface{}) {
+	m.DebugfInFormat = format
+	m.DebugfOutVals = vals
+}
+
+func (m *mockCUI) Tracef(format string, vals ...interface{}) {
+	m.TracefInFormat = format
+	m.TracefOutVals = vals
+}
+
+func (m *mockCUI) Event(e cui.Event) {
+	m.EventInEvents = append(m.EventInEvents, e)
+}
+
+func (m *mockCUI) Ask(query string) (string, error) {
+	m.AskInQuery = query
+	return m.AskOutAnswer, m.AskOutError
+}
+
+func (m *mockCUI) Confirm(query string) (bool, error) {
+	m.ConfirmInQuery = query
+	return m.ConfirmOutResult, m.ConfirmOutError
+}
+
 type mockStep struct {
 	DryInCtx       context.Context
 	DryOutError    error
@@ -27,3 +96,103 @@ func (m *mockStep) Revert(ctx context.Context) error {
 	m.RevertInCtx = ctx
 	return m.RevertOutError
 }
+
+type mockReleaseProvider struct {
+	NameOutString           string
+	ListReleasesOutReleases []Release
+	ListReleasesOutError    error
+	GetLatestOutRelease     Release
+	GetLatestOutError       error
+	GetByTagInTag           string
+	GetByTagOutRelease      Release
+	GetByTagOutError        error
+	CreateInData            ReleaseData
+	CreateOutRelease        Release
+	CreateOutError          error
+	EditInRelease           Release
+	EditInData              ReleaseData
+	EditOutRelease          Release
+	EditOutError            error
+	DeleteInRelease         Release
+	DeleteOutError          error
+	UploadInFiles           []string
+	UploadOutAssets         []ReleaseAsset
+	UploadOutError          error
+	DeleteAssetsInAssets    []ReleaseAsset
+	DeleteAssetsOutError    error
+	DownloadInTag           string
+	DownloadInAssetName     string
+	DownloadInFilepath      string
+	DownloadOutSize         int64
+	DownloadOutError        error
+	BranchProtectedOutBool  bool
+	BranchProtectedOutError error
+	ProtectBranchInBranch   string
+	ProtectBranchOutError   error
+	UnprotectBranchInBranch string
+	UnprotectBranchOutError error
+}
+
+func (m *mockReleaseProvider) Name() string {
+	return m.NameOutString
+}
+
+func (m *mockReleaseProvider) ListReleases(ctx context.Context) ([]Release, error) {
+	return m.ListReleasesOutReleases, m.ListReleasesOutError
+}
+
+func (m *mockReleaseProvider) GetLatestRelease(ctx context.Context) (Release, error) {
+	return m.GetLatestOutRelease, m.GetLatestOutError
+}
+
+func (m *mockReleaseProvider) GetReleaseByTag(ctx context.Context, tag string) (Release, error) {
+	m.GetByTagInTag = tag
+	return m.GetByTagOutRelease, m.GetByTagOutError
+}
+
+func (m *mockReleaseProvider) CreateRelease(ctx context.Context, data ReleaseData) (Release, error) {
+	m.CreateInData = data
+	return m.CreateOutRelease, m.CreateOutError
+}
+
+func (m *mockReleaseProvider) EditRelease(ctx context.Context, release Release, data ReleaseData) (Release, error) {
+	m.EditInRelease = release
+	m.EditInData = data
+	return m.EditOutRelease, m.EditOutError
+}
+
+func (m *mockReleaseProvider) DeleteRelease(ctx context.Context, release Release) error {
+	m.DeleteInRelease = release
+	return m.DeleteOutError
+}
+
+func (m *mockReleaseProvider) UploadAssets(ctx context.Context, release Release, files []string) ([]ReleaseAsset, error) {
+	m.UploadInFiles = files
+	return m.UploadOutAssets, m.UploadOutError
+}
+
+func (m *mockReleaseProvider) DeleteAssets(ctx context.Context, release Release, assets []ReleaseAsset) error {
+	m.DeleteAssetsInAssets = assets
+	return m.DeleteAssetsOutError
+}
+
+func (m *mockReleaseProvider) DownloadAsset(ctx context.Context, tag, assetName, filepath string) (int64, error) {
+	m.DownloadInTag = tag
+	m.DownloadInAssetName = assetName
+	m.DownloadInFilepath = filepath
+	return m.DownloadOutSize, m.DownloadOutError
+}
+
+func (m *mockReleaseProvider) BranchProtected(ctx context.Context, branch string) (bool, error) {
+	return m.BranchProtectedOutBool, m.BranchProtectedOutError
+}
+
+func (m *mockReleaseProvider) ProtectBranch(ctx context.Context, branch string) error {
+	m.ProtectBranchInBranch = branch
+	return m.ProtectBranchOutError
+}
+
+func (m *mockReleaseProvider) UnprotectBranch(ctx context.Context, branch string) error {
+	m.UnprotectBranchInBranch = branch
+	return m.UnprotectBranchOutError
+}
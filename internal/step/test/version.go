@@ -0,0 +1,4 @@
+package main
+
+// Version is the semantic version of application.
+const Version = "0.1.0"
@@ -8,6 +8,7 @@ import (
 	"github.com/moorara/cherry/cmd/command"
 	"github.com/moorara/cherry/cmd/version"
 	"github.com/moorara/cherry/internal/spec"
+	"github.com/moorara/cherry/internal/step"
 	"github.com/moorara/cherry/pkg/cui"
 	"github.com/moorara/konfig"
 )
@@ -16,14 +17,29 @@ const (
 	osErr     = 10
 	configErr = 11
 	specErr   = 12
+	flagErr   = 13
 )
 
 var config = struct {
-	GithubToken string
+	GithubToken             string
+	GithubURL               string
+	GithubUploadURL         string
+	GithubAppID             int64
+	GithubAppPrivateKey     string
+	GithubAppInstallationID int64
+	GithubCredentialHelper  string
+	GithubCredentialsFile   string
+	GitlabToken             string
+	GiteaURL                string
+	GiteaToken              string
+	DockerUsername          string
+	DockerPassword          string
+	CABundle                string
+	Output                  string
 }{}
 
 func main() {
-	ui := cui.New()
+	ui := cui.New(cui.Normal)
 
 	wd, err := os.Getwd()
 	if err != nil {
@@ -37,6 +53,36 @@ func main() {
 		os.Exit(configErr)
 	}
 
+	// Global flags take precedence over environment variables
+	flags, args, err := command.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		ui.Errorf("%s", err)
+		os.Exit(flagErr)
+	}
+
+	if flags.Output == "" {
+		flags.Output = config.Output
+	}
+
+	// Tokens are scrubbed from all output
+	secrets := []string{
+		config.GithubToken,
+		config.GithubAppPrivateKey,
+		config.GitlabToken,
+		config.GiteaToken,
+		config.DockerPassword,
+	}
+
+	switch flags.Output {
+	case "", cui.OutputText:
+		ui = cui.New(flags.Level(), secrets...)
+	case cui.OutputJSON:
+		ui = cui.NewJSON(os.Stdout, flags.Level(), secrets...)
+	default:
+		ui.Errorf("invalid output format: %s", flags.Output)
+		os.Exit(flagErr)
+	}
+
 	// Read the spec
 	// If spec file not found, create a default spec
 	s, err := spec.Read()
@@ -54,17 +100,37 @@ func main() {
 	s.SetDefaults()
 	s.ToolVersion = version.Version
 
+	providers := step.ProviderConfig{
+		GitHubToken:             config.GithubToken,
+		GitHubURL:               config.GithubURL,
+		GitHubUploadURL:         config.GithubUploadURL,
+		GitHubAppID:             config.GithubAppID,
+		GitHubAppPrivateKey:     config.GithubAppPrivateKey,
+		GitHubAppInstallationID: config.GithubAppInstallationID,
+		GitHubCredentialHelper:  config.GithubCredentialHelper,
+		GitHubCredentialsFile:   config.GithubCredentialsFile,
+		GitLabToken:             config.GitlabToken,
+		GiteaURL:                config.GiteaURL,
+		GiteaToken:              config.GiteaToken,
+		DockerUsername:          config.DockerUsername,
+		DockerPassword:          config.DockerPassword,
+		CABundle:                config.CABundle,
+	}
+
 	c := cli.NewCLI("cherry", version.String())
-	c.Args = os.Args[1:]
+	c.Args = args
 	c.Commands = map[string]cli.CommandFactory{
 		"build": func() (cli.Command, error) {
 			return command.NewBuild(ui, wd, *s)
 		},
 		"release": func() (cli.Command, error) {
-			return command.NewRelease(ui, wd, config.GithubToken, *s)
+			return command.NewRelease(ui, wd, providers, *s)
 		},
 		"update": func() (cli.Command, error) {
-			return command.NewUpdate(ui, config.GithubToken)
+			return command.NewUpdate(ui, providers)
+		},
+		"version": func() (cli.Command, error) {
+			return command.NewVersion(ui, wd, *s)
 		},
 	}
 
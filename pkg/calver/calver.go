@@ -0,0 +1,219 @@
+package calver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFormat is the default format for calendar versions.
+const DefaultFormat = "YYYY.0M.MICRO"
+
+const micro = "MICRO"
+
+// tokens are the supported tokens in a calendar versioning format.
+// The value is the minimum width of segment (zero-padded) or zero if the segment is not padded.
+var tokens = map[string]int{
+	"YYYY": 4, // Full year (2006)
+	"YY":   0, // Short year (6, 16, 106)
+	"0Y":   2, // Zero-padded year (06, 16, 106)
+	"MM":   0, // Short month (1, 2, ..., 12)
+	"0M":   2, // Zero-padded month (01, 02, ..., 12)
+	"WW":   0, // Short week of year (1, 2, ..., 53)
+	"0W":   2, // Zero-padded week of year (01, 02, ..., 53)
+	"DD":   0, // Short day of month (1, 2, ..., 31)
+	"0D":   2, // Zero-padded day of month (01, 02, ..., 31)
+	micro:  0, // Incremental number for multiple releases in the same period (0, 1, 2, ...)
+}
+
+// Format is a calendar versioning scheme consisting of tokens separated by dots (e.g. YYYY.0M.MICRO).
+type Format struct {
+	tokens []string
+}
+
+// ParseFormat parses a calendar versioning format.
+// The supported tokens are YYYY, YY, 0Y, MM, 0M, WW, 0W, DD, 0D, and MICRO.
+// MICRO is optional, but if present, it has to be the last token.
+func ParseFormat(format string) (Format, error) {
+	if format == "" {
+		return Format{}, errors.New("empty calendar versioning format")
+	}
+
+	f := Format{
+		tokens: strings.Split(format, "."),
+	}
+
+	for i, token := range f.tokens {
+		if _, ok := tokens[token]; !ok {
+			return Format{}, fmt.Errorf("invalid calendar versioning format %q: unknown token %q", format, token)
+		}
+
+		if token == micro && i != len(f.tokens)-1 {
+			return Format{}, fmt.Errorf("invalid calendar versioning format %q: %s has to be the last token", format, micro)
+		}
+	}
+
+	if f.tokens[0] == micro {
+		return Format{}, fmt.Errorf("invalid calendar versioning format %q: no date token", format)
+	}
+
+	return f, nil
+}
+
+// String returns the format string.
+func (f Format) String() string {
+	return strings.Join(f.tokens, ".")
+}
+
+// hasMicro determines whether or not the format has a MICRO token.
+func (f Format) hasMicro() bool {
+	return len(f.tokens) > 0 && f.tokens[len(f.tokens)-1] == micro
+}
+
+// segment returns the value of a date token for a time.
+func segment(token string, t time.Time) int {
+	switch token {
+	case "YYYY":
+		return t.Year()
+	case "YY", "0Y":
+		return t.Year() - 2000
+	case "MM", "0M":
+		return int(t.Month())
+	case "WW", "0W":
+		return (t.YearDay()-1)/7 + 1
+	case "DD", "0D":
+		return t.Day()
+	default:
+		return 0
+	}
+}
+
+// Version returns the first calendar version for a time.
+func (f Format) Version(t time.Time) CalVer {
+	v := CalVer{
+		Format:   f,
+		Segments: make([]int, len(f.tokens)),
+	}
+
+	for i, token := range f.tokens {
+		v.Segments[i] = segment(token, t)
+	}
+
+	return v
+}
+
+// Parse reads a calendar version string (e.g. 2020.01.2 or 2020.01.2-beta) and returns a CalVer.
+func (f Format) Parse(version string) (CalVer, error) {
+	var zero CalVer
+	err := fmt.Errorf("invalid calendar version %q for format %s", version, f)
+
+	core, modifier := version, ""
+	if i := strings.Index(version, "-"); i >= 0 {
+		core, modifier = version[:i], version[i+1:]
+		if modifier == "" {
+			return zero, err
+		}
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) != len(f.tokens) {
+		return zero, err
+	}
+
+	v := CalVer{
+		Format:   f,
+		Segments: make([]int, len(parts)),
+		Modifier: modifier,
+	}
+
+	for i, part := range parts {
+		token := f.tokens[i]
+		width := tokens[token]
+
+		n, e := strconv.ParseUint(part, 10, 32)
+		if e != nil || len(part) < width {
+			return zero, err
+		}
+
+		// Short segments are not zero-padded
+		if width < 2 && len(part) > 1 && part[0] == '0' {
+			return zero, err
+		}
+
+		// Zero-padded segments are only padded up to their width
+		if width == 2 && len(part) > 2 && part[0] == '0' {
+			return zero, err
+		}
+
+		v.Segments[i] = int(n)
+	}
+
+	return v, nil
+}
+
+// CalVer represents a calendar version.
+// Segments are the values of tokens in the format and Modifier is an optional tag (e.g. dev or beta).
+type CalVer struct {
+	Format   Format
+	Segments []int
+	Modifier string
+}
+
+// Version returns a calendar version string.
+func (v CalVer) Version() string {
+	parts := make([]string, len(v.Segments))
+	for i, n := range v.Segments {
+		parts[i] = fmt.Sprintf("%0*d", tokens[v.Format.tokens[i]], n)
+	}
+
+	version := strings.Join(parts, ".")
+	if v.Modifier != "" {
+		version += "-" + v.Modifier
+	}
+
+	return version
+}
+
+// String implements the fmt.Stringer interface.
+func (v CalVer) String() string {
+	return v.Version()
+}
+
+// Next returns the next calendar version for releasing at a time.
+// If the release is in a new period, the MICRO segment is reset to zero.
+// If the release is in the same period as the current version, the MICRO segment is incremented
+// unless the current version has a modifier (i.e. 2020.01.2-dev), in which case the modifier is dropped.
+func (v CalVer) Next(t time.Time) (CalVer, error) {
+	next := v.Format.Version(t)
+
+	dates := len(next.Segments)
+	if v.Format.hasMicro() {
+		dates--
+	}
+
+	for i := 0; i < dates; i++ {
+		if next.Segments[i] > v.Segments[i] {
+			return next, nil
+		}
+
+		if next.Segments[i] < v.Segments[i] {
+			return CalVer{}, fmt.Errorf("calendar version %s is ahead of the release date %s", v, t.Format("2006-01-02"))
+		}
+	}
+
+	// The release is in the same period as the current version
+	if v.Modifier != "" {
+		copy(next.Segments, v.Segments)
+		return next, nil
+	}
+
+	if !v.Format.hasMicro() {
+		return CalVer{}, fmt.Errorf("calendar version %s is already released and format %s has no %s", v, v.Format, micro)
+	}
+
+	next.Segments[dates] = v.Segments[dates] + 1
+
+	return next, nil
+}
@@ -0,0 +1,275 @@
+package calver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		expectedError error
+	}{
+		{
+			name:          "Empty",
+			format:        "",
+			expectedError: errors.New("empty calendar versioning format"),
+		},
+		{
+			name:          "UnknownToken",
+			format:        "YYYY.0M.PATCH",
+			expectedError: errors.New(`invalid calendar versioning format "YYYY.0M.PATCH": unknown token "PATCH"`),
+		},
+		{
+			name:          "MicroNotLast",
+			format:        "YYYY.MICRO.0M",
+			expectedError: errors.New(`invalid calendar versioning format "YYYY.MICRO.0M": MICRO has to be the last token`),
+		},
+		{
+			name:          "NoDate",
+			format:        "MICRO",
+			expectedError: errors.New(`invalid calendar versioning format "MICRO": no date token`),
+		},
+		{
+			name:   "Default",
+			format: DefaultFormat,
+		},
+		{
+			name:   "Week",
+			format: "YY.WW.MICRO",
+		},
+		{
+			name:   "NoMicro",
+			format: "YYYY.0M.0D",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseFormat(tc.format)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.format, f.String())
+			} else {
+				assert.Equal(t, tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestFormatParse(t *testing.T) {
+	tests := []struct {
+		name             string
+		format           string
+		version          string
+		expectedError    string
+		expectedSegments []int
+		expectedModifier string
+	}{
+		{
+			name:          "Empty",
+			format:        DefaultFormat,
+			version:       "",
+			expectedError: `invalid calendar version "" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:          "MissingMicro",
+			format:        DefaultFormat,
+			version:       "2020.01",
+			expectedError: `invalid calendar version "2020.01" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:          "ShortYear",
+			format:        DefaultFormat,
+			version:       "20.01.0",
+			expectedError: `invalid calendar version "20.01.0" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:          "NotPadded",
+			format:        DefaultFormat,
+			version:       "2020.1.0",
+			expectedError: `invalid calendar version "2020.1.0" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:          "Padded",
+			format:        "YY.MM.MICRO",
+			version:       "20.01.0",
+			expectedError: `invalid calendar version "20.01.0" for format YY.MM.MICRO`,
+		},
+		{
+			name:          "InvalidMicro",
+			format:        DefaultFormat,
+			version:       "2020.01.x",
+			expectedError: `invalid calendar version "2020.01.x" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:          "EmptyModifier",
+			format:        DefaultFormat,
+			version:       "2020.01.0-",
+			expectedError: `invalid calendar version "2020.01.0-" for format YYYY.0M.MICRO`,
+		},
+		{
+			name:             "OK",
+			format:           DefaultFormat,
+			version:          "2020.01.2",
+			expectedSegments: []int{2020, 1, 2},
+		},
+		{
+			name:             "WithModifier",
+			format:           DefaultFormat,
+			version:          "2020.12.0-beta.1",
+			expectedSegments: []int{2020, 12, 0},
+			expectedModifier: "beta.1",
+		},
+		{
+			name:             "Week",
+			format:           "YY.0W.MICRO",
+			version:          "20.05.11",
+			expectedSegments: []int{20, 5, 11},
+		},
+		{
+			name:             "NoMicro",
+			format:           "0Y.MM.DD",
+			version:          "06.1.31",
+			expectedSegments: []int{6, 1, 31},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseFormat(tc.format)
+			assert.NoError(t, err)
+
+			v, err := f.Parse(tc.version)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedSegments, v.Segments)
+				assert.Equal(t, tc.expectedModifier, v.Modifier)
+				assert.Equal(t, tc.version, v.Version())
+				assert.Equal(t, tc.version, v.String())
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestFormatVersion(t *testing.T) {
+	date := time.Date(2020, time.February, 9, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		format          string
+		expectedVersion string
+	}{
+		{"YYYY.0M.MICRO", "2020.02.0"},
+		{"YYYY.MM.MICRO", "2020.2.0"},
+		{"YY.0W.MICRO", "20.06.0"},
+		{"0Y.WW", "20.6"},
+		{"YYYY.0M.0D", "2020.02.09"},
+		{"YYYY.MM.DD", "2020.2.9"},
+	}
+
+	for _, tc := range tests {
+		f, err := ParseFormat(tc.format)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectedVersion, f.Version(date).Version())
+	}
+}
+
+func TestCalVerNext(t *testing.T) {
+	date := time.Date(2020, time.February, 9, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		format          string
+		version         string
+		expectedVersion string
+		expectedError   string
+	}{
+		{
+			name:            "NewMonth",
+			format:          DefaultFormat,
+			version:         "2020.01.3",
+			expectedVersion: "2020.02.0",
+		},
+		{
+			name:            "NewYear",
+			format:          DefaultFormat,
+			version:         "2019.12.1",
+			expectedVersion: "2020.02.0",
+		},
+		{
+			name:            "SameMonth",
+			format:          DefaultFormat,
+			version:         "2020.02.1",
+			expectedVersion: "2020.02.2",
+		},
+		{
+			name:            "WithModifier",
+			format:          DefaultFormat,
+			version:         "2020.02.1-dev",
+			expectedVersion: "2020.02.1",
+		},
+		{
+			name:            "NewMonthWithModifier",
+			format:          DefaultFormat,
+			version:         "2020.01.1-dev",
+			expectedVersion: "2020.02.0",
+		},
+		{
+			name:            "SameDayWithModifier",
+			format:          "YYYY.0M.0D",
+			version:         "2020.02.09-rc",
+			expectedVersion: "2020.02.09",
+		},
+		{
+			name:            "SameWeek",
+			format:          "YY.WW.MICRO",
+			version:         "20.6.0",
+			expectedVersion: "20.6.1",
+		},
+		{
+			name:          "Ahead",
+			format:        DefaultFormat,
+			version:       "2020.03.0",
+			expectedError: "calendar version 2020.03.0 is ahead of the release date 2020-02-09",
+		},
+		{
+			name:          "SameDayNoMicro",
+			format:        "YYYY.0M.0D",
+			version:       "2020.02.09",
+			expectedError: "calendar version 2020.02.09 is already released and format YYYY.0M.0D has no MICRO",
+		},
+		{
+			name:            "NewDayNoMicro",
+			format:          "YYYY.0M.0D",
+			version:         "2020.02.08",
+			expectedVersion: "2020.02.09",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ParseFormat(tc.format)
+			assert.NoError(t, err)
+
+			v, err := f.Parse(tc.version)
+			assert.NoError(t, err)
+
+			next, err := v.Next(date)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedVersion, next.Version())
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+			}
+		})
+	}
+}
@@ -2,26 +2,65 @@ package cui
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/mitchellh/cli"
 )
 
+// Level is the verbosity level of a CUI.
+type Level int
+
+const (
+	// Quiet only shows warnings and errors.
+	Quiet Level = iota - 1
+	// Normal shows all messages except debug and trace messages.
+	Normal
+	// Verbose shows debug messages in addition to normal messages.
+	Verbose
+	// Trace shows trace messages in addition to debug and normal messages.
+	Trace
+)
+
 // CUI is the command-line user interface.
 type CUI interface {
 	Outputf(string, ...interface{})
 	Infof(string, ...interface{})
 	Warnf(string, ...interface{})
 	Errorf(string, ...interface{})
+	Debugf(string, ...interface{})
+	Tracef(string, ...interface{})
+	Event(Event)
+	Ask(string) (string, error)
+	Confirm(string) (bool, error)
 }
 
 type cui struct {
-	ui cli.Ui
+	ui    cli.Ui
+	level Level
+	// secrets are scrubbed from all messages.
+	secrets []string
+	// out is the terminal for updating the progress in place.
+	// It is nil if the standard output is not a terminal.
+	out io.Writer
+	// interactive is true if the standard input is a terminal.
+	interactive bool
 }
 
 // New creates a new instance of CUI which is colored and concurrency safe.
-func New() CUI {
+// Secrets and known token formats are redacted from all messages.
+func New(level Level, secrets ...string) CUI {
+	var out io.Writer
+	if level > Quiet && isTerminal(os.Stdout) {
+		out = os.Stdout
+	}
+
 	return &cui{
+		level:       level,
+		secrets:     secrets,
+		out:         out,
+		interactive: isTerminal(os.Stdin),
 		ui: &cli.ConcurrentUi{
 			Ui: &cli.ColoredUi{
 				Ui: &cli.BasicUi{
@@ -38,26 +77,80 @@ func New() CUI {
 	}
 }
 
+func (c *cui) sprintf(format string, v ...interface{}) string {
+	return redact(fmt.Sprintf(format, v...), c.secrets)
+}
+
 func (c *cui) Outputf(format string, v ...interface{}) {
-	if c.ui != nil {
-		c.ui.Output(fmt.Sprintf(format, v...))
+	if c.ui != nil && c.level >= Normal {
+		c.ui.Output(c.sprintf(format, v...))
 	}
 }
 
 func (c *cui) Infof(format string, v ...interface{}) {
-	if c.ui != nil {
-		c.ui.Info(fmt.Sprintf(format, v...))
+	if c.ui != nil && c.level >= Normal {
+		c.ui.Info(c.sprintf(format, v...))
 	}
 }
 
 func (c *cui) Warnf(format string, v ...interface{}) {
 	if c.ui != nil {
-		c.ui.Warn(fmt.Sprintf(format, v...))
+		c.ui.Warn(c.sprintf(format, v...))
 	}
 }
 
 func (c *cui) Errorf(format string, v ...interface{}) {
 	if c.ui != nil {
-		c.ui.Error(fmt.Sprintf(format, v...))
+		c.ui.Error(c.sprintf(format, v...))
+	}
+}
+
+func (c *cui) Debugf(format string, v ...interface{}) {
+	if c.ui != nil && c.level >= Verbose {
+		c.ui.Output(c.sprintf(format, v...))
+	}
+}
+
+func (c *cui) Tracef(format string, v ...interface{}) {
+	if c.ui != nil && c.level >= Trace {
+		c.ui.Output(c.sprintf(format, v...))
 	}
 }
+
+// Event is a no-op since events are already reported as human-readable messages.
+func (c *cui) Event(e Event) {}
+
+// Ask asks a question and returns the answer read from the standard input.
+func (c *cui) Ask(query string) (string, error) {
+	return c.ui.Ask(query)
+}
+
+// Confirm asks a yes/no question and returns true only if the answer is yes.
+// If the standard input is not a terminal, the question is not asked and true is returned.
+func (c *cui) Confirm(query string) (bool, error) {
+	if !c.interactive {
+		return true, nil
+	}
+
+	answer, err := c.ui.Ask(query + " [y/N]")
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// isTerminal determines whether or not a file is a terminal (character device).
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&os.ModeCharDevice != 0
+}
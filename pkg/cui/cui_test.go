@@ -1,6 +1,7 @@
 package cui
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,55 +49,188 @@ func (m *mockUI) AskSecret(query string) (string, error) {
 }
 
 func TestNew(t *testing.T) {
-	ci := New()
+	ci := New(Normal, "secret")
 	assert.NotNil(t, ci)
 
 	cs, ok := ci.(*cui)
 	assert.True(t, ok)
 	assert.NotNil(t, cs)
 	assert.NotNil(t, cs.ui)
+	assert.Equal(t, Normal, cs.level)
+	assert.Equal(t, []string{"secret"}, cs.secrets)
 }
 
 func TestCUI(t *testing.T) {
 	tests := []struct {
-		ui             *mockUI
+		name           string
+		level          Level
+		secrets        []string
 		format         string
 		vals           []interface{}
 		expectedOutput string
+		expectedInfo   string
+		expectedWarn   string
+		expectedError  string
+		expectedDebug  string
+		expectedTrace  string
 	}{
 		{
-			ui:     &mockUI{},
-			format: "Hello, %s!",
-			vals: []interface{}{
-				"World",
-			},
+			name:           "Quiet",
+			level:          Quiet,
+			format:         "Hello, %s!",
+			vals:           []interface{}{"World"},
+			expectedOutput: "",
+			expectedInfo:   "",
+			expectedWarn:   "Hello, World!",
+			expectedError:  "Hello, World!",
+			expectedDebug:  "",
+			expectedTrace:  "",
+		},
+		{
+			name:           "Normal",
+			level:          Normal,
+			format:         "Hello, %s!",
+			vals:           []interface{}{"World"},
+			expectedOutput: "Hello, World!",
+			expectedInfo:   "Hello, World!",
+			expectedWarn:   "Hello, World!",
+			expectedError:  "Hello, World!",
+			expectedDebug:  "",
+			expectedTrace:  "",
+		},
+		{
+			name:           "Verbose",
+			level:          Verbose,
+			format:         "Hello, %s!",
+			vals:           []interface{}{"World"},
 			expectedOutput: "Hello, World!",
+			expectedInfo:   "Hello, World!",
+			expectedWarn:   "Hello, World!",
+			expectedError:  "Hello, World!",
+			expectedDebug:  "Hello, World!",
+			expectedTrace:  "",
+		},
+		{
+			name:           "Trace",
+			level:          Trace,
+			format:         "Hello, %s!",
+			vals:           []interface{}{"World"},
+			expectedOutput: "Hello, World!",
+			expectedInfo:   "Hello, World!",
+			expectedWarn:   "Hello, World!",
+			expectedError:  "Hello, World!",
+			expectedDebug:  "Hello, World!",
+			expectedTrace:  "Hello, World!",
+		},
+		{
+			name:           "Redacted",
+			level:          Normal,
+			secrets:        []string{"s3cr3t"},
+			format:         "git push https://%s@github.com/octocat/Hello-World",
+			vals:           []interface{}{"s3cr3t"},
+			expectedOutput: "git push https://***@github.com/octocat/Hello-World",
+			expectedInfo:   "git push https://***@github.com/octocat/Hello-World",
+			expectedWarn:   "git push https://***@github.com/octocat/Hello-World",
+			expectedError:  "git push https://***@github.com/octocat/Hello-World",
 		},
 	}
 
 	for _, tc := range tests {
-		c := &cui{
-			ui: tc.ui,
-		}
-
-		t.Run("Outputf", func(t *testing.T) {
+		t.Run(tc.name, func(t *testing.T) {
+			// Debug and trace messages are written as outputs
+			debugUI, traceUI := &mockUI{}, &mockUI{}
+			(&cui{ui: debugUI, level: tc.level, secrets: tc.secrets}).Debugf(tc.format, tc.vals...)
+			(&cui{ui: traceUI, level: tc.level, secrets: tc.secrets}).Tracef(tc.format, tc.vals...)
+			assert.Equal(t, tc.expectedDebug, debugUI.OutputInMsg)
+			assert.Equal(t, tc.expectedTrace, traceUI.OutputInMsg)
+
+			ui := &mockUI{}
+			c := &cui{ui: ui, level: tc.level, secrets: tc.secrets}
 			c.Outputf(tc.format, tc.vals...)
-			assert.Equal(t, tc.expectedOutput, tc.ui.OutputInMsg)
-		})
-
-		t.Run("Infof", func(t *testing.T) {
 			c.Infof(tc.format, tc.vals...)
-			assert.Equal(t, tc.expectedOutput, tc.ui.InfoInMsg)
-		})
-
-		t.Run("Warnf", func(t *testing.T) {
 			c.Warnf(tc.format, tc.vals...)
-			assert.Equal(t, tc.expectedOutput, tc.ui.WarnInMsg)
+			c.Errorf(tc.format, tc.vals...)
+			c.Event(Event{Type: EventVersion})
+
+			assert.Equal(t, tc.expectedOutput, ui.OutputInMsg)
+			assert.Equal(t, tc.expectedInfo, ui.InfoInMsg)
+			assert.Equal(t, tc.expectedWarn, ui.WarnInMsg)
+			assert.Equal(t, tc.expectedError, ui.ErrorInMsg)
 		})
+	}
+}
 
-		t.Run("Errorf", func(t *testing.T) {
-			c.Errorf(tc.format, tc.vals...)
-			assert.Equal(t, tc.expectedOutput, tc.ui.ErrorInMsg)
+func TestCUIAsk(t *testing.T) {
+	ui := &mockUI{AskOutResult: "answer"}
+	c := &cui{ui: ui}
+
+	answer, err := c.Ask("question?")
+	assert.NoError(t, err)
+	assert.Equal(t, "answer", answer)
+	assert.Equal(t, "question?", ui.AskInQuery)
+}
+
+func TestCUIConfirm(t *testing.T) {
+	tests := []struct {
+		name           string
+		interactive    bool
+		answer         string
+		err            error
+		expectedQuery  string
+		expectedResult bool
+		expectedError  string
+	}{
+		{
+			name:           "NotInteractive",
+			interactive:    false,
+			expectedQuery:  "",
+			expectedResult: true,
+		},
+		{
+			name:          "AskFails",
+			interactive:   true,
+			err:           errors.New("EOF"),
+			expectedQuery: "Proceed? [y/N]",
+			expectedError: "EOF",
+		},
+		{
+			name:           "Default",
+			interactive:    true,
+			answer:         "",
+			expectedQuery:  "Proceed? [y/N]",
+			expectedResult: false,
+		},
+		{
+			name:           "No",
+			interactive:    true,
+			answer:         "n",
+			expectedQuery:  "Proceed? [y/N]",
+			expectedResult: false,
+		},
+		{
+			name:           "Yes",
+			interactive:    true,
+			answer:         " Yes ",
+			expectedQuery:  "Proceed? [y/N]",
+			expectedResult: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := &mockUI{AskOutResult: tc.answer, AskOutError: tc.err}
+			c := &cui{ui: ui, interactive: tc.interactive}
+
+			ok, err := c.Confirm("Proceed?")
+			assert.Equal(t, tc.expectedQuery, ui.AskInQuery)
+
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedResult, ok)
+			} else {
+				assert.EqualError(t, err, tc.expectedError)
+				assert.False(t, ok)
+			}
 		})
 	}
 }
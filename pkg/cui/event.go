@@ -0,0 +1,53 @@
+package cui
+
+import "time"
+
+// Event types.
+const (
+	EventMessage      = "message"
+	EventStepStarted  = "step_started"
+	EventStepFinished = "step_finished"
+	EventStepFailed   = "step_failed"
+	EventArtifact     = "artifact"
+	EventVersion      = "version"
+	EventRelease      = "release"
+	EventPlan         = "plan"
+	EventSummary      = "summary"
+)
+
+// Statuses of a command in the summary event.
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Event is a machine-readable event about the progress or result of a command.
+type Event struct {
+	Type      string     `json:"type"`
+	Time      time.Time  `json:"time"`
+	Level     string     `json:"level,omitempty"`
+	Message   string     `json:"message,omitempty"`
+	Step      string     `json:"step,omitempty"`
+	Duration  float64    `json:"duration,omitempty"` // seconds
+	Error     string     `json:"error,omitempty"`
+	Artifact  string     `json:"artifact,omitempty"`
+	Version   string     `json:"version,omitempty"`
+	URL       string     `json:"url,omitempty"`
+	Command   string     `json:"command,omitempty"`
+	Status    string     `json:"status,omitempty"`
+	Artifacts []string   `json:"artifacts,omitempty"`
+	Plan      []PlanStep `json:"plan,omitempty"`
+}
+
+// PlanStep is a step a command will execute with its resolved parameters.
+type PlanStep struct {
+	Step        string      `json:"step"`
+	Description string      `json:"description"`
+	Params      []PlanParam `json:"params,omitempty"`
+}
+
+// PlanParam is a resolved parameter of a planned step.
+type PlanParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
@@ -0,0 +1,139 @@
+package cui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Output formats.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+type jsonCUI struct {
+	mu      sync.Mutex
+	enc     *json.Encoder
+	now     func() time.Time
+	level   Level
+	secrets []string
+
+	// The summary event is completed with the artifacts, version, and release url reported before.
+	artifacts []string
+	version   string
+	url       string
+}
+
+// NewJSON creates a new instance of CUI which writes newline-delimited JSON events.
+// Messages are reported as message events and the final summary includes the reported artifacts, version, and release url.
+// The verbosity level only applies to messages and secrets are redacted from messages and errors.
+func NewJSON(w io.Writer, level Level, secrets ...string) CUI {
+	return &jsonCUI{
+		enc:     json.NewEncoder(w),
+		now:     time.Now,
+		level:   level,
+		secrets: secrets,
+	}
+}
+
+func (c *jsonCUI) message(minLevel Level, level, format string, v ...interface{}) {
+	if c.level < minLevel {
+		return
+	}
+
+	msg := fmt.Sprintf(format, v...)
+
+	// Decorative emojis are not useful for machines
+	msg = strings.TrimLeftFunc(msg, func(r rune) bool {
+		return r > unicode.MaxASCII || unicode.IsSpace(r)
+	})
+
+	c.Event(Event{
+		Type:    EventMessage,
+		Level:   level,
+		Message: strings.TrimSpace(msg),
+	})
+}
+
+func (c *jsonCUI) Outputf(format string, v ...interface{}) {
+	c.message(Normal, "output", format, v...)
+}
+
+func (c *jsonCUI) Infof(format string, v ...interface{}) {
+	c.message(Normal, "info", format, v...)
+}
+
+func (c *jsonCUI) Warnf(format string, v ...interface{}) {
+	c.message(Quiet, "warn", format, v...)
+}
+
+func (c *jsonCUI) Errorf(format string, v ...interface{}) {
+	c.message(Quiet, "error", format, v...)
+}
+
+func (c *jsonCUI) Debugf(format string, v ...interface{}) {
+	c.message(Verbose, "debug", format, v...)
+}
+
+func (c *jsonCUI) Tracef(format string, v ...interface{}) {
+	c.message(Trace, "trace", format, v...)
+}
+
+func (c *jsonCUI) Event(e Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e.Time.IsZero() {
+		e.Time = c.now()
+	}
+
+	e.Message = redact(e.Message, c.secrets)
+	e.Error = redact(e.Error, c.secrets)
+
+	for i := range e.Plan {
+		for j := range e.Plan[i].Params {
+			e.Plan[i].Params[j].Value = redact(e.Plan[i].Params[j].Value, c.secrets)
+		}
+	}
+
+	switch e.Type {
+	case EventArtifact:
+		c.artifacts = append(c.artifacts, e.Artifact)
+	case EventVersion:
+		c.version = e.Version
+	case EventRelease:
+		c.url = e.URL
+		if e.Version != "" {
+			c.version = e.Version
+		}
+	case EventSummary:
+		if e.Artifacts == nil {
+			e.Artifacts = c.artifacts
+		}
+		if e.Version == "" {
+			e.Version = c.version
+		}
+		if e.URL == "" {
+			e.URL = c.url
+		}
+	}
+
+	// Errors cannot be reported anywhere else
+	_ = c.enc.Encode(e)
+}
+
+// Ask always fails since questions cannot be answered in JSON output mode.
+func (c *jsonCUI) Ask(query string) (string, error) {
+	return "", errors.New("cannot ask for input in JSON output mode")
+}
+
+// Confirm does not ask for confirmation in JSON output mode and returns true.
+func (c *jsonCUI) Confirm(query string) (bool, error) {
+	return true, nil
+}
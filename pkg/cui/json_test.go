@@ -0,0 +1,117 @@
+package cui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJSON(t *testing.T) {
+	c := NewJSON(&bytes.Buffer{}, Normal)
+	assert.NotNil(t, c)
+
+	_, ok := c.(*jsonCUI)
+	assert.True(t, ok)
+}
+
+func TestJSONCUI(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := NewJSON(buf, Normal).(*jsonCUI)
+	c.now = func() time.Time {
+		return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	c.Outputf("⏺️  Running preflight checks ...")
+	c.Infof("⬆️  Pushing release tag %s ...", "v0.1.0")
+	c.Warnf("🔓 Temporarily enabling push to master branch ...")
+	c.Errorf("Error: %s", "failed")
+	c.Event(Event{Type: EventPlan, Plan: []PlanStep{
+		{Step: "GitTag", Description: "Create tag v0.1.0", Params: []PlanParam{{Name: "annotation", Value: "Version 0.1.0"}}},
+	}})
+	c.Event(Event{Type: EventStepStarted, Step: "GoBuild"})
+	c.Event(Event{Type: EventStepFinished, Step: "GoBuild", Duration: 1.5})
+	c.Event(Event{Type: EventVersion, Version: "0.1.0"})
+	c.Event(Event{Type: EventArtifact, Artifact: "bin/app-linux-amd64"})
+	c.Event(Event{Type: EventArtifact, Artifact: "bin/app-darwin-amd64"})
+	c.Event(Event{Type: EventRelease, URL: "https://github.com/octocat/Hello-World/releases/tag/v0.1.0"})
+	c.Event(Event{Type: EventSummary, Command: "release", Status: StatusSucceeded})
+
+	expectedLines := []string{
+		`{"type":"message","time":"2020-01-01T00:00:00Z","level":"output","message":"Running preflight checks ..."}`,
+		`{"type":"message","time":"2020-01-01T00:00:00Z","level":"info","message":"Pushing release tag v0.1.0 ..."}`,
+		`{"type":"message","time":"2020-01-01T00:00:00Z","level":"warn","message":"Temporarily enabling push to master branch ..."}`,
+		`{"type":"message","time":"2020-01-01T00:00:00Z","level":"error","message":"Error: failed"}`,
+		`{"type":"plan","time":"2020-01-01T00:00:00Z","plan":[{"step":"GitTag","description":"Create tag v0.1.0","params":[{"name":"annotation","value":"Version 0.1.0"}]}]}`,
+		`{"type":"step_started","time":"2020-01-01T00:00:00Z","step":"GoBuild"}`,
+		`{"type":"step_finished","time":"2020-01-01T00:00:00Z","step":"GoBuild","duration":1.5}`,
+		`{"type":"version","time":"2020-01-01T00:00:00Z","version":"0.1.0"}`,
+		`{"type":"artifact","time":"2020-01-01T00:00:00Z","artifact":"bin/app-linux-amd64"}`,
+		`{"type":"artifact","time":"2020-01-01T00:00:00Z","artifact":"bin/app-darwin-amd64"}`,
+		`{"type":"release","time":"2020-01-01T00:00:00Z","url":"https://github.com/octocat/Hello-World/releases/tag/v0.1.0"}`,
+		`{"type":"summary","time":"2020-01-01T00:00:00Z","version":"0.1.0","url":"https://github.com/octocat/Hello-World/releases/tag/v0.1.0","command":"release","status":"succeeded","artifacts":["bin/app-linux-amd64","bin/app-darwin-amd64"]}`,
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, expectedLines, lines)
+
+	for _, line := range lines {
+		var e Event
+		assert.NoError(t, json.Unmarshal([]byte(line), &e))
+	}
+}
+
+func TestJSONCUILevel(t *testing.T) {
+	tests := []struct {
+		name           string
+		level          Level
+		expectedLevels []string
+	}{
+		{"Quiet", Quiet, []string{"warn", "error"}},
+		{"Normal", Normal, []string{"output", "info", "warn", "error"}},
+		{"Verbose", Verbose, []string{"output", "info", "warn", "error", "debug"}},
+		{"Trace", Trace, []string{"output", "info", "warn", "error", "debug", "trace"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			c := NewJSON(buf, tc.level, "s3cr3t")
+
+			c.Outputf("output")
+			c.Infof("info")
+			c.Warnf("warn")
+			c.Errorf("error")
+			c.Debugf("$ git push https://%s@github.com/octocat/Hello-World", "s3cr3t")
+			c.Tracef("trace")
+
+			levels := []string{}
+			dec := json.NewDecoder(buf)
+			for dec.More() {
+				var e Event
+				assert.NoError(t, dec.Decode(&e))
+				assert.NotContains(t, e.Message, "s3cr3t")
+				levels = append(levels, e.Level)
+			}
+
+			assert.Equal(t, tc.expectedLevels, levels)
+		})
+	}
+}
+
+func TestJSONCUIAskConfirm(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := NewJSON(buf, Normal)
+
+	_, err := c.Ask("question?")
+	assert.EqualError(t, err, "cannot ask for input in JSON output mode")
+
+	ok, err := c.Confirm("Proceed?")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Empty(t, buf.String())
+}
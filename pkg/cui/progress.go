@@ -0,0 +1,241 @@
+package cui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	terminalInterval = 200 * time.Millisecond
+	summaryInterval  = 5 * time.Second
+)
+
+// Progress reports the progress of data transfers such as uploads and downloads.
+// On a terminal, bytes, percentage, and throughput of each transfer are updated in place.
+// Otherwise, a summary line is printed periodically.
+type Progress struct {
+	ui       CUI
+	out      io.Writer
+	verb     string
+	interval time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	start     time.Time
+	lastPrint time.Time
+	lines     int
+	transfers []*Transfer
+}
+
+// Transfer is a single data transfer tracked by a Progress.
+type Transfer struct {
+	progress *Progress
+	name     string
+	total    int64
+	current  int64 // accessed atomically
+	start    time.Time
+	end      time.Time
+}
+
+// NewProgress creates a new progress for a set of data transfers.
+// verb describes the transfers in the output (i.e. Uploading or Downloading).
+func NewProgress(ui CUI, verb string) *Progress {
+	p := &Progress{
+		ui:       ui,
+		verb:     verb,
+		interval: summaryInterval,
+		now:      time.Now,
+	}
+
+	if c, ok := ui.(*cui); ok && c.out != nil {
+		p.out = c.out
+		p.interval = terminalInterval
+	}
+
+	p.start = p.now()
+	p.lastPrint = p.start
+
+	return p
+}
+
+// Add starts tracking a new transfer.
+// If the size of data is unknown, total should be -1.
+func (p *Progress) Add(name string, total int64) *Transfer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	t := &Transfer{
+		progress: p,
+		name:     name,
+		total:    total,
+		start:    p.now(),
+	}
+
+	p.transfers = append(p.transfers, t)
+
+	return t
+}
+
+// Finish prints the final state of transfers and the total bytes, time, and throughput.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.out != nil {
+		p.render()
+	}
+
+	var bytes int64
+	for _, t := range p.transfers {
+		bytes += atomic.LoadInt64(&t.current)
+	}
+
+	elapsed := p.now().Sub(p.start)
+	p.ui.Outputf("%s %d file(s), %s in %s (%s/s)",
+		strings.TrimSuffix(p.verb, "ing")+"ed", len(p.transfers), formatBytes(bytes), elapsed.Round(10*time.Millisecond), formatBytes(rate(bytes, elapsed)),
+	)
+}
+
+// update prints the progress if enough time has passed since the last time.
+func (p *Progress) update(force bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if !force && now.Sub(p.lastPrint) < p.interval {
+		return
+	}
+	p.lastPrint = now
+
+	if p.out != nil {
+		p.render()
+	} else {
+		p.summarize(now)
+	}
+}
+
+// render redraws the progress of every transfer in place on a terminal.
+func (p *Progress) render() {
+	var b strings.Builder
+
+	// Move the cursor back to the first line of progress
+	if p.lines > 0 {
+		fmt.Fprintf(&b, "\033[%dA", p.lines)
+	}
+
+	now := p.now()
+	for _, t := range p.transfers {
+		fmt.Fprintf(&b, "\r\033[K%s\n", t.String(now))
+	}
+
+	p.lines = len(p.transfers)
+	io.WriteString(p.out, b.String())
+}
+
+// summarize prints a single line with the progress of all transfers.
+func (p *Progress) summarize(now time.Time) {
+	var done int
+	var current, total int64
+	for _, t := range p.transfers {
+		current += atomic.LoadInt64(&t.current)
+		if t.total > 0 {
+			total += t.total
+		}
+		if !t.end.IsZero() {
+			done++
+		}
+	}
+
+	p.ui.Outputf("%s %d/%d file(s): %s of %s (%s/s)",
+		p.verb, done, len(p.transfers), formatBytes(current), formatBytes(total), formatBytes(rate(current, now.Sub(p.start))),
+	)
+}
+
+// Reader wraps a reader and counts the bytes read from it as transferred.
+func (t *Transfer) Reader(r io.Reader) io.Reader {
+	return &countingReader{
+		Reader:   r,
+		transfer: t,
+	}
+}
+
+// Reset sets the transferred bytes back to zero for retrying a transfer.
+func (t *Transfer) Reset() {
+	atomic.StoreInt64(&t.current, 0)
+	t.progress.update(false)
+}
+
+// Done marks the transfer as completed.
+func (t *Transfer) Done() {
+	t.progress.mu.Lock()
+	t.end = t.progress.now()
+	t.progress.mu.Unlock()
+
+	t.progress.update(t.progress.out != nil)
+}
+
+func (t *Transfer) add(n int) {
+	atomic.AddInt64(&t.current, int64(n))
+	t.progress.update(false)
+}
+
+// String returns the bytes, percentage, and throughput of a transfer.
+func (t *Transfer) String(now time.Time) string {
+	current := atomic.LoadInt64(&t.current)
+
+	end := t.end
+	if end.IsZero() {
+		end = now
+	}
+	throughput := formatBytes(rate(current, end.Sub(t.start))) + "/s"
+
+	if t.total <= 0 {
+		return fmt.Sprintf("%s  %s  %s", t.name, formatBytes(current), throughput)
+	}
+
+	percent := current * 100 / t.total
+	return fmt.Sprintf("%s  %s / %s  %3d%%  %s", t.name, formatBytes(current), formatBytes(t.total), percent, throughput)
+}
+
+type countingReader struct {
+	io.Reader
+	transfer *Transfer
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	if n > 0 {
+		r.transfer.add(n)
+	}
+
+	return n, err
+}
+
+// rate returns the number of bytes per second.
+func rate(bytes int64, d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+
+	return int64(float64(bytes) / d.Seconds())
+}
+
+// formatBytes returns a human-readable string for a number of bytes.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,152 @@
+package cui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock returns a time that is advanced by one second every time it is called.
+func fakeClock() func() time.Time {
+	now := time.Unix(1000, 0)
+	return func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+}
+
+func TestNewProgress(t *testing.T) {
+	tests := []struct {
+		name             string
+		ui               CUI
+		expectedTerminal bool
+		expectedInterval time.Duration
+	}{
+		{
+			name:             "NotTerminal",
+			ui:               &cui{ui: &mockUI{}},
+			expectedTerminal: false,
+			expectedInterval: summaryInterval,
+		},
+		{
+			name:             "Terminal",
+			ui:               &cui{ui: &mockUI{}, out: &bytes.Buffer{}},
+			expectedTerminal: true,
+			expectedInterval: terminalInterval,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewProgress(tc.ui, "Uploading")
+			assert.NotNil(t, p)
+			assert.Equal(t, tc.expectedTerminal, p.out != nil)
+			assert.Equal(t, tc.expectedInterval, p.interval)
+		})
+	}
+}
+
+func TestProgressSummary(t *testing.T) {
+	ui := &mockUI{}
+	p := NewProgress(&cui{ui: ui}, "Uploading")
+	p.now = fakeClock()
+	p.start = p.now()
+	p.lastPrint = p.start
+	p.interval = 0
+
+	t1 := p.Add("app-linux-amd64", 2048)
+	t2 := p.Add("app-darwin-amd64", 2048)
+
+	data, err := ioutil.ReadAll(t1.Reader(strings.NewReader(strings.Repeat("x", 2048))))
+	assert.NoError(t, err)
+	assert.Len(t, data, 2048)
+	t1.Done()
+	assert.Regexp(t, `^Uploading 1/2 file\(s\): 2.0 KiB of 4.0 KiB \(\d+ B/s\)$`, ui.OutputInMsg)
+
+	_, err = ioutil.ReadAll(t2.Reader(strings.NewReader(strings.Repeat("x", 1024))))
+	assert.NoError(t, err)
+	t2.Reset()
+	assert.Regexp(t, `^Uploading 1/2 file\(s\): 2.0 KiB of 4.0 KiB`, ui.OutputInMsg)
+
+	p.Finish()
+	assert.Regexp(t, `^Uploaded 2 file\(s\), 2.0 KiB in \d+s \(\d+ B/s\)$`, ui.OutputInMsg)
+}
+
+func TestProgressTerminal(t *testing.T) {
+	out := &bytes.Buffer{}
+	ui := &mockUI{}
+	p := NewProgress(&cui{ui: ui, out: out}, "Downloading")
+	p.now = fakeClock()
+	p.start = p.now()
+	p.lastPrint = p.start
+
+	tr := p.Add("cherry-linux-amd64", -1)
+	_, err := ioutil.ReadAll(tr.Reader(strings.NewReader(strings.Repeat("x", 3*1024*1024))))
+	assert.NoError(t, err)
+	tr.Done()
+
+	assert.Contains(t, out.String(), "\r\033[Kcherry-linux-amd64  3.0 MiB  ")
+	assert.Contains(t, out.String(), "\033[1A")
+	assert.Equal(t, 1, p.lines)
+
+	p.Finish()
+	assert.Regexp(t, `^Downloaded 1 file\(s\), 3.0 MiB in \d+s \(.+/s\)$`, ui.OutputInMsg)
+}
+
+func TestTransferString(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	tests := []struct {
+		name           string
+		transfer       *Transfer
+		now            time.Time
+		expectedString string
+	}{
+		{
+			name:           "UnknownSize",
+			transfer:       &Transfer{name: "asset", total: -1, current: 2048, start: start},
+			now:            start.Add(2 * time.Second),
+			expectedString: "asset  2.0 KiB  1.0 KiB/s",
+		},
+		{
+			name:           "InProgress",
+			transfer:       &Transfer{name: "asset", total: 4096, current: 1024, start: start},
+			now:            start.Add(time.Second),
+			expectedString: "asset  1.0 KiB / 4.0 KiB   25%  1.0 KiB/s",
+		},
+		{
+			name:           "Done",
+			transfer:       &Transfer{name: "asset", total: 4096, current: 4096, start: start, end: start.Add(4 * time.Second)},
+			now:            start.Add(time.Minute),
+			expectedString: "asset  4.0 KiB / 4.0 KiB  100%  1.0 KiB/s",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedString, tc.transfer.String(tc.now))
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes          int64
+		expectedString string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{10 * 1024 * 1024, "10.0 MiB"},
+		{5 * 1024 * 1024 * 1024, "5.0 GiB"},
+	}
+
+	for _, tc := range tests {
+		assert.Equal(t, tc.expectedString, formatBytes(tc.bytes))
+	}
+}
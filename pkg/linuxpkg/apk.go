@@ -0,0 +1,98 @@
+package linuxpkg
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// apkInfo returns the .PKGINFO file of an apk package.
+func (p Package) apkInfo(deps []dependency, datahash []byte) string {
+	var b strings.Builder
+
+	b.WriteString("# Generated by cherry\n")
+	fmt.Fprintf(&b, "pkgname = %s\n", p.Name)
+	fmt.Fprintf(&b, "pkgver = %s-r0\n", p.version(FormatAPK))
+	fmt.Fprintf(&b, "pkgdesc = %s\n", p.summary())
+
+	if p.Homepage != "" {
+		fmt.Fprintf(&b, "url = %s\n", p.Homepage)
+	}
+
+	fmt.Fprintf(&b, "builddate = %d\n", p.modTime().Unix())
+	fmt.Fprintf(&b, "packager = %s\n", p.Maintainer)
+	fmt.Fprintf(&b, "size = %d\n", p.installedSize())
+	fmt.Fprintf(&b, "arch = %s\n", p.arch(FormatAPK))
+	fmt.Fprintf(&b, "origin = %s\n", p.Name)
+	fmt.Fprintf(&b, "maintainer = %s\n", p.Maintainer)
+
+	if p.License != "" {
+		fmt.Fprintf(&b, "license = %s\n", p.License)
+	}
+
+	for _, d := range deps {
+		fmt.Fprintf(&b, "depend = %s%s%s\n", d.Name, d.Op, d.Version)
+	}
+
+	fmt.Fprintf(&b, "datahash = %x\n", datahash)
+
+	return b.String()
+}
+
+// writeAPK writes an unsigned apk package consisting of concatenated gzip streams for control and data tar archives.
+// The control archive is not terminated, so both archives form a single tar archive when decompressed together.
+// The checksums of files are stored in PAX headers as apk-tools expects.
+func (p Package) writeAPK(w io.Writer) error {
+	mtime := p.modTime()
+
+	deps, err := p.dependencies()
+	if err != nil {
+		return err
+	}
+
+	data := []tarFile{}
+	for _, dir := range p.dirs() {
+		data = append(data, tarFile{Name: strings.TrimPrefix(dir, "/") + "/", Mode: 0755, Dir: true})
+	}
+
+	for _, f := range p.files() {
+		data = append(data, tarFile{
+			Name:    strings.TrimPrefix(f.Path, "/"),
+			Content: f.Content,
+			Mode:    int64(f.Mode.Perm()),
+			PAX: map[string]string{
+				"APK-TOOLS.checksum.SHA1": fmt.Sprintf("%x", sha1.Sum(f.Content)),
+			},
+		})
+	}
+
+	dataTar, err := gzipTar(mtime, data, true)
+	if err != nil {
+		return err
+	}
+
+	datahash := sha256.Sum256(dataTar)
+
+	control := []tarFile{
+		{Name: ".PKGINFO", Content: []byte(p.apkInfo(deps, datahash[:])), Mode: 0644},
+	}
+
+	if p.PostInstall != "" {
+		control = append(control, tarFile{Name: ".post-install", Content: []byte(shellScript(p.PostInstall)), Mode: 0755})
+	}
+
+	controlTar, err := gzipTar(mtime, control, false)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range [][]byte{controlTar, dataTar} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
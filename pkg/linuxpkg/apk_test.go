@@ -0,0 +1,53 @@
+package linuxpkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageWriteAPK(t *testing.T) {
+	p := testPackage()
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.Write(&buf, FormatAPK))
+
+	data := buf.Bytes()
+
+	// The first gzip stream is the control archive and the second one is the data archive
+	r := bytes.NewReader(data)
+	gr, err := gzip.NewReader(r)
+	assert.NoError(t, err)
+	gr.Multistream(false)
+	_, err = io.Copy(ioutil.Discard, gr)
+	assert.NoError(t, err)
+	dataTar := data[len(data)-r.Len():]
+
+	names, headers, contents := readTarGz(t, data)
+	assert.Equal(t, []string{".PKGINFO", ".post-install", "etc/", "etc/app/", "usr/", "usr/bin/", "etc/app/config.yaml", "usr/bin/app"}, names)
+	assert.Equal(t, fmt.Sprintf(`# Generated by cherry
+pkgname = app
+pkgver = 0.2.0-r0
+pkgdesc = The app tool
+url = https://github.com/username/app
+builddate = 1600000000
+packager = Jane Doe <jane@example.com>
+size = 16
+arch = x86_64
+origin = app
+maintainer = Jane Doe <jane@example.com>
+license = MIT
+depend = ca-certificates
+depend = libc6>=2.28
+datahash = %x
+`, sha256.Sum256(dataTar)), contents[".PKGINFO"])
+	assert.Equal(t, "#!/bin/sh\nsystemctl daemon-reload\n", contents[".post-install"])
+	assert.Equal(t, "binary", contents["usr/bin/app"])
+	assert.Equal(t, "7e57cfe843145135aee1f4d0d63ceb7842093712", headers["usr/bin/app"].PAXRecords["APK-TOOLS.checksum.SHA1"])
+}
@@ -0,0 +1,230 @@
+package linuxpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// writeAr writes an ar archive with the given members in order.
+// Deb packages use the common ar format with file names padded to 16 characters.
+func writeAr(w io.Writer, mtime time.Time, names []string, members [][]byte) error {
+	if _, err := io.WriteString(w, "!<arch>\n"); err != nil {
+		return err
+	}
+
+	for i, name := range names {
+		data := members[i]
+		header := fmt.Sprintf("%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, mtime.Unix(), 0, 0, "100644", len(data))
+		if _, err := io.WriteString(w, header); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+
+		// Members are aligned to even offsets
+		if len(data)%2 == 1 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// tarFile is a file or directory in a tar archive.
+type tarFile struct {
+	Name    string
+	Content []byte
+	Mode    int64
+	Dir     bool
+	PAX     map[string]string
+}
+
+// writeTar writes a tar archive with the given files in order.
+// If trailer is false, the end-of-archive blocks are not written, so the archive can be concatenated to another one.
+func writeTar(w io.Writer, mtime time.Time, files []tarFile, trailer bool) error {
+	tw := tar.NewWriter(w)
+
+	for _, f := range files {
+		h := &tar.Header{
+			Name:       f.Name,
+			Mode:       f.Mode,
+			Uname:      "root",
+			Gname:      "root",
+			ModTime:    mtime,
+			PAXRecords: f.PAX,
+		}
+
+		if f.Dir {
+			h.Typeflag = tar.TypeDir
+		} else {
+			h.Typeflag = tar.TypeReg
+			h.Size = int64(len(f.Content))
+		}
+
+		if f.PAX != nil {
+			h.Format = tar.FormatPAX
+		} else {
+			h.Format = tar.FormatGNU
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+
+		if _, err := tw.Write(f.Content); err != nil {
+			return err
+		}
+	}
+
+	if !trailer {
+		return tw.Flush()
+	}
+
+	return tw.Close()
+}
+
+// gzipTar returns a gzip-compressed tar archive with the given files.
+func gzipTar(mtime time.Time, files []tarFile, trailer bool) ([]byte, error) {
+	var buf bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeTar(gw, mtime, files, trailer); err != nil {
+		return nil, err
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// debControl returns the control file of a deb package.
+func (p Package) debControl(deps []dependency) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Package: %s\n", p.Name)
+	fmt.Fprintf(&b, "Version: %s\n", p.version(FormatDeb))
+	fmt.Fprintf(&b, "Architecture: %s\n", p.arch(FormatDeb))
+	fmt.Fprintf(&b, "Maintainer: %s\n", p.Maintainer)
+	fmt.Fprintf(&b, "Installed-Size: %d\n", (p.installedSize()+1023)/1024)
+
+	if len(deps) > 0 {
+		list := make([]string, len(deps))
+		for i, d := range deps {
+			list[i] = d.Name
+			if d.Op != "" {
+				op := d.Op
+				switch op {
+				case ">":
+					op = ">>"
+				case "<":
+					op = "<<"
+				}
+				list[i] = fmt.Sprintf("%s (%s %s)", d.Name, op, d.Version)
+			}
+		}
+		fmt.Fprintf(&b, "Depends: %s\n", strings.Join(list, ", "))
+	}
+
+	b.WriteString("Section: default\n")
+	b.WriteString("Priority: optional\n")
+
+	if p.Homepage != "" {
+		fmt.Fprintf(&b, "Homepage: %s\n", p.Homepage)
+	}
+
+	// The extended description lines start with a space and empty lines are replaced with a dot
+	lines := strings.Split(strings.TrimSpace(p.Description), "\n")
+	fmt.Fprintf(&b, "Description: %s\n", lines[0])
+	for _, line := range lines[1:] {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			line = "."
+		}
+		fmt.Fprintf(&b, " %s\n", line)
+	}
+
+	return b.String()
+}
+
+// writeDeb writes a deb package consisting of debian-binary, control.tar.gz, and data.tar.gz.
+func (p Package) writeDeb(w io.Writer) error {
+	mtime := p.modTime()
+
+	deps, err := p.dependencies()
+	if err != nil {
+		return err
+	}
+
+	data := []tarFile{
+		{Name: "./", Mode: 0755, Dir: true},
+	}
+
+	for _, dir := range p.dirs() {
+		data = append(data, tarFile{Name: "." + dir + "/", Mode: 0755, Dir: true})
+	}
+
+	var md5sums, conffiles strings.Builder
+	for _, f := range p.files() {
+		data = append(data, tarFile{Name: "." + f.Path, Content: f.Content, Mode: int64(f.Mode.Perm())})
+		fmt.Fprintf(&md5sums, "%x  %s\n", md5.Sum(f.Content), strings.TrimPrefix(f.Path, "/"))
+		if f.Config {
+			fmt.Fprintf(&conffiles, "%s\n", f.Path)
+		}
+	}
+
+	control := []tarFile{
+		{Name: "./", Mode: 0755, Dir: true},
+		{Name: "./control", Content: []byte(p.debControl(deps)), Mode: 0644},
+		{Name: "./md5sums", Content: []byte(md5sums.String()), Mode: 0644},
+	}
+
+	if conffiles.Len() > 0 {
+		control = append(control, tarFile{Name: "./conffiles", Content: []byte(conffiles.String()), Mode: 0644})
+	}
+
+	if p.PostInstall != "" {
+		control = append(control, tarFile{Name: "./postinst", Content: []byte(shellScript(p.PostInstall)), Mode: 0755})
+	}
+
+	controlTar, err := gzipTar(mtime, control, true)
+	if err != nil {
+		return err
+	}
+
+	dataTar, err := gzipTar(mtime, data, true)
+	if err != nil {
+		return err
+	}
+
+	return writeAr(w, mtime,
+		[]string{"debian-binary", "control.tar.gz", "data.tar.gz"},
+		[][]byte{[]byte("2.0\n"), controlTar, dataTar},
+	)
+}
+
+// shellScript adds a shebang line to a script if it does not have one.
+func shellScript(script string) string {
+	if !strings.HasPrefix(script, "#!") {
+		script = "#!/bin/sh\n" + script
+	}
+
+	if !strings.HasSuffix(script, "\n") {
+		script += "\n"
+	}
+
+	return script
+}
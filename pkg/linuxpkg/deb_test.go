@@ -0,0 +1,110 @@
+package linuxpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readAr reads the members of an ar archive.
+func readAr(t *testing.T, data []byte) ([]string, map[string][]byte) {
+	assert.True(t, bytes.HasPrefix(data, []byte("!<arch>\n")))
+	data = data[8:]
+
+	names := []string{}
+	members := map[string][]byte{}
+	for len(data) > 0 {
+		header := string(data[:60])
+		assert.Equal(t, "`\n", header[58:60])
+
+		name := strings.TrimSpace(header[:16])
+		size, err := strconv.Atoi(strings.TrimSpace(header[48:58]))
+		assert.NoError(t, err)
+
+		names = append(names, name)
+		members[name] = data[60 : 60+size]
+
+		data = data[60+size+size%2:]
+	}
+
+	return names, members
+}
+
+// readTarGz reads the files of a gzip-compressed tar archive (multiple gzip streams are read as one).
+func readTarGz(t *testing.T, data []byte) ([]string, map[string]*tar.Header, map[string]string) {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	names := []string{}
+	headers := map[string]*tar.Header{}
+	contents := map[string]string{}
+
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+
+		content, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+
+		names = append(names, h.Name)
+		headers[h.Name] = h
+		contents[h.Name] = string(content)
+	}
+
+	return names, headers, contents
+}
+
+func TestPackageWriteDeb(t *testing.T) {
+	p := testPackage()
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.Write(&buf, FormatDeb))
+
+	names, members := readAr(t, buf.Bytes())
+	assert.Equal(t, []string{"debian-binary", "control.tar.gz", "data.tar.gz"}, names)
+	assert.Equal(t, "2.0\n", string(members["debian-binary"]))
+
+	controlNames, controlHeaders, control := readTarGz(t, members["control.tar.gz"])
+	assert.Equal(t, []string{"./", "./control", "./md5sums", "./conffiles", "./postinst"}, controlNames)
+	assert.Equal(t, `Package: app
+Version: 0.2.0
+Architecture: amd64
+Maintainer: Jane Doe <jane@example.com>
+Installed-Size: 1
+Depends: ca-certificates, libc6 (>= 2.28)
+Section: default
+Priority: optional
+Homepage: https://github.com/username/app
+Description: The app tool
+ The app tool does many things.
+ .
+ And it does them well.
+`, control["./control"])
+	assert.Equal(t, "cf56ccad3eafce53f0adf503c7e03094  etc/app/config.yaml\n9d7183f16acce70658f686ae7f1a4d20  usr/bin/app\n", control["./md5sums"])
+	assert.Equal(t, "/etc/app/config.yaml\n", control["./conffiles"])
+	assert.Equal(t, "#!/bin/sh\nsystemctl daemon-reload\n", control["./postinst"])
+	assert.Equal(t, int64(0755), controlHeaders["./postinst"].Mode)
+
+	dataNames, dataHeaders, data := readTarGz(t, members["data.tar.gz"])
+	assert.Equal(t, []string{"./", "./etc/", "./etc/app/", "./usr/", "./usr/bin/", "./etc/app/config.yaml", "./usr/bin/app"}, dataNames)
+	assert.Equal(t, "binary", data["./usr/bin/app"])
+	assert.Equal(t, int64(0755), dataHeaders["./usr/bin/app"].Mode)
+	assert.Equal(t, "root", dataHeaders["./usr/bin/app"].Uname)
+	assert.Equal(t, p.Time.Unix(), dataHeaders["./usr/bin/app"].ModTime.Unix())
+}
+
+func TestShellScript(t *testing.T) {
+	assert.Equal(t, "#!/bin/sh\necho hi\n", shellScript("echo hi"))
+	assert.Equal(t, "#!/bin/bash\necho hi\n", shellScript("#!/bin/bash\necho hi\n"))
+}
@@ -0,0 +1,272 @@
+// Package linuxpkg builds Linux packages (deb, rpm, and apk) without any external tool.
+package linuxpkg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Supported package formats.
+const (
+	FormatDeb = "deb"
+	FormatRPM = "rpm"
+	FormatAPK = "apk"
+)
+
+// Formats are the supported package formats.
+var Formats = []string{FormatDeb, FormatRPM, FormatAPK}
+
+// archs are the architectures of package formats for Go architectures.
+var archs = map[string]map[string]string{
+	FormatDeb: {
+		"amd64":   "amd64",
+		"386":     "i386",
+		"arm64":   "arm64",
+		"arm":     "armhf",
+		"ppc64le": "ppc64el",
+		"s390x":   "s390x",
+		"riscv64": "riscv64",
+	},
+	FormatRPM: {
+		"amd64":   "x86_64",
+		"386":     "i386",
+		"arm64":   "aarch64",
+		"arm":     "armv7hl",
+		"ppc64le": "ppc64le",
+		"s390x":   "s390x",
+		"riscv64": "riscv64",
+	},
+	FormatAPK: {
+		"amd64":   "x86_64",
+		"386":     "x86",
+		"arm64":   "aarch64",
+		"arm":     "armv7",
+		"ppc64le": "ppc64le",
+		"s390x":   "s390x",
+		"riscv64": "riscv64",
+	},
+}
+
+var (
+	nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9+.-]*$`)
+	depRegex  = regexp.MustCompile(`^([^\s()<>=]+)\s*\(?\s*(?:(>=|<=|>>|<<|=|>|<)\s*([^\s()<>=]+))?\s*\)?$`)
+)
+
+// File is a regular file installed by a package.
+// Path is the absolute path of file on the target system.
+// Config files are not overwritten when a package is upgraded if they have been modified.
+type File struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+	Config  bool
+}
+
+// Package is a Linux package for a Go architecture (i.e. amd64 or arm64).
+// Depends are the package dependencies either in name or name op version form (i.e. ca-certificates or libc6 >= 2.28).
+// The first line of Description is the summary of package.
+// PostInstall is a shell script that runs after the package is installed.
+// Time is used for modification times of files and the build time of package, so packages can be reproduced.
+type Package struct {
+	Name        string
+	Version     string
+	Arch        string
+	Maintainer  string
+	Description string
+	Homepage    string
+	License     string
+	Depends     []string
+	Files       []File
+	PostInstall string
+	Time        time.Time
+}
+
+// dependency is a parsed package dependency.
+type dependency struct {
+	Name    string
+	Op      string
+	Version string
+}
+
+// parseDependency parses a dependency in name, name op version, or name (op version) form.
+// The operators are >=, <=, =, > (or >>), and < (or <<).
+func parseDependency(dep string) (dependency, error) {
+	m := depRegex.FindStringSubmatch(strings.TrimSpace(dep))
+	if m == nil {
+		return dependency{}, fmt.Errorf("invalid dependency: %q", dep)
+	}
+
+	op := m[2]
+	switch op {
+	case ">>":
+		op = ">"
+	case "<<":
+		op = "<"
+	}
+
+	return dependency{
+		Name:    m[1],
+		Op:      op,
+		Version: m[3],
+	}, nil
+}
+
+// arch returns the architecture of package in a package format (i.e. amd64 for deb and x86_64 for rpm).
+func (p Package) arch(format string) string {
+	return archs[format][p.Arch]
+}
+
+// version returns the version of package in a package format.
+// Hyphens are not allowed in deb and rpm versions (without a revision), so pre-release versions are changed to sort before the release.
+func (p Package) version(format string) string {
+	switch format {
+	case FormatAPK:
+		return strings.Replace(p.Version, "-", "_", -1)
+	default:
+		return strings.Replace(p.Version, "-", "~", -1)
+	}
+}
+
+// summary returns the first line of description.
+func (p Package) summary() string {
+	return strings.SplitN(strings.TrimSpace(p.Description), "\n", 2)[0]
+}
+
+// modTime returns the modification time for files.
+func (p Package) modTime() time.Time {
+	if p.Time.IsZero() {
+		return time.Now()
+	}
+
+	return p.Time
+}
+
+// installedSize returns the total size of files in bytes.
+func (p Package) installedSize() int64 {
+	var size int64
+	for _, f := range p.Files {
+		size += int64(len(f.Content))
+	}
+
+	return size
+}
+
+// dependencies returns the parsed package dependencies.
+func (p Package) dependencies() ([]dependency, error) {
+	deps := make([]dependency, 0, len(p.Depends))
+	for _, d := range p.Depends {
+		dep, err := parseDependency(d)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// files returns the files of package sorted by their paths.
+func (p Package) files() []File {
+	files := make([]File, len(p.Files))
+	copy(files, p.Files)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+
+	return files
+}
+
+// dirs returns the parent directories of files sorted by their paths (the root directory is not included).
+func (p Package) dirs() []string {
+	set := map[string]bool{}
+	for _, f := range p.Files {
+		for dir := path.Dir(f.Path); dir != "/"; dir = path.Dir(dir) {
+			set[dir] = true
+		}
+	}
+
+	dirs := make([]string, 0, len(set))
+	for dir := range set {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	return dirs
+}
+
+// Validate checks if the package can be built in a package format.
+func (p Package) Validate(format string) error {
+	if _, ok := archs[format]; !ok {
+		return fmt.Errorf("unknown package format: %s", format)
+	}
+
+	if !nameRegex.MatchString(p.Name) {
+		return fmt.Errorf("invalid package name: %q", p.Name)
+	}
+
+	if p.Version == "" || strings.ContainsAny(p.Version, " \t\n/") {
+		return fmt.Errorf("invalid package version: %q", p.Version)
+	}
+
+	if p.arch(format) == "" {
+		return fmt.Errorf("unsupported architecture for %s packages: %s", format, p.Arch)
+	}
+
+	if p.Maintainer == "" {
+		return errors.New("package maintainer is required")
+	}
+
+	if p.summary() == "" {
+		return errors.New("package description is required")
+	}
+
+	if _, err := p.dependencies(); err != nil {
+		return err
+	}
+
+	paths := map[string]bool{}
+	for _, f := range p.Files {
+		if !path.IsAbs(f.Path) || path.Clean(f.Path) != f.Path || f.Path == "/" {
+			return fmt.Errorf("invalid file path: %q", f.Path)
+		}
+		if paths[f.Path] {
+			return fmt.Errorf("duplicate file path: %q", f.Path)
+		}
+		paths[f.Path] = true
+	}
+
+	return nil
+}
+
+// Filename returns the conventional file name of package in a package format.
+func (p Package) Filename(format string) string {
+	switch format {
+	case FormatRPM:
+		return fmt.Sprintf("%s-%s-1.%s.rpm", p.Name, p.version(format), p.arch(format))
+	default:
+		return fmt.Sprintf("%s_%s_%s.%s", p.Name, p.version(format), p.arch(format), format)
+	}
+}
+
+// Write builds the package in a package format and writes it to w.
+func (p Package) Write(w io.Writer, format string) error {
+	if err := p.Validate(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatDeb:
+		return p.writeDeb(w)
+	case FormatRPM:
+		return p.writeRPM(w)
+	default:
+		return p.writeAPK(w)
+	}
+}
@@ -0,0 +1,173 @@
+package linuxpkg
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testPackage() Package {
+	return Package{
+		Name:        "app",
+		Version:     "0.2.0",
+		Arch:        "amd64",
+		Maintainer:  "Jane Doe <jane@example.com>",
+		Description: "The app tool\nThe app tool does many things.\n\nAnd it does them well.",
+		Homepage:    "https://github.com/username/app",
+		License:     "MIT",
+		Depends:     []string{"ca-certificates", "libc6 >= 2.28"},
+		Files: []File{
+			{Path: "/usr/bin/app", Content: []byte("binary"), Mode: 0755},
+			{Path: "/etc/app/config.yaml", Content: []byte("log: info\n"), Mode: 0644, Config: true},
+		},
+		PostInstall: "systemctl daemon-reload",
+		Time:        time.Unix(1600000000, 0),
+	}
+}
+
+func TestParseDependency(t *testing.T) {
+	tests := []struct {
+		dep                string
+		expectedDependency dependency
+		expectedError      error
+	}{
+		{"ca-certificates", dependency{Name: "ca-certificates"}, nil},
+		{"libc6 >= 2.28", dependency{Name: "libc6", Op: ">=", Version: "2.28"}, nil},
+		{"libc6 (>= 2.28)", dependency{Name: "libc6", Op: ">=", Version: "2.28"}, nil},
+		{"libc6>=2.28", dependency{Name: "libc6", Op: ">=", Version: "2.28"}, nil},
+		{"openssl (<< 3.0)", dependency{Name: "openssl", Op: "<", Version: "3.0"}, nil},
+		{"openssl > 1.1", dependency{Name: "openssl", Op: ">", Version: "1.1"}, nil},
+		{"libc6 >=", dependency{}, errors.New(`invalid dependency: "libc6 >="`)},
+		{"lib c6", dependency{}, errors.New(`invalid dependency: "lib c6"`)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.dep, func(t *testing.T) {
+			dep, err := parseDependency(tc.dep)
+
+			assert.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedDependency, dep)
+		})
+	}
+}
+
+func TestPackageValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		modify        func(*Package)
+		format        string
+		expectedError error
+	}{
+		{
+			name:          "UnknownFormat",
+			modify:        func(p *Package) {},
+			format:        "snap",
+			expectedError: errors.New("unknown package format: snap"),
+		},
+		{
+			name:          "InvalidName",
+			modify:        func(p *Package) { p.Name = "My App" },
+			format:        FormatDeb,
+			expectedError: errors.New(`invalid package name: "My App"`),
+		},
+		{
+			name:          "InvalidVersion",
+			modify:        func(p *Package) { p.Version = "" },
+			format:        FormatDeb,
+			expectedError: errors.New(`invalid package version: ""`),
+		},
+		{
+			name:          "UnsupportedArch",
+			modify:        func(p *Package) { p.Arch = "mips" },
+			format:        FormatRPM,
+			expectedError: errors.New("unsupported architecture for rpm packages: mips"),
+		},
+		{
+			name:          "NoMaintainer",
+			modify:        func(p *Package) { p.Maintainer = "" },
+			format:        FormatDeb,
+			expectedError: errors.New("package maintainer is required"),
+		},
+		{
+			name:          "NoDescription",
+			modify:        func(p *Package) { p.Description = "\n" },
+			format:        FormatAPK,
+			expectedError: errors.New("package description is required"),
+		},
+		{
+			name:          "InvalidDependency",
+			modify:        func(p *Package) { p.Depends = []string{"libc6 (>= )"} },
+			format:        FormatDeb,
+			expectedError: errors.New(`invalid dependency: "libc6 (>= )"`),
+		},
+		{
+			name:          "RelativePath",
+			modify:        func(p *Package) { p.Files[0].Path = "usr/bin/app" },
+			format:        FormatDeb,
+			expectedError: errors.New(`invalid file path: "usr/bin/app"`),
+		},
+		{
+			name:          "DuplicatePath",
+			modify:        func(p *Package) { p.Files[1].Path = "/usr/bin/app" },
+			format:        FormatDeb,
+			expectedError: errors.New(`duplicate file path: "/usr/bin/app"`),
+		},
+		{
+			name:   "Success",
+			modify: func(p *Package) {},
+			format: FormatRPM,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := testPackage()
+			tc.modify(&p)
+
+			err := p.Validate(tc.format)
+			assert.Equal(t, tc.expectedError, err)
+
+			var buf bytes.Buffer
+			err = p.Write(&buf, tc.format)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestPackageFilename(t *testing.T) {
+	tests := []struct {
+		arch             string
+		version          string
+		format           string
+		expectedFilename string
+	}{
+		{"amd64", "0.2.0", FormatDeb, "app_0.2.0_amd64.deb"},
+		{"arm", "0.2.0", FormatDeb, "app_0.2.0_armhf.deb"},
+		{"amd64", "0.2.0", FormatRPM, "app-0.2.0-1.x86_64.rpm"},
+		{"arm64", "0.3.0-rc.1", FormatRPM, "app-0.3.0~rc.1-1.aarch64.rpm"},
+		{"386", "0.2.0", FormatAPK, "app_0.2.0_x86.apk"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.expectedFilename, func(t *testing.T) {
+			p := Package{Name: "app", Version: tc.version, Arch: tc.arch}
+			assert.Equal(t, tc.expectedFilename, p.Filename(tc.format))
+		})
+	}
+}
+
+func TestPackageWriteReproducible(t *testing.T) {
+	p := testPackage()
+
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			var b1, b2 bytes.Buffer
+			assert.NoError(t, p.Write(&b1, format))
+			assert.NoError(t, p.Write(&b2, format))
+			assert.Equal(t, b1.Bytes(), b2.Bytes())
+		})
+	}
+}
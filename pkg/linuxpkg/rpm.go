@@ -0,0 +1,452 @@
+package linuxpkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RPM header data types.
+const (
+	rpmTypeInt16       = 3
+	rpmTypeInt32       = 4
+	rpmTypeString      = 6
+	rpmTypeBin         = 7
+	rpmTypeStringArray = 8
+	rpmTypeI18NString  = 9
+)
+
+// RPM signature and header tags.
+// See https://rpm-software-management.github.io/rpm/manual/format.html
+const (
+	rpmTagHeaderSignatures = 62
+	rpmTagHeaderImmutable  = 63
+	rpmTagHeaderI18NTable  = 100
+
+	rpmSigTagSHA1        = 269
+	rpmSigTagSHA256      = 273
+	rpmSigTagSize        = 1000
+	rpmSigTagMD5         = 1004
+	rpmSigTagPayloadSize = 1007
+
+	rpmTagName              = 1000
+	rpmTagVersion           = 1001
+	rpmTagRelease           = 1002
+	rpmTagSummary           = 1004
+	rpmTagDescription       = 1005
+	rpmTagBuildTime         = 1006
+	rpmTagBuildHost         = 1007
+	rpmTagSize              = 1009
+	rpmTagLicense           = 1014
+	rpmTagPackager          = 1015
+	rpmTagGroup             = 1016
+	rpmTagURL               = 1020
+	rpmTagOS                = 1021
+	rpmTagArch              = 1022
+	rpmTagPostIn            = 1024
+	rpmTagFileSizes         = 1028
+	rpmTagFileModes         = 1030
+	rpmTagFileRDevs         = 1033
+	rpmTagFileMTimes        = 1034
+	rpmTagFileDigests       = 1035
+	rpmTagFileLinkTos       = 1036
+	rpmTagFileFlags         = 1037
+	rpmTagFileUserName      = 1039
+	rpmTagFileGroupName     = 1040
+	rpmTagSourceRPM         = 1044
+	rpmTagProvideName       = 1047
+	rpmTagRequireFlags      = 1048
+	rpmTagRequireName       = 1049
+	rpmTagRequireVersion    = 1050
+	rpmTagRPMVersion        = 1064
+	rpmTagPostInProg        = 1086
+	rpmTagFileDevices       = 1095
+	rpmTagFileInodes        = 1096
+	rpmTagFileLangs         = 1097
+	rpmTagProvideFlags      = 1112
+	rpmTagProvideVersion    = 1113
+	rpmTagDirIndexes        = 1116
+	rpmTagBaseNames         = 1117
+	rpmTagDirNames          = 1118
+	rpmTagPayloadFormat     = 1124
+	rpmTagPayloadCompressor = 1125
+	rpmTagPayloadFlags      = 1126
+	rpmTagFileDigestAlgo    = 5011
+)
+
+// RPM dependency and file flags.
+const (
+	rpmSenseLess       = 1 << 1
+	rpmSenseGreater    = 1 << 2
+	rpmSenseEqual      = 1 << 3
+	rpmSenseInterp     = 1 << 8
+	rpmSenseScriptPost = 1 << 10
+	rpmSenseRPMLib     = 1 << 24
+
+	rpmFileConfig    = 1 << 0
+	rpmFileNoReplace = 1 << 4
+
+	rpmDigestSHA256 = 8
+)
+
+// rpmSenses are the RPM dependency flags for dependency operators.
+var rpmSenses = map[string]int32{
+	"":   0,
+	">=": rpmSenseGreater | rpmSenseEqual,
+	"<=": rpmSenseLess | rpmSenseEqual,
+	"=":  rpmSenseEqual,
+	">":  rpmSenseGreater,
+	"<":  rpmSenseLess,
+}
+
+// rpmEntry is an entry in an RPM header.
+type rpmEntry struct {
+	tag   int32
+	typ   int32
+	count int32
+	data  []byte
+}
+
+// rpmHeader is an RPM header structure used for both signature and header sections.
+type rpmHeader struct {
+	entries []rpmEntry
+}
+
+func (h *rpmHeader) add(tag, typ, count int32, data []byte) {
+	h.entries = append(h.entries, rpmEntry{tag: tag, typ: typ, count: count, data: data})
+}
+
+func (h *rpmHeader) addString(tag int32, s string) {
+	h.add(tag, rpmTypeString, 1, []byte(s+"\x00"))
+}
+
+func (h *rpmHeader) addI18NString(tag int32, s string) {
+	h.add(tag, rpmTypeI18NString, 1, []byte(s+"\x00"))
+}
+
+func (h *rpmHeader) addStrings(tag int32, ss []string) {
+	var buf bytes.Buffer
+	for _, s := range ss {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+	h.add(tag, rpmTypeStringArray, int32(len(ss)), buf.Bytes())
+}
+
+func (h *rpmHeader) addInt32(tag int32, vals ...int32) {
+	buf := make([]byte, 4*len(vals))
+	for i, v := range vals {
+		binary.BigEndian.PutUint32(buf[4*i:], uint32(v))
+	}
+	h.add(tag, rpmTypeInt32, int32(len(vals)), buf)
+}
+
+func (h *rpmHeader) addInt16(tag int32, vals ...uint16) {
+	buf := make([]byte, 2*len(vals))
+	for i, v := range vals {
+		binary.BigEndian.PutUint16(buf[2*i:], v)
+	}
+	h.add(tag, rpmTypeInt16, int32(len(vals)), buf)
+}
+
+func (h *rpmHeader) addBin(tag int32, data []byte) {
+	h.add(tag, rpmTypeBin, int32(len(data)), data)
+}
+
+// marshal encodes the header with a region tag (header signatures or header immutable).
+// The entries are sorted by their tags and their data is aligned to the size of their types.
+func (h *rpmHeader) marshal(regionTag int32) []byte {
+	entries := make([]rpmEntry, len(h.entries))
+	copy(entries, h.entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].tag < entries[j].tag
+	})
+
+	var index, store bytes.Buffer
+	n := int32(len(entries) + 1)
+
+	writeEntry := func(tag, typ, offset, count int32) {
+		_ = binary.Write(&index, binary.BigEndian, []int32{tag, typ, offset, count})
+	}
+
+	// The region entry comes first and points to the trailer at the end of data store
+	regionIndex := index.Len()
+	writeEntry(0, 0, 0, 0)
+
+	for _, e := range entries {
+		align := 1
+		switch e.typ {
+		case rpmTypeInt16:
+			align = 2
+		case rpmTypeInt32:
+			align = 4
+		}
+
+		for store.Len()%align != 0 {
+			store.WriteByte(0)
+		}
+
+		writeEntry(e.tag, e.typ, int32(store.Len()), e.count)
+		store.Write(e.data)
+	}
+
+	trailerOffset := int32(store.Len())
+	_ = binary.Write(&store, binary.BigEndian, []int32{regionTag, rpmTypeBin, -n * 16, 16})
+
+	idx := index.Bytes()
+	binary.BigEndian.PutUint32(idx[regionIndex:], uint32(regionTag))
+	binary.BigEndian.PutUint32(idx[regionIndex+4:], rpmTypeBin)
+	binary.BigEndian.PutUint32(idx[regionIndex+8:], uint32(trailerOffset))
+	binary.BigEndian.PutUint32(idx[regionIndex+12:], 16)
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0x8e, 0xad, 0xe8, 0x01, 0, 0, 0, 0})
+	_ = binary.Write(&buf, binary.BigEndian, []int32{n, int32(store.Len())})
+	buf.Write(idx)
+	buf.Write(store.Bytes())
+
+	return buf.Bytes()
+}
+
+// writeCPIO writes a cpio archive in new ASCII (newc) format with the given files.
+// The file names are prefixed with . as RPM expects.
+func writeCPIO(w io.Writer, mtime int64, files []File) error {
+	writeEntry := func(ino int, name string, mode uint32, data []byte) error {
+		name += "\x00"
+		header := fmt.Sprintf("070701%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x%08x",
+			ino, mode, 0, 0, 1, mtime, len(data), 0, 0, 0, 0, len(name), 0)
+
+		pad := func(n int) []byte {
+			return make([]byte, (4-n%4)%4)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(header)
+		buf.WriteString(name)
+		buf.Write(pad(len(header) + len(name)))
+		buf.Write(data)
+		buf.Write(pad(len(data)))
+
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	for i, f := range files {
+		if err := writeEntry(i+1, "."+f.Path, 0100000|uint32(f.Mode.Perm()), f.Content); err != nil {
+			return err
+		}
+	}
+
+	return writeEntry(0, "TRAILER!!!", 0, nil)
+}
+
+// writeRPM writes an rpm package consisting of lead, signature, header, and gzip-compressed cpio payload.
+func (p Package) writeRPM(w io.Writer) error {
+	mtime := p.modTime().Unix()
+	version := p.version(FormatRPM)
+	release := "1"
+	arch := p.arch(FormatRPM)
+	files := p.files()
+
+	deps, err := p.dependencies()
+	if err != nil {
+		return err
+	}
+
+	// Payload
+
+	var cpio bytes.Buffer
+	if err := writeCPIO(&cpio, mtime, files); err != nil {
+		return err
+	}
+
+	var payload bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&payload, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	if _, err := gw.Write(cpio.Bytes()); err != nil {
+		return err
+	}
+
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	// Header
+
+	h := new(rpmHeader)
+	h.addStrings(rpmTagHeaderI18NTable, []string{"C"})
+	h.addString(rpmTagName, p.Name)
+	h.addString(rpmTagVersion, version)
+	h.addString(rpmTagRelease, release)
+	h.addI18NString(rpmTagSummary, p.summary())
+	h.addI18NString(rpmTagDescription, strings.TrimSpace(p.Description))
+	h.addInt32(rpmTagBuildTime, int32(mtime))
+	h.addString(rpmTagBuildHost, "localhost")
+	h.addInt32(rpmTagSize, int32(p.installedSize()))
+	h.addString(rpmTagPackager, p.Maintainer)
+	h.addI18NString(rpmTagGroup, "Unspecified")
+	h.addString(rpmTagOS, "linux")
+	h.addString(rpmTagArch, arch)
+	h.addString(rpmTagSourceRPM, fmt.Sprintf("%s-%s-%s.src.rpm", p.Name, version, release))
+	h.addString(rpmTagRPMVersion, "4.16.1")
+	h.addString(rpmTagPayloadFormat, "cpio")
+	h.addString(rpmTagPayloadCompressor, "gzip")
+	h.addString(rpmTagPayloadFlags, "9")
+
+	if p.License != "" {
+		h.addString(rpmTagLicense, p.License)
+	}
+
+	if p.Homepage != "" {
+		h.addString(rpmTagURL, p.Homepage)
+	}
+
+	if p.PostInstall != "" {
+		h.addString(rpmTagPostIn, p.PostInstall)
+		h.addString(rpmTagPostInProg, "/bin/sh")
+	}
+
+	h.addStrings(rpmTagProvideName, []string{p.Name})
+	h.addInt32(rpmTagProvideFlags, rpmSenseEqual)
+	h.addStrings(rpmTagProvideVersion, []string{version + "-" + release})
+
+	requireNames := []string{}
+	requireFlags := []int32{}
+	requireVersions := []string{}
+
+	for _, d := range deps {
+		requireNames = append(requireNames, d.Name)
+		requireFlags = append(requireFlags, rpmSenses[d.Op])
+		requireVersions = append(requireVersions, d.Version)
+	}
+
+	if p.PostInstall != "" {
+		requireNames = append(requireNames, "/bin/sh")
+		requireFlags = append(requireFlags, rpmSenseInterp|rpmSenseScriptPost)
+		requireVersions = append(requireVersions, "")
+	}
+
+	for _, lib := range [][2]string{
+		{"rpmlib(CompressedFileNames)", "3.0.4-1"},
+		{"rpmlib(FileDigests)", "4.6.0-1"},
+		{"rpmlib(PayloadFilesHavePrefix)", "4.0-1"},
+	} {
+		requireNames = append(requireNames, lib[0])
+		requireFlags = append(requireFlags, rpmSenseLess|rpmSenseEqual|rpmSenseRPMLib)
+		requireVersions = append(requireVersions, lib[1])
+	}
+
+	h.addStrings(rpmTagRequireName, requireNames)
+	h.addInt32(rpmTagRequireFlags, requireFlags...)
+	h.addStrings(rpmTagRequireVersion, requireVersions)
+
+	if len(files) > 0 {
+		var (
+			sizes, mtimes, flags, devices, inodes, dirIndexes []int32
+			modes, rdevs                                      []uint16
+			digests, linkTos, users, groups, langs, baseNames []string
+			dirNames                                          []string
+		)
+
+		dirIndex := map[string]int32{}
+		for i, f := range files {
+			dir := path.Dir(f.Path) + "/"
+			if _, ok := dirIndex[dir]; !ok {
+				dirIndex[dir] = int32(len(dirNames))
+				dirNames = append(dirNames, dir)
+			}
+
+			var flag int32
+			if f.Config {
+				flag = rpmFileConfig | rpmFileNoReplace
+			}
+
+			sizes = append(sizes, int32(len(f.Content)))
+			modes = append(modes, uint16(0100000|f.Mode.Perm()))
+			rdevs = append(rdevs, 0)
+			mtimes = append(mtimes, int32(mtime))
+			digests = append(digests, fmt.Sprintf("%x", sha256.Sum256(f.Content)))
+			linkTos = append(linkTos, "")
+			flags = append(flags, flag)
+			users = append(users, "root")
+			groups = append(groups, "root")
+			devices = append(devices, 1)
+			inodes = append(inodes, int32(i+1))
+			langs = append(langs, "")
+			dirIndexes = append(dirIndexes, dirIndex[dir])
+			baseNames = append(baseNames, path.Base(f.Path))
+		}
+
+		h.addInt32(rpmTagFileSizes, sizes...)
+		h.addInt16(rpmTagFileModes, modes...)
+		h.addInt16(rpmTagFileRDevs, rdevs...)
+		h.addInt32(rpmTagFileMTimes, mtimes...)
+		h.addStrings(rpmTagFileDigests, digests)
+		h.addStrings(rpmTagFileLinkTos, linkTos)
+		h.addInt32(rpmTagFileFlags, flags...)
+		h.addStrings(rpmTagFileUserName, users)
+		h.addStrings(rpmTagFileGroupName, groups)
+		h.addInt32(rpmTagFileDevices, devices...)
+		h.addInt32(rpmTagFileInodes, inodes...)
+		h.addStrings(rpmTagFileLangs, langs)
+		h.addInt32(rpmTagDirIndexes, dirIndexes...)
+		h.addStrings(rpmTagBaseNames, baseNames)
+		h.addStrings(rpmTagDirNames, dirNames)
+		h.addInt32(rpmTagFileDigestAlgo, rpmDigestSHA256)
+	}
+
+	header := h.marshal(rpmTagHeaderImmutable)
+
+	// Signature
+
+	md5sum := md5.New()
+	md5sum.Write(header)
+	md5sum.Write(payload.Bytes())
+
+	sig := new(rpmHeader)
+	sig.addInt32(rpmSigTagSize, int32(len(header)+payload.Len()))
+	sig.addBin(rpmSigTagMD5, md5sum.Sum(nil))
+	sig.addString(rpmSigTagSHA1, fmt.Sprintf("%x", sha1.Sum(header)))
+	sig.addString(rpmSigTagSHA256, fmt.Sprintf("%x", sha256.Sum256(header)))
+	sig.addInt32(rpmSigTagPayloadSize, int32(cpio.Len()))
+
+	signature := sig.marshal(rpmTagHeaderSignatures)
+
+	// The signature is padded to a multiple of 8 bytes
+	if n := len(signature) % 8; n != 0 {
+		signature = append(signature, make([]byte, 8-n)...)
+	}
+
+	// Lead
+
+	lead := make([]byte, 96)
+	copy(lead, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0})
+	binary.BigEndian.PutUint16(lead[6:], 0) // binary package
+	binary.BigEndian.PutUint16(lead[8:], 1)
+	name := fmt.Sprintf("%s-%s-%s", p.Name, version, release)
+	if len(name) > 65 {
+		name = name[:65]
+	}
+	copy(lead[10:76], name)
+	binary.BigEndian.PutUint16(lead[76:], 1) // linux
+	binary.BigEndian.PutUint16(lead[78:], 5) // header-style signature
+
+	for _, b := range [][]byte{lead, signature, header, payload.Bytes()} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
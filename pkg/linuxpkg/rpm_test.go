@@ -0,0 +1,163 @@
+package linuxpkg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readRPMHeader reads an rpm header structure and returns its entries by tag and its length.
+func readRPMHeader(t *testing.T, data []byte) (map[int32]rpmEntry, int) {
+	assert.Equal(t, []byte{0x8e, 0xad, 0xe8, 0x01}, data[:4])
+
+	n := int(binary.BigEndian.Uint32(data[8:]))
+	size := int(binary.BigEndian.Uint32(data[12:]))
+	index := data[16 : 16+16*n]
+	store := data[16+16*n : 16+16*n+size]
+
+	entries := map[int32]rpmEntry{}
+	prevTag := int32(0)
+	for i := 0; i < n; i++ {
+		tag := int32(binary.BigEndian.Uint32(index[16*i:]))
+		typ := int32(binary.BigEndian.Uint32(index[16*i+4:]))
+		offset := int(binary.BigEndian.Uint32(index[16*i+8:]))
+		count := int32(binary.BigEndian.Uint32(index[16*i+12:]))
+
+		// The region entry comes first and the rest are sorted by tag
+		if i > 0 {
+			assert.True(t, tag > prevTag, "tag %d is out of order", tag)
+		}
+		prevTag = tag
+
+		var end int
+		switch typ {
+		case rpmTypeInt16:
+			assert.Equal(t, 0, offset%2)
+			end = offset + 2*int(count)
+		case rpmTypeInt32:
+			assert.Equal(t, 0, offset%4)
+			end = offset + 4*int(count)
+		case rpmTypeBin:
+			end = offset + int(count)
+		default:
+			end = offset
+			for c := int32(0); c < count; c++ {
+				end += bytes.IndexByte(store[end:], 0) + 1
+			}
+		}
+
+		entries[tag] = rpmEntry{tag: tag, typ: typ, count: count, data: store[offset:end]}
+	}
+
+	return entries, 16 + 16*n + size
+}
+
+func rpmString(e rpmEntry) string {
+	return strings.TrimSuffix(string(e.data), "\x00")
+}
+
+func rpmStrings(e rpmEntry) []string {
+	return strings.Split(strings.TrimSuffix(string(e.data), "\x00"), "\x00")
+}
+
+func rpmInt32s(e rpmEntry) []int32 {
+	vals := make([]int32, e.count)
+	for i := range vals {
+		vals[i] = int32(binary.BigEndian.Uint32(e.data[4*i:]))
+	}
+	return vals
+}
+
+// readCPIO reads the files of a cpio archive in new ASCII format.
+func readCPIO(t *testing.T, data []byte) ([]string, map[string]string) {
+	names := []string{}
+	contents := map[string]string{}
+
+	align := func(n int) int {
+		return (n + 3) &^ 3
+	}
+
+	for {
+		assert.Equal(t, "070701", string(data[:6]))
+		field := func(i int) int {
+			v, err := strconv.ParseUint(string(data[6+8*i:14+8*i]), 16, 32)
+			assert.NoError(t, err)
+			return int(v)
+		}
+
+		size, namesize := field(6), field(11)
+		name := string(data[110 : 110+namesize-1])
+		if name == "TRAILER!!!" {
+			break
+		}
+
+		start := align(110 + namesize)
+		names = append(names, name)
+		contents[name] = string(data[start : start+size])
+		data = data[align(start+size):]
+	}
+
+	return names, contents
+}
+
+func TestPackageWriteRPM(t *testing.T) {
+	p := testPackage()
+
+	var buf bytes.Buffer
+	assert.NoError(t, p.Write(&buf, FormatRPM))
+	data := buf.Bytes()
+
+	// Lead
+	assert.Equal(t, []byte{0xed, 0xab, 0xee, 0xdb, 3, 0}, data[:6])
+	assert.Equal(t, "app-0.2.0-1", string(bytes.TrimRight(data[10:76], "\x00")))
+
+	// Signature
+	sig, n := readRPMHeader(t, data[96:])
+	sigLen := (n + 7) &^ 7
+	assert.Contains(t, sig, int32(rpmTagHeaderSignatures))
+
+	// Header
+	header, n := readRPMHeader(t, data[96+sigLen:])
+	headerBytes := data[96+sigLen : 96+sigLen+n]
+	payload := data[96+sigLen+n:]
+
+	md5sum := md5.Sum(data[96+sigLen:])
+	assert.Equal(t, md5sum[:], sig[rpmSigTagMD5].data)
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(headerBytes)), rpmString(sig[rpmSigTagSHA256]))
+	assert.Equal(t, []int32{int32(len(headerBytes) + len(payload))}, rpmInt32s(sig[rpmSigTagSize]))
+
+	assert.Contains(t, header, int32(rpmTagHeaderImmutable))
+	assert.Equal(t, "app", rpmString(header[rpmTagName]))
+	assert.Equal(t, "0.2.0", rpmString(header[rpmTagVersion]))
+	assert.Equal(t, "1", rpmString(header[rpmTagRelease]))
+	assert.Equal(t, "x86_64", rpmString(header[rpmTagArch]))
+	assert.Equal(t, "The app tool", rpmString(header[rpmTagSummary]))
+	assert.Equal(t, "MIT", rpmString(header[rpmTagLicense]))
+	assert.Equal(t, "https://github.com/username/app", rpmString(header[rpmTagURL]))
+	assert.Equal(t, "systemctl daemon-reload", rpmString(header[rpmTagPostIn]))
+	assert.Equal(t, []string{"ca-certificates", "libc6", "/bin/sh", "rpmlib(CompressedFileNames)", "rpmlib(FileDigests)", "rpmlib(PayloadFilesHavePrefix)"}, rpmStrings(header[rpmTagRequireName]))
+	assert.Equal(t, []string{"", "2.28", "", "3.0.4-1", "4.6.0-1", "4.0-1"}, rpmStrings(header[rpmTagRequireVersion]))
+	assert.Equal(t, []string{"config.yaml", "app"}, rpmStrings(header[rpmTagBaseNames]))
+	assert.Equal(t, []string{"/etc/app/", "/usr/bin/"}, rpmStrings(header[rpmTagDirNames]))
+	assert.Equal(t, []int32{rpmFileConfig | rpmFileNoReplace, 0}, rpmInt32s(header[rpmTagFileFlags]))
+
+	// Payload
+	gr, err := gzip.NewReader(bytes.NewReader(payload))
+	assert.NoError(t, err)
+	cpio, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+
+	names, contents := readCPIO(t, cpio)
+	assert.Equal(t, []string{"./etc/app/config.yaml", "./usr/bin/app"}, names)
+	assert.Equal(t, "binary", contents["./usr/bin/app"])
+	assert.Equal(t, []int32{int32(len(cpio))}, rpmInt32s(sig[rpmSigTagPayloadSize]))
+}
@@ -0,0 +1,499 @@
+// Package ociimage builds minimal multi-platform container images in OCI format without a container runtime.
+// Every image has a single layer with the given files on top of an empty filesystem (FROM scratch).
+// See https://github.com/opencontainers/image-spec/blob/main/spec.md
+package ociimage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Media types of OCI content.
+const (
+	MediaTypeImageIndex    = "application/vnd.oci.image.index.v1+json"
+	MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeImageConfig   = "application/vnd.oci.image.config.v1+json"
+	MediaTypeImageLayer    = "application/vnd.oci.image.layer.v1.tar+gzip"
+)
+
+// Pre-defined annotation keys.
+// See https://github.com/opencontainers/image-spec/blob/main/annotations.md
+const (
+	AnnotationCreated  = "org.opencontainers.image.created"
+	AnnotationSource   = "org.opencontainers.image.source"
+	AnnotationVersion  = "org.opencontainers.image.version"
+	AnnotationRevision = "org.opencontainers.image.revision"
+	AnnotationRefName  = "org.opencontainers.image.ref.name"
+)
+
+// variants are the default CPU variants of Go architectures.
+var variants = map[string]string{
+	"arm":   "v7",
+	"arm64": "v8",
+}
+
+// architectures are the Go architectures supported for images.
+var architectures = map[string]bool{
+	"386":     true,
+	"amd64":   true,
+	"arm":     true,
+	"arm64":   true,
+	"ppc64le": true,
+	"s390x":   true,
+	"riscv64": true,
+}
+
+// Platform is the platform an image runs on.
+type Platform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// LinuxPlatform returns the platform of a linux image for a Go architecture.
+func LinuxPlatform(arch string) (Platform, bool) {
+	if !architectures[arch] {
+		return Platform{}, false
+	}
+
+	return Platform{
+		Architecture: arch,
+		OS:           "linux",
+		Variant:      variants[arch],
+	}, true
+}
+
+// String returns the platform in os/arch[/variant] format.
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Descriptor describes a content addressable blob.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *Platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an image manifest for a single platform.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Index is an image index pointing to the manifests of an image for different platforms.
+type Index struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Manifests     []Descriptor      `json:"manifests"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Config is the execution parameters of a container.
+type Config struct {
+	User         string              `json:"User,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+}
+
+// imageConfig is the configuration of an image.
+// See https://github.com/opencontainers/image-spec/blob/main/config.md
+type imageConfig struct {
+	Created      string    `json:"created"`
+	Architecture string    `json:"architecture"`
+	OS           string    `json:"os"`
+	Variant      string    `json:"variant,omitempty"`
+	Config       Config    `json:"config"`
+	RootFS       rootFS    `json:"rootfs"`
+	History      []history `json:"history"`
+}
+
+type rootFS struct {
+	Type    string   `json:"type"`
+	DiffIDs []string `json:"diff_ids"`
+}
+
+type history struct {
+	Created   string `json:"created"`
+	CreatedBy string `json:"created_by"`
+}
+
+// File is a file in the filesystem of an image.
+type File struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+}
+
+// Image is an image for a single platform.
+type Image struct {
+	Platform Platform
+	Config   Config
+	Files    []File
+}
+
+// Bundle is a multi-platform image consisting of an image index and the manifests, configs, and layers it points to.
+type Bundle struct {
+	// Index is the descriptor of image index.
+	Index Descriptor
+	// Manifests are the descriptors of image manifests in the order of images.
+	Manifests []Descriptor
+	// Blobs are the contents of index, manifests, configs, and layers by their digests.
+	Blobs map[string][]byte
+	// Created is the creation time of images.
+	Created time.Time
+}
+
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// add adds a blob to the bundle and returns its descriptor.
+func (b *Bundle) add(mediaType string, data []byte) Descriptor {
+	d := Descriptor{
+		MediaType: mediaType,
+		Digest:    digest(data),
+		Size:      int64(len(data)),
+	}
+
+	b.Blobs[d.Digest] = data
+
+	return d
+}
+
+// addJSON encodes v as JSON and adds it to the bundle.
+func (b *Bundle) addJSON(mediaType string, v interface{}) (Descriptor, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return Descriptor{}, err
+	}
+
+	return b.add(mediaType, data), nil
+}
+
+// Manifest returns the image manifest of a descriptor in the bundle.
+func (b *Bundle) Manifest(d Descriptor) (Manifest, error) {
+	var m Manifest
+	data, ok := b.Blobs[d.Digest]
+	if !ok {
+		return m, fmt.Errorf("blob not found: %s", d.Digest)
+	}
+
+	err := json.Unmarshal(data, &m)
+	return m, err
+}
+
+// layer returns the uncompressed tar archive for the files of an image.
+// Parent directories are added and the entries are sorted, so the same files always result in the same layer.
+func layer(files []File, mtime time.Time) ([]byte, error) {
+	sorted := make([]File, len(files))
+	copy(sorted, files)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	dirs := map[string]bool{}
+	for _, f := range sorted {
+		for dir := path.Dir(f.Path); dir != "/"; dir = path.Dir(dir) {
+			dirs[dir] = true
+		}
+	}
+
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	for _, dir := range names {
+		h := &tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     strings.TrimPrefix(dir, "/") + "/",
+			Mode:     0755,
+			ModTime:  mtime,
+			Format:   tar.FormatPAX,
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range sorted {
+		mode := f.Mode.Perm()
+		if mode == 0 {
+			mode = 0644
+		}
+
+		h := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     strings.TrimPrefix(f.Path, "/"),
+			Mode:     int64(mode),
+			Size:     int64(len(f.Content)),
+			ModTime:  mtime,
+			Format:   tar.FormatPAX,
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			return nil, err
+		}
+
+		if _, err := tw.Write(f.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := gw.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// validate makes sure the images can be built into a bundle.
+func validate(images []Image) error {
+	if len(images) == 0 {
+		return errors.New("no image to build")
+	}
+
+	platforms := map[string]bool{}
+	for _, img := range images {
+		p := img.Platform.String()
+		if img.Platform.OS == "" || img.Platform.Architecture == "" {
+			return fmt.Errorf("invalid image platform: %s", p)
+		}
+
+		if platforms[p] {
+			return fmt.Errorf("duplicate image platform: %s", p)
+		}
+		platforms[p] = true
+
+		paths := map[string]bool{}
+		for _, f := range img.Files {
+			if !path.IsAbs(f.Path) || path.Clean(f.Path) != f.Path || f.Path == "/" {
+				return fmt.Errorf("invalid file path: %q", f.Path)
+			}
+
+			if paths[f.Path] {
+				return fmt.Errorf("duplicate file path: %q", f.Path)
+			}
+			paths[f.Path] = true
+		}
+	}
+
+	return nil
+}
+
+// Build builds a multi-platform image from images for different platforms.
+// The annotations are added to the index and the manifests.
+// If created is zero, the current time is used.
+func Build(images []Image, created time.Time, annotations map[string]string) (*Bundle, error) {
+	if err := validate(images); err != nil {
+		return nil, err
+	}
+
+	if created.IsZero() {
+		created = time.Now()
+	}
+
+	created = created.UTC().Truncate(time.Second)
+	timestamp := created.Format(time.RFC3339)
+
+	b := &Bundle{
+		Manifests: []Descriptor{},
+		Blobs:     map[string][]byte{},
+		Created:   created,
+	}
+
+	for _, img := range images {
+		tarData, err := layer(img.Files, created)
+		if err != nil {
+			return nil, err
+		}
+
+		gzData, err := gzipData(tarData)
+		if err != nil {
+			return nil, err
+		}
+
+		layerDesc := b.add(MediaTypeImageLayer, gzData)
+
+		config, err := b.addJSON(MediaTypeImageConfig, imageConfig{
+			Created:      timestamp,
+			Architecture: img.Platform.Architecture,
+			OS:           img.Platform.OS,
+			Variant:      img.Platform.Variant,
+			Config:       img.Config,
+			RootFS: rootFS{
+				Type:    "layers",
+				DiffIDs: []string{digest(tarData)},
+			},
+			History: []history{
+				{Created: timestamp, CreatedBy: "cherry"},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		manifest, err := b.addJSON(MediaTypeImageManifest, Manifest{
+			SchemaVersion: 2,
+			MediaType:     MediaTypeImageManifest,
+			Config:        config,
+			Layers:        []Descriptor{layerDesc},
+			Annotations:   annotations,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		platform := img.Platform
+		manifest.Platform = &platform
+		b.Manifests = append(b.Manifests, manifest)
+	}
+
+	index, err := b.addJSON(MediaTypeImageIndex, Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+		Manifests:     b.Manifests,
+		Annotations:   annotations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.Index = index
+
+	return b, nil
+}
+
+// WriteLayout writes the bundle as a tar archive in OCI image layout format.
+// The image index is referenced by every tag in index.json of the layout.
+// See https://github.com/opencontainers/image-spec/blob/main/image-layout.md
+func (b *Bundle) WriteLayout(w io.Writer, tags []string) error {
+	refs := []Descriptor{}
+	for _, tag := range tags {
+		d := b.Index
+		d.Annotations = map[string]string{AnnotationRefName: tag}
+		refs = append(refs, d)
+	}
+
+	if len(refs) == 0 {
+		refs = append(refs, b.Index)
+	}
+
+	indexJSON, err := json.Marshal(Index{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageIndex,
+		Manifests:     refs,
+	})
+	if err != nil {
+		return err
+	}
+
+	digests := make([]string, 0, len(b.Blobs))
+	for d := range b.Blobs {
+		digests = append(digests, d)
+	}
+	sort.Strings(digests)
+
+	// The creation time is used for all entries, so the same bundle always results in the same archive
+	mtime := b.Created
+
+	tw := tar.NewWriter(w)
+
+	writeFile := func(name string, data []byte) error {
+		h := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(data)),
+			ModTime:  mtime,
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+
+		_, err := tw.Write(data)
+		return err
+	}
+
+	writeDir := func(name string) error {
+		return tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     name,
+			Mode:     0755,
+			ModTime:  mtime,
+		})
+	}
+
+	if err := writeFile("oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`)); err != nil {
+		return err
+	}
+
+	if err := writeFile("index.json", indexJSON); err != nil {
+		return err
+	}
+
+	if err := writeDir("blobs/"); err != nil {
+		return err
+	}
+
+	if err := writeDir("blobs/sha256/"); err != nil {
+		return err
+	}
+
+	for _, d := range digests {
+		if err := writeFile("blobs/sha256/"+strings.TrimPrefix(d, "sha256:"), b.Blobs[d]); err != nil {
+			return err
+		}
+	}
+
+	return tw.Close()
+}
@@ -0,0 +1,253 @@
+package ociimage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testImages() []Image {
+	config := Config{
+		User:       "65534:65534",
+		Entrypoint: []string{"/usr/local/bin/app"},
+	}
+
+	return []Image{
+		{
+			Platform: Platform{Architecture: "amd64", OS: "linux"},
+			Config:   config,
+			Files: []File{
+				{Path: "/usr/local/bin/app", Content: []byte("amd64"), Mode: 0755},
+				{Path: "/etc/app/config.yaml", Content: []byte("log: info\n")},
+			},
+		},
+		{
+			Platform: Platform{Architecture: "arm64", OS: "linux", Variant: "v8"},
+			Config:   config,
+			Files: []File{
+				{Path: "/usr/local/bin/app", Content: []byte("arm64"), Mode: 0755},
+				{Path: "/etc/app/config.yaml", Content: []byte("log: info\n")},
+			},
+		},
+	}
+}
+
+// readTar reads the entries of a tar archive.
+func readTar(t *testing.T, r io.Reader) ([]string, map[string]*tar.Header, map[string][]byte) {
+	names := []string{}
+	headers := map[string]*tar.Header{}
+	contents := map[string][]byte{}
+
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+
+		data, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+
+		names = append(names, h.Name)
+		headers[h.Name] = h
+		contents[h.Name] = data
+	}
+
+	return names, headers, contents
+}
+
+func TestLinuxPlatform(t *testing.T) {
+	tests := []struct {
+		arch             string
+		expectedPlatform Platform
+		expectedOK       bool
+		expectedString   string
+	}{
+		{"amd64", Platform{Architecture: "amd64", OS: "linux"}, true, "linux/amd64"},
+		{"arm64", Platform{Architecture: "arm64", OS: "linux", Variant: "v8"}, true, "linux/arm64/v8"},
+		{"arm", Platform{Architecture: "arm", OS: "linux", Variant: "v7"}, true, "linux/arm/v7"},
+		{"mips", Platform{}, false, "/"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.arch, func(t *testing.T) {
+			platform, ok := LinuxPlatform(tc.arch)
+
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedPlatform, platform)
+			assert.Equal(t, tc.expectedString, platform.String())
+		})
+	}
+}
+
+func TestBuildError(t *testing.T) {
+	tests := []struct {
+		name          string
+		images        []Image
+		expectedError error
+	}{
+		{
+			name:          "NoImage",
+			images:        nil,
+			expectedError: errors.New("no image to build"),
+		},
+		{
+			name:          "InvalidPlatform",
+			images:        []Image{{Platform: Platform{OS: "linux"}}},
+			expectedError: errors.New("invalid image platform: linux/"),
+		},
+		{
+			name: "DuplicatePlatform",
+			images: []Image{
+				{Platform: Platform{Architecture: "amd64", OS: "linux"}},
+				{Platform: Platform{Architecture: "amd64", OS: "linux"}},
+			},
+			expectedError: errors.New("duplicate image platform: linux/amd64"),
+		},
+		{
+			name: "RelativePath",
+			images: []Image{
+				{Platform: Platform{Architecture: "amd64", OS: "linux"}, Files: []File{{Path: "bin/app"}}},
+			},
+			expectedError: errors.New(`invalid file path: "bin/app"`),
+		},
+		{
+			name: "DuplicatePath",
+			images: []Image{
+				{Platform: Platform{Architecture: "amd64", OS: "linux"}, Files: []File{{Path: "/app"}, {Path: "/app"}}},
+			},
+			expectedError: errors.New(`duplicate file path: "/app"`),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle, err := Build(tc.images, time.Time{}, nil)
+
+			assert.Nil(t, bundle)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	created := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	annotations := map[string]string{AnnotationVersion: "0.2.0"}
+
+	bundle, err := Build(testImages(), created, annotations)
+	assert.NoError(t, err)
+
+	// Every blob is content addressable
+	for d, data := range bundle.Blobs {
+		assert.Equal(t, d, digest(data))
+	}
+
+	var index Index
+	assert.NoError(t, json.Unmarshal(bundle.Blobs[bundle.Index.Digest], &index))
+	assert.Equal(t, MediaTypeImageIndex, bundle.Index.MediaType)
+	assert.Equal(t, int64(len(bundle.Blobs[bundle.Index.Digest])), bundle.Index.Size)
+	assert.Equal(t, 2, index.SchemaVersion)
+	assert.Equal(t, annotations, index.Annotations)
+	assert.Equal(t, bundle.Manifests, index.Manifests)
+	assert.Len(t, index.Manifests, 2)
+	assert.Equal(t, &Platform{Architecture: "amd64", OS: "linux"}, index.Manifests[0].Platform)
+	assert.Equal(t, &Platform{Architecture: "arm64", OS: "linux", Variant: "v8"}, index.Manifests[1].Platform)
+
+	for i, d := range index.Manifests {
+		manifest, err := bundle.Manifest(d)
+		assert.NoError(t, err)
+		assert.Equal(t, MediaTypeImageManifest, manifest.MediaType)
+		assert.Equal(t, annotations, manifest.Annotations)
+		assert.Len(t, manifest.Layers, 1)
+
+		var config imageConfig
+		assert.NoError(t, json.Unmarshal(bundle.Blobs[manifest.Config.Digest], &config))
+		assert.Equal(t, "2020-09-13T12:26:40Z", config.Created)
+		assert.Equal(t, d.Platform.Architecture, config.Architecture)
+		assert.Equal(t, d.Platform.Variant, config.Variant)
+		assert.Equal(t, "linux", config.OS)
+		assert.Equal(t, []string{"/usr/local/bin/app"}, config.Config.Entrypoint)
+		assert.Equal(t, "65534:65534", config.Config.User)
+
+		// The diff id is the digest of uncompressed layer
+		gr, err := gzip.NewReader(bytes.NewReader(bundle.Blobs[manifest.Layers[0].Digest]))
+		assert.NoError(t, err)
+		tarData, err := ioutil.ReadAll(gr)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{digest(tarData)}, config.RootFS.DiffIDs)
+
+		names, headers, contents := readTar(t, bytes.NewReader(tarData))
+		assert.Equal(t, []string{"etc/", "etc/app/", "usr/", "usr/local/", "usr/local/bin/", "etc/app/config.yaml", "usr/local/bin/app"}, names)
+		assert.Equal(t, testImages()[i].Files[0].Content, contents["usr/local/bin/app"])
+		assert.Equal(t, int64(0755), headers["usr/local/bin/app"].Mode)
+		assert.Equal(t, int64(0644), headers["etc/app/config.yaml"].Mode)
+		assert.Equal(t, created, headers["usr/local/bin/app"].ModTime.UTC())
+	}
+
+	_, err = bundle.Manifest(Descriptor{Digest: "sha256:0000"})
+	assert.EqualError(t, err, "blob not found: sha256:0000")
+
+	// The same images always result in the same image
+	again, err := Build(testImages(), created, annotations)
+	assert.NoError(t, err)
+	assert.Equal(t, bundle.Index, again.Index)
+}
+
+func TestBundleWriteLayout(t *testing.T) {
+	created := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	bundle, err := Build(testImages(), created, nil)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		tags         []string
+		expectedRefs []string
+	}{
+		{
+			name:         "NoTag",
+			tags:         nil,
+			expectedRefs: []string{""},
+		},
+		{
+			name:         "Tags",
+			tags:         []string{"0.2.0", "latest"},
+			expectedRefs: []string{"0.2.0", "latest"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, bundle.WriteLayout(&buf, tc.tags))
+
+			names, _, contents := readTar(t, &buf)
+			assert.Equal(t, []string{"oci-layout", "index.json", "blobs/", "blobs/sha256/"}, names[:4])
+			assert.Len(t, names, 4+len(bundle.Blobs))
+			assert.JSONEq(t, `{"imageLayoutVersion":"1.0.0"}`, string(contents["oci-layout"]))
+
+			for d, data := range bundle.Blobs {
+				assert.Equal(t, data, contents["blobs/sha256/"+d[len("sha256:"):]])
+			}
+
+			var index Index
+			assert.NoError(t, json.Unmarshal(contents["index.json"], &index))
+
+			refs := []string{}
+			for _, m := range index.Manifests {
+				assert.Equal(t, bundle.Index.Digest, m.Digest)
+				assert.Equal(t, MediaTypeImageIndex, m.MediaType)
+				refs = append(refs, m.Annotations[AnnotationRefName])
+			}
+			assert.Equal(t, tc.expectedRefs, refs)
+		})
+	}
+}